@@ -144,16 +144,26 @@ func getKubeConfig(_ context.Context, cfg *config2.Config) (*kubernetes.Clientse
 	return kubeClient, kubecfg, err
 }
 
-func sharedInformerOptions(cfg *config2.Config) []informers.SharedInformerOption {
+func sharedInformerOptions(cfg *config2.Config) ([]informers.SharedInformerOption, error) {
+	selector := controller.IgnoreCompletedWorkflowsLabelSelector()
+	// When sharded, only list the workflows owned by this replica
+	shardSelector, err := controller.NewShardLabelSelector(cfg.Sharding)
+	if err != nil {
+		return nil, err
+	}
+	if shardSelector != nil {
+		selector.MatchExpressions = append(selector.MatchExpressions, shardSelector.MatchExpressions...)
+	}
+
 	opts := []informers.SharedInformerOption{
 		informers.WithTweakListOptions(func(options *v1.ListOptions) {
-			options.LabelSelector = v1.FormatLabelSelector(controller.IgnoreCompletedWorkflowsLabelSelector())
+			options.LabelSelector = v1.FormatLabelSelector(selector)
 		}),
 	}
 	if cfg.LimitNamespace != defaultNamespace {
 		opts = append(opts, informers.WithNamespace(cfg.LimitNamespace))
 	}
-	return opts
+	return opts, nil
 }
 
 func safeMetricName(original string) string {
@@ -177,7 +187,10 @@ func executeRootCmd(cfg *config2.Config) {
 		logger.Fatalf(ctx, "Error building example clientset: %s", err.Error())
 	}
 
-	opts := sharedInformerOptions(cfg)
+	opts, err := sharedInformerOptions(cfg)
+	if err != nil {
+		logger.Fatalf(ctx, "Error building informer options: %s", err.Error())
+	}
 	flyteworkflowInformerFactory := informers.NewSharedInformerFactoryWithOptions(flyteworkflowClient, cfg.WorkflowReEval.Duration, opts...)
 
 	// Add the propeller subscope because the MetricsPrefix only has "flyte:" to get uniform collection of metrics.
@@ -9,10 +9,17 @@ import (
 	"github.com/lyft/flytepropeller/pkg/compiler"
 	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	compilerErrors "github.com/lyft/flytepropeller/pkg/compiler/errors"
+	"github.com/lyft/flytepropeller/pkg/visualize"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+const (
+	graphFormatDot     = "dot"
+	graphFormatMermaid = "mermaid"
+	graphFormatJSON    = "json"
+)
+
 type CompileOpts struct {
 	*RootOptions
 	inputFormat     format
@@ -20,6 +27,8 @@ type CompileOpts struct {
 	protoFile       string
 	outputPath      string
 	dumpClosureYaml bool
+	graphFormat     string
+	graphOutputPath string
 }
 
 func NewCompileCommand(opts *RootOptions) *cobra.Command {
@@ -49,6 +58,8 @@ func NewCompileCommand(opts *RootOptions) *cobra.Command {
 	compileCmd.Flags().StringVarP(&compileOpts.outputPath, "output-file", "o", "", "Path of the generated output file.")
 	compileCmd.Flags().StringVarP(&compileOpts.outputFormat, "output-format", "m", formatProto, "Format of the generated file. Supported formats: proto (default), json, yaml")
 	compileCmd.Flags().BoolVarP(&compileOpts.dumpClosureYaml, "dump-closure-yaml", "d", false, "Compiles and transforms, but does not create a workflow. OutputsRef ts to STDOUT.")
+	compileCmd.Flags().StringVarP(&compileOpts.graphFormat, "graph-format", "g", "", "Also exports the graph of the compiled workflow. Supported formats: dot, mermaid, json")
+	compileCmd.Flags().StringVar(&compileOpts.graphOutputPath, "graph-output-file", "", "Path of the exported graph file. Required with --graph-format.")
 
 	return compileCmd
 }
@@ -57,6 +68,11 @@ func (c *CompileOpts) compileWorkflowCmd() error {
 	if c.protoFile == "" {
 		return errors.Errorf("Input file not specified")
 	}
+
+	// The closure and the progress messages are printed to STDOUT, the graph can only be exported to a file
+	if c.graphFormat != "" && c.graphOutputPath == "" {
+		return errors.Errorf("Graph output file not specified, --graph-output-file is required with --graph-format")
+	}
 	fmt.Printf("Received protofiles : [%v].\n", c.protoFile)
 
 	rawWf, err := ioutil.ReadFile(c.protoFile)
@@ -99,8 +115,37 @@ func (c *CompileOpts) compileWorkflowCmd() error {
 	}
 
 	if c.outputPath != "" {
-		return ioutil.WriteFile(c.outputPath, o, os.ModePerm)
+		if err := ioutil.WriteFile(c.outputPath, o, os.ModePerm); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%v", string(o))
+	}
+
+	if c.graphFormat != "" {
+		return c.exportGraph(compileWfClosure.Primary)
 	}
-	fmt.Printf("%v", string(o))
 	return nil
 }
+
+func exportGraph(wf *core.CompiledWorkflow, graphFormat string) ([]byte, error) {
+	switch graphFormat {
+	case graphFormatDot:
+		return []byte(visualize.ToGraphViz(wf)), nil
+	case graphFormatMermaid:
+		return []byte(visualize.ToMermaid(wf)), nil
+	case graphFormatJSON:
+		return visualize.ToJSON(wf)
+	}
+	return nil, errors.Errorf("Unknown graph format [%v]. Supported formats: %v, %v, %v", graphFormat,
+		graphFormatDot, graphFormatMermaid, graphFormatJSON)
+}
+
+func (c *CompileOpts) exportGraph(wf *core.CompiledWorkflow) error {
+	g, err := exportGraph(wf, c.graphFormat)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.graphOutputPath, g, os.ModePerm)
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"strings"
 
 	"github.com/ghodss/yaml"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytepropeller/pkg/compiler"
 	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	compilerErrors "github.com/lyft/flytepropeller/pkg/compiler/errors"
@@ -21,11 +23,15 @@ import (
 )
 
 const (
-	protofileKey   = "proto-path"
-	formatKey      = "format"
-	executionIDKey = "execution-id"
-	inputsKey      = "input-path"
-	annotationsKey = "annotations"
+	protofileKey    = "proto-path"
+	formatKey       = "format"
+	executionIDKey  = "execution-id"
+	inputsKey       = "input-path"
+	annotationsKey  = "annotations"
+	triggerRulesKey = "trigger-rules"
+	skipCacheKey    = "cache-skip-lookup"
+	overwriteKey    = "cache-overwrite"
+	disableCacheKey = "cache-disable"
 )
 
 type format = string
@@ -40,12 +46,15 @@ const createCmdName = "create"
 
 type CreateOpts struct {
 	*RootOptions
-	format      format
-	execID      string
-	inputsPath  string
-	protoFile   string
-	annotations *stringMapValue
-	dryRun      bool
+	format       format
+	execID       string
+	inputsPath   string
+	protoFile    string
+	annotations  *stringMapValue
+	triggerRules *stringMapValue
+	// Node ids per cache override
+	cacheOverrides map[v1alpha1.CacheOverride]*[]string
+	dryRun         bool
 }
 
 func NewCreateCommand(opts *RootOptions) *cobra.Command {
@@ -77,6 +86,17 @@ func NewCreateCommand(opts *RootOptions) *cobra.Command {
 	createCmd.Flags().StringVarP(&createOpts.inputsPath, inputsKey, "i", "", "Path to inputs file.")
 	createOpts.annotations = newStringMapValue()
 	createCmd.Flags().VarP(createOpts.annotations, annotationsKey, "a", "Defines extra annotations to declare on the created object.")
+	createOpts.triggerRules = newStringMapValue()
+	createCmd.Flags().Var(createOpts.triggerRules, triggerRulesKey, "Defines the trigger rules of nodes of the workflow, "+
+		"e.g. cleanup=all_done. Supported rules: all_success (default), all_done, one_failed, one_success, none_failed.")
+	createOpts.cacheOverrides = map[v1alpha1.CacheOverride]*[]string{
+		v1alpha1.CacheOverrideSkipLookup: createCmd.Flags().StringSlice(skipCacheKey, nil, "Ids of the nodes that execute "+
+			"their tasks without looking up cached results, * for all nodes. Their results are still cached."),
+		v1alpha1.CacheOverrideOverwrite: createCmd.Flags().StringSlice(overwriteKey, nil, "Ids of the nodes that execute "+
+			"their tasks without looking up cached results and replace them with their results, * for all nodes."),
+		v1alpha1.CacheOverrideDisable: createCmd.Flags().StringSlice(disableCacheKey, nil, "Ids of the nodes that neither "+
+			"look up nor cache the results of their tasks, * for all nodes."),
+	}
 	createCmd.Flags().BoolVarP(&createOpts.dryRun, "dry-run", "d", false, "Compiles and transforms, but does not create a workflow. OutputsRef ts to STDOUT.")
 
 	return createCmd
@@ -153,6 +173,49 @@ func compileTasks(tasks []*core.TaskTemplate) ([]*core.CompiledTask, error) {
 	return res, nil
 }
 
+func hasNode(flyteWf *v1alpha1.FlyteWorkflow, nodeID v1alpha1.NodeID) bool {
+	if _, ok := flyteWf.Nodes[nodeID]; ok {
+		return true
+	}
+
+	for _, s := range flyteWf.SubWorkflows {
+		if _, ok := s.Nodes[nodeID]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sets the cache override annotations of the workflow for the nodes listed in the cache flags, they replace the
+// annotations set through the annotations flag.
+func setCacheOverrides(flyteWf *v1alpha1.FlyteWorkflow, overrides map[v1alpha1.CacheOverride]*[]string) error {
+	errs := compilerErrors.NewCompileErrors()
+	for override, nodeIDs := range overrides {
+		if nodeIDs == nil || len(*nodeIDs) == 0 {
+			continue
+		}
+
+		for _, id := range *nodeIDs {
+			if id != v1alpha1.CacheOverrideAllNodes && !hasNode(flyteWf, id) {
+				errs.Collect(compilerErrors.NewNodeReferenceNotFoundErr(flyteWf.ID, id))
+			}
+		}
+
+		if flyteWf.Annotations == nil {
+			flyteWf.Annotations = map[string]string{}
+		}
+
+		flyteWf.Annotations[override.AnnotationKey()] = strings.Join(*nodeIDs, ",")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	return nil
+}
+
 func (c *CreateOpts) createWorkflowFromProto() error {
 	fmt.Printf("Received protofiles : [%v] [%v].\n", c.protoFile, c.inputsPath)
 	rawWf, err := ioutil.ReadFile(c.protoFile)
@@ -197,6 +260,18 @@ func (c *CreateOpts) createWorkflowFromProto() error {
 	if err != nil {
 		return err
 	}
+
+	if c.triggerRules != nil && len(*c.triggerRules.value) > 0 {
+		rules := make(map[v1alpha1.NodeID]v1alpha1.TriggerRule, len(*c.triggerRules.value))
+		for nodeID, rule := range *c.triggerRules.value {
+			rules[nodeID] = v1alpha1.TriggerRule(rule)
+		}
+
+		if err := k8s.SetTriggerRules(flyteWf, rules); err != nil {
+			return err
+		}
+	}
+
 	if flyteWf.Annotations == nil {
 		flyteWf.Annotations = *c.annotations.value
 	} else {
@@ -205,6 +280,10 @@ func (c *CreateOpts) createWorkflowFromProto() error {
 		}
 	}
 
+	if err := setCacheOverrides(flyteWf, c.cacheOverrides); err != nil {
+		return err
+	}
+
 	if c.dryRun {
 		fmt.Printf("Dry Run mode enabled. Printing the compiled workflow.")
 		j, err := json.Marshal(flyteWf)
@@ -13,6 +13,7 @@ import (
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytepropeller/pkg/compiler"
 	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	"github.com/lyft/flytepropeller/pkg/compiler/transformers/k8s"
@@ -275,6 +276,14 @@ func testCompile(t *testing.T) {
 			assert.NoError(t, err)
 			_, err = k8s.BuildFlyteWorkflow(compiledWf, nil, nil, "")
 			assert.NoError(t, err)
+
+			for _, graphFormat := range []string{graphFormatDot, graphFormatMermaid, graphFormatJSON} {
+				g, err := exportGraph(compiledWf.Primary, graphFormat)
+				assert.NoError(t, err, graphFormat)
+				assert.NotEmpty(t, g, graphFormat)
+			}
+			_, err = exportGraph(compiledWf.Primary, "svg")
+			assert.Error(t, err)
 		}
 	}
 
@@ -289,4 +298,85 @@ func testCompile(t *testing.T) {
 	t.Run("proto", func(t *testing.T) {
 		f(t, "workflow.pb.golden", formatProto)
 	})
+
+	t.Run("graph", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "compile")
+		assert.NoError(t, err)
+		defer func() {
+			assert.NoError(t, os.RemoveAll(dir))
+		}()
+
+		opts := &CompileOpts{
+			protoFile:    filepath.Join("testdata", "workflow.pb.golden"),
+			inputFormat:  formatProto,
+			outputFormat: formatProto,
+			outputPath:   filepath.Join(dir, "closure.pb"),
+			graphFormat:  graphFormatMermaid,
+		}
+		assert.Error(t, opts.compileWorkflowCmd())
+
+		opts.graphOutputPath = filepath.Join(dir, "workflow.mmd")
+		assert.NoError(t, opts.compileWorkflowCmd())
+		g, err := ioutil.ReadFile(opts.graphOutputPath)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, g)
+	})
+}
+
+func TestSetCacheOverrides(t *testing.T) {
+	newWorkflow := func() *v1alpha1.FlyteWorkflow {
+		return &v1alpha1.FlyteWorkflow{
+			WorkflowSpec: &v1alpha1.WorkflowSpec{
+				ID: "wf",
+				Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+					"n0": {ID: "n0"},
+					"n1": {ID: "n1"},
+				},
+			},
+			SubWorkflows: map[v1alpha1.WorkflowID]*v1alpha1.WorkflowSpec{
+				"sub": {
+					ID: "sub",
+					Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+						"s0": {ID: "s0"},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("set", func(t *testing.T) {
+		wf := newWorkflow()
+		assert.NoError(t, setCacheOverrides(wf, map[v1alpha1.CacheOverride]*[]string{
+			v1alpha1.CacheOverrideSkipLookup: {"n0", "s0"},
+			v1alpha1.CacheOverrideOverwrite:  {"n1"},
+			v1alpha1.CacheOverrideDisable:    {},
+		}))
+		assert.Equal(t, map[string]string{
+			v1alpha1.CacheSkipLookupAnnotationKey: "n0,s0",
+			v1alpha1.CacheOverwriteAnnotationKey:  "n1",
+		}, wf.Annotations)
+		assert.Equal(t, v1alpha1.CacheOverrideSkipLookup, v1alpha1.GetCacheOverride(wf.Annotations, "n0"))
+		assert.Equal(t, v1alpha1.CacheOverrideOverwrite, v1alpha1.GetCacheOverride(wf.Annotations, "n1"))
+	})
+
+	t.Run("all-nodes", func(t *testing.T) {
+		wf := newWorkflow()
+		assert.NoError(t, setCacheOverrides(wf, map[v1alpha1.CacheOverride]*[]string{
+			v1alpha1.CacheOverrideDisable: {"*"},
+		}))
+		assert.Equal(t, v1alpha1.CacheOverrideDisable, v1alpha1.GetCacheOverride(wf.Annotations, "s0"))
+	})
+
+	t.Run("unknown-node", func(t *testing.T) {
+		wf := newWorkflow()
+		assert.Error(t, setCacheOverrides(wf, map[v1alpha1.CacheOverride]*[]string{
+			v1alpha1.CacheOverrideDisable: {"n0", "n2"},
+		}))
+	})
+
+	t.Run("none", func(t *testing.T) {
+		wf := newWorkflow()
+		assert.NoError(t, setCacheOverrides(wf, map[v1alpha1.CacheOverride]*[]string{}))
+		assert.Nil(t, wf.Annotations)
+	})
 }
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	pluginCore "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/core"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/ioutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/cmd/kubectl-flyte/cmd/printers"
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+type DescribeNodeOpts struct {
+	*RootOptions
+	configFile string
+	out        io.Writer
+}
+
+func NewDescribeNodeCommand(opts *RootOptions) *cobra.Command {
+
+	describeOpts := &DescribeNodeOpts{
+		RootOptions: opts,
+		out:         os.Stdout,
+	}
+
+	describeCmd := &cobra.Command{
+		Use:   "describe-node [opts] <workflow_name> <node_id>",
+		Short: "Describes a single node execution of a workflow, including its inputs, outputs and error",
+		Long: `Nested nodes, of branches, sub workflows and dynamic nodes, are addressed by the path of node ids from the top
+level node, separated by slashes, e.g. n0/dn1. Inputs, outputs and errors, as well as node statuses offloaded by
+propeller, are read from the blob storage configured in the propeller config file passed with --config.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return fmt.Errorf("a workflow name and a node id are required")
+			}
+			return describeOpts.describeNode(context.Background(), args[0], args[1])
+		},
+	}
+
+	describeCmd.Flags().StringVar(&describeOpts.configFile, "config", "", "Propeller config file, used to read the inputs, outputs and error of the node from blob storage.")
+
+	return describeCmd
+}
+
+// A node execution resolved from its path of node ids.
+type resolvedNode struct {
+	path string
+	// The spec of the node, nil for nodes that are not part of the workflow spec, e.g. nodes of dynamic workflows
+	spec   v1alpha1.ExecutableNode
+	status v1alpha1.ExecutableNodeStatus
+}
+
+func hasNodeStatus(s v1alpha1.ExecutableNodeStatus, id v1alpha1.NodeID) bool {
+	found := false
+	s.VisitNodeStatuses(func(node v1alpha1.NodeID, _ v1alpha1.ExecutableNodeStatus) {
+		if node == id {
+			found = true
+		}
+	})
+	return found
+}
+
+// Resolves a node given as the path of node ids from its top level node. Nodes of branches are part of the same spec
+// as the branch node, nodes of sub workflows are part of the sub workflow spec.
+func resolveNode(ctx context.Context, w *v1alpha1.FlyteWorkflow, path string) (*resolvedNode, error) {
+	ids := strings.Split(strings.Trim(path, "/"), "/")
+	var scope v1alpha1.ExecutableSubWorkflow = w
+	var node v1alpha1.ExecutableNode
+	var status v1alpha1.ExecutableNodeStatus
+	for i, id := range ids {
+		var child v1alpha1.ExecutableNode
+		if scope != nil {
+			if n, ok := scope.GetNode(id); ok {
+				child = n
+			}
+		}
+
+		if i == 0 {
+			if child == nil {
+				return nil, fmt.Errorf("node [%s] not found in workflow [%s]", id, w.GetK8sWorkflowID())
+			}
+			status = w.GetNodeExecutionStatus(ctx, id)
+		} else {
+			if child == nil && !hasNodeStatus(status, id) {
+				return nil, fmt.Errorf("node [%s] not found under [%s]", id, strings.Join(ids[:i], "/"))
+			}
+			status = status.GetNodeExecutionStatus(ctx, id)
+		}
+
+		node = child
+		if node == nil || node.GetKind() != v1alpha1.NodeKindBranch {
+			scope = nil
+		}
+		if node != nil && node.GetKind() == v1alpha1.NodeKindWorkflow && node.GetWorkflowNode().GetSubWorkflowRef() != nil {
+			scope = w.FindSubWorkflow(*node.GetWorkflowNode().GetSubWorkflowRef())
+		}
+	}
+
+	return &resolvedNode{
+		path:   strings.Join(ids, "/"),
+		spec:   node,
+		status: status,
+	}, nil
+}
+
+type nodeEvent struct {
+	at    time.Time
+	event string
+}
+
+// The node status only keeps the latest timestamps, ordered they are the history of the current execution.
+func nodeHistory(s v1alpha1.ExecutableNodeStatus) []nodeEvent {
+	var events []nodeEvent
+	add := func(t *metav1.Time, event string) {
+		if t != nil && !t.IsZero() {
+			events = append(events, nodeEvent{at: t.Time, event: event})
+		}
+	}
+
+	add(s.GetQueuedAt(), "Queued")
+	add(s.GetStartedAt(), "Started")
+	add(s.GetLastAttemptStartedAt(), fmt.Sprintf("Attempt %d started", s.GetAttempts()))
+	add(s.GetNextAttemptAt(), "Next attempt scheduled")
+	add(s.GetStoppedAt(), fmt.Sprintf("Stopped (%s)", s.GetPhase().String()))
+	add(s.GetLastUpdatedAt(), fmt.Sprintf("Last updated (%s)", s.GetPhase().String()))
+	if t := s.GetTaskNodeStatus(); t != nil && !t.GetLastPhaseUpdatedAt().IsZero() {
+		events = append(events, nodeEvent{
+			at:    t.GetLastPhaseUpdatedAt(),
+			event: fmt.Sprintf("Task plugin phase updated (%s)", pluginCore.Phase(t.GetPhase()).String()),
+		})
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at.Before(events[j].at)
+	})
+	return events
+}
+
+func formatTimestamp(t *metav1.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Reads a protobuf document from blob storage and renders it as json.
+func readProtobufAsJSON(ctx context.Context, store *storage.DataStore, ref storage.DataReference, msg proto.Message) string {
+	if err := store.ReadProtobuf(ctx, ref, msg); err != nil {
+		if storage.IsNotFound(err) {
+			return fmt.Sprintf("not found at [%s]", ref)
+		}
+		return fmt.Sprintf("failed to read [%s]: %v", ref, err)
+	}
+
+	m := jsonpb.Marshaler{Indent: "  "}
+	raw, err := m.MarshalToString(msg)
+	if err != nil {
+		return fmt.Sprintf("failed to render [%s]: %v", ref, err)
+	}
+	return raw
+}
+
+func (d *DescribeNodeOpts) describe(ctx context.Context, w *v1alpha1.FlyteWorkflow, n *resolvedNode, store *storage.DataStore) error {
+	s := n.status
+	b := &strings.Builder{}
+	field := func(name string, value interface{}) {
+		fmt.Fprintf(b, "%-20s %v\n", name+":", value)
+	}
+
+	field("Node", n.path)
+	field("Workflow", fmt.Sprintf("%s [ExecId: %s]", w.GetK8sWorkflowID(), w.GetExecutionID()))
+	if n.spec != nil {
+		field("Kind", n.spec.GetKind().String())
+	} else {
+		field("Kind", "unknown, not part of the workflow spec")
+	}
+	field("Phase", printers.ColorizeNodePhase(s.GetPhase()))
+	field("Message", s.GetMessage())
+	field("Attempts", fmt.Sprintf("%d (system failures: %d)", s.GetAttempts(), s.GetSystemFailures()))
+	field("Cached", s.IsCached())
+	field("Runtime", printers.CalculateRuntime(s))
+	field("Data Dir", s.GetDataDir())
+	field("Output Dir", s.GetOutputDir())
+
+	b.WriteString("\nHistory:\n")
+	for _, e := range nodeHistory(s) {
+		fmt.Fprintf(b, "  %s  %s\n", e.at.UTC().Format(time.RFC3339), e.event)
+	}
+
+	if t := s.GetTaskNodeStatus(); t != nil {
+		b.WriteString("\nTask:\n")
+		field("  Plugin Phase", fmt.Sprintf("%s (version %d)", pluginCore.Phase(t.GetPhase()).String(), t.GetPhaseVersion()))
+		field("  Last Phase Update", formatTimestamp(&metav1.Time{Time: t.GetLastPhaseUpdatedAt()}))
+		field("  Plugin State", fmt.Sprintf("%d bytes (version %d)", len(t.GetPluginState()), t.GetPluginStateVersion()))
+		if c := t.GetCacheOverride(); c != v1alpha1.CacheOverrideNone {
+			field("  Cache Override", string(c))
+		}
+	}
+
+	if execErr := s.GetExecutionError(); execErr != nil {
+		b.WriteString("\nError:\n")
+		field("  Code", execErr.GetCode())
+		field("  Kind", execErr.GetKind().String())
+		field("  Message", execErr.GetMessage())
+	}
+
+	var subNodes []string
+	s.VisitNodeStatuses(func(id v1alpha1.NodeID, sub v1alpha1.ExecutableNodeStatus) {
+		subNodes = append(subNodes, fmt.Sprintf("  %s/%s  %s", n.path, id, printers.ColorizeNodePhase(sub.GetPhase())))
+	})
+	if len(subNodes) > 0 {
+		sort.Strings(subNodes)
+		b.WriteString("\nNodes:\n")
+		b.WriteString(strings.Join(subNodes, "\n"))
+		b.WriteString("\n")
+	}
+
+	if n.spec != nil {
+		raw, err := yaml.Marshal(n.spec)
+		if err != nil {
+			return err
+		}
+		b.WriteString("\nSpec:\n")
+		b.WriteString(string(raw))
+	}
+
+	if store == nil {
+		b.WriteString("\nPass --config to read the inputs, outputs and error of the node from blob storage.\n")
+	} else {
+		if s.GetDataDir() != "" {
+			b.WriteString("\nInputs:\n")
+			b.WriteString(readProtobufAsJSON(ctx, store, v1alpha1.GetInputsFile(s.GetDataDir()), &core.LiteralMap{}))
+			b.WriteString("\n")
+		}
+
+		if s.GetOutputDir() != "" {
+			b.WriteString("\nOutputs:\n")
+			b.WriteString(readProtobufAsJSON(ctx, store, v1alpha1.GetOutputsFile(s.GetOutputDir()), &core.LiteralMap{}))
+			b.WriteString("\n")
+
+			errorsRef, err := ioutils.GetErrorsPath(ctx, store, s.GetOutputDir())
+			if err != nil {
+				return err
+			}
+			b.WriteString("\nError Document:\n")
+			b.WriteString(readProtobufAsJSON(ctx, store, errorsRef, &core.ErrorDocument{}))
+			b.WriteString("\n")
+		}
+	}
+
+	_, err := fmt.Fprint(d.out, b.String())
+	return err
+}
+
+func (d *DescribeNodeOpts) describeNode(ctx context.Context, name, nodePath string) error {
+	namespace := d.ConfigOverrides.Context.Namespace
+	if parts := strings.Split(name, "/"); len(parts) > 1 {
+		namespace = parts[0]
+		name = parts[1]
+	}
+
+	w, err := d.flyteClient.FlyteworkflowV1alpha1().FlyteWorkflows(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	var store *storage.DataStore
+	var reader *workflowstore.OffloadedNodeStatusReader
+	if d.configFile != "" {
+		store, err = newDataStore(ctx, d.configFile)
+		if err != nil {
+			return err
+		}
+		reader = workflowstore.NewOffloadedNodeStatusReader(store, workflowstore.GetConfig().NodeStatusCacheSize)
+	}
+
+	w, err = rehydrateNodeStatus(ctx, reader, w)
+	if err != nil {
+		return err
+	}
+
+	w.DataReferenceConstructor = storage.URLPathConstructor{}
+	n, err := resolveNode(ctx, w, nodePath)
+	if err != nil {
+		return err
+	}
+
+	return d.describe(ctx, w, n, store)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	pluginCore "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/core"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/utils"
+)
+
+func newDescribeTestWorkflow() *v1alpha1.FlyteWorkflow {
+	subWorkflowID := "sub"
+	startedAt := time.Date(2020, time.March, 4, 10, 0, 0, 0, time.UTC)
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: "wf"},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				"b1": {ID: "b1", Kind: v1alpha1.NodeKindBranch},
+				"t1": {ID: "t1", Kind: v1alpha1.NodeKindTask},
+				"sw": {ID: "sw", Kind: v1alpha1.NodeKindWorkflow,
+					WorkflowNode: &v1alpha1.WorkflowNodeSpec{SubWorkflowReference: &subWorkflowID}},
+				"d1": {ID: "d1", Kind: v1alpha1.NodeKindTask},
+			},
+		},
+		SubWorkflows: map[v1alpha1.WorkflowID]*v1alpha1.WorkflowSpec{
+			subWorkflowID: {
+				ID: subWorkflowID,
+				Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+					"s1": {ID: "s1", Kind: v1alpha1.NodeKindTask},
+				},
+			},
+		},
+		Status: v1alpha1.WorkflowStatus{
+			Phase:   v1alpha1.WorkflowPhaseFailing,
+			DataDir: "s3://bucket/metadata/wf",
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				"b1": {Phase: v1alpha1.NodePhaseRunning, SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+					"t1": {Phase: v1alpha1.NodePhaseRunning},
+				}},
+				"sw": {Phase: v1alpha1.NodePhaseRunning, SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+					"s1": {Phase: v1alpha1.NodePhaseRunning},
+				}},
+				"d1": {
+					Phase:     v1alpha1.NodePhaseFailed,
+					Attempts:  1,
+					QueuedAt:  &v1.Time{Time: startedAt.Add(-time.Second)},
+					StartedAt: &v1.Time{Time: startedAt},
+					StoppedAt: &v1.Time{Time: startedAt.Add(time.Minute)},
+					Error: &v1alpha1.ExecutionError{ExecutionError: &core.ExecutionError{
+						Code: "USER:Failed", Kind: core.ExecutionError_USER, Message: "d1 failed",
+					}},
+					TaskNodeStatus: &v1alpha1.TaskNodeStatus{
+						Phase:              int(pluginCore.PhasePermanentFailure),
+						PhaseVersion:       2,
+						LastPhaseUpdatedAt: startedAt.Add(59 * time.Second),
+						CacheOverride:      v1alpha1.CacheOverrideSkipLookup,
+					},
+					SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+						"dn0": {Phase: v1alpha1.NodePhaseSucceeded},
+					},
+				},
+			},
+		},
+		DataReferenceConstructor: storage.URLPathConstructor{},
+	}
+}
+
+func TestResolveNode(t *testing.T) {
+	ctx := context.TODO()
+	w := newDescribeTestWorkflow()
+
+	for _, tc := range []struct {
+		path string
+		kind v1alpha1.NodeKind
+	}{
+		{path: "d1", kind: v1alpha1.NodeKindTask},
+		// Branch nodes are part of the same spec
+		{path: "b1/t1", kind: v1alpha1.NodeKindTask},
+		{path: "sw/s1", kind: v1alpha1.NodeKindTask},
+		// Nodes of dynamic workflows are not part of the spec
+		{path: "/d1/dn0/", kind: ""},
+	} {
+		n, err := resolveNode(ctx, w, tc.path)
+		if assert.NoError(t, err, tc.path) {
+			if tc.kind == "" {
+				assert.Nil(t, n.spec)
+			} else if assert.NotNil(t, n.spec) {
+				assert.Equal(t, tc.kind, n.spec.GetKind())
+			}
+		}
+	}
+
+	n, err := resolveNode(ctx, w, "/d1/dn0/")
+	assert.NoError(t, err)
+	assert.Equal(t, "d1/dn0", n.path)
+	assert.Equal(t, v1alpha1.NodePhaseSucceeded, n.status.GetPhase())
+
+	for _, path := range []string{"missing", "d1/missing", "sw/t1"} {
+		_, err := resolveNode(ctx, w, path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestDescribeNodeOpts_describe(t *testing.T) {
+	color.NoColor = true
+	ctx := context.TODO()
+	w := newDescribeTestWorkflow()
+	n, err := resolveNode(ctx, w, "d1")
+	assert.NoError(t, err)
+
+	t.Run("no-store", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		d := &DescribeNodeOpts{out: out}
+		assert.NoError(t, d.describe(ctx, w, n, nil))
+		assert.Contains(t, out.String(), "Plugin Phase:")
+		assert.Contains(t, out.String(), "PhasePermanentFailure (version 2)")
+		assert.Contains(t, out.String(), "Cache Override:")
+		assert.Contains(t, out.String(), "skip_lookup")
+		assert.Contains(t, out.String(), "d1 failed")
+		assert.Contains(t, out.String(), "d1/dn0  Succeeded")
+		assert.Contains(t, out.String(), "2020-03-04T09:59:59Z  Queued\n  2020-03-04T10:00:00Z  Started\n")
+		assert.Contains(t, out.String(), "Pass --config")
+	})
+
+	t.Run("store", func(t *testing.T) {
+		store, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		assert.NoError(t, store.WriteProtobuf(ctx, v1alpha1.GetInputsFile(n.status.GetDataDir()), storage.Options{},
+			utils.MustMakeLiteral(map[string]interface{}{"x": 42}).GetMap()))
+		assert.NoError(t, store.WriteProtobuf(ctx, n.status.GetOutputDir()+"/error.pb", storage.Options{},
+			&core.ErrorDocument{Error: &core.ContainerError{Code: "USER:Failed", Message: "boom"}}))
+
+		out := &bytes.Buffer{}
+		d := &DescribeNodeOpts{out: out}
+		assert.NoError(t, d.describe(ctx, w, n, store))
+		assert.Contains(t, out.String(), `"integer": "42"`)
+		assert.Contains(t, out.String(), "Outputs:\nnot found at [s3://bucket/metadata/wf/d1/data/1/outputs.pb]")
+		assert.Contains(t, out.String(), `"message": "boom"`)
+	})
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 
@@ -14,6 +15,8 @@ import (
 
 	"github.com/lyft/flytepropeller/cmd/kubectl-flyte/cmd/printers"
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	controllerConfig "github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
 )
 
 type GetOpts struct {
@@ -22,6 +25,11 @@ type GetOpts struct {
 	limit              int64
 	chunkSize          int64
 	showQuota          bool
+	archived           bool
+	configFile         string
+	output             string
+	outputPrinter      *printers.OutputPrinter
+	nodeStatusReader   *workflowstore.OffloadedNodeStatusReader
 }
 
 func NewGetCommand(opts *RootOptions) *cobra.Command {
@@ -34,13 +42,44 @@ func NewGetCommand(opts *RootOptions) *cobra.Command {
 		Use:   "get [opts] [<workflow_name>]",
 		Short: "Gets a single workflow or lists all workflows currently in execution",
 		Long:  `use labels to filter`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Archived workflows are read from blob storage, no cluster access is needed
+			if getOpts.archived {
+				return nil
+			}
+			return opts.ConfigureClient()
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
 
+			if getOpts.output != "" {
+				p, err := printers.NewOutputPrinter(getOpts.output)
+				if err != nil {
+					return err
+				}
+				getOpts.outputPrinter = p
+			}
+
+			if getOpts.archived {
+				if len(args) == 0 {
+					return fmt.Errorf("an archived workflow must be specified, as <project>/<domain>/<yyyy-mm-dd>/<name>")
+				}
+				return getOpts.getArchivedWorkflow(ctx, args[0])
+			}
+
+			reader, err := newNodeStatusReader(ctx, getOpts.configFile)
+			if err != nil {
+				return err
+			}
+			getOpts.nodeStatusReader = reader
+
 			if len(args) > 0 {
 				name := args[0]
 				return getOpts.getWorkflow(ctx, name)
 			}
+			if getOpts.outputPrinter != nil {
+				return getOpts.printWorkflows(ctx)
+			}
 			return getOpts.listWorkflows()
 		},
 	}
@@ -49,6 +88,9 @@ func NewGetCommand(opts *RootOptions) *cobra.Command {
 	getCmd.Flags().BoolVarP(&getOpts.showQuota, "show-quota", "q", false, "Shows resource quota usage for that resource.")
 	getCmd.Flags().Int64VarP(&getOpts.chunkSize, "chunk-size", "c", 100, "Use this much batch size.")
 	getCmd.Flags().Int64VarP(&getOpts.limit, "limit", "l", -1, "Only get limit records. -1 => all records.")
+	getCmd.Flags().BoolVar(&getOpts.archived, "archived", false, "Reads a garbage collected workflow from the archive, given as <project>/<domain>/<yyyy-mm-dd>/<name> or a full storage reference.")
+	getCmd.Flags().StringVar(&getOpts.configFile, "config", "", "Propeller config file, used to locate the archive with --archived and to read node statuses offloaded to blob storage.")
+	getCmd.Flags().StringVarP(&getOpts.output, "output", "o", "", "Machine readable output format, one of json, yaml, csv or jsonpath=<template>. Prints a tree if not set.")
 
 	return getCmd
 }
@@ -63,9 +105,47 @@ func (g *GetOpts) getWorkflow(ctx context.Context, name string) error {
 	if err != nil {
 		return err
 	}
+
+	w, err = rehydrateNodeStatus(ctx, g.nodeStatusReader, w)
+	if err != nil {
+		return err
+	}
+	return g.printWorkflow(ctx, w)
+}
+
+func (g *GetOpts) getArchivedWorkflow(ctx context.Context, name string) error {
+	store, err := newDataStore(ctx, g.configFile)
+	if err != nil {
+		return err
+	}
+
+	cfg := controllerConfig.GetConfig()
+	archive, err := workflowstore.NewWorkflowArchive(ctx, store, cfg.MetadataPrefix, cfg.Archive.Prefix)
+	if err != nil {
+		return err
+	}
+
+	ref, err := archive.ResolveReference(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	w, err := archive.Get(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	return g.printWorkflow(ctx, w)
+}
+
+func (g *GetOpts) printWorkflow(ctx context.Context, w *v1alpha1.FlyteWorkflow) error {
+	w.DataReferenceConstructor = storage.URLPathConstructor{}
+	if g.outputPrinter != nil {
+		return g.outputPrinter.PrintWorkflow(ctx, os.Stdout, w)
+	}
+
 	wp := printers.WorkflowPrinter{}
 	tree := gotree.New("Workflow")
-	w.DataReferenceConstructor = storage.URLPathConstructor{}
 	if err := wp.Print(ctx, tree, w); err != nil {
 		return err
 	}
@@ -73,6 +153,26 @@ func (g *GetOpts) getWorkflow(ctx context.Context, name string) error {
 	return nil
 }
 
+// Prints all workflows in the machine readable output format, without the progress and summary of the list view.
+func (g *GetOpts) printWorkflows(ctx context.Context) error {
+	var workflows []*v1alpha1.FlyteWorkflow
+	err := g.iterateOverWorkflows(
+		func(w *v1alpha1.FlyteWorkflow) error {
+			w, err := rehydrateNodeStatus(ctx, g.nodeStatusReader, w.DeepCopy())
+			if err != nil {
+				return err
+			}
+			w.DataReferenceConstructor = storage.URLPathConstructor{}
+			workflows = append(workflows, w)
+			return nil
+		}, g.chunkSize, g.limit)
+	if err != nil {
+		return err
+	}
+
+	return g.outputPrinter.PrintWorkflowList(ctx, os.Stdout, workflows)
+}
+
 func (g *GetOpts) iterateOverWorkflows(f func(*v1alpha1.FlyteWorkflow) error, batchSize int64, limit int64) error {
 	if limit > 0 && limit < batchSize {
 		batchSize = limit
@@ -226,7 +326,7 @@ func (g *GetOpts) listWorkflows() error {
 			c := perNS[w.Namespace]
 			c.total++
 			switch w.GetExecutionStatus().GetPhase() {
-			case v1alpha1.WorkflowPhaseReady:
+			case v1alpha1.WorkflowPhaseReady, v1alpha1.WorkflowPhaseQueued:
 				c.waiting++
 				waiting++
 			case v1alpha1.WorkflowPhaseSuccess:
@@ -53,7 +53,7 @@ func (p NodeStatusPrinter) BaseNodeInfo(node v1alpha1.BaseNode, nodeStatus v1alp
 	return []string{
 		fmt.Sprintf("%s (%s)", boldString.Sprint(node.GetID()), node.GetKind().String()),
 		CalculateRuntime(nodeStatus),
-		ColorizeNodePhase(nodeStatus.GetPhase()),
+		p.Transitions.ColorizeNodePhase(nodePhaseKey(node.GetID(), nodeStatus), nodeStatus.GetPhase()),
 		nodeStatus.GetMessage(),
 	}
 }
@@ -112,7 +112,7 @@ func (p NodePrinter) traverseNode(ctx context.Context, tree gotree.Tree, w v1alp
 	case v1alpha1.NodeKindWorkflow:
 		if node.GetWorkflowNode().GetSubWorkflowRef() != nil {
 			s := w.FindSubWorkflow(*node.GetWorkflowNode().GetSubWorkflowRef())
-			wp := WorkflowPrinter{}
+			wp := WorkflowPrinter{Transitions: p.Transitions}
 			return wp.PrintSubWorkflow(ctx, tree, w, s, nodeStatus)
 		}
 	case v1alpha1.NodeKindTask:
@@ -137,6 +137,8 @@ func (p NodePrinter) PrintList(ctx context.Context, tree gotree.Tree, w v1alpha1
 }
 
 type NodeStatusPrinter struct {
+	// Optional, highlights node phase transitions
+	Transitions *PhaseTransitions
 }
 
 func (p NodeStatusPrinter) PrintRecursive(tree gotree.Tree, wfName string, s v1alpha1.ExecutableNodeStatus) error {
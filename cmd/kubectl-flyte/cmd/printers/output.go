@@ -0,0 +1,349 @@
+package printers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+// Machine readable output formats. Unlike the colorized trees, these emit a stable schema that is safe to parse.
+const (
+	OutputFormatJSON     = "json"
+	OutputFormatYAML     = "yaml"
+	OutputFormatJSONPath = "jsonpath"
+	OutputFormatCSV      = "csv"
+)
+
+// Columns of the csv output, one row per node. Workflow columns are repeated for every node of the workflow.
+var csvHeader = []string{
+	"namespace", "name", "project", "domain", "execution", "workflow_phase", "workflow_started_at",
+	"workflow_stopped_at", "workflow_error_code", "node_id", "node_kind", "node_phase", "attempts", "started_at",
+	"stopped_at", "duration_seconds", "error_code", "error_kind", "error_message", "data_dir", "output_dir",
+}
+
+type ErrorSummary struct {
+	Code    string `json:"code"`
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+}
+
+type NodeFailureSummary struct {
+	NodeID  string `json:"nodeId"`
+	Code    string `json:"code"`
+	Kind    string `json:"kind"`
+	Message string `json:"message,omitempty"`
+	Attempt uint32 `json:"attempt"`
+}
+
+type ExecutionIDSummary struct {
+	Project string `json:"project"`
+	Domain  string `json:"domain"`
+	Name    string `json:"name"`
+}
+
+type NodeSummary struct {
+	// Ids of nested nodes are qualified by the ids of their parents, e.g. n0/dn1
+	ID              string        `json:"id"`
+	Kind            string        `json:"kind,omitempty"`
+	Phase           string        `json:"phase"`
+	Message         string        `json:"message,omitempty"`
+	Attempts        uint32        `json:"attempts"`
+	Cached          bool          `json:"cached,omitempty"`
+	StartedAt       *time.Time    `json:"startedAt,omitempty"`
+	StoppedAt       *time.Time    `json:"stoppedAt,omitempty"`
+	LastUpdatedAt   *time.Time    `json:"lastUpdatedAt,omitempty"`
+	DurationSeconds *float64      `json:"durationSeconds,omitempty"`
+	Error           *ErrorSummary `json:"error,omitempty"`
+	DataDir         string        `json:"dataDir,omitempty"`
+	OutputDir       string        `json:"outputDir,omitempty"`
+	Nodes           []NodeSummary `json:"nodes,omitempty"`
+}
+
+// WorkflowSummary is the machine readable status of a workflow and its nodes.
+type WorkflowSummary struct {
+	Namespace       string              `json:"namespace"`
+	Name            string              `json:"name"`
+	ExecutionID     *ExecutionIDSummary `json:"executionId,omitempty"`
+	Phase           string              `json:"phase"`
+	Message         string              `json:"message,omitempty"`
+	CreatedAt       time.Time           `json:"createdAt"`
+	StartedAt       *time.Time          `json:"startedAt,omitempty"`
+	StoppedAt       *time.Time          `json:"stoppedAt,omitempty"`
+	LastUpdatedAt   *time.Time          `json:"lastUpdatedAt,omitempty"`
+	DurationSeconds *float64            `json:"durationSeconds,omitempty"`
+	Error           *ErrorSummary       `json:"error,omitempty"`
+	// Every node that failed, when the workflow failed because of node failures
+	Failures []NodeFailureSummary `json:"failures,omitempty"`
+	DataDir  string               `json:"dataDir,omitempty"`
+	Nodes    []NodeSummary        `json:"nodes"`
+}
+
+type WorkflowSummaryList struct {
+	Items []WorkflowSummary `json:"items"`
+}
+
+func toTime(t *metav1.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func durationSeconds(s v1alpha1.ExecutionTimeInfo) *float64 {
+	if s.GetStartedAt() == nil || s.GetStoppedAt() == nil {
+		return nil
+	}
+	d := s.GetStoppedAt().Sub(s.GetStartedAt().Time).Seconds()
+	return &d
+}
+
+func toErrorSummary(err *core.ExecutionError) *ErrorSummary {
+	if err == nil {
+		return nil
+	}
+	return &ErrorSummary{
+		Code:    err.GetCode(),
+		Kind:    err.GetKind().String(),
+		Message: err.GetMessage(),
+	}
+}
+
+func sortedNodeIDs(ids []v1alpha1.NodeID) []v1alpha1.NodeID {
+	sort.Strings(ids)
+	return ids
+}
+
+func summarizeNode(ctx context.Context, w v1alpha1.ExecutableWorkflow, path string, node v1alpha1.ExecutableNode,
+	s v1alpha1.ExecutableNodeStatus) NodeSummary {
+
+	summary := NodeSummary{
+		ID:              path,
+		Phase:           s.GetPhase().String(),
+		Message:         s.GetMessage(),
+		Attempts:        s.GetAttempts(),
+		Cached:          s.IsCached(),
+		StartedAt:       toTime(s.GetStartedAt()),
+		StoppedAt:       toTime(s.GetStoppedAt()),
+		LastUpdatedAt:   toTime(s.GetLastUpdatedAt()),
+		DurationSeconds: durationSeconds(s),
+		Error:           toErrorSummary(s.GetExecutionError()),
+		DataDir:         s.GetDataDir().String(),
+		OutputDir:       s.GetOutputDir().String(),
+	}
+
+	// Nodes of a sub workflow are resolved against its spec, other nested nodes (e.g. of dynamic nodes) have no spec
+	var subWorkflow v1alpha1.ExecutableSubWorkflow
+	if node != nil {
+		summary.Kind = node.GetKind().String()
+		if node.GetKind() == v1alpha1.NodeKindWorkflow && node.GetWorkflowNode().GetSubWorkflowRef() != nil {
+			subWorkflow = w.FindSubWorkflow(*node.GetWorkflowNode().GetSubWorkflowRef())
+		}
+	}
+
+	var ids []v1alpha1.NodeID
+	s.VisitNodeStatuses(func(id v1alpha1.NodeID, _ v1alpha1.ExecutableNodeStatus) {
+		ids = append(ids, id)
+	})
+
+	for _, id := range sortedNodeIDs(ids) {
+		var subNode v1alpha1.ExecutableNode
+		if subWorkflow != nil {
+			subNode, _ = subWorkflow.GetNode(id)
+		}
+		summary.Nodes = append(summary.Nodes, summarizeNode(ctx, w, path+"/"+id, subNode, s.GetNodeExecutionStatus(ctx, id)))
+	}
+
+	return summary
+}
+
+// Summarizes the status of the workflow and all its nodes. Node statuses are read through the workflow, which requires
+// its DataReferenceConstructor to be set to resolve data dirs.
+func SummarizeWorkflow(ctx context.Context, w *v1alpha1.FlyteWorkflow) WorkflowSummary {
+	status := w.GetExecutionStatus()
+	summary := WorkflowSummary{
+		Namespace:       w.GetNamespace(),
+		Name:            w.GetName(),
+		Phase:           status.GetPhase().String(),
+		Message:         status.GetMessage(),
+		CreatedAt:       w.GetCreationTimestamp().UTC(),
+		StartedAt:       toTime(status.GetStartedAt()),
+		StoppedAt:       toTime(status.GetStoppedAt()),
+		LastUpdatedAt:   toTime(status.GetLastUpdatedAt()),
+		DurationSeconds: durationSeconds(status),
+		Error:           toErrorSummary(status.GetExecutionError()),
+		DataDir:         status.GetDataDir().String(),
+		Nodes:           []NodeSummary{},
+	}
+
+	for _, f := range status.GetNodeFailures() {
+		summary.Failures = append(summary.Failures, NodeFailureSummary{
+			NodeID:  f.NodeID,
+			Code:    f.Code,
+			Kind:    f.Kind,
+			Message: f.Message,
+			Attempt: f.Attempt,
+		})
+	}
+
+	if execID := w.GetExecutionID(); execID.WorkflowExecutionIdentifier != nil {
+		summary.ExecutionID = &ExecutionIDSummary{
+			Project: execID.GetProject(),
+			Domain:  execID.GetDomain(),
+			Name:    execID.GetName(),
+		}
+	}
+
+	if w.WorkflowSpec == nil {
+		return summary
+	}
+
+	for _, id := range sortedNodeIDs(w.GetNodes()) {
+		node, _ := w.GetNode(id)
+		summary.Nodes = append(summary.Nodes, summarizeNode(ctx, w, id, node, w.GetNodeExecutionStatus(ctx, id)))
+	}
+
+	return summary
+}
+
+// OutputPrinter prints workflows in one of the machine readable output formats.
+type OutputPrinter struct {
+	format   string
+	template *jsonpath.JSONPath
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatSeconds(s *float64) string {
+	if s == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*s, 'f', -1, 64)
+}
+
+func csvRows(w WorkflowSummary, nodes []NodeSummary, rows [][]string) [][]string {
+	var project, domain, execution string
+	if w.ExecutionID != nil {
+		project, domain, execution = w.ExecutionID.Project, w.ExecutionID.Domain, w.ExecutionID.Name
+	}
+
+	var workflowErrorCode string
+	if w.Error != nil {
+		workflowErrorCode = w.Error.Code
+	}
+
+	for _, n := range nodes {
+		var errorCode, errorKind, errorMessage string
+		if n.Error != nil {
+			errorCode, errorKind, errorMessage = n.Error.Code, n.Error.Kind, n.Error.Message
+		}
+
+		rows = append(rows, []string{
+			w.Namespace, w.Name, project, domain, execution, w.Phase, formatTime(w.StartedAt), formatTime(w.StoppedAt),
+			workflowErrorCode, n.ID, n.Kind, n.Phase, strconv.FormatUint(uint64(n.Attempts), 10),
+			formatTime(n.StartedAt), formatTime(n.StoppedAt), formatSeconds(n.DurationSeconds), errorCode, errorKind,
+			errorMessage, n.DataDir, n.OutputDir,
+		})
+		rows = csvRows(w, n.Nodes, rows)
+	}
+
+	return rows
+}
+
+func (p OutputPrinter) print(out io.Writer, obj interface{}, workflows []WorkflowSummary) error {
+	switch p.format {
+	case OutputFormatJSON:
+		raw, err := json.MarshalIndent(obj, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(raw))
+		return err
+	case OutputFormatYAML:
+		raw, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(raw)
+		return err
+	case OutputFormatJSONPath:
+		// Evaluate the template against the json representation, so that it uses the same field names
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		var data interface{}
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return err
+		}
+		return p.template.Execute(out, data)
+	case OutputFormatCSV:
+		rows := [][]string{csvHeader}
+		for _, w := range workflows {
+			rows = csvRows(w, w.Nodes, rows)
+		}
+		writer := csv.NewWriter(out)
+		return writer.WriteAll(rows)
+	}
+
+	return fmt.Errorf("unsupported output format [%s]", p.format)
+}
+
+func (p OutputPrinter) PrintWorkflow(ctx context.Context, out io.Writer, w *v1alpha1.FlyteWorkflow) error {
+	summary := SummarizeWorkflow(ctx, w)
+	return p.print(out, summary, []WorkflowSummary{summary})
+}
+
+func (p OutputPrinter) PrintWorkflowList(ctx context.Context, out io.Writer, workflows []*v1alpha1.FlyteWorkflow) error {
+	list := WorkflowSummaryList{Items: make([]WorkflowSummary, 0, len(workflows))}
+	for _, w := range workflows {
+		list.Items = append(list.Items, SummarizeWorkflow(ctx, w))
+	}
+	return p.print(out, list, list.Items)
+}
+
+// Creates a printer for the given output, one of json, yaml, csv or jsonpath=<template>.
+func NewOutputPrinter(output string) (*OutputPrinter, error) {
+	format := output
+	template := ""
+	if i := strings.Index(output, "="); i >= 0 {
+		format, template = output[:i], output[i+1:]
+	}
+
+	switch format {
+	case OutputFormatJSON, OutputFormatYAML, OutputFormatCSV:
+		if template != "" {
+			return nil, fmt.Errorf("output format [%s] does not take a template", format)
+		}
+		return &OutputPrinter{format: format}, nil
+	case OutputFormatJSONPath:
+		if template == "" {
+			return nil, fmt.Errorf("a template is required, as jsonpath=<template>")
+		}
+		j := jsonpath.New("output")
+		if err := j.Parse(template); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath template [%s]: %v", template, err)
+		}
+		return &OutputPrinter{format: format, template: j}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported output format [%s], expected one of json, yaml, csv or jsonpath=<template>", output)
+}
@@ -0,0 +1,156 @@
+package printers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+func newOutputTestWorkflow() *v1alpha1.FlyteWorkflow {
+	startedAt := time.Date(2020, time.March, 4, 10, 0, 0, 0, time.UTC)
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: "wf", CreationTimestamp: v1.Time{Time: startedAt}},
+		ExecutionID: v1alpha1.WorkflowExecutionIdentifier{
+			WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: "d", Name: "exec"},
+		},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+				"n1":                 {ID: "n1", Kind: v1alpha1.NodeKindTask},
+				v1alpha1.EndNodeID:   {ID: v1alpha1.EndNodeID, Kind: v1alpha1.NodeKindEnd},
+			},
+		},
+		Status: v1alpha1.WorkflowStatus{
+			Phase:     v1alpha1.WorkflowPhaseFailed,
+			StartedAt: &v1.Time{Time: startedAt},
+			StoppedAt: &v1.Time{Time: startedAt.Add(time.Minute)},
+			DataDir:   "s3://bucket/metadata/wf",
+			Error: &v1alpha1.ExecutionError{ExecutionError: &core.ExecutionError{
+				Code: "USER:Failed", Kind: core.ExecutionError_USER, Message: "n1 failed",
+			}},
+			NodeFailures: []v1alpha1.NodeFailure{
+				{NodeID: "n1", Code: "USER:Failed", Kind: "USER", Message: "n1 failed", Attempt: 2},
+			},
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				v1alpha1.StartNodeID: {Phase: v1alpha1.NodePhaseSucceeded},
+				"n1": {
+					Phase:     v1alpha1.NodePhaseFailed,
+					Attempts:  2,
+					StartedAt: &v1.Time{Time: startedAt},
+					StoppedAt: &v1.Time{Time: startedAt.Add(30 * time.Second)},
+					Error: &v1alpha1.ExecutionError{ExecutionError: &core.ExecutionError{
+						Code: "USER:Failed", Kind: core.ExecutionError_USER, Message: "n1 failed",
+					}},
+					SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+						"dn0": {Phase: v1alpha1.NodePhaseSucceeded},
+					},
+				},
+			},
+		},
+		DataReferenceConstructor: storage.URLPathConstructor{},
+	}
+}
+
+func TestSummarizeWorkflow(t *testing.T) {
+	summary := SummarizeWorkflow(context.TODO(), newOutputTestWorkflow())
+	assert.Equal(t, "Failed", summary.Phase)
+	assert.Equal(t, &ExecutionIDSummary{Project: "p", Domain: "d", Name: "exec"}, summary.ExecutionID)
+	assert.Equal(t, 60.0, *summary.DurationSeconds)
+	assert.Equal(t, "USER:Failed", summary.Error.Code)
+	assert.Equal(t, []NodeFailureSummary{
+		{NodeID: "n1", Code: "USER:Failed", Kind: "USER", Message: "n1 failed", Attempt: 2},
+	}, summary.Failures)
+
+	// Nodes are sorted by id
+	if assert.Len(t, summary.Nodes, 3) {
+		assert.Equal(t, v1alpha1.EndNodeID, summary.Nodes[0].ID)
+		assert.Equal(t, "NotYetStarted", summary.Nodes[0].Phase)
+
+		n1 := summary.Nodes[1]
+		assert.Equal(t, "n1", n1.ID)
+		assert.Equal(t, "task", n1.Kind)
+		assert.Equal(t, "Failed", n1.Phase)
+		assert.Equal(t, uint32(2), n1.Attempts)
+		assert.Equal(t, 30.0, *n1.DurationSeconds)
+		assert.Equal(t, &ErrorSummary{Code: "USER:Failed", Kind: "USER", Message: "n1 failed"}, n1.Error)
+		assert.Equal(t, "s3://bucket/metadata/wf/n1/data", n1.DataDir)
+		assert.Equal(t, "s3://bucket/metadata/wf/n1/data/2", n1.OutputDir)
+		if assert.Len(t, n1.Nodes, 1) {
+			assert.Equal(t, "n1/dn0", n1.Nodes[0].ID)
+			assert.Equal(t, "s3://bucket/metadata/wf/n1/data/dn0", n1.Nodes[0].DataDir)
+		}
+	}
+}
+
+func TestOutputPrinter(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("json", func(t *testing.T) {
+		p, err := NewOutputPrinter("json")
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		assert.NoError(t, p.PrintWorkflowList(ctx, out, []*v1alpha1.FlyteWorkflow{newOutputTestWorkflow()}))
+
+		list := WorkflowSummaryList{}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &list))
+		if assert.Len(t, list.Items, 1) {
+			assert.Equal(t, "wf", list.Items[0].Name)
+			assert.Len(t, list.Items[0].Nodes, 3)
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		p, err := NewOutputPrinter("yaml")
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		assert.NoError(t, p.PrintWorkflow(ctx, out, newOutputTestWorkflow()))
+		assert.Contains(t, out.String(), "phase: Failed\n")
+		assert.Contains(t, out.String(), "id: n1/dn0\n")
+	})
+
+	t.Run("jsonpath", func(t *testing.T) {
+		p, err := NewOutputPrinter("jsonpath={.phase} {.nodes[?(@.id==\"n1\")].error.code}")
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		assert.NoError(t, p.PrintWorkflow(ctx, out, newOutputTestWorkflow()))
+		assert.Equal(t, "Failed USER:Failed", out.String())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		p, err := NewOutputPrinter("csv")
+		assert.NoError(t, err)
+		out := &bytes.Buffer{}
+		assert.NoError(t, p.PrintWorkflow(ctx, out, newOutputTestWorkflow()))
+
+		rows, err := csv.NewReader(out).ReadAll()
+		assert.NoError(t, err)
+		// Header, 3 nodes and a nested node
+		if assert.Len(t, rows, 5) {
+			assert.Equal(t, csvHeader, rows[0])
+			assert.Equal(t, []string{
+				"ns", "wf", "p", "d", "exec", "Failed", "2020-03-04T10:00:00Z", "2020-03-04T10:01:00Z", "USER:Failed",
+				"n1", "task", "Failed", "2", "2020-03-04T10:00:00Z", "2020-03-04T10:00:30Z", "30", "USER:Failed", "USER",
+				"n1 failed", "s3://bucket/metadata/wf/n1/data", "s3://bucket/metadata/wf/n1/data/2",
+			}, rows[2])
+			assert.Equal(t, "n1/dn0", rows[3][9])
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, output := range []string{"table", "jsonpath", "jsonpath={.phase", "json={.phase}"} {
+			_, err := NewOutputPrinter(output)
+			assert.Error(t, err, output)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package printers
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+var transitionString = color.New(color.Bold, color.FgHiMagenta)
+
+// PhaseTransitions tracks node phases across successive prints of the same workflow, so that the nodes whose phase
+// changed since the previous version of the workflow are highlighted. A nil PhaseTransitions highlights nothing.
+type PhaseTransitions struct {
+	initialized bool
+	previous    map[string]v1alpha1.NodePhase
+	current     map[string]v1alpha1.NodePhase
+}
+
+// Marks a new version of the workflow. The phases printed for the previous version become the baseline that
+// transitions are computed against. Printing the same version again highlights the same transitions.
+func (t *PhaseTransitions) Next() {
+	if t.current != nil {
+		t.previous = t.current
+		t.initialized = true
+	}
+	t.current = map[string]v1alpha1.NodePhase{}
+}
+
+// Node ids are only unique within a (sub)workflow, the data dir of a started node is unique within the execution.
+func nodePhaseKey(id v1alpha1.NodeID, s v1alpha1.ExecutableNodeStatus) string {
+	if dataDir := s.GetDataDir(); dataDir != "" {
+		return dataDir.String()
+	}
+	return id
+}
+
+// Colorizes the phase of the node, prefixed with its previous phase if it changed since the previous version.
+func (t *PhaseTransitions) ColorizeNodePhase(key string, p v1alpha1.NodePhase) string {
+	if t == nil {
+		return ColorizeNodePhase(p)
+	}
+
+	if t.current == nil {
+		t.Next()
+	}
+
+	t.current[key] = p
+	prev, ok := t.previous[key]
+	if !ok {
+		prev = v1alpha1.NodePhaseNotYetStarted
+	}
+
+	if !t.initialized || prev == p {
+		return ColorizeNodePhase(p)
+	}
+
+	return fmt.Sprintf("%s %s", transitionString.Sprintf("%s ->", prev.String()), ColorizeNodePhase(p))
+}
+
+func NewPhaseTransitions() *PhaseTransitions {
+	return &PhaseTransitions{}
+}
@@ -14,7 +14,7 @@ import (
 
 func ColorizeWorkflowPhase(p v1alpha1.WorkflowPhase) string {
 	switch p {
-	case v1alpha1.WorkflowPhaseReady:
+	case v1alpha1.WorkflowPhaseReady, v1alpha1.WorkflowPhaseQueued:
 		return p.String()
 	case v1alpha1.WorkflowPhaseRunning:
 		return color.YellowString("%s", p.String())
@@ -43,6 +43,8 @@ type ContextualWorkflow struct {
 }
 
 type WorkflowPrinter struct {
+	// Optional, highlights node phase transitions
+	Transitions *PhaseTransitions
 }
 
 func (p WorkflowPrinter) Print(ctx context.Context, tree gotree.Tree, w v1alpha1.ExecutableWorkflow) error {
@@ -56,8 +58,26 @@ func (p WorkflowPrinter) Print(ctx context.Context, tree gotree.Tree, w v1alpha1
 	if tree != nil {
 		tree.AddTree(newTree)
 	}
-	np := NodePrinter{}
-	return np.PrintList(ctx, newTree, w, sortedNodes)
+	np := NodePrinter{NodeStatusPrinter{Transitions: p.Transitions}}
+	if err := np.PrintList(ctx, newTree, w, sortedNodes); err != nil {
+		return err
+	}
+
+	PrintNodeFailures(newTree, w.GetExecutionStatus().GetNodeFailures())
+	return nil
+}
+
+// Adds the failures of the nodes, that caused the workflow to fail, to the tree
+func PrintNodeFailures(tree gotree.Tree, failures []v1alpha1.NodeFailure) {
+	if len(failures) == 0 {
+		return
+	}
+
+	failuresTree := tree.Add(fmt.Sprintf("Failures (%d)", len(failures)))
+	for _, f := range failures {
+		failuresTree.Add(fmt.Sprintf("%s attempt %d [%s %s] %s", boldString.Sprint(f.NodeID), f.Attempt, f.Kind, f.Code,
+			f.Message))
+	}
 }
 
 func (p WorkflowPrinter) PrintSubWorkflow(ctx context.Context, tree gotree.Tree, w v1alpha1.ExecutableWorkflow, swf v1alpha1.ExecutableSubWorkflow, ns v1alpha1.ExecutableNodeStatus) error {
@@ -67,11 +87,11 @@ func (p WorkflowPrinter) PrintSubWorkflow(ctx context.Context, tree gotree.Tree,
 	}
 	newTree := gotree.New(fmt.Sprintf("SubWorkflow [%s] (%s %s %s)",
 		swf.GetID(), CalculateWorkflowRuntime(ns),
-		ColorizeNodePhase(ns.GetPhase()), ns.GetMessage()))
+		p.Transitions.ColorizeNodePhase(nodePhaseKey(swf.GetID(), ns), ns.GetPhase()), ns.GetMessage()))
 	if tree != nil {
 		tree.AddTree(newTree)
 	}
-	np := NodePrinter{}
+	np := NodePrinter{NodeStatusPrinter{Transitions: p.Transitions}}
 
 	return np.PrintList(ctx, newTree, &ContextualWorkflow{MetaExtended: w, ExecutableSubWorkflow: swf, NodeStatusGetter: ns}, sortedNodes)
 }
@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/transformers/k8s"
+	"github.com/lyft/flytepropeller/pkg/controller"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+// Length of the prefix of the original execution name kept in generated execution ids. K8s names are limited to 63
+// chars and the execution id is used to construct pod names.
+const relaunchNamePrefixLength = 20
+
+type RelaunchOpts struct {
+	*RootOptions
+	execID           string
+	recoverNodes     bool
+	annotations      *stringMapValue
+	dryRun           bool
+	configFile       string
+	nodeStatusReader *workflowstore.OffloadedNodeStatusReader
+	out              io.Writer
+}
+
+func NewRelaunchCommand(opts *RootOptions) *cobra.Command {
+
+	relaunchOpts := &RelaunchOpts{
+		RootOptions: opts,
+		out:         os.Stdout,
+	}
+
+	relaunchCmd := &cobra.Command{
+		Use:   "relaunch [opts] <workflow_name>",
+		Short: "Relaunches a workflow as a new execution, optionally recovering the nodes that already succeeded",
+		Long: `The spec, inputs, service account, labels and annotations of the workflow are cloned into a new execution.
+With --recover, every top level node that succeeded in the original execution is marked as succeeded in the new one and
+its outputs are read from the original execution, so only the failed nodes and the nodes downstream of them run again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("a workflow name is required")
+			}
+			ctx := context.Background()
+			reader, err := newNodeStatusReader(ctx, relaunchOpts.configFile)
+			if err != nil {
+				return err
+			}
+			relaunchOpts.nodeStatusReader = reader
+			return relaunchOpts.relaunchWorkflow(ctx, args[0])
+		},
+	}
+
+	relaunchCmd.Flags().StringVarP(&relaunchOpts.execID, executionIDKey, "", "", "Execution Id of the new workflow. Generated from the original execution id if not set.")
+	relaunchCmd.Flags().BoolVar(&relaunchOpts.recoverNodes, "recover", false, "Recover the nodes that succeeded in the original execution instead of running them again.")
+	relaunchOpts.annotations = newStringMapValue()
+	relaunchCmd.Flags().VarP(relaunchOpts.annotations, annotationsKey, "a", "Defines extra annotations to declare on the new workflow.")
+	relaunchCmd.Flags().BoolVarP(&relaunchOpts.dryRun, "dry-run", "d", false, "Prints the new workflow to STDOUT, but does not create it.")
+	relaunchCmd.Flags().StringVar(&relaunchOpts.configFile, "config", "", "Propeller config file, used to read node statuses offloaded to blob storage with --recover.")
+
+	return relaunchCmd
+}
+
+func generateRelaunchExecutionID(name string) string {
+	if len(name) > relaunchNamePrefixLength {
+		name = strings.TrimRight(name[:relaunchNamePrefixLength], "-")
+	}
+	return fmt.Sprintf("%s-%s", name, rand.String(5))
+}
+
+// Clones the spec of a workflow into a new execution. The status and the completed labels are not cloned.
+func cloneWorkflow(w *v1alpha1.FlyteWorkflow, execID string) *v1alpha1.FlyteWorkflow {
+	orig := w.DeepCopy()
+	labels := orig.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	clone := &v1alpha1.FlyteWorkflow{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       v1alpha1.FlyteWorkflowKind,
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   orig.Namespace,
+			Name:        execID,
+			Labels:      labels,
+			Annotations: orig.GetAnnotations(),
+		},
+		WorkflowSpec:          orig.WorkflowSpec,
+		Inputs:                orig.Inputs,
+		Tasks:                 orig.Tasks,
+		SubWorkflows:          orig.SubWorkflows,
+		ActiveDeadlineSeconds: orig.ActiveDeadlineSeconds,
+		NodeDefaults:          orig.NodeDefaults,
+		ServiceAccountName:    orig.ServiceAccountName,
+	}
+
+	project := ""
+	if orig.ExecutionID.WorkflowExecutionIdentifier != nil {
+		project = orig.ExecutionID.GetProject()
+		clone.ExecutionID = v1alpha1.ExecutionID{
+			WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{
+				Project: project,
+				Domain:  orig.ExecutionID.GetDomain(),
+				Name:    execID,
+			},
+		}
+	}
+
+	// The original workflow is usually completed, the new execution must not be
+	controller.ClearCompletedLabels(clone)
+	clone.Labels[k8s.ExecutionIDLabel] = execID
+	k8s.SetShardKeyLabels(clone, project)
+	return clone
+}
+
+// Marks every top level node that succeeded in the original execution as succeeded in the new one, reading its outputs
+// from the output dir of the original execution. Returns the ids of the recovered nodes.
+func recoverNodes(ctx context.Context, orig, w *v1alpha1.FlyteWorkflow) ([]v1alpha1.NodeID, error) {
+	if len(orig.GetExecutionStatus().GetDataDir()) == 0 {
+		return nil, fmt.Errorf("workflow [%s] has no data dir, it never started executing", orig.GetK8sWorkflowID())
+	}
+
+	orig = orig.DeepCopy()
+	orig.DataReferenceConstructor = storage.URLPathConstructor{}
+	var recovered []v1alpha1.NodeID
+	for _, id := range sortedNodeIDs(orig.Status.NodeStatus) {
+		status := orig.GetNodeExecutionStatus(ctx, id)
+		// The inputs of the new execution are written as the outputs of its start node
+		if id == v1alpha1.StartNodeID || status.GetPhase() != v1alpha1.NodePhaseSucceeded {
+			continue
+		}
+
+		// Branch nodes forward the outputs of the node taken
+		outputDir := status.GetOutputDir()
+		var branchStatus *v1alpha1.BranchNodeStatus
+		if b := orig.Status.NodeStatus[id].BranchStatus; b != nil {
+			branchStatus = b.DeepCopy()
+			if b.GetFinalizedNode() != nil {
+				outputDir = orig.GetNodeExecutionStatus(ctx, *b.GetFinalizedNode()).GetOutputDir()
+			}
+		}
+
+		if w.Status.NodeStatus == nil {
+			w.Status.NodeStatus = map[v1alpha1.NodeID]*v1alpha1.NodeStatus{}
+		}
+		w.Status.NodeStatus[id] = &v1alpha1.NodeStatus{
+			Phase:              v1alpha1.NodePhaseSucceeded,
+			Message:            fmt.Sprintf("Recovered from [%s]", orig.GetK8sWorkflowID()),
+			Attempts:           status.GetAttempts(),
+			Cached:             status.IsCached(),
+			BranchStatus:       branchStatus,
+			RecoveredOutputDir: outputDir,
+		}
+		recovered = append(recovered, id)
+	}
+
+	return recovered, nil
+}
+
+func sortedNodeIDs(statuses map[v1alpha1.NodeID]*v1alpha1.NodeStatus) []v1alpha1.NodeID {
+	ids := make([]v1alpha1.NodeID, 0, len(statuses))
+	for id := range statuses {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (r *RelaunchOpts) relaunchWorkflow(ctx context.Context, name string) error {
+	namespace := r.ConfigOverrides.Context.Namespace
+	if parts := strings.Split(name, "/"); len(parts) > 1 {
+		namespace = parts[0]
+		name = parts[1]
+	}
+
+	orig, err := r.flyteClient.FlyteworkflowV1alpha1().FlyteWorkflows(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	execID := r.execID
+	if len(execID) == 0 {
+		origName := orig.Name
+		if orig.ExecutionID.WorkflowExecutionIdentifier != nil {
+			origName = orig.ExecutionID.GetName()
+		}
+		execID = generateRelaunchExecutionID(origName)
+	}
+
+	w := cloneWorkflow(orig, execID)
+	if w.Annotations == nil {
+		w.Annotations = map[string]string{}
+	}
+	for key, val := range *r.annotations.value {
+		w.Annotations[key] = val
+	}
+
+	var recovered []v1alpha1.NodeID
+	if r.recoverNodes {
+		// The succeeded nodes are only known from the node status of the original workflow
+		orig, err = rehydrateNodeStatus(ctx, r.nodeStatusReader, orig)
+		if err != nil {
+			return err
+		}
+
+		recovered, err = recoverNodes(ctx, orig, w)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.dryRun {
+		j, err := json.Marshal(w)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to marshal the relaunched workflow.")
+		}
+		y, err := yaml.JSONToYAML(j)
+		if err != nil {
+			return errors.Wrapf(err, "Failed to marshal the relaunched workflow from json to yaml.")
+		}
+		_, err = fmt.Fprintln(r.out, string(y))
+		return err
+	}
+
+	created, err := r.flyteClient.FlyteworkflowV1alpha1().FlyteWorkflows(namespace).Create(w)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(r.out, "Successfully relaunched Flyte Workflow %v as %v, recovered %d nodes %v.\n",
+		orig.GetK8sWorkflowID(), created.GetK8sWorkflowID(), len(recovered), recovered)
+	return err
+}
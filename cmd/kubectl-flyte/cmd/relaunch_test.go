@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/fake"
+	"github.com/lyft/flytepropeller/pkg/compiler/transformers/k8s"
+	"github.com/lyft/flytepropeller/pkg/controller"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+	"github.com/lyft/flytepropeller/pkg/utils"
+)
+
+func newRelaunchTestWorkflow() *v1alpha1.FlyteWorkflow {
+	branchTaken := "b1-n0"
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "exec",
+			Labels:      map[string]string{k8s.ExecutionIDLabel: "exec", k8s.WorkflowNameLabel: "wf"},
+			Annotations: map[string]string{"owner": "flyte"},
+			Finalizers:  []string{"flyte-finalizer"},
+		},
+		ExecutionID: v1alpha1.WorkflowExecutionIdentifier{
+			WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: "d", Name: "exec"},
+		},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+				"n1":                 {ID: "n1", Kind: v1alpha1.NodeKindTask},
+				"b1":                 {ID: "b1", Kind: v1alpha1.NodeKindBranch},
+				branchTaken:          {ID: branchTaken, Kind: v1alpha1.NodeKindTask},
+				"n2":                 {ID: "n2", Kind: v1alpha1.NodeKindTask},
+				v1alpha1.EndNodeID:   {ID: v1alpha1.EndNodeID, Kind: v1alpha1.NodeKindEnd},
+			},
+		},
+		Inputs:             &v1alpha1.Inputs{LiteralMap: utils.MustMakeLiteral(map[string]interface{}{"x": 1}).GetMap()},
+		ServiceAccountName: "sa",
+		Status: v1alpha1.WorkflowStatus{
+			Phase:   v1alpha1.WorkflowPhaseFailed,
+			DataDir: "s3://bucket/metadata/p-d-exec",
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				v1alpha1.StartNodeID: {Phase: v1alpha1.NodePhaseSucceeded},
+				"n1":                 {Phase: v1alpha1.NodePhaseSucceeded, Attempts: 2},
+				"b1": {Phase: v1alpha1.NodePhaseSucceeded, BranchStatus: &v1alpha1.BranchNodeStatus{
+					Phase: v1alpha1.BranchNodeSuccess, FinalizedNodeID: &branchTaken,
+				}},
+				branchTaken: {Phase: v1alpha1.NodePhaseSucceeded, Attempts: 1},
+				"n2":        {Phase: v1alpha1.NodePhaseFailed, Attempts: 3},
+			},
+		},
+	}
+}
+
+func TestCloneWorkflow(t *testing.T) {
+	orig := newRelaunchTestWorkflow()
+	w := cloneWorkflow(orig, "exec-2")
+
+	assert.Equal(t, "exec-2", w.Name)
+	assert.Equal(t, "ns", w.Namespace)
+	assert.Empty(t, w.Finalizers)
+	assert.Equal(t, "exec-2", w.ExecutionID.GetName())
+	assert.Equal(t, "p", w.ExecutionID.GetProject())
+	assert.Equal(t, "exec-2", w.Labels[k8s.ExecutionIDLabel])
+	assert.Equal(t, "wf", w.Labels[k8s.WorkflowNameLabel])
+	assert.NotEmpty(t, w.Labels[k8s.ExecutionShardKeyLabel])
+	assert.Equal(t, map[string]string{"owner": "flyte"}, w.Annotations)
+	assert.Equal(t, "sa", w.ServiceAccountName)
+	assert.Equal(t, orig.Inputs, w.Inputs)
+	assert.Equal(t, orig.WorkflowSpec, w.WorkflowSpec)
+	assert.Equal(t, v1alpha1.WorkflowPhaseReady, w.Status.Phase)
+	assert.Empty(t, w.Status.NodeStatus)
+
+	// The original is not modified
+	assert.Equal(t, "exec", orig.Labels[k8s.ExecutionIDLabel])
+
+	t.Run("completed", func(t *testing.T) {
+		orig := newRelaunchTestWorkflow()
+		orig.Annotations[controller.RetainWorkflowKey] = "true"
+		controller.SetCompletedLabel(orig, time.Now())
+		assert.True(t, controller.HasCompletedLabel(orig))
+		labelCount := len(orig.Labels)
+
+		w := cloneWorkflow(orig, "exec-2")
+		assert.False(t, controller.HasCompletedLabel(w))
+		for key := range orig.Labels {
+			if _, ok := newRelaunchTestWorkflow().Labels[key]; !ok {
+				assert.NotContains(t, w.Labels, key)
+			}
+		}
+		assert.Equal(t, "wf", w.Labels[k8s.WorkflowNameLabel])
+		assert.Equal(t, labelCount, len(orig.Labels))
+	})
+}
+
+func TestRecoverNodes(t *testing.T) {
+	ctx := context.TODO()
+	orig := newRelaunchTestWorkflow()
+	w := cloneWorkflow(orig, "exec-2")
+
+	recovered, err := recoverNodes(ctx, orig, w)
+	assert.NoError(t, err)
+	assert.Equal(t, []v1alpha1.NodeID{"b1", "b1-n0", "n1"}, recovered)
+
+	n1 := w.Status.NodeStatus["n1"]
+	assert.Equal(t, v1alpha1.NodePhaseSucceeded, n1.Phase)
+	assert.Equal(t, uint32(2), n1.Attempts)
+	assert.Equal(t, v1alpha1.DataReference("s3://bucket/metadata/p-d-exec/n1/data/2"), n1.RecoveredOutputDir)
+	// The branch node forwards the outputs of the node taken
+	assert.Equal(t, v1alpha1.DataReference("s3://bucket/metadata/p-d-exec/b1-n0/data/1"), w.Status.NodeStatus["b1"].RecoveredOutputDir)
+	assert.NotContains(t, w.Status.NodeStatus, v1alpha1.StartNodeID)
+	assert.NotContains(t, w.Status.NodeStatus, "n2")
+
+	// Relaunching with recovery from a relaunched workflow reads the outputs of the first execution
+	w.Status.DataDir = "s3://bucket/metadata/p-d-exec-2"
+	third := cloneWorkflow(w, "exec-3")
+	_, err = recoverNodes(ctx, w, third)
+	assert.NoError(t, err)
+	assert.Equal(t, n1.RecoveredOutputDir, third.Status.NodeStatus["n1"].RecoveredOutputDir)
+
+	_, err = recoverNodes(ctx, third, cloneWorkflow(third, "exec-4"))
+	assert.Error(t, err)
+}
+
+func TestRelaunchOpts_relaunchWorkflow(t *testing.T) {
+	ctx := context.TODO()
+	client := fake.NewSimpleClientset()
+	_, err := client.FlyteworkflowV1alpha1().FlyteWorkflows("ns").Create(newRelaunchTestWorkflow())
+	assert.NoError(t, err)
+
+	newOpts := func() (*RelaunchOpts, *bytes.Buffer) {
+		out := &bytes.Buffer{}
+		annotations := newStringMapValue()
+		assert.NoError(t, annotations.Set("reason=retry"))
+		return &RelaunchOpts{
+			RootOptions:  &RootOptions{ConfigOverrides: &clientcmd.ConfigOverrides{}, flyteClient: client},
+			recoverNodes: true,
+			annotations:  annotations,
+			out:          out,
+		}, out
+	}
+
+	t.Run("dry-run", func(t *testing.T) {
+		opts, out := newOpts()
+		opts.execID = "exec-2"
+		opts.dryRun = true
+		assert.NoError(t, opts.relaunchWorkflow(ctx, "ns/exec"))
+		assert.Contains(t, out.String(), "recoveredOutputDir: s3://bucket/metadata/p-d-exec/n1/data/2")
+		_, err := client.FlyteworkflowV1alpha1().FlyteWorkflows("ns").Get("exec-2", v1.GetOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		opts, out := newOpts()
+		assert.NoError(t, opts.relaunchWorkflow(ctx, "ns/exec"))
+		assert.Contains(t, out.String(), "recovered 3 nodes")
+
+		var created *v1alpha1.FlyteWorkflow
+		for _, action := range client.Actions() {
+			if create, ok := action.(clienttesting.CreateAction); ok {
+				created = create.GetObject().(*v1alpha1.FlyteWorkflow)
+			}
+		}
+		if assert.NotNil(t, created) {
+			assert.True(t, strings.HasPrefix(created.Name, "exec-"))
+			assert.Equal(t, "retry", created.Annotations["reason"])
+			assert.Len(t, created.Status.NodeStatus, 3)
+		}
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		opts, _ := newOpts()
+		assert.Error(t, opts.relaunchWorkflow(ctx, "ns/missing"))
+	})
+
+	t.Run("offloaded", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+
+		// Offloads the node status the way propeller does
+		w := newRelaunchTestWorkflow()
+		w.Name = "offloaded"
+		underlying := workflowstore.NewInMemoryWorkflowStore()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+		_, err = workflowstore.NewOffloadingWorkflowStore(ctx, promutils.NewTestScope(), 1, 0, dataStore, underlying).
+			UpdateStatus(ctx, w, workflowstore.PriorityClassRegular)
+		assert.NoError(t, err)
+		offloaded, err := underlying.Get(ctx, "ns", "offloaded")
+		assert.NoError(t, err)
+		assert.Empty(t, offloaded.Status.NodeStatus)
+		_, err = client.FlyteworkflowV1alpha1().FlyteWorkflows("ns").Create(offloaded)
+		assert.NoError(t, err)
+
+		opts, _ := newOpts()
+		opts.dryRun = true
+		err = opts.relaunchWorkflow(ctx, "ns/offloaded")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "pass --config")
+		}
+
+		opts, out := newOpts()
+		opts.dryRun = true
+		opts.nodeStatusReader = workflowstore.NewOffloadedNodeStatusReader(dataStore, 1)
+		assert.NoError(t, opts.relaunchWorkflow(ctx, "ns/offloaded"))
+		assert.Contains(t, out.String(), "recoveredOutputDir: s3://bucket/metadata/p-d-exec/n1/data/2")
+	})
+}
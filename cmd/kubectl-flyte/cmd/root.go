@@ -96,8 +96,11 @@ func NewFlyteCommand() *cobra.Command {
 
 	command.AddCommand(NewDeleteCommand(rootOpts))
 	command.AddCommand(NewGetCommand(rootOpts))
+	command.AddCommand(NewWatchCommand(rootOpts))
+	command.AddCommand(NewDescribeNodeCommand(rootOpts))
 	command.AddCommand(NewVisualizeCommand(rootOpts))
 	command.AddCommand(NewCreateCommand(rootOpts))
+	command.AddCommand(NewRelaunchCommand(rootOpts))
 	command.AddCommand(NewCompileCommand(rootOpts))
 
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
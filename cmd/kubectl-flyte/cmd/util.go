@@ -1,9 +1,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/lyft/flytestdlib/config"
+	"github.com/lyft/flytestdlib/config/viper"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
 	"github.com/spf13/cobra"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
 )
 
 func requiredFlags(cmd *cobra.Command, flags ...string) error {
@@ -16,3 +24,46 @@ func requiredFlags(cmd *cobra.Command, flags ...string) error {
 
 	return nil
 }
+
+// Loads the propeller config file and creates the data store it configures, to read from propeller's blob storage.
+func newDataStore(ctx context.Context, configFile string) (*storage.DataStore, error) {
+	configAccessor := viper.NewAccessor(config.Options{
+		SearchPaths: []string{configFile},
+	})
+	if err := configAccessor.UpdateConfig(ctx); err != nil {
+		return nil, err
+	}
+
+	return storage.NewDataStore(storage.GetConfig(), promutils.NewScope("kubectl_flyte"))
+}
+
+// Creates a reader for the node statuses offloaded to blob storage, from the propeller config file. Returns nil if no
+// config file is given.
+func newNodeStatusReader(ctx context.Context, configFile string) (*workflowstore.OffloadedNodeStatusReader, error) {
+	if configFile == "" {
+		return nil, nil
+	}
+
+	store, err := newDataStore(ctx, configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return workflowstore.NewOffloadedNodeStatusReader(store, workflowstore.GetConfig().NodeStatusCacheSize), nil
+}
+
+// Inlines the node status of the workflow if it was offloaded to blob storage, which requires a reader.
+func rehydrateNodeStatus(ctx context.Context, reader *workflowstore.OffloadedNodeStatusReader, w *v1alpha1.FlyteWorkflow) (
+	*v1alpha1.FlyteWorkflow, error) {
+
+	if w.Status.OffloadedNodeStatus == nil {
+		return w, nil
+	}
+
+	if reader == nil {
+		return nil, fmt.Errorf("the node status of workflow [%s] is offloaded to blob storage, pass --config to read it",
+			w.GetK8sWorkflowID())
+	}
+
+	return reader.Rehydrate(ctx, w)
+}
@@ -1,39 +1,84 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
 	"github.com/lyft/flytepropeller/pkg/visualize"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	vizFormatDot  = "dot"
+	vizFormatHTML = "html"
+)
+
 type VisualizeOpts struct {
 	*RootOptions
+	format           string
+	configFile       string
+	nodeStatusReader *workflowstore.OffloadedNodeStatusReader
+	out              io.Writer
 }
 
 func NewVisualizeCommand(opts *RootOptions) *cobra.Command {
 
 	vizOpts := &VisualizeOpts{
 		RootOptions: opts,
+		out:         os.Stdout,
 	}
 
 	visualizeCmd := &cobra.Command{
 		Use:   "visualize <workflow_name>",
 		Short: "Get GraphViz dot-formatted output.",
-		Long:  `Generates GraphViz dot-formatted output for the workflow.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Generates GraphViz dot-formatted output for the workflow. With --format html, generates a self-contained html
+page of the current state of the workflow instead, with nodes colored by phase.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
-			w, err := vizOpts.flyteClient.FlyteworkflowV1alpha1().FlyteWorkflows(vizOpts.ConfigOverrides.Context.Namespace).Get(name, v1.GetOptions{})
+			ctx := context.Background()
+			reader, err := newNodeStatusReader(ctx, vizOpts.configFile)
 			if err != nil {
 				return err
 			}
-
-			fmt.Printf("Dot-formatted: %v\n", visualize.WorkflowToGraphViz(w))
-			return nil
+			vizOpts.nodeStatusReader = reader
+			return vizOpts.visualizeWorkflow(ctx, args[0])
 		},
 	}
 
+	visualizeCmd.Flags().StringVarP(&vizOpts.format, "format", "f", vizFormatDot, "Output format. Supported formats: dot (default), html")
+	visualizeCmd.Flags().StringVar(&vizOpts.configFile, "config", "", "Propeller config file, used to read node statuses offloaded to blob storage with --format html.")
+
 	return visualizeCmd
 }
+
+func (v *VisualizeOpts) visualizeWorkflow(ctx context.Context, name string) error {
+	w, err := v.flyteClient.FlyteworkflowV1alpha1().FlyteWorkflows(v.ConfigOverrides.Context.Namespace).Get(name, v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	switch v.format {
+	case vizFormatDot:
+		_, err = fmt.Fprintf(v.out, "Dot-formatted: %v\n", visualize.WorkflowToGraphViz(w))
+		return err
+	case vizFormatHTML:
+		// The page colors nodes by the phase in their status, which may be offloaded
+		w, err = rehydrateNodeStatus(ctx, v.nodeStatusReader, w)
+		if err != nil {
+			return err
+		}
+
+		page, err := visualize.WorkflowToHTML(ctx, w)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(v.out, page)
+		return err
+	}
+
+	return fmt.Errorf("unsupported format [%s], supported formats: %s, %s", v.format, vizFormatDot, vizFormatHTML)
+}
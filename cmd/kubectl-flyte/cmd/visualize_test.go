@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/fake"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+func newTestVisualizeOpts(w *v1alpha1.FlyteWorkflow, format string) (*VisualizeOpts, *bytes.Buffer) {
+	client := fake.NewSimpleClientset()
+	_, _ = client.FlyteworkflowV1alpha1().FlyteWorkflows(w.Namespace).Create(w)
+
+	out := &bytes.Buffer{}
+	return &VisualizeOpts{
+		RootOptions: &RootOptions{
+			ConfigOverrides: &clientcmd.ConfigOverrides{Context: clientcmdapi.Context{Namespace: w.Namespace}},
+			flyteClient:     client,
+		},
+		format: format,
+		out:    out,
+	}, out
+}
+
+func TestVisualizeWorkflow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("html", func(t *testing.T) {
+		opts, out := newTestVisualizeOpts(newWatchTestWorkflow(v1alpha1.WorkflowPhaseSuccess, v1alpha1.NodePhaseSucceeded), vizFormatHTML)
+		assert.NoError(t, opts.visualizeWorkflow(ctx, "wf"))
+		assert.Contains(t, out.String(), "<title>n1 (task)\nPhase: Succeeded")
+	})
+
+	t.Run("unsupported-format", func(t *testing.T) {
+		opts, _ := newTestVisualizeOpts(newWatchTestWorkflow(v1alpha1.WorkflowPhaseSuccess, v1alpha1.NodePhaseSucceeded), "svg")
+		assert.Error(t, opts.visualizeWorkflow(ctx, "wf"))
+	})
+
+	t.Run("offloaded", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+
+		// Offloads the node status the way propeller does
+		w := newWatchTestWorkflow(v1alpha1.WorkflowPhaseSuccess, v1alpha1.NodePhaseSucceeded)
+		w.Status.DataDir = "s3://bucket/ns/wf"
+		underlying := workflowstore.NewInMemoryWorkflowStore()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+		_, err = workflowstore.NewOffloadingWorkflowStore(ctx, promutils.NewTestScope(), 1, 0, dataStore, underlying).
+			UpdateStatus(ctx, w, workflowstore.PriorityClassRegular)
+		assert.NoError(t, err)
+		offloaded, err := underlying.Get(ctx, "ns", "wf")
+		assert.NoError(t, err)
+		assert.NotNil(t, offloaded.Status.OffloadedNodeStatus)
+
+		// The graph does not need the node statuses
+		opts, _ := newTestVisualizeOpts(offloaded, vizFormatDot)
+		assert.NoError(t, opts.visualizeWorkflow(ctx, "wf"))
+
+		opts, _ = newTestVisualizeOpts(offloaded, vizFormatHTML)
+		err = opts.visualizeWorkflow(ctx, "wf")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "pass --config")
+		}
+
+		opts, out := newTestVisualizeOpts(offloaded, vizFormatHTML)
+		opts.nodeStatusReader = workflowstore.NewOffloadedNodeStatusReader(dataStore, 1)
+		assert.NoError(t, opts.visualizeWorkflow(ctx, "wf"))
+		assert.Contains(t, out.String(), "<title>n1 (task)\nPhase: Succeeded")
+	})
+}
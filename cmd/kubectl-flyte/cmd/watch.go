@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	gotree "github.com/DiSiqueira/GoTree"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/lyft/flytepropeller/cmd/kubectl-flyte/cmd/printers"
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+// Moves the cursor home and clears the screen, so that every render replaces the previous one
+const clearScreen = "\033[H\033[2J"
+
+type WatchOpts struct {
+	*RootOptions
+	refreshInterval  time.Duration
+	noClear          bool
+	configFile       string
+	nodeStatusReader *workflowstore.OffloadedNodeStatusReader
+	out              io.Writer
+}
+
+func NewWatchCommand(opts *RootOptions) *cobra.Command {
+
+	watchOpts := &WatchOpts{
+		RootOptions: opts,
+		out:         os.Stdout,
+	}
+
+	watchCmd := &cobra.Command{
+		Use:   "watch [opts] <workflow_name>",
+		Short: "Watches a single workflow, re-rendering its node tree as node phases change",
+		Long: `Nodes that changed phase since the previous update are highlighted. Exits once the workflow completes, with a
+non-zero exit code if the workflow did not succeed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return fmt.Errorf("a workflow name is required")
+			}
+			ctx := context.Background()
+			reader, err := newNodeStatusReader(ctx, watchOpts.configFile)
+			if err != nil {
+				return err
+			}
+			watchOpts.nodeStatusReader = reader
+			return watchOpts.watchWorkflow(ctx, args[0])
+		},
+	}
+
+	watchCmd.Flags().DurationVarP(&watchOpts.refreshInterval, "refresh-interval", "r", time.Second, "Re-render at this interval to update the elapsed times, even if the workflow did not change.")
+	watchCmd.Flags().BoolVar(&watchOpts.noClear, "no-clear", false, "Appends every render instead of replacing the previous one.")
+	watchCmd.Flags().StringVar(&watchOpts.configFile, "config", "", "Propeller config file, used to read node statuses offloaded to blob storage.")
+
+	return watchCmd
+}
+
+// Returns whether the workflow completed and, if it did not succeed, an error describing why.
+func workflowResult(w *v1alpha1.FlyteWorkflow) (bool, error) {
+	status := w.GetExecutionStatus()
+	switch status.GetPhase() {
+	case v1alpha1.WorkflowPhaseSuccess:
+		return true, nil
+	case v1alpha1.WorkflowPhaseFailed, v1alpha1.WorkflowPhaseAborted:
+		msg := status.GetMessage()
+		if w.Status.Error != nil && w.Status.Error.GetMessage() != "" {
+			msg = w.Status.Error.GetMessage()
+		}
+		return true, fmt.Errorf("workflow [%s] %s: %s", w.GetK8sWorkflowID(), status.GetPhase().String(), msg)
+	}
+	return false, nil
+}
+
+func (w *WatchOpts) render(ctx context.Context, wf *v1alpha1.FlyteWorkflow, transitions *printers.PhaseTransitions) error {
+	wp := printers.WorkflowPrinter{Transitions: transitions}
+	tree := gotree.New("Workflow")
+	wf.DataReferenceConstructor = storage.URLPathConstructor{}
+	if err := wp.Print(ctx, tree, wf); err != nil {
+		return err
+	}
+
+	if !w.noClear {
+		if _, err := fmt.Fprint(w.out, clearScreen); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w.out, "%s\nLast updated at %s, watching for changes...\n", tree.Print(), time.Now().Format(time.Stamp))
+	return err
+}
+
+// Sends the workflow, dropping any update that has not been rendered yet. Only the latest version is ever rendered.
+func sendLatest(updates chan *v1alpha1.FlyteWorkflow, wf *v1alpha1.FlyteWorkflow) {
+	select {
+	case <-updates:
+	default:
+	}
+	updates <- wf
+}
+
+func (w *WatchOpts) watchWorkflow(ctx context.Context, name string) error {
+	namespace := w.ConfigOverrides.Context.Namespace
+	if parts := strings.Split(name, "/"); len(parts) > 1 {
+		namespace = parts[0]
+		name = parts[1]
+	}
+
+	client := w.flyteClient.FlyteworkflowV1alpha1().FlyteWorkflows(namespace)
+	// Fail fast if the workflow does not exist, rather than waiting for it to show up
+	latest, err := client.Get(name, v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+
+	// Offloaded node statuses are cached by the reader, so only changed statuses are read again
+	latest, err = rehydrateNodeStatus(ctx, w.nodeStatusReader, latest)
+	if err != nil {
+		return err
+	}
+
+	selector := fields.OneTermEqualSelector("metadata.name", name).String()
+	lw := &cache.ListWatch{
+		ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = selector
+			return client.List(options)
+		},
+		WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = selector
+			return client.Watch(options)
+		},
+	}
+
+	// A nil workflow signals that it was deleted
+	updates := make(chan *v1alpha1.FlyteWorkflow, 1)
+	onUpdate := func(obj interface{}) {
+		if wf, ok := obj.(*v1alpha1.FlyteWorkflow); ok && wf.GetName() == name {
+			// Objects of the informer cache are shared, never mutate them
+			sendLatest(updates, wf.DeepCopy())
+		}
+	}
+
+	_, informer := cache.NewInformer(lw, &v1alpha1.FlyteWorkflow{}, 0, cache.ResourceEventHandlerFuncs{
+		AddFunc: onUpdate,
+		UpdateFunc: func(_, newObj interface{}) {
+			onUpdate(newObj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if wf, ok := obj.(*v1alpha1.FlyteWorkflow); ok && wf.GetName() == name {
+				sendLatest(updates, nil)
+			}
+		},
+	})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go informer.Run(stop)
+
+	ticker := time.NewTicker(w.refreshInterval)
+	defer ticker.Stop()
+
+	transitions := printers.NewPhaseTransitions()
+	transitions.Next()
+	for {
+		if err := w.render(ctx, latest, transitions); err != nil {
+			return err
+		}
+
+		if done, err := workflowResult(latest); done {
+			return err
+		}
+
+		select {
+		case wf := <-updates:
+			if wf == nil {
+				return fmt.Errorf("workflow [%s/%s] was deleted", namespace, name)
+			}
+			wf, err := rehydrateNodeStatus(ctx, w.nodeStatusReader, wf)
+			if err != nil {
+				return err
+			}
+			// Only a new version of the workflow moves the baseline, re-renders keep highlighting the same transitions
+			if wf.GetResourceVersion() != latest.GetResourceVersion() {
+				transitions.Next()
+			}
+			latest = wf
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/fake"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+func newWatchTestWorkflow(phase v1alpha1.WorkflowPhase, nodePhase v1alpha1.NodePhase) *v1alpha1.FlyteWorkflow {
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: "wf", ResourceVersion: "1"},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+				"n1":                 {ID: "n1", Kind: v1alpha1.NodeKindTask},
+				v1alpha1.EndNodeID:   {ID: v1alpha1.EndNodeID, Kind: v1alpha1.NodeKindEnd},
+			},
+			Connections: v1alpha1.Connections{
+				DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+					v1alpha1.StartNodeID: {"n1"},
+					"n1":                 {v1alpha1.EndNodeID},
+				},
+			},
+		},
+		Status: v1alpha1.WorkflowStatus{
+			Phase: phase,
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				"n1": {Phase: nodePhase},
+			},
+		},
+	}
+}
+
+// The watch renders from its own goroutine, while the test reads the output
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func newTestWatchOpts(workflows ...*v1alpha1.FlyteWorkflow) (*WatchOpts, *fake.Clientset, *syncBuffer) {
+	client := fake.NewSimpleClientset()
+	for _, w := range workflows {
+		_, _ = client.FlyteworkflowV1alpha1().FlyteWorkflows(w.Namespace).Create(w)
+	}
+
+	// The object tracker of the fake clientset cannot list flyteworkflows, serve the list from the tracked objects
+	client.PrependReactor("list", "flyteworkflows", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		list := &v1alpha1.FlyteWorkflowList{}
+		for _, w := range workflows {
+			obj, err := client.Tracker().Get(v1alpha1.SchemeGroupVersion.WithResource("flyteworkflows"), w.Namespace, w.Name)
+			if err == nil {
+				list.Items = append(list.Items, *obj.(*v1alpha1.FlyteWorkflow))
+			}
+		}
+		return true, list, nil
+	})
+
+	out := &syncBuffer{}
+	return &WatchOpts{
+		RootOptions:     &RootOptions{ConfigOverrides: &clientcmd.ConfigOverrides{}, flyteClient: client},
+		refreshInterval: time.Hour,
+		noClear:         true,
+		out:             out,
+	}, client, out
+}
+
+func TestWorkflowResult(t *testing.T) {
+	done, err := workflowResult(newWatchTestWorkflow(v1alpha1.WorkflowPhaseRunning, v1alpha1.NodePhaseRunning))
+	assert.False(t, done)
+	assert.NoError(t, err)
+
+	done, err = workflowResult(newWatchTestWorkflow(v1alpha1.WorkflowPhaseSuccess, v1alpha1.NodePhaseSucceeded))
+	assert.True(t, done)
+	assert.NoError(t, err)
+
+	w := newWatchTestWorkflow(v1alpha1.WorkflowPhaseFailed, v1alpha1.NodePhaseFailed)
+	w.Status.Error = &v1alpha1.ExecutionError{ExecutionError: &core.ExecutionError{Message: "n1 failed"}}
+	done, err = workflowResult(w)
+	assert.True(t, done)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "n1 failed")
+	}
+}
+
+func TestWatchOpts_watchWorkflow(t *testing.T) {
+	color.NoColor = true
+	ctx := context.TODO()
+
+	t.Run("not-found", func(t *testing.T) {
+		opts, _, _ := newTestWatchOpts()
+		assert.Error(t, opts.watchWorkflow(ctx, "ns/wf"))
+	})
+
+	t.Run("failed", func(t *testing.T) {
+		opts, _, _ := newTestWatchOpts(newWatchTestWorkflow(v1alpha1.WorkflowPhaseFailed, v1alpha1.NodePhaseFailed))
+		assert.Error(t, opts.watchWorkflow(ctx, "ns/wf"))
+	})
+
+	t.Run("transitions", func(t *testing.T) {
+		opts, client, out := newTestWatchOpts(newWatchTestWorkflow(v1alpha1.WorkflowPhaseRunning, v1alpha1.NodePhaseRunning))
+		done := make(chan error)
+		go func() {
+			done <- opts.watchWorkflow(ctx, "ns/wf")
+		}()
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(out.String(), "watching for changes")
+		}, 10*time.Second, 10*time.Millisecond)
+
+		updated := newWatchTestWorkflow(v1alpha1.WorkflowPhaseSuccess, v1alpha1.NodePhaseSucceeded)
+		updated.ResourceVersion = "2"
+		_, err := client.FlyteworkflowV1alpha1().FlyteWorkflows("ns").Update(updated)
+		assert.NoError(t, err)
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(10 * time.Second):
+			assert.FailNow(t, "timed out waiting for the workflow to complete")
+		}
+
+		assert.Contains(t, out.String(), "Running -> Succeeded")
+	})
+
+	t.Run("offloaded", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+
+		// Offloads the node status the way propeller does
+		w := newWatchTestWorkflow(v1alpha1.WorkflowPhaseSuccess, v1alpha1.NodePhaseSucceeded)
+		w.Status.DataDir = "s3://bucket/ns/wf"
+		underlying := workflowstore.NewInMemoryWorkflowStore()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+		_, err = workflowstore.NewOffloadingWorkflowStore(ctx, promutils.NewTestScope(), 1, 0, dataStore, underlying).
+			UpdateStatus(ctx, w, workflowstore.PriorityClassRegular)
+		assert.NoError(t, err)
+		offloaded, err := underlying.Get(ctx, "ns", "wf")
+		assert.NoError(t, err)
+		assert.NotNil(t, offloaded.Status.OffloadedNodeStatus)
+
+		opts, _, _ := newTestWatchOpts(offloaded)
+		err = opts.watchWorkflow(ctx, "ns/wf")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "pass --config")
+		}
+
+		opts, _, out := newTestWatchOpts(offloaded)
+		opts.nodeStatusReader = workflowstore.NewOffloadedNodeStatusReader(dataStore, 1)
+		assert.NoError(t, opts.watchWorkflow(ctx, "ns/wf"))
+		assert.Contains(t, out.String(), "n1 (task) | na | Succeeded")
+	})
+}
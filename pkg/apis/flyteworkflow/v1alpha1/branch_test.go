@@ -7,6 +7,7 @@ import (
 
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,3 +21,34 @@ func TestMarshalUnMarshal_BranchTask(t *testing.T) {
 	assert.Equal(t, core.ComparisonExpression_GT, o.BranchNode.If.Condition.BooleanExpression.GetComparison().Operator)
 	assert.Equal(t, 1, len(o.InputBindings))
 }
+
+func TestMarshalUnMarshal_ComparisonOperators(t *testing.T) {
+	// Operators propeller supports in addition to the IDL are persisted using values the IDL does not assign
+	for _, op := range []common.ComparisonOperator{common.ComparisonOperatorContains, common.ComparisonOperatorStartsWith,
+		common.ComparisonOperatorMatches, common.ComparisonOperatorIsNull, common.ComparisonOperatorIsNotNull} {
+
+		t.Run(op.String(), func(t *testing.T) {
+			b := v1alpha1.BranchNodeSpec{
+				If: v1alpha1.IfBlock{
+					Condition: v1alpha1.BooleanExpression{
+						BooleanExpression: &core.BooleanExpression{
+							Expr: &core.BooleanExpression_Comparison{
+								Comparison: &core.ComparisonExpression{
+									Operator:  op.ToCore(),
+									LeftValue: &core.Operand{Val: &core.Operand_Var{Var: "x"}},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			raw, err := json.Marshal(b)
+			assert.NoError(t, err)
+
+			o := v1alpha1.BranchNodeSpec{}
+			assert.NoError(t, json.Unmarshal(raw, &o))
+			assert.Equal(t, op, common.GetComparisonOperator(o.If.GetCondition().GetComparison()))
+		})
+	}
+}
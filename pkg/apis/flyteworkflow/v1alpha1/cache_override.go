@@ -0,0 +1,85 @@
+package v1alpha1
+
+import (
+	"strings"
+)
+
+// Annotations of a FlyteWorkflow that override, for this execution only, how the discoverable tasks of its nodes use
+// the catalog cache. Their values are comma separated lists of node ids, or * for all the nodes of the workflow.
+const (
+	// The tasks of the nodes are executed without looking up their results in the cache, the results are still cached.
+	CacheSkipLookupAnnotationKey = "flyte.lyft.com/cache-skip-lookup"
+	// The tasks of the nodes are executed without looking up their results in the cache, and their results replace the
+	// cached ones.
+	CacheOverwriteAnnotationKey = "flyte.lyft.com/cache-overwrite"
+	// The results of the tasks of the nodes are neither looked up nor cached.
+	CacheDisableAnnotationKey = "flyte.lyft.com/cache-disable"
+
+	// Lists all the nodes of the workflow, including those of its sub workflows
+	CacheOverrideAllNodes = "*"
+)
+
+// CacheOverride determines how a node uses the catalog cache, in place of the discovery settings of its task
+type CacheOverride string
+
+const (
+	// The node uses the cache as configured by its task
+	CacheOverrideNone CacheOverride = ""
+	// See CacheSkipLookupAnnotationKey
+	CacheOverrideSkipLookup CacheOverride = "skip_lookup"
+	// See CacheOverwriteAnnotationKey
+	CacheOverrideOverwrite CacheOverride = "overwrite"
+	// See CacheDisableAnnotationKey
+	CacheOverrideDisable CacheOverride = "disable"
+)
+
+// Ordered by precedence, when a node is listed in several of the annotations
+var cacheOverrides = []CacheOverride{
+	CacheOverrideDisable,
+	CacheOverrideOverwrite,
+	CacheOverrideSkipLookup,
+}
+
+// Returns the workflow annotation that lists the nodes with the override
+func (c CacheOverride) AnnotationKey() string {
+	switch c {
+	case CacheOverrideSkipLookup:
+		return CacheSkipLookupAnnotationKey
+	case CacheOverrideOverwrite:
+		return CacheOverwriteAnnotationKey
+	case CacheOverrideDisable:
+		return CacheDisableAnnotationKey
+	}
+	return ""
+}
+
+// Returns true if the cached results of the task must not be looked up
+func (c CacheOverride) SkipsLookup() bool {
+	return c != CacheOverrideNone
+}
+
+// Returns true if the results of the task must not be cached
+func (c CacheOverride) SkipsWrite() bool {
+	return c == CacheOverrideDisable
+}
+
+// Returns the cache override of a node from the annotations of its workflow. Disabling the cache takes precedence over
+// overwriting it, which takes precedence over skipping the lookup.
+func GetCacheOverride(annotations map[string]string, nodeID NodeID) CacheOverride {
+	for _, c := range cacheOverrides {
+		if v, ok := annotations[c.AnnotationKey()]; ok && cacheOverrideMatches(v, nodeID) {
+			return c
+		}
+	}
+	return CacheOverrideNone
+}
+
+func cacheOverrideMatches(nodeIDs string, nodeID NodeID) bool {
+	for _, id := range strings.Split(nodeIDs, ",") {
+		id = strings.TrimSpace(id)
+		if id == CacheOverrideAllNodes || (id != "" && id == nodeID) {
+			return true
+		}
+	}
+	return false
+}
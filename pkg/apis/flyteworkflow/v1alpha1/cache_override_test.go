@@ -0,0 +1,33 @@
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCacheOverride(t *testing.T) {
+	annotations := map[string]string{
+		v1alpha1.CacheSkipLookupAnnotationKey: "n0, n1,n2",
+		v1alpha1.CacheOverwriteAnnotationKey:  "n1",
+		v1alpha1.CacheDisableAnnotationKey:    "n2,",
+	}
+
+	assert.Equal(t, v1alpha1.CacheOverrideSkipLookup, v1alpha1.GetCacheOverride(annotations, "n0"))
+	assert.Equal(t, v1alpha1.CacheOverrideOverwrite, v1alpha1.GetCacheOverride(annotations, "n1"))
+	assert.Equal(t, v1alpha1.CacheOverrideDisable, v1alpha1.GetCacheOverride(annotations, "n2"))
+	assert.Equal(t, v1alpha1.CacheOverrideNone, v1alpha1.GetCacheOverride(annotations, "n3"))
+	assert.Equal(t, v1alpha1.CacheOverrideNone, v1alpha1.GetCacheOverride(annotations, ""))
+	assert.Equal(t, v1alpha1.CacheOverrideNone, v1alpha1.GetCacheOverride(nil, "n0"))
+
+	annotations[v1alpha1.CacheOverwriteAnnotationKey] = "*"
+	assert.Equal(t, v1alpha1.CacheOverrideOverwrite, v1alpha1.GetCacheOverride(annotations, "n0"))
+	assert.Equal(t, v1alpha1.CacheOverrideOverwrite, v1alpha1.GetCacheOverride(annotations, "n3"))
+	assert.Equal(t, v1alpha1.CacheOverrideDisable, v1alpha1.GetCacheOverride(annotations, "n2"))
+
+	assert.True(t, v1alpha1.CacheOverrideSkipLookup.SkipsLookup())
+	assert.False(t, v1alpha1.CacheOverrideSkipLookup.SkipsWrite())
+	assert.True(t, v1alpha1.CacheOverrideDisable.SkipsWrite())
+	assert.False(t, v1alpha1.CacheOverrideNone.SkipsLookup())
+}
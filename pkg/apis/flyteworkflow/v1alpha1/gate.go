@@ -0,0 +1,41 @@
+package v1alpha1
+
+import (
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Spec for a gate node. A gate node pauses the execution of its downstream nodes until a signal is delivered to it.
+// The signal is a LiteralMap written to the signal file (see GetSignalFile) in the data directory of the node, its
+// literals are made available as the outputs of the gate node.
+type GateNodeSpec struct {
+	// Variables that the signaller is expected to supply. If not specified, any (or an empty) LiteralMap approves the
+	// gate.
+	// +optional
+	Signal *OutputVarMap `json:"signal,omitempty"`
+	// Maximum duration to wait for the signal, measured from the time the node started. The node fails once it elapses.
+	// Waits indefinitely if not specified.
+	// +optional
+	Timeout *v1.Duration `json:"timeout,omitempty"`
+}
+
+func (in *GateNodeSpec) GetSignal() *core.VariableMap {
+	if in.Signal == nil {
+		return nil
+	}
+	return in.Signal.VariableMap
+}
+
+func (in *GateNodeSpec) GetTimeout() *time.Duration {
+	if in.Timeout == nil {
+		return nil
+	}
+	return &in.Timeout.Duration
+}
+
+// Location of the file that approves a gate node, given the data directory of the node.
+func GetSignalFile(dataDir DataReference) DataReference {
+	return dataDir + "/signal.pb"
+}
@@ -44,6 +44,9 @@ const (
 	NodeKindWorkflow NodeKind = "workflow" // Either an inline workflow or a remote workflow definition
 	NodeKindStart    NodeKind = "start"    // Start node is a special node
 	NodeKindEnd      NodeKind = "end"
+	NodeKindMap      NodeKind = "map"   // Fans out a single task over a collection of inputs
+	NodeKindGate     NodeKind = "gate"  // Waits for an external signal before completing
+	NodeKindSleep    NodeKind = "sleep" // Waits for a duration or until a point in time
 )
 
 // NodePhase indicates the current state of the Node (phase). A node progresses through these states
@@ -112,6 +115,9 @@ const (
 	// its failure reason. In other words, its failure will mask the original failure for the workflow. It's imperative
 	// failure nodes should be very simple, very resilient and very well tested.
 	WorkflowPhaseHandlingFailureNode
+	// WorkflowPhaseQueued is the phase a ready workflow is held in while starting it would exceed one of the configured
+	// concurrency limits. The workflow moves on to running once it is admitted.
+	WorkflowPhaseQueued
 )
 
 func (p WorkflowPhase) String() string {
@@ -132,6 +138,8 @@ func (p WorkflowPhase) String() string {
 		return "Aborted"
 	case WorkflowPhaseHandlingFailureNode:
 		return "HandlingFailureNode"
+	case WorkflowPhaseQueued:
+		return "Queued"
 	}
 	return "Unknown"
 }
@@ -237,6 +245,36 @@ type ExecutableBranchNode interface {
 	GetElseFail() *core.Error
 }
 
+// Interface for a Map node, that executes the node's task once for every item of its (collection) inputs
+type ExecutableMapNode interface {
+	GetParallelism() uint32
+	GetMinSuccessRatio() float32
+}
+
+// Interface for a Gate node, that waits for an external signal (e.g. a manual approval)
+type ExecutableGateNode interface {
+	GetSignal() *core.VariableMap
+	GetTimeout() *time.Duration
+}
+
+// Interface for a Sleep node, that waits without executing anything
+type ExecutableSleepNode interface {
+	GetDuration() *time.Duration
+	GetUntil() *metav1.Time
+}
+
+type ExecutableMapNodeStatus interface {
+	GetMapNodePhase() MapNodePhase
+	GetItems() []MapItemStatus
+}
+
+type MutableMapNodeStatus interface {
+	Mutable
+	ExecutableMapNodeStatus
+	SetMapNodePhase(phase MapNodePhase)
+	SetItems(items []MapItemStatus)
+}
+
 type ExecutableWorkflowNodeStatus interface {
 	GetWorkflowNodePhase() WorkflowNodePhase
 	GetExecutionError() *core.ExecutionError
@@ -278,7 +316,12 @@ type MutableNodeStatus interface {
 	GetOrCreateDynamicNodeStatus() MutableDynamicNodeStatus
 	GetDynamicNodeStatus() MutableDynamicNodeStatus
 	ClearDynamicNodeStatus()
+	GetOrCreateMapNodeStatus() MutableMapNodeStatus
+	GetMapNodeStatus() MutableMapNodeStatus
+	ClearMapNodeStatus()
 	ClearLastAttemptStartedAt()
+	SetNextAttemptAt(t metav1.Time)
+	ClearNextAttemptAt()
 	ClearSubNodeStatus()
 }
 
@@ -297,6 +340,7 @@ type ExecutableNodeStatus interface {
 	GetPhase() NodePhase
 	GetQueuedAt() *metav1.Time
 	GetLastAttemptStartedAt() *metav1.Time
+	GetNextAttemptAt() *metav1.Time
 	GetParentNodeID() *NodeID
 	GetParentTaskID() *core.TaskExecutionIdentifier
 	GetDataDir() DataReference
@@ -328,6 +372,7 @@ type ExecutableTaskNodeStatus interface {
 	GetPluginStateVersion() uint32
 	GetBarrierClockTick() uint32
 	GetLastPhaseUpdatedAt() time.Time
+	GetCacheOverride() CacheOverride
 }
 
 type MutableTaskNodeStatus interface {
@@ -339,6 +384,7 @@ type MutableTaskNodeStatus interface {
 	SetPluginState([]byte)
 	SetPluginStateVersion(uint32)
 	SetBarrierClockTick(tick uint32)
+	SetCacheOverride(cacheOverride CacheOverride)
 }
 
 // Interface for a Child Workflow Node
@@ -360,6 +406,9 @@ type ExecutableNode interface {
 	GetTaskID() *TaskID
 	GetBranchNode() ExecutableBranchNode
 	GetWorkflowNode() ExecutableWorkflowNode
+	GetMapNode() ExecutableMapNode
+	GetGateNode() ExecutableGateNode
+	GetSleepNode() ExecutableSleepNode
 	GetOutputAlias() []Alias
 	GetInputBindings() []*Binding
 	GetResources() *v1.ResourceRequirements
@@ -368,6 +417,7 @@ type ExecutableNode interface {
 	GetExecutionDeadline() *time.Duration
 	GetActiveDeadline() *time.Duration
 	IsInterruptible() *bool
+	GetTriggerRule() TriggerRule
 }
 
 // Interface for the Workflow p. This is the mutable portion for a Workflow
@@ -379,6 +429,8 @@ type ExecutableWorkflowStatus interface {
 	IsTerminated() bool
 	GetMessage() string
 	GetExecutionError() *core.ExecutionError
+	GetNodeFailures() []NodeFailure
+	SetNodeFailures(failures []NodeFailure)
 	SetDataDir(DataReference)
 	GetDataDir() DataReference
 	GetOutputReference() DataReference
@@ -0,0 +1,23 @@
+package v1alpha1
+
+// Spec for a map node. The task referenced by the node is executed once for every item of the node's inputs, all of
+// which are expected to be collections of the same length. The outputs of all the items are gathered back into a
+// collection for every output variable of the task.
+type MapNodeSpec struct {
+	// Maximum number of items executing concurrently. 0 means all items are executed at once.
+	Parallelism uint32 `json:"parallelism,omitempty"`
+	// Ratio of items that need to succeed for the map node to succeed. Outputs of failed items are gathered as void.
+	// Defaults to 1.0, i.e all items should succeed.
+	MinSuccessRatio *float32 `json:"minSuccessRatio,omitempty"`
+}
+
+func (in *MapNodeSpec) GetParallelism() uint32 {
+	return in.Parallelism
+}
+
+func (in *MapNodeSpec) GetMinSuccessRatio() float32 {
+	if in.MinSuccessRatio == nil {
+		return 1.0
+	}
+	return *in.MinSuccessRatio
+}
@@ -153,6 +153,40 @@ func (_m *ExecutableNode) GetExecutionDeadline() *time.Duration {
 	return r0
 }
 
+type ExecutableNode_GetGateNode struct {
+	*mock.Call
+}
+
+func (_m ExecutableNode_GetGateNode) Return(_a0 v1alpha1.ExecutableGateNode) *ExecutableNode_GetGateNode {
+	return &ExecutableNode_GetGateNode{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNode) OnGetGateNode() *ExecutableNode_GetGateNode {
+	c := _m.On("GetGateNode")
+	return &ExecutableNode_GetGateNode{Call: c}
+}
+
+func (_m *ExecutableNode) OnGetGateNodeMatch(matchers ...interface{}) *ExecutableNode_GetGateNode {
+	c := _m.On("GetGateNode", matchers...)
+	return &ExecutableNode_GetGateNode{Call: c}
+}
+
+// GetGateNode provides a mock function with given fields: 
+func (_m *ExecutableNode) GetGateNode() v1alpha1.ExecutableGateNode {
+	ret := _m.Called()
+
+	var r0 v1alpha1.ExecutableGateNode
+	if rf, ok := ret.Get(0).(func() v1alpha1.ExecutableGateNode); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.ExecutableGateNode)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableNode_GetID struct {
 	*mock.Call
 }
@@ -251,6 +285,40 @@ func (_m *ExecutableNode) GetKind() v1alpha1.NodeKind {
 	return r0
 }
 
+type ExecutableNode_GetMapNode struct {
+	*mock.Call
+}
+
+func (_m ExecutableNode_GetMapNode) Return(_a0 v1alpha1.ExecutableMapNode) *ExecutableNode_GetMapNode {
+	return &ExecutableNode_GetMapNode{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNode) OnGetMapNode() *ExecutableNode_GetMapNode {
+	c := _m.On("GetMapNode")
+	return &ExecutableNode_GetMapNode{Call: c}
+}
+
+func (_m *ExecutableNode) OnGetMapNodeMatch(matchers ...interface{}) *ExecutableNode_GetMapNode {
+	c := _m.On("GetMapNode", matchers...)
+	return &ExecutableNode_GetMapNode{Call: c}
+}
+
+// GetMapNode provides a mock function with given fields: 
+func (_m *ExecutableNode) GetMapNode() v1alpha1.ExecutableMapNode {
+	ret := _m.Called()
+
+	var r0 v1alpha1.ExecutableMapNode
+	if rf, ok := ret.Get(0).(func() v1alpha1.ExecutableMapNode); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.ExecutableMapNode)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableNode_GetOutputAlias struct {
 	*mock.Call
 }
@@ -353,6 +421,40 @@ func (_m *ExecutableNode) GetRetryStrategy() *v1alpha1.RetryStrategy {
 	return r0
 }
 
+type ExecutableNode_GetSleepNode struct {
+	*mock.Call
+}
+
+func (_m ExecutableNode_GetSleepNode) Return(_a0 v1alpha1.ExecutableSleepNode) *ExecutableNode_GetSleepNode {
+	return &ExecutableNode_GetSleepNode{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNode) OnGetSleepNode() *ExecutableNode_GetSleepNode {
+	c := _m.On("GetSleepNode")
+	return &ExecutableNode_GetSleepNode{Call: c}
+}
+
+func (_m *ExecutableNode) OnGetSleepNodeMatch(matchers ...interface{}) *ExecutableNode_GetSleepNode {
+	c := _m.On("GetSleepNode", matchers...)
+	return &ExecutableNode_GetSleepNode{Call: c}
+}
+
+// GetSleepNode provides a mock function with given fields: 
+func (_m *ExecutableNode) GetSleepNode() v1alpha1.ExecutableSleepNode {
+	ret := _m.Called()
+
+	var r0 v1alpha1.ExecutableSleepNode
+	if rf, ok := ret.Get(0).(func() v1alpha1.ExecutableSleepNode); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.ExecutableSleepNode)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableNode_GetTaskID struct {
 	*mock.Call
 }
@@ -387,6 +489,38 @@ func (_m *ExecutableNode) GetTaskID() *string {
 	return r0
 }
 
+type ExecutableNode_GetTriggerRule struct {
+	*mock.Call
+}
+
+func (_m ExecutableNode_GetTriggerRule) Return(_a0 v1alpha1.TriggerRule) *ExecutableNode_GetTriggerRule {
+	return &ExecutableNode_GetTriggerRule{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNode) OnGetTriggerRule() *ExecutableNode_GetTriggerRule {
+	c := _m.On("GetTriggerRule")
+	return &ExecutableNode_GetTriggerRule{Call: c}
+}
+
+func (_m *ExecutableNode) OnGetTriggerRuleMatch(matchers ...interface{}) *ExecutableNode_GetTriggerRule {
+	c := _m.On("GetTriggerRule", matchers...)
+	return &ExecutableNode_GetTriggerRule{Call: c}
+}
+
+// GetTriggerRule provides a mock function with given fields:
+func (_m *ExecutableNode) GetTriggerRule() v1alpha1.TriggerRule {
+	ret := _m.Called()
+
+	var r0 v1alpha1.TriggerRule
+	if rf, ok := ret.Get(0).(func() v1alpha1.TriggerRule); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(v1alpha1.TriggerRule)
+	}
+
+	return r0
+}
+
 type ExecutableNode_GetWorkflowNode struct {
 	*mock.Call
 }
@@ -30,6 +30,16 @@ func (_m *ExecutableNodeStatus) ClearLastAttemptStartedAt() {
 	_m.Called()
 }
 
+// ClearMapNodeStatus provides a mock function with given fields: 
+func (_m *ExecutableNodeStatus) ClearMapNodeStatus() {
+	_m.Called()
+}
+
+// ClearNextAttemptAt provides a mock function with given fields: 
+func (_m *ExecutableNodeStatus) ClearNextAttemptAt() {
+	_m.Called()
+}
+
 // ClearSubNodeStatus provides a mock function with given fields:
 func (_m *ExecutableNodeStatus) ClearSubNodeStatus() {
 	_m.Called()
@@ -279,6 +289,40 @@ func (_m *ExecutableNodeStatus) GetLastUpdatedAt() *v1.Time {
 	return r0
 }
 
+type ExecutableNodeStatus_GetMapNodeStatus struct {
+	*mock.Call
+}
+
+func (_m ExecutableNodeStatus_GetMapNodeStatus) Return(_a0 v1alpha1.MutableMapNodeStatus) *ExecutableNodeStatus_GetMapNodeStatus {
+	return &ExecutableNodeStatus_GetMapNodeStatus{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNodeStatus) OnGetMapNodeStatus() *ExecutableNodeStatus_GetMapNodeStatus {
+	c := _m.On("GetMapNodeStatus")
+	return &ExecutableNodeStatus_GetMapNodeStatus{Call: c}
+}
+
+func (_m *ExecutableNodeStatus) OnGetMapNodeStatusMatch(matchers ...interface{}) *ExecutableNodeStatus_GetMapNodeStatus {
+	c := _m.On("GetMapNodeStatus", matchers...)
+	return &ExecutableNodeStatus_GetMapNodeStatus{Call: c}
+}
+
+// GetMapNodeStatus provides a mock function with given fields: 
+func (_m *ExecutableNodeStatus) GetMapNodeStatus() v1alpha1.MutableMapNodeStatus {
+	ret := _m.Called()
+
+	var r0 v1alpha1.MutableMapNodeStatus
+	if rf, ok := ret.Get(0).(func() v1alpha1.MutableMapNodeStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.MutableMapNodeStatus)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableNodeStatus_GetMessage struct {
 	*mock.Call
 }
@@ -311,6 +355,40 @@ func (_m *ExecutableNodeStatus) GetMessage() string {
 	return r0
 }
 
+type ExecutableNodeStatus_GetNextAttemptAt struct {
+	*mock.Call
+}
+
+func (_m ExecutableNodeStatus_GetNextAttemptAt) Return(_a0 *v1.Time) *ExecutableNodeStatus_GetNextAttemptAt {
+	return &ExecutableNodeStatus_GetNextAttemptAt{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNodeStatus) OnGetNextAttemptAt() *ExecutableNodeStatus_GetNextAttemptAt {
+	c := _m.On("GetNextAttemptAt")
+	return &ExecutableNodeStatus_GetNextAttemptAt{Call: c}
+}
+
+func (_m *ExecutableNodeStatus) OnGetNextAttemptAtMatch(matchers ...interface{}) *ExecutableNodeStatus_GetNextAttemptAt {
+	c := _m.On("GetNextAttemptAt", matchers...)
+	return &ExecutableNodeStatus_GetNextAttemptAt{Call: c}
+}
+
+// GetNextAttemptAt provides a mock function with given fields: 
+func (_m *ExecutableNodeStatus) GetNextAttemptAt() *v1.Time {
+	ret := _m.Called()
+
+	var r0 *v1.Time
+	if rf, ok := ret.Get(0).(func() *v1.Time); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Time)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableNodeStatus_GetNodeExecutionStatus struct {
 	*mock.Call
 }
@@ -413,6 +491,40 @@ func (_m *ExecutableNodeStatus) GetOrCreateDynamicNodeStatus() v1alpha1.MutableD
 	return r0
 }
 
+type ExecutableNodeStatus_GetOrCreateMapNodeStatus struct {
+	*mock.Call
+}
+
+func (_m ExecutableNodeStatus_GetOrCreateMapNodeStatus) Return(_a0 v1alpha1.MutableMapNodeStatus) *ExecutableNodeStatus_GetOrCreateMapNodeStatus {
+	return &ExecutableNodeStatus_GetOrCreateMapNodeStatus{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableNodeStatus) OnGetOrCreateMapNodeStatus() *ExecutableNodeStatus_GetOrCreateMapNodeStatus {
+	c := _m.On("GetOrCreateMapNodeStatus")
+	return &ExecutableNodeStatus_GetOrCreateMapNodeStatus{Call: c}
+}
+
+func (_m *ExecutableNodeStatus) OnGetOrCreateMapNodeStatusMatch(matchers ...interface{}) *ExecutableNodeStatus_GetOrCreateMapNodeStatus {
+	c := _m.On("GetOrCreateMapNodeStatus", matchers...)
+	return &ExecutableNodeStatus_GetOrCreateMapNodeStatus{Call: c}
+}
+
+// GetOrCreateMapNodeStatus provides a mock function with given fields: 
+func (_m *ExecutableNodeStatus) GetOrCreateMapNodeStatus() v1alpha1.MutableMapNodeStatus {
+	ret := _m.Called()
+
+	var r0 v1alpha1.MutableMapNodeStatus
+	if rf, ok := ret.Get(0).(func() v1alpha1.MutableMapNodeStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.MutableMapNodeStatus)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableNodeStatus_GetOrCreateTaskStatus struct {
 	*mock.Call
 }
@@ -1026,6 +1138,11 @@ func (_m *ExecutableNodeStatus) SetDataDir(_a0 storage.DataReference) {
 	_m.Called(_a0)
 }
 
+// SetNextAttemptAt provides a mock function with given fields: t
+func (_m *ExecutableNodeStatus) SetNextAttemptAt(t v1.Time) {
+	_m.Called(t)
+}
+
 // SetOutputDir provides a mock function with given fields: d
 func (_m *ExecutableNodeStatus) SetOutputDir(d storage.DataReference) {
 	_m.Called(d)
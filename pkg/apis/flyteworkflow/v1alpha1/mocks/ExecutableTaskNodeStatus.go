@@ -6,6 +6,8 @@ import (
 	time "time"
 
 	mock "github.com/stretchr/testify/mock"
+
+	v1alpha1 "github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 )
 
 // ExecutableTaskNodeStatus is an autogenerated mock type for the ExecutableTaskNodeStatus type
@@ -45,6 +47,38 @@ func (_m *ExecutableTaskNodeStatus) GetBarrierClockTick() uint32 {
 	return r0
 }
 
+type ExecutableTaskNodeStatus_GetCacheOverride struct {
+	*mock.Call
+}
+
+func (_m ExecutableTaskNodeStatus_GetCacheOverride) Return(_a0 v1alpha1.CacheOverride) *ExecutableTaskNodeStatus_GetCacheOverride {
+	return &ExecutableTaskNodeStatus_GetCacheOverride{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableTaskNodeStatus) OnGetCacheOverride() *ExecutableTaskNodeStatus_GetCacheOverride {
+	c := _m.On("GetCacheOverride")
+	return &ExecutableTaskNodeStatus_GetCacheOverride{Call: c}
+}
+
+func (_m *ExecutableTaskNodeStatus) OnGetCacheOverrideMatch(matchers ...interface{}) *ExecutableTaskNodeStatus_GetCacheOverride {
+	c := _m.On("GetCacheOverride", matchers...)
+	return &ExecutableTaskNodeStatus_GetCacheOverride{Call: c}
+}
+
+// GetCacheOverride provides a mock function with given fields:
+func (_m *ExecutableTaskNodeStatus) GetCacheOverride() v1alpha1.CacheOverride {
+	ret := _m.Called()
+
+	var r0 v1alpha1.CacheOverride
+	if rf, ok := ret.Get(0).(func() v1alpha1.CacheOverride); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(v1alpha1.CacheOverride)
+	}
+
+	return r0
+}
+
 type ExecutableTaskNodeStatus_GetLastPhaseUpdatedAt struct {
 	*mock.Call
 }
@@ -225,6 +225,40 @@ func (_m *ExecutableWorkflowStatus) GetNodeExecutionStatus(ctx context.Context,
 	return r0
 }
 
+type ExecutableWorkflowStatus_GetNodeFailures struct {
+	*mock.Call
+}
+
+func (_m ExecutableWorkflowStatus_GetNodeFailures) Return(_a0 []v1alpha1.NodeFailure) *ExecutableWorkflowStatus_GetNodeFailures {
+	return &ExecutableWorkflowStatus_GetNodeFailures{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *ExecutableWorkflowStatus) OnGetNodeFailures() *ExecutableWorkflowStatus_GetNodeFailures {
+	c := _m.On("GetNodeFailures")
+	return &ExecutableWorkflowStatus_GetNodeFailures{Call: c}
+}
+
+func (_m *ExecutableWorkflowStatus) OnGetNodeFailuresMatch(matchers ...interface{}) *ExecutableWorkflowStatus_GetNodeFailures {
+	c := _m.On("GetNodeFailures", matchers...)
+	return &ExecutableWorkflowStatus_GetNodeFailures{Call: c}
+}
+
+// GetNodeFailures provides a mock function with given fields:
+func (_m *ExecutableWorkflowStatus) GetNodeFailures() []v1alpha1.NodeFailure {
+	ret := _m.Called()
+
+	var r0 []v1alpha1.NodeFailure
+	if rf, ok := ret.Get(0).(func() []v1alpha1.NodeFailure); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]v1alpha1.NodeFailure)
+		}
+	}
+
+	return r0
+}
+
 type ExecutableWorkflowStatus_GetOutputReference struct {
 	*mock.Call
 }
@@ -404,6 +438,11 @@ func (_m *ExecutableWorkflowStatus) SetMessage(msg string) {
 	_m.Called(msg)
 }
 
+// SetNodeFailures provides a mock function with given fields: failures
+func (_m *ExecutableWorkflowStatus) SetNodeFailures(failures []v1alpha1.NodeFailure) {
+	_m.Called(failures)
+}
+
 // SetOutputReference provides a mock function with given fields: reference
 func (_m *ExecutableWorkflowStatus) SetOutputReference(reference storage.DataReference) {
 	_m.Called(reference)
@@ -28,6 +28,16 @@ func (_m *MutableNodeStatus) ClearLastAttemptStartedAt() {
 	_m.Called()
 }
 
+// ClearMapNodeStatus provides a mock function with given fields: 
+func (_m *MutableNodeStatus) ClearMapNodeStatus() {
+	_m.Called()
+}
+
+// ClearNextAttemptAt provides a mock function with given fields: 
+func (_m *MutableNodeStatus) ClearNextAttemptAt() {
+	_m.Called()
+}
+
 // ClearSubNodeStatus provides a mock function with given fields:
 func (_m *MutableNodeStatus) ClearSubNodeStatus() {
 	_m.Called()
@@ -111,6 +121,40 @@ func (_m *MutableNodeStatus) GetDynamicNodeStatus() v1alpha1.MutableDynamicNodeS
 	return r0
 }
 
+type MutableNodeStatus_GetMapNodeStatus struct {
+	*mock.Call
+}
+
+func (_m MutableNodeStatus_GetMapNodeStatus) Return(_a0 v1alpha1.MutableMapNodeStatus) *MutableNodeStatus_GetMapNodeStatus {
+	return &MutableNodeStatus_GetMapNodeStatus{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *MutableNodeStatus) OnGetMapNodeStatus() *MutableNodeStatus_GetMapNodeStatus {
+	c := _m.On("GetMapNodeStatus")
+	return &MutableNodeStatus_GetMapNodeStatus{Call: c}
+}
+
+func (_m *MutableNodeStatus) OnGetMapNodeStatusMatch(matchers ...interface{}) *MutableNodeStatus_GetMapNodeStatus {
+	c := _m.On("GetMapNodeStatus", matchers...)
+	return &MutableNodeStatus_GetMapNodeStatus{Call: c}
+}
+
+// GetMapNodeStatus provides a mock function with given fields: 
+func (_m *MutableNodeStatus) GetMapNodeStatus() v1alpha1.MutableMapNodeStatus {
+	ret := _m.Called()
+
+	var r0 v1alpha1.MutableMapNodeStatus
+	if rf, ok := ret.Get(0).(func() v1alpha1.MutableMapNodeStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.MutableMapNodeStatus)
+		}
+	}
+
+	return r0
+}
+
 type MutableNodeStatus_GetOrCreateBranchStatus struct {
 	*mock.Call
 }
@@ -179,6 +223,40 @@ func (_m *MutableNodeStatus) GetOrCreateDynamicNodeStatus() v1alpha1.MutableDyna
 	return r0
 }
 
+type MutableNodeStatus_GetOrCreateMapNodeStatus struct {
+	*mock.Call
+}
+
+func (_m MutableNodeStatus_GetOrCreateMapNodeStatus) Return(_a0 v1alpha1.MutableMapNodeStatus) *MutableNodeStatus_GetOrCreateMapNodeStatus {
+	return &MutableNodeStatus_GetOrCreateMapNodeStatus{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *MutableNodeStatus) OnGetOrCreateMapNodeStatus() *MutableNodeStatus_GetOrCreateMapNodeStatus {
+	c := _m.On("GetOrCreateMapNodeStatus")
+	return &MutableNodeStatus_GetOrCreateMapNodeStatus{Call: c}
+}
+
+func (_m *MutableNodeStatus) OnGetOrCreateMapNodeStatusMatch(matchers ...interface{}) *MutableNodeStatus_GetOrCreateMapNodeStatus {
+	c := _m.On("GetOrCreateMapNodeStatus", matchers...)
+	return &MutableNodeStatus_GetOrCreateMapNodeStatus{Call: c}
+}
+
+// GetOrCreateMapNodeStatus provides a mock function with given fields: 
+func (_m *MutableNodeStatus) GetOrCreateMapNodeStatus() v1alpha1.MutableMapNodeStatus {
+	ret := _m.Called()
+
+	var r0 v1alpha1.MutableMapNodeStatus
+	if rf, ok := ret.Get(0).(func() v1alpha1.MutableMapNodeStatus); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(v1alpha1.MutableMapNodeStatus)
+		}
+	}
+
+	return r0
+}
+
 type MutableNodeStatus_GetOrCreateTaskStatus struct {
 	*mock.Call
 }
@@ -426,6 +504,11 @@ func (_m *MutableNodeStatus) SetDataDir(_a0 storage.DataReference) {
 	_m.Called(_a0)
 }
 
+// SetNextAttemptAt provides a mock function with given fields: t
+func (_m *MutableNodeStatus) SetNextAttemptAt(t v1.Time) {
+	_m.Called(t)
+}
+
 // SetOutputDir provides a mock function with given fields: d
 func (_m *MutableNodeStatus) SetOutputDir(d storage.DataReference) {
 	_m.Called(d)
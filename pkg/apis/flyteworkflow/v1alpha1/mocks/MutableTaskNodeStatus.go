@@ -6,6 +6,8 @@ import (
 	time "time"
 
 	mock "github.com/stretchr/testify/mock"
+
+	v1alpha1 "github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 )
 
 // MutableTaskNodeStatus is an autogenerated mock type for the MutableTaskNodeStatus type
@@ -45,6 +47,38 @@ func (_m *MutableTaskNodeStatus) GetBarrierClockTick() uint32 {
 	return r0
 }
 
+type MutableTaskNodeStatus_GetCacheOverride struct {
+	*mock.Call
+}
+
+func (_m MutableTaskNodeStatus_GetCacheOverride) Return(_a0 v1alpha1.CacheOverride) *MutableTaskNodeStatus_GetCacheOverride {
+	return &MutableTaskNodeStatus_GetCacheOverride{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *MutableTaskNodeStatus) OnGetCacheOverride() *MutableTaskNodeStatus_GetCacheOverride {
+	c := _m.On("GetCacheOverride")
+	return &MutableTaskNodeStatus_GetCacheOverride{Call: c}
+}
+
+func (_m *MutableTaskNodeStatus) OnGetCacheOverrideMatch(matchers ...interface{}) *MutableTaskNodeStatus_GetCacheOverride {
+	c := _m.On("GetCacheOverride", matchers...)
+	return &MutableTaskNodeStatus_GetCacheOverride{Call: c}
+}
+
+// GetCacheOverride provides a mock function with given fields:
+func (_m *MutableTaskNodeStatus) GetCacheOverride() v1alpha1.CacheOverride {
+	ret := _m.Called()
+
+	var r0 v1alpha1.CacheOverride
+	if rf, ok := ret.Get(0).(func() v1alpha1.CacheOverride); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(v1alpha1.CacheOverride)
+	}
+
+	return r0
+}
+
 type MutableTaskNodeStatus_GetLastPhaseUpdatedAt struct {
 	*mock.Call
 }
@@ -244,6 +278,11 @@ func (_m *MutableTaskNodeStatus) SetBarrierClockTick(tick uint32) {
 	_m.Called(tick)
 }
 
+// SetCacheOverride provides a mock function with given fields: cacheOverride
+func (_m *MutableTaskNodeStatus) SetCacheOverride(cacheOverride v1alpha1.CacheOverride) {
+	_m.Called(cacheOverride)
+}
+
 // SetLastPhaseUpdatedAt provides a mock function with given fields: updatedAt
 func (_m *MutableTaskNodeStatus) SetLastPhaseUpdatedAt(updatedAt time.Time) {
 	_m.Called(updatedAt)
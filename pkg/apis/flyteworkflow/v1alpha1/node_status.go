@@ -181,6 +181,76 @@ func (in *WorkflowNodeStatus) SetWorkflowNodePhase(phase WorkflowNodePhase) {
 	}
 }
 
+type MapNodePhase int
+
+const (
+	MapNodePhaseUndefined MapNodePhase = iota
+	MapNodePhaseExecuting
+)
+
+type MapItemPhase uint8
+
+const (
+	MapItemPhaseNotYetStarted MapItemPhase = iota
+	MapItemPhaseRunning
+	MapItemPhaseSucceeded
+	MapItemPhaseFailed
+)
+
+func (p MapItemPhase) IsTerminal() bool {
+	return p == MapItemPhaseSucceeded || p == MapItemPhaseFailed
+}
+
+// Execution state of a single item of a map node. Map nodes may have a large number of items, so only the state that
+// is needed to drive the item's task is stored, using short keys to keep the CRD small.
+type MapItemStatus struct {
+	Phase              MapItemPhase `json:"p,omitempty"`
+	Attempts           uint32       `json:"a,omitempty"`
+	PluginPhase        int          `json:"pp,omitempty"`
+	PluginPhaseVersion uint32       `json:"ppv,omitempty"`
+	PluginState        []byte       `json:"ps,omitempty"`
+	PluginStateVersion uint32       `json:"psv,omitempty"`
+	BarrierClockTick   uint32       `json:"bt,omitempty"`
+}
+
+type MapNodeStatus struct {
+	MutableStruct
+	Phase MapNodePhase    `json:"phase"`
+	Items []MapItemStatus `json:"items,omitempty"`
+}
+
+func (in *MapNodeStatus) GetMapNodePhase() MapNodePhase {
+	return in.Phase
+}
+
+func (in *MapNodeStatus) SetMapNodePhase(phase MapNodePhase) {
+	if in.Phase != phase {
+		in.SetDirty()
+		in.Phase = phase
+	}
+}
+
+func (in *MapNodeStatus) GetItems() []MapItemStatus {
+	return in.Items
+}
+
+func (in *MapNodeStatus) SetItems(items []MapItemStatus) {
+	if !reflect.DeepEqual(in.Items, items) {
+		in.SetDirty()
+		in.Items = items
+	}
+}
+
+func (in *MapNodeStatus) Equals(o *MapNodeStatus) bool {
+	if in == nil && o == nil {
+		return true
+	}
+	if in == nil || o == nil {
+		return false
+	}
+	return in.Phase == o.Phase && reflect.DeepEqual(in.Items, o.Items)
+}
+
 type NodeStatus struct {
 	MutableStruct
 	Phase                NodePhase     `json:"phase"`
@@ -189,12 +259,16 @@ type NodeStatus struct {
 	StoppedAt            *metav1.Time  `json:"stoppedAt,omitempty"`
 	LastUpdatedAt        *metav1.Time  `json:"lastUpdatedAt,omitempty"`
 	LastAttemptStartedAt *metav1.Time  `json:"laStartedAt,omitempty"`
+	NextAttemptAt        *metav1.Time  `json:"nextAttemptAt,omitempty"`
 	Message              string        `json:"message,omitempty"`
 	DataDir              DataReference `json:"-"`
 	OutputDir            DataReference `json:"-"`
 	Attempts             uint32        `json:"attempts"`
 	SystemFailures       uint32        `json:"systemFailures,omitempty"`
 	Cached               bool          `json:"cached"`
+	// Set on nodes recovered from a previous execution, the outputs of the node are read from here instead of the
+	// output dir of this execution
+	RecoveredOutputDir DataReference `json:"recoveredOutputDir,omitempty"`
 
 	// This is useful only for branch nodes. If this is set, then it can be used to determine if execution can proceed
 	ParentNode    *NodeID                  `json:"parentNode,omitempty"`
@@ -208,6 +282,7 @@ type NodeStatus struct {
 
 	TaskNodeStatus    *TaskNodeStatus    `json:",omitempty"`
 	DynamicNodeStatus *DynamicNodeStatus `json:"dynamicNodeStatus,omitempty"`
+	MapNodeStatus     *MapNodeStatus     `json:"mapNodeStatus,omitempty"`
 	// In case of Failing/Failed Phase, an execution error can be optionally associated with the Node
 	Error *ExecutionError `json:"error,omitempty"`
 
@@ -220,6 +295,7 @@ func (in *NodeStatus) IsDirty() bool {
 		(in.TaskNodeStatus != nil && in.TaskNodeStatus.IsDirty()) ||
 		(in.DynamicNodeStatus != nil && in.DynamicNodeStatus.IsDirty()) ||
 		(in.WorkflowNodeStatus != nil && in.WorkflowNodeStatus.IsDirty()) ||
+		(in.MapNodeStatus != nil && in.MapNodeStatus.IsDirty()) ||
 		(in.BranchStatus != nil && in.BranchStatus.IsDirty())
 	if isDirty {
 		return true
@@ -250,6 +326,10 @@ func (in *NodeStatus) ResetDirty() {
 		in.WorkflowNodeStatus.ResetDirty()
 	}
 
+	if in.MapNodeStatus != nil {
+		in.MapNodeStatus.ResetDirty()
+	}
+
 	if in.BranchStatus != nil {
 		in.BranchStatus.ResetDirty()
 	}
@@ -309,6 +389,18 @@ func (in *NodeStatus) ClearLastAttemptStartedAt() {
 	in.SetDirty()
 }
 
+func (in *NodeStatus) SetNextAttemptAt(t metav1.Time) {
+	in.NextAttemptAt = &t
+	in.SetDirty()
+}
+
+func (in *NodeStatus) ClearNextAttemptAt() {
+	if in.NextAttemptAt != nil {
+		in.NextAttemptAt = nil
+		in.SetDirty()
+	}
+}
+
 func (in *NodeStatus) ClearSubNodeStatus() {
 	in.SubNodeStatus = nil
 	in.SetDirty()
@@ -322,6 +414,10 @@ func (in *NodeStatus) GetLastAttemptStartedAt() *metav1.Time {
 	return in.LastAttemptStartedAt
 }
 
+func (in *NodeStatus) GetNextAttemptAt() *metav1.Time {
+	return in.NextAttemptAt
+}
+
 func (in *NodeStatus) GetAttempts() uint32 {
 	return in.Attempts
 }
@@ -367,6 +463,29 @@ func (in *NodeStatus) ClearDynamicNodeStatus() {
 	in.SetDirty()
 }
 
+func (in *NodeStatus) GetMapNodeStatus() MutableMapNodeStatus {
+	if in.MapNodeStatus == nil {
+		return nil
+	}
+	return in.MapNodeStatus
+}
+
+func (in *NodeStatus) GetOrCreateMapNodeStatus() MutableMapNodeStatus {
+	if in.MapNodeStatus == nil {
+		in.SetDirty()
+		in.MapNodeStatus = &MapNodeStatus{
+			MutableStruct: MutableStruct{},
+		}
+	}
+
+	return in.MapNodeStatus
+}
+
+func (in *NodeStatus) ClearMapNodeStatus() {
+	in.MapNodeStatus = nil
+	in.SetDirty()
+}
+
 func (in *NodeStatus) GetOrCreateBranchStatus() MutableBranchNodeStatus {
 	if in.BranchStatus == nil {
 		in.SetDirty()
@@ -530,7 +649,9 @@ func (in *NodeStatus) GetNodeExecutionStatus(ctx context.Context, id NodeID) Exe
 			n.SetDataDir(dataDir)
 		}
 
-		if len(n.GetOutputDir()) == 0 {
+		if len(n.RecoveredOutputDir) > 0 {
+			n.SetOutputDir(n.RecoveredOutputDir)
+		} else if len(n.GetOutputDir()) == 0 {
 			outputDir, err := in.DataReferenceConstructor.ConstructReference(ctx, n.GetDataDir(), strconv.FormatUint(uint64(in.Attempts), 10))
 			if err != nil {
 				logger.Errorf(ctx, "Failed to construct output dir for node [%v]", id)
@@ -633,6 +754,10 @@ func (in *NodeStatus) Equals(other *NodeStatus) bool {
 		return false
 	}
 
+	if in.RecoveredOutputDir != other.RecoveredOutputDir {
+		return false
+	}
+
 	if in.ParentNode != nil && other.ParentNode != nil {
 		if *in.ParentNode != *other.ParentNode {
 			return false
@@ -660,7 +785,8 @@ func (in *NodeStatus) Equals(other *NodeStatus) bool {
 		}
 	}
 
-	return in.BranchStatus.Equals(other.BranchStatus) && in.DynamicNodeStatus.Equals(other.DynamicNodeStatus)
+	return in.BranchStatus.Equals(other.BranchStatus) && in.DynamicNodeStatus.Equals(other.DynamicNodeStatus) &&
+		in.MapNodeStatus.Equals(other.MapNodeStatus)
 }
 
 func (in *NodeStatus) GetExecutionError() *core.ExecutionError {
@@ -705,6 +831,8 @@ type TaskNodeStatus struct {
 	PluginStateVersion uint32    `json:"psv,omitempty"`
 	BarrierClockTick   uint32    `json:"tick,omitempty"`
 	LastPhaseUpdatedAt time.Time `json:"updAt,omitempty"`
+	// The cache override of the workflow applied to the task, which explains a cache miss
+	CacheOverride CacheOverride `json:"cacheOverride,omitempty"`
 }
 
 func (in *TaskNodeStatus) GetBarrierClockTick() uint32 {
@@ -716,6 +844,15 @@ func (in *TaskNodeStatus) SetBarrierClockTick(tick uint32) {
 	in.SetDirty()
 }
 
+func (in *TaskNodeStatus) GetCacheOverride() CacheOverride {
+	return in.CacheOverride
+}
+
+func (in *TaskNodeStatus) SetCacheOverride(cacheOverride CacheOverride) {
+	in.CacheOverride = cacheOverride
+	in.SetDirty()
+}
+
 func (in *TaskNodeStatus) SetPluginState(s []byte) {
 	in.PluginState = s
 	in.SetDirty()
@@ -802,5 +939,6 @@ func (in *TaskNodeStatus) Equals(other *TaskNodeStatus) bool {
 	if in == nil || other == nil {
 		return false
 	}
-	return in.Phase == other.Phase && in.PhaseVersion == other.PhaseVersion && in.PluginStateVersion == other.PluginStateVersion && bytes.Equal(in.PluginState, other.PluginState) && in.BarrierClockTick == other.BarrierClockTick
+	return in.Phase == other.Phase && in.PhaseVersion == other.PhaseVersion && in.PluginStateVersion == other.PluginStateVersion && bytes.Equal(in.PluginState, other.PluginState) && in.BarrierClockTick == other.BarrierClockTick &&
+		in.CacheOverride == other.CacheOverride
 }
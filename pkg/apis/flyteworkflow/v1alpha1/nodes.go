@@ -62,13 +62,83 @@ func (in *Binding) DeepCopyInto(out *Binding) {
 	// Once we figure out the autogenerate story we can replace this
 }
 
+// RetryBackoffPolicy determines how the delay between successive attempts of a node grows
+type RetryBackoffPolicy string
+
+const (
+	// Every retry waits for the configured RetryDelay
+	RetryBackoffPolicyFixed RetryBackoffPolicy = "fixed"
+	// The delay starts at RetryDelay and doubles with every attempt
+	RetryBackoffPolicyExponential RetryBackoffPolicy = "exponential"
+	// Same as exponential, but a random delay between zero and the exponential delay is picked. This spreads out the
+	// retries of many nodes that failed at the same time.
+	RetryBackoffPolicyJittered RetryBackoffPolicy = "jittered"
+)
+
 // Strategy to be used to Retry a node that is in RetryableFailure state
 type RetryStrategy struct {
 	// MinAttempts implies the atleast n attempts to try this node before giving up. The atleast here is because we may
 	// fail to write the attempt information and end up retrying again.
 	// Also `0` and `1` both mean atleast one attempt will be done. 0 is a degenerate case.
 	MinAttempts *int `json:"minAttempts"`
-	// TODO Add retrydelay?
+	// RetryDelay is the (base) duration to wait before the next attempt of a node is started. If not specified the node
+	// is retried immediately.
+	// +optional
+	RetryDelay *v1.Duration `json:"retryDelay,omitempty"`
+	// MaxRetryDelay caps the delay computed by the BackoffPolicy. If not specified the delay is unbounded.
+	// +optional
+	MaxRetryDelay *v1.Duration `json:"maxRetryDelay,omitempty"`
+	// BackoffPolicy to use when computing the delay between attempts. Defaults to RetryBackoffPolicyFixed.
+	// +optional
+	BackoffPolicy RetryBackoffPolicy `json:"backoffPolicy,omitempty"`
+}
+
+func (in *RetryStrategy) GetRetryDelay() time.Duration {
+	if in == nil || in.RetryDelay == nil {
+		return 0
+	}
+	return in.RetryDelay.Duration
+}
+
+func (in *RetryStrategy) GetMaxRetryDelay() time.Duration {
+	if in == nil || in.MaxRetryDelay == nil {
+		return 0
+	}
+	return in.MaxRetryDelay.Duration
+}
+
+func (in *RetryStrategy) GetBackoffPolicy() RetryBackoffPolicy {
+	if in == nil || len(in.BackoffPolicy) == 0 {
+		return RetryBackoffPolicyFixed
+	}
+	return in.BackoffPolicy
+}
+
+// TriggerRule determines, from the phases of its upstream nodes, whether a node runs or is skipped
+type TriggerRule string
+
+const (
+	// Runs when all upstream nodes succeeded, skipped as soon as one of them failed, timed out or was skipped
+	TriggerRuleAllSuccess TriggerRule = "all_success"
+	// Runs when all upstream nodes completed, regardless of their phase
+	TriggerRuleAllDone TriggerRule = "all_done"
+	// Runs as soon as one of the upstream nodes failed or timed out, skipped if all of them completed otherwise
+	TriggerRuleOneFailed TriggerRule = "one_failed"
+	// Runs as soon as one of the upstream nodes succeeded, skipped if all of them completed otherwise
+	TriggerRuleOneSuccess TriggerRule = "one_success"
+	// Runs when all upstream nodes succeeded or were skipped, skipped if one of them failed or timed out
+	TriggerRuleNoneFailed TriggerRule = "none_failed"
+)
+
+// Returns all the known trigger rules
+func TriggerRules() []TriggerRule {
+	return []TriggerRule{
+		TriggerRuleAllSuccess,
+		TriggerRuleAllDone,
+		TriggerRuleOneFailed,
+		TriggerRuleOneSuccess,
+		TriggerRuleNoneFailed,
+	}
 }
 
 type Alias struct {
@@ -99,6 +169,9 @@ type NodeSpec struct {
 	BranchNode    *BranchNodeSpec               `json:"branch,omitempty"`
 	TaskRef       *TaskID                       `json:"task,omitempty"`
 	WorkflowNode  *WorkflowNodeSpec             `json:"workflow,omitempty"`
+	MapNode       *MapNodeSpec                  `json:"map,omitempty"`
+	GateNode      *GateNodeSpec                 `json:"gate,omitempty"`
+	SleepNode     *SleepNodeSpec                `json:"sleep,omitempty"`
 	InputBindings []*Binding                    `json:"inputBindings,omitempty"`
 	Config        *typesv1.ConfigMap            `json:"config,omitempty"`
 	RetryStrategy *RetryStrategy                `json:"retry,omitempty"`
@@ -143,6 +216,10 @@ type NodeSpec struct {
 	// The value set to True means task is OK with getting interrupted
 	// +optional
 	Interruptibe *bool `json:"interruptible,omitempty"`
+	// TriggerRule determines when the node runs given the phases of its upstream nodes. Defaults to
+	// TriggerRuleAllSuccess.
+	// +optional
+	TriggerRule TriggerRule `json:"triggerRule,omitempty"`
 }
 
 func (in *NodeSpec) GetRetryStrategy() *RetryStrategy {
@@ -167,6 +244,13 @@ func (in *NodeSpec) IsInterruptible() *bool {
 	return in.Interruptibe
 }
 
+func (in *NodeSpec) GetTriggerRule() TriggerRule {
+	if len(in.TriggerRule) == 0 {
+		return TriggerRuleAllSuccess
+	}
+	return in.TriggerRule
+}
+
 func (in *NodeSpec) GetConfig() *typesv1.ConfigMap {
 	return in.Config
 }
@@ -186,6 +270,27 @@ func (in *NodeSpec) GetWorkflowNode() ExecutableWorkflowNode {
 	return in.WorkflowNode
 }
 
+func (in *NodeSpec) GetMapNode() ExecutableMapNode {
+	if in.MapNode == nil {
+		return nil
+	}
+	return in.MapNode
+}
+
+func (in *NodeSpec) GetGateNode() ExecutableGateNode {
+	if in.GateNode == nil {
+		return nil
+	}
+	return in.GateNode
+}
+
+func (in *NodeSpec) GetSleepNode() ExecutableSleepNode {
+	if in.SleepNode == nil {
+		return nil
+	}
+	return in.SleepNode
+}
+
 func (in *NodeSpec) GetBranchNode() ExecutableBranchNode {
 	if in.BranchNode == nil {
 		return nil
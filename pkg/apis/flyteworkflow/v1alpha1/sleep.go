@@ -0,0 +1,28 @@
+package v1alpha1
+
+import (
+	"time"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Spec for a sleep node. A sleep node waits for a duration (measured from the time the node started) or until an
+// absolute point in time without launching any pods. Either of them can also be bound from upstream outputs through
+// the input variables "duration" and "until" of the node, which take precedence over the values in the spec.
+type SleepNodeSpec struct {
+	// +optional
+	Duration *v1.Duration `json:"duration,omitempty"`
+	// +optional
+	Until *v1.Time `json:"until,omitempty"`
+}
+
+func (in *SleepNodeSpec) GetDuration() *time.Duration {
+	if in.Duration == nil {
+		return nil
+	}
+	return &in.Duration.Duration
+}
+
+func (in *SleepNodeSpec) GetUntil() *v1.Time {
+	return in.Until
+}
@@ -27,6 +27,10 @@ type WorkflowStatus struct {
 
 	NodeStatus map[NodeID]*NodeStatus `json:"nodeStatus,omitempty"`
 
+	// Set when NodeStatus was too large to be stored inline and has been offloaded to blob storage instead. NodeStatus
+	// is rehydrated from the reference when the workflow is read from the workflow store.
+	OffloadedNodeStatus *OffloadedNodeStatus `json:"offloadedNodeStatus,omitempty"`
+
 	// Number of Attempts completed with rounds resulting in error. this is used to cap out poison pill workflows
 	// that spin in an error loop. The value should be set at the global level and will be enforced. At the end of
 	// the retries the workflow will fail
@@ -35,10 +39,31 @@ type WorkflowStatus struct {
 	// Stores the Error during the Execution of the Workflow. It is optional and usually associated with Failing/Failed state only
 	Error *ExecutionError `json:"error,omitempty"`
 
+	// Summary of every node that failed, when the workflow failed because of node failures. With the
+	// FAIL_AFTER_EXECUTABLE_NODES_COMPLETE policy, Error aggregates them.
+	NodeFailures []NodeFailure `json:"nodeFailures,omitempty"`
+
 	// non-Serialized fields
 	DataReferenceConstructor storage.ReferenceConstructor `json:"-"`
 }
 
+// Points to the serialized NodeStatus map of a workflow, stored outside of the CRD.
+type OffloadedNodeStatus struct {
+	Reference DataReference `json:"reference"`
+	// Hex encoded sha256 of the serialized NodeStatus, used to verify the contents on read.
+	Checksum string `json:"checksum"`
+}
+
+// Summary of the failure of a single node execution.
+type NodeFailure struct {
+	NodeID  NodeID `json:"nodeId"`
+	Code    string `json:"code,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Message string `json:"message,omitempty"`
+	// The attempt of the node that failed
+	Attempt uint32 `json:"attempt,omitempty"`
+}
+
 func IsWorkflowPhaseTerminal(p WorkflowPhase) bool {
 	return p == WorkflowPhaseFailed || p == WorkflowPhaseSuccess || p == WorkflowPhaseAborted
 }
@@ -55,7 +80,8 @@ func (in *WorkflowStatus) UpdatePhase(p WorkflowPhase, msg string, err *core.Exe
 	}
 
 	n := metav1.Now()
-	if in.StartedAt == nil {
+	// A queued workflow has not started yet
+	if in.StartedAt == nil && p != WorkflowPhaseQueued {
 		in.StartedAt = &n
 	}
 
@@ -77,6 +103,14 @@ func (in *WorkflowStatus) GetExecutionError() *core.ExecutionError {
 	return nil
 }
 
+func (in *WorkflowStatus) GetNodeFailures() []NodeFailure {
+	return in.NodeFailures
+}
+
+func (in *WorkflowStatus) SetNodeFailures(failures []NodeFailure) {
+	in.NodeFailures = failures
+}
+
 func (in *WorkflowStatus) IncFailedAttempts() {
 	in.FailedAttempts++
 }
@@ -119,6 +153,11 @@ func (in *WorkflowStatus) GetNodeExecutionStatus(ctx context.Context, id NodeID)
 			n.SetDataDir(dataDir)
 		}
 
+		if len(n.RecoveredOutputDir) > 0 {
+			n.SetOutputDir(n.RecoveredOutputDir)
+			return n
+		}
+
 		outputDir, err := in.DataReferenceConstructor.ConstructReference(ctx, n.GetDataDir(), strconv.FormatUint(uint64(n.Attempts), 10))
 		if err != nil {
 			logger.Errorf(ctx, "Failed to construct output dir for node [%v]", id)
@@ -1,8 +1,10 @@
 package v1alpha1
 
 import (
+	"context"
 	"testing"
 
+	"github.com/lyft/flytestdlib/storage"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -52,3 +54,22 @@ func TestWorkflowStatus_Equals(t *testing.T) {
 	other.OutputReference = "out"
 	assert.True(t, one.Equals(other))
 }
+
+func TestWorkflowStatus_GetNodeExecutionStatus_Recovered(t *testing.T) {
+	ctx := context.TODO()
+	s := &WorkflowStatus{
+		DataDir: "s3://bucket/metadata/new",
+		NodeStatus: map[NodeID]*NodeStatus{
+			"n1": {Phase: NodePhaseSucceeded, Attempts: 1},
+			"n2": {Phase: NodePhaseSucceeded, Attempts: 1, RecoveredOutputDir: "s3://bucket/metadata/old/n2/data/1"},
+		},
+		DataReferenceConstructor: storage.URLPathConstructor{},
+	}
+
+	n1 := s.GetNodeExecutionStatus(ctx, "n1")
+	assert.Equal(t, DataReference("s3://bucket/metadata/new/n1/data/1"), n1.GetOutputDir())
+
+	n2 := s.GetNodeExecutionStatus(ctx, "n2")
+	assert.Equal(t, DataReference("s3://bucket/metadata/new/n2/data"), n2.GetDataDir())
+	assert.Equal(t, DataReference("s3://bucket/metadata/old/n2/data/1"), n2.GetOutputDir())
+}
@@ -265,6 +265,31 @@ func (in *FlyteWorkflowList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GateNodeSpec) DeepCopyInto(out *GateNodeSpec) {
+	*out = *in
+	if in.Signal != nil {
+		in, out := &in.Signal, &out.Signal
+		*out = (*in).DeepCopy()
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GateNodeSpec.
+func (in *GateNodeSpec) DeepCopy() *GateNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GateNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Identifier.
 func (in *Identifier) DeepCopy() *Identifier {
 	if in == nil {
@@ -307,6 +332,72 @@ func (in *Inputs) DeepCopy() *Inputs {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MapItemStatus) DeepCopyInto(out *MapItemStatus) {
+	*out = *in
+	if in.PluginState != nil {
+		in, out := &in.PluginState, &out.PluginState
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MapItemStatus.
+func (in *MapItemStatus) DeepCopy() *MapItemStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MapItemStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MapNodeSpec) DeepCopyInto(out *MapNodeSpec) {
+	*out = *in
+	if in.MinSuccessRatio != nil {
+		in, out := &in.MinSuccessRatio, &out.MinSuccessRatio
+		*out = new(float32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MapNodeSpec.
+func (in *MapNodeSpec) DeepCopy() *MapNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MapNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MapNodeStatus) DeepCopyInto(out *MapNodeStatus) {
+	*out = *in
+	out.MutableStruct = in.MutableStruct
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MapItemStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MapNodeStatus.
+func (in *MapNodeStatus) DeepCopy() *MapNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MapNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MutableStruct) DeepCopyInto(out *MutableStruct) {
 	*out = *in
@@ -339,6 +430,22 @@ func (in *NodeDefaults) DeepCopy() *NodeDefaults {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeFailure) DeepCopyInto(out *NodeFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeFailure.
+func (in *NodeFailure) DeepCopy() *NodeFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetadata.
 func (in *NodeMetadata) DeepCopy() *NodeMetadata {
 	if in == nil {
@@ -372,6 +479,21 @@ func (in *NodeSpec) DeepCopyInto(out *NodeSpec) {
 		*out = new(WorkflowNodeSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MapNode != nil {
+		in, out := &in.MapNode, &out.MapNode
+		*out = new(MapNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GateNode != nil {
+		in, out := &in.GateNode, &out.GateNode
+		*out = new(GateNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SleepNode != nil {
+		in, out := &in.SleepNode, &out.SleepNode
+		*out = new(SleepNodeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.InputBindings != nil {
 		in, out := &in.InputBindings, &out.InputBindings
 		*out = make([]*Binding, len(*in))
@@ -473,6 +595,10 @@ func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
 		in, out := &in.LastAttemptStartedAt, &out.LastAttemptStartedAt
 		*out = (*in).DeepCopy()
 	}
+	if in.NextAttemptAt != nil {
+		in, out := &in.NextAttemptAt, &out.NextAttemptAt
+		*out = (*in).DeepCopy()
+	}
 	if in.ParentNode != nil {
 		in, out := &in.ParentNode, &out.ParentNode
 		*out = new(string)
@@ -516,6 +642,11 @@ func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
 		*out = new(DynamicNodeStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.MapNodeStatus != nil {
+		in, out := &in.MapNodeStatus, &out.MapNodeStatus
+		*out = new(MapNodeStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Error != nil {
 		in, out := &in.Error, &out.Error
 		*out = (*in).DeepCopy()
@@ -548,6 +679,22 @@ func (in *OutputVarMap) DeepCopy() *OutputVarMap {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OffloadedNodeStatus) DeepCopyInto(out *OffloadedNodeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OffloadedNodeStatus.
+func (in *OffloadedNodeStatus) DeepCopy() *OffloadedNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OffloadedNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetryStrategy) DeepCopyInto(out *RetryStrategy) {
 	*out = *in
@@ -556,6 +703,16 @@ func (in *RetryStrategy) DeepCopyInto(out *RetryStrategy) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.RetryDelay != nil {
+		in, out := &in.RetryDelay, &out.RetryDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxRetryDelay != nil {
+		in, out := &in.MaxRetryDelay, &out.MaxRetryDelay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -569,6 +726,31 @@ func (in *RetryStrategy) DeepCopy() *RetryStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SleepNodeSpec) DeepCopyInto(out *SleepNodeSpec) {
+	*out = *in
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SleepNodeSpec.
+func (in *SleepNodeSpec) DeepCopy() *SleepNodeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SleepNodeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskExecutionIdentifier.
 func (in *TaskExecutionIdentifier) DeepCopy() *TaskExecutionIdentifier {
 	if in == nil {
@@ -727,10 +909,20 @@ func (in *WorkflowStatus) DeepCopyInto(out *WorkflowStatus) {
 			(*out)[key] = outVal
 		}
 	}
+	if in.OffloadedNodeStatus != nil {
+		in, out := &in.OffloadedNodeStatus, &out.OffloadedNodeStatus
+		*out = new(OffloadedNodeStatus)
+		**out = **in
+	}
 	if in.Error != nil {
 		in, out := &in.Error, &out.Error
 		*out = (*in).DeepCopy()
 	}
+	if in.NodeFailures != nil {
+		in, out := &in.NodeFailures, &out.NodeFailures
+		*out = make([]NodeFailure, len(*in))
+		copy(*out, *in)
+	}
 	if in.DataReferenceConstructor != nil {
 		// This was manually modified to not generated a deep copy constructor for this. There is no way to skip
 		// generation of fields
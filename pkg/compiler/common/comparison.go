@@ -0,0 +1,63 @@
+package common
+
+import (
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+)
+
+// Defines the operator of a comparison in a branch condition. It covers the operators defined by
+// core.ComparisonExpression_Operator as well as the ones propeller supports in addition. The additional operators are
+// carried in the operator field of the comparison using values the IDL does not assign.
+type ComparisonOperator int32
+
+const (
+	// Substring of a string, key of a map or element of a collection.
+	ComparisonOperatorContains ComparisonOperator = 100 + iota
+	ComparisonOperatorStartsWith
+	// The left string matches the regular expression on the right.
+	ComparisonOperatorMatches
+	// The left value is void (or not set). No right value is expected.
+	ComparisonOperatorIsNull
+	ComparisonOperatorIsNotNull
+)
+
+var comparisonOperatorNames = map[ComparisonOperator]string{
+	ComparisonOperatorContains:   "CONTAINS",
+	ComparisonOperatorStartsWith: "STARTS_WITH",
+	ComparisonOperatorMatches:    "MATCHES",
+	ComparisonOperatorIsNull:     "IS_NULL",
+	ComparisonOperatorIsNotNull:  "IS_NOT_NULL",
+}
+
+// Gets the operator of the comparison.
+func GetComparisonOperator(expr *core.ComparisonExpression) ComparisonOperator {
+	return ComparisonOperator(expr.GetOperator())
+}
+
+// Returns the value to set as the operator of a comparison.
+func (o ComparisonOperator) ToCore() core.ComparisonExpression_Operator {
+	return core.ComparisonExpression_Operator(o)
+}
+
+func (o ComparisonOperator) String() string {
+	if name, ok := comparisonOperatorNames[o]; ok {
+		return name
+	}
+	return o.ToCore().String()
+}
+
+// Whether the operator is defined by the IDL.
+func (o ComparisonOperator) IsCore() bool {
+	_, ok := core.ComparisonExpression_Operator_name[int32(o)]
+	return ok
+}
+
+// Whether the operator is either defined by the IDL or one of the additional comparison operators.
+func (o ComparisonOperator) IsKnown() bool {
+	_, ok := comparisonOperatorNames[o]
+	return ok || o.IsCore()
+}
+
+// Whether the operator only checks the left value for null.
+func (o ComparisonOperator) IsNullCheck() bool {
+	return o == ComparisonOperatorIsNull || o == ComparisonOperatorIsNotNull
+}
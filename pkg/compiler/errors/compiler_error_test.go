@@ -50,3 +50,10 @@ func TestIncludeSource(t *testing.T) {
 	assert.Equal(t, e.source, "compiler_error_test.go:49")
 	SetConfig(Config{})
 }
+
+func TestTriggerRuleNotAllowedErr(t *testing.T) {
+	e := NewTriggerRuleNotAllowedErr("n1", "all_done", "the start node has no upstream nodes")
+	mustErrorCode(t, e, TriggerRuleNotAllowed)
+	assert.Equal(t, e.Error(), "Code: TriggerRuleNotAllowed, Node Id: n1, Description: Trigger rule [all_done] is not "+
+		"allowed, the start node has no upstream nodes.")
+}
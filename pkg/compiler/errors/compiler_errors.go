@@ -72,6 +72,12 @@ const (
 
 	// A value isn't on the right syntax.
 	SyntaxError ErrorCode = "SyntaxError"
+
+	// A comparison operator isn't defined for the type of its operands.
+	UnsupportedComparison ErrorCode = "UnsupportedComparison"
+
+	// A trigger rule can't be set on a node, e.g. the node binds outputs of upstream nodes that may not succeed.
+	TriggerRuleNotAllowed ErrorCode = "TriggerRuleNotAllowed"
 )
 
 func NewBranchNodeNotSpecified(branchNodeID string) *CompileError {
@@ -246,6 +252,22 @@ func NewSyntaxError(nodeID string, element string, err error) *CompileError {
 	)
 }
 
+func NewUnsupportedComparisonErr(nodeID, operator, operandType string) *CompileError {
+	return newError(
+		UnsupportedComparison,
+		fmt.Sprintf("Operator [%v] is not defined for operands of type [%v].", operator, operandType),
+		nodeID,
+	)
+}
+
+func NewTriggerRuleNotAllowedErr(nodeID, rule, reason string) *CompileError {
+	return newError(
+		TriggerRuleNotAllowed,
+		fmt.Sprintf("Trigger rule [%v] is not allowed, %v.", rule, reason),
+		nodeID,
+	)
+}
+
 func newError(code ErrorCode, description, nodeID string) (err *CompileError) {
 	err = &CompileError{
 		code:        code,
@@ -0,0 +1,46 @@
+package k8s
+
+import (
+	"hash/fnv"
+	"strconv"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+// Labels holding the shard key of a workflow for each of the attributes propeller can shard workflows by.
+const (
+	NamespaceShardKeyLabel = "namespace-shard-key"
+	ProjectShardKeyLabel   = "project-shard-key"
+	ExecutionShardKeyLabel = "execution-shard-key"
+)
+
+// Number of distinct shard keys. Propeller replicas own ranges of shard keys, so this bounds the number of replicas.
+// Changing it reassigns the shard keys of all new workflows.
+const ShardKeySpaceSize = 100
+
+// Deterministically maps a value to a shard key in [0, ShardKeySpaceSize).
+func ComputeShardKey(value string) int {
+	hasher := fnv.New32a()
+	// Using 32a an error can never happen
+	_, _ = hasher.Write([]byte(value)) // #nosec
+	return int(hasher.Sum32() % ShardKeySpaceSize)
+}
+
+// Sets the shard key labels of a workflow from its namespace, project and execution id label.
+func SetShardKeyLabels(obj *v1alpha1.FlyteWorkflow, project string) {
+	obj.ObjectMeta.Labels[NamespaceShardKeyLabel] = strconv.Itoa(ComputeShardKey(obj.Namespace))
+	obj.ObjectMeta.Labels[ProjectShardKeyLabel] = strconv.Itoa(ComputeShardKey(project))
+	obj.ObjectMeta.Labels[ExecutionShardKeyLabel] = strconv.Itoa(ComputeShardKey(executionShardKeyValue(obj)))
+}
+
+// Workflows created before the execution id label was introduced do not carry it, those are keyed by their execution
+// id, or their name, so that they are spread across shards instead of all sharing the key of an empty label.
+func executionShardKeyValue(obj *v1alpha1.FlyteWorkflow) string {
+	if id := obj.ObjectMeta.Labels[ExecutionIDLabel]; id != "" {
+		return id
+	}
+	if name := obj.GetExecutionID().GetName(); name != "" {
+		return name
+	}
+	return obj.Name
+}
@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+func TestComputeShardKey(t *testing.T) {
+	assert.Equal(t, ComputeShardKey("project-development"), ComputeShardKey("project-development"))
+
+	keys := map[int]bool{}
+	for _, v := range []string{"", "a", "b", "project-development", "project-production", "f1a2b3c4d5"} {
+		k := ComputeShardKey(v)
+		assert.True(t, k >= 0 && k < ShardKeySpaceSize)
+		keys[k] = true
+	}
+	assert.True(t, len(keys) > 1)
+}
+
+func TestSetShardKeyLabels(t *testing.T) {
+	newWorkflow := func(labels map[string]string, executionName string) *v1alpha1.FlyteWorkflow {
+		w := &v1alpha1.FlyteWorkflow{ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: "wf", Labels: labels}}
+		if executionName != "" {
+			w.ExecutionID = v1alpha1.WorkflowExecutionIdentifier{
+				WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: "d", Name: executionName},
+			}
+		}
+		return w
+	}
+
+	for name, tc := range map[string]struct {
+		w   *v1alpha1.FlyteWorkflow
+		key string
+	}{
+		"label":        {w: newWorkflow(map[string]string{ExecutionIDLabel: "label-id"}, "exec-id"), key: "label-id"},
+		"execution-id": {w: newWorkflow(map[string]string{}, "exec-id"), key: "exec-id"},
+		"name":         {w: newWorkflow(map[string]string{}, ""), key: "wf"},
+		"empty-label":  {w: newWorkflow(map[string]string{ExecutionIDLabel: ""}, "exec-id"), key: "exec-id"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			SetShardKeyLabels(tc.w, "p")
+			assert.Equal(t, strconv.Itoa(ComputeShardKey(tc.key)), tc.w.Labels[ExecutionShardKeyLabel])
+			assert.Equal(t, strconv.Itoa(ComputeShardKey("p")), tc.w.Labels[ProjectShardKeyLabel])
+			assert.Equal(t, strconv.Itoa(ComputeShardKey("ns")), tc.w.Labels[NamespaceShardKeyLabel])
+		})
+	}
+}
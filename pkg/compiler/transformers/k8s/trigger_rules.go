@@ -0,0 +1,206 @@
+package k8s
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
+	"github.com/lyft/flytepropeller/pkg/compiler/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Collects the ids of the nodes whose outputs are bound by a binding.
+func promiseNodeIDs(b *core.BindingData, ids sets.String) {
+	switch {
+	case b.GetPromise() != nil:
+		ids.Insert(b.GetPromise().GetNodeId())
+	case b.GetCollection() != nil:
+		for _, item := range b.GetCollection().GetBindings() {
+			promiseNodeIDs(item, ids)
+		}
+	case b.GetMap() != nil:
+		for _, item := range b.GetMap().GetBindings() {
+			promiseNodeIDs(item, ids)
+		}
+	}
+}
+
+// Returns the ids of the nodes run as a case of a branch node.
+func branchCaseNodeIDs(spec *v1alpha1.WorkflowSpec) sets.String {
+	ids := sets.NewString()
+	for _, n := range spec.Nodes {
+		if n.BranchNode == nil {
+			continue
+		}
+
+		if n.BranchNode.If.ThenNode != nil {
+			ids.Insert(*n.BranchNode.If.ThenNode)
+		}
+		for _, elif := range n.BranchNode.ElseIf {
+			if elif != nil && elif.ThenNode != nil {
+				ids.Insert(*elif.ThenNode)
+			}
+		}
+		if n.BranchNode.Else != nil {
+			ids.Insert(*n.BranchNode.Else)
+		}
+	}
+
+	return ids
+}
+
+// Returns the ids of the nodes whose outputs are bound to the outputs of the workflow.
+func workflowOutputNodeIDs(spec *v1alpha1.WorkflowSpec) sets.String {
+	ids := sets.NewString()
+	for _, b := range spec.OutputBindings {
+		promiseNodeIDs(b.GetBinding(), ids)
+	}
+
+	if end, ok := spec.Nodes[common.EndNodeID]; ok {
+		for _, b := range end.InputBindings {
+			promiseNodeIDs(b.GetBinding(), ids)
+		}
+	}
+
+	return ids
+}
+
+func validateTriggerRule(n *v1alpha1.NodeSpec, branchCases, workflowOutputNodes sets.String, errs errors.CompileErrors) (ok bool) {
+	rule := n.GetTriggerRule()
+	known := false
+	for _, r := range v1alpha1.TriggerRules() {
+		known = known || r == rule
+	}
+
+	if !known {
+		errs.Collect(errors.NewUnrecognizedValueErr(n.ID, string(rule)))
+		return !errs.HasErrors()
+	}
+
+	if rule == v1alpha1.TriggerRuleAllSuccess {
+		return !errs.HasErrors()
+	}
+
+	if n.ID == common.StartNodeID {
+		errs.Collect(errors.NewTriggerRuleNotAllowedErr(n.ID, string(rule), "the start node has no upstream nodes"))
+		return !errs.HasErrors()
+	}
+
+	if branchCases.Has(n.ID) {
+		errs.Collect(errors.NewTriggerRuleNotAllowedErr(n.ID, string(rule),
+			"the node is a case of a branch node and runs when its condition is satisfied"))
+		return !errs.HasErrors()
+	}
+
+	// The node is skipped when its rule is not met, the workflow then completes without its outputs
+	if workflowOutputNodes.Has(n.ID) {
+		errs.Collect(errors.NewTriggerRuleNotAllowedErr(n.ID, string(rule),
+			"the outputs of the node are bound to outputs of the workflow and the node may be skipped"))
+	}
+
+	// Unless all upstream nodes succeeded, some of them may not have produced outputs. Only the workflow inputs can be
+	// bound.
+	upstream := sets.NewString()
+	for _, b := range n.InputBindings {
+		promiseNodeIDs(b.GetBinding(), upstream)
+	}
+	upstream.Delete("", common.StartNodeID)
+	if upstream.Len() > 0 {
+		errs.Collect(errors.NewTriggerRuleNotAllowedErr(n.ID, string(rule),
+			fmt.Sprintf("the node binds outputs of [%v] that may not succeed", strings.Join(upstream.List(), ","))))
+	}
+
+	return !errs.HasErrors()
+}
+
+// Returns the ids of the all_success nodes that are skipped when a node upstream of them is skipped by its trigger
+// rule, mapped to the id of that node. Nodes with the all_done rule run once their upstream nodes complete, whatever
+// their phase, so they are never skipped and do not skip their downstream nodes.
+func skippedByTriggerRuleNodeIDs(spec *v1alpha1.WorkflowSpec) map[v1alpha1.NodeID]v1alpha1.NodeID {
+	skippedBy := map[v1alpha1.NodeID]v1alpha1.NodeID{}
+	var visit func(id, cause v1alpha1.NodeID)
+	visit = func(id, cause v1alpha1.NodeID) {
+		for _, downstreamID := range spec.Connections.DownstreamEdges[id] {
+			n, ok := spec.Nodes[downstreamID]
+			if _, visited := skippedBy[downstreamID]; visited || !ok || n.GetTriggerRule() != v1alpha1.TriggerRuleAllSuccess {
+				continue
+			}
+
+			skippedBy[downstreamID] = cause
+			visit(downstreamID, cause)
+		}
+	}
+
+	ids := make([]v1alpha1.NodeID, 0, len(spec.Nodes))
+	for id, n := range spec.Nodes {
+		if rule := n.GetTriggerRule(); rule != v1alpha1.TriggerRuleAllSuccess && rule != v1alpha1.TriggerRuleAllDone {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		visit(id, id)
+	}
+
+	return skippedBy
+}
+
+// Validates the trigger rules of all the nodes of a workflow spec.
+func validateTriggerRules(spec *v1alpha1.WorkflowSpec, errs errors.CompileErrors) (ok bool) {
+	branchCases := branchCaseNodeIDs(spec)
+	workflowOutputNodes := workflowOutputNodeIDs(spec)
+	ids := make([]v1alpha1.NodeID, 0, len(spec.Nodes))
+	for id := range spec.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		validateTriggerRule(spec.Nodes[id], branchCases, workflowOutputNodes, errs.NewScope())
+	}
+
+	if errs.HasErrors() {
+		return false
+	}
+
+	// A node that is skipped because a node upstream of it was skipped by its trigger rule does not skip the end node
+	// either, the workflow would then complete without its outputs
+	skippedBy := skippedByTriggerRuleNodeIDs(spec)
+	for _, id := range workflowOutputNodes.List() {
+		if cause, skippable := skippedBy[id]; skippable {
+			errs.Collect(errors.NewTriggerRuleNotAllowedErr(cause, string(spec.Nodes[cause].GetTriggerRule()),
+				fmt.Sprintf("the outputs of [%v], which is skipped when the node is, are bound to outputs of the workflow", id)))
+		}
+	}
+
+	return !errs.HasErrors()
+}
+
+// Sets the trigger rules of nodes of the primary workflow, by node id, and validates them. The IDL has no notion of
+// trigger rules, nodes not in the map run when all their upstream nodes succeeded.
+func SetTriggerRules(obj *v1alpha1.FlyteWorkflow, rules map[v1alpha1.NodeID]v1alpha1.TriggerRule) error {
+	errs := errors.NewCompileErrors()
+	for id, rule := range rules {
+		n, ok := obj.Nodes[id]
+		if !ok {
+			errs.Collect(errors.NewNodeReferenceNotFoundErr(obj.ID, id))
+			continue
+		}
+
+		n.TriggerRule = rule
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+
+	if ok := validateTriggerRules(obj.WorkflowSpec, errs.NewScope()); !ok {
+		return errs
+	}
+
+	return nil
+}
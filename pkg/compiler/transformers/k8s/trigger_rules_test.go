@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
+	"github.com/lyft/flytepropeller/pkg/compiler/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTriggerRulesTestWorkflow() *v1alpha1.FlyteWorkflow {
+	promise := func(nodeID string) *v1alpha1.Binding {
+		return &v1alpha1.Binding{Binding: &core.Binding{
+			Var: "x",
+			Binding: &core.BindingData{Value: &core.BindingData_Collection{Collection: &core.BindingDataCollection{
+				Bindings: []*core.BindingData{
+					{Value: &core.BindingData_Promise{Promise: &core.OutputReference{NodeId: nodeID, Var: "o"}}},
+				},
+			}}},
+		}}
+	}
+
+	thenNode := "b1-n0"
+	return &v1alpha1.FlyteWorkflow{
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				common.StartNodeID: {ID: common.StartNodeID, Kind: v1alpha1.NodeKindStart},
+				"n1": {ID: "n1", Kind: v1alpha1.NodeKindTask,
+					InputBindings: []*v1alpha1.Binding{promise(common.StartNodeID)}},
+				"n2": {ID: "n2", Kind: v1alpha1.NodeKindTask, InputBindings: []*v1alpha1.Binding{promise("n1")}},
+				"b1": {ID: "b1", Kind: v1alpha1.NodeKindBranch, BranchNode: &v1alpha1.BranchNodeSpec{
+					If: v1alpha1.IfBlock{ThenNode: &thenNode},
+				}},
+				thenNode:  {ID: thenNode, Kind: v1alpha1.NodeKindTask},
+				"cleanup": {ID: "cleanup", Kind: v1alpha1.NodeKindTask},
+				"n3":      {ID: "n3", Kind: v1alpha1.NodeKindTask},
+				common.EndNodeID: {ID: common.EndNodeID, Kind: v1alpha1.NodeKindEnd,
+					InputBindings: []*v1alpha1.Binding{promise("n3")}},
+			},
+			OutputBindings: []*v1alpha1.Binding{promise("n3")},
+			Connections: v1alpha1.Connections{DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+				common.StartNodeID: {"n1", "b1", "cleanup"},
+				"n1":               {"n2"},
+				"cleanup":          {"n3"},
+				"n2":               {common.EndNodeID},
+				"b1":               {common.EndNodeID},
+				"n3":               {common.EndNodeID},
+			}},
+		},
+	}
+}
+
+func TestSetTriggerRules(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		w := newTriggerRulesTestWorkflow()
+		assert.NoError(t, SetTriggerRules(w, map[v1alpha1.NodeID]v1alpha1.TriggerRule{
+			"n1":      v1alpha1.TriggerRuleNoneFailed,
+			"n2":      v1alpha1.TriggerRuleAllSuccess,
+			"cleanup": v1alpha1.TriggerRuleAllDone,
+		}))
+		assert.Equal(t, v1alpha1.TriggerRuleNoneFailed, w.Nodes["n1"].GetTriggerRule())
+		assert.Equal(t, v1alpha1.TriggerRuleAllDone, w.Nodes["cleanup"].GetTriggerRule())
+		assert.Equal(t, v1alpha1.TriggerRuleAllSuccess, w.Nodes[common.EndNodeID].GetTriggerRule())
+	})
+
+	for name, tc := range map[string]struct {
+		nodeID v1alpha1.NodeID
+		rule   v1alpha1.TriggerRule
+		code   errors.ErrorCode
+	}{
+		"unknown-node":    {"n4", v1alpha1.TriggerRuleAllDone, errors.NodeReferenceNotFound},
+		"workflow-output": {"n3", v1alpha1.TriggerRuleAllDone, errors.TriggerRuleNotAllowed},
+		"unknown-rule":    {"cleanup", "all_failed", errors.UnrecognizedValue},
+		"start-node":      {common.StartNodeID, v1alpha1.TriggerRuleAllDone, errors.TriggerRuleNotAllowed},
+		"branch-case":     {"b1-n0", v1alpha1.TriggerRuleOneSuccess, errors.TriggerRuleNotAllowed},
+		"binds-upstream":  {"n2", v1alpha1.TriggerRuleOneFailed, errors.TriggerRuleNotAllowed},
+		"binds-inputs-ok": {"n1", v1alpha1.TriggerRuleOneFailed, ""},
+		"skips-output":    {"cleanup", v1alpha1.TriggerRuleOneFailed, errors.TriggerRuleNotAllowed},
+		"all-done-ok":     {"cleanup", v1alpha1.TriggerRuleAllDone, ""},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := SetTriggerRules(newTriggerRulesTestWorkflow(), map[v1alpha1.NodeID]v1alpha1.TriggerRule{tc.nodeID: tc.rule})
+			if len(tc.code) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				compileErrs, ok := err.(errors.CompileErrors)
+				if assert.True(t, ok) && assert.Equal(t, 1, compileErrs.ErrorCount()) {
+					assert.Equal(t, tc.code, compileErrs.Errors().List()[0].Code())
+				}
+			}
+		})
+	}
+}
@@ -195,6 +195,12 @@ func BuildFlyteWorkflow(wfClosure *core.CompiledWorkflowClosure, inputs *core.Li
 	}
 	obj.ObjectMeta.Labels[WorkflowNameLabel] = utils.SanitizeLabelValue(WorkflowNameFromID(primarySpec.ID))
 
+	project := wf.GetId().GetProject()
+	if executionID != nil {
+		project = executionID.GetProject()
+	}
+	SetShardKeyLabels(obj, project)
+
 	if obj.Nodes == nil || obj.Connections.DownstreamEdges == nil {
 		// If we come here, we'd better have an error generated earlier. Otherwise, add one to make sure build fails.
 		if !errs.HasErrors() {
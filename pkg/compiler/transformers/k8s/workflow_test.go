@@ -1,6 +1,7 @@
 package k8s
 
 import (
+	"strconv"
 	"testing"
 
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
@@ -113,6 +114,8 @@ func TestBuildFlyteWorkflow(t *testing.T) {
 	assert.True(t, *wf.WorkflowSpec.Nodes["n_1"].Interruptibe)
 	assert.Nil(t, wf.WorkflowSpec.Nodes[common.StartNodeID].Interruptibe)
 	assert.Equal(t, "wf-1", wf.Labels[WorkflowNameLabel])
+	assert.Equal(t, strconv.Itoa(ComputeShardKey(wf.Labels[ExecutionIDLabel])), wf.Labels[ExecutionShardKeyLabel])
+	assert.Equal(t, strconv.Itoa(ComputeShardKey("")), wf.Labels[NamespaceShardKeyLabel])
 	assert.NoError(t, err)
 	assert.NotNil(t, wf)
 	errors.SetConfig(errors.Config{})
@@ -2,6 +2,7 @@ package validators
 
 import (
 	"fmt"
+	"regexp"
 
 	flyte "github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
 	c "github.com/lyft/flytepropeller/pkg/compiler/common"
@@ -28,21 +29,92 @@ func validateOperand(node c.NodeBuilder, paramName string, operand *flyte.Operan
 	return literalType, !errs.HasErrors()
 }
 
+func isSimpleType(t *flyte.LiteralType, simple flyte.SimpleType) bool {
+	return t.GetSimple() == simple
+}
+
+// Checks that the operator is defined for the types of its operands. The regular comparison operators compare
+// collections and maps by their length.
+func validateComparisonTypes(node c.NodeBuilder, comparison *flyte.ComparisonExpression, leftType, rightType *flyte.LiteralType,
+	errs errors.CompileErrors) (ok bool) {
+
+	op := c.GetComparisonOperator(comparison)
+	switch op {
+	case c.ComparisonOperatorContains:
+		switch {
+		case leftType.GetCollectionType() != nil:
+			if !AreTypesCastable(rightType, leftType.GetCollectionType()) {
+				errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue", rightType.String(),
+					leftType.GetCollectionType().String()))
+			}
+		case leftType.GetMapValueType() != nil, isSimpleType(leftType, flyte.SimpleType_STRING):
+			if !isSimpleType(rightType, flyte.SimpleType_STRING) {
+				errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue", rightType.String(), "STRING"))
+			}
+		default:
+			errs.Collect(errors.NewUnsupportedComparisonErr(node.GetId(), op.String(), leftType.String()))
+		}
+	case c.ComparisonOperatorStartsWith, c.ComparisonOperatorMatches:
+		if !isSimpleType(leftType, flyte.SimpleType_STRING) {
+			errs.Collect(errors.NewUnsupportedComparisonErr(node.GetId(), op.String(), leftType.String()))
+		} else if !isSimpleType(rightType, flyte.SimpleType_STRING) {
+			errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue", rightType.String(), leftType.String()))
+		} else if p := comparison.GetRightValue().GetPrimitive(); op == c.ComparisonOperatorMatches && p != nil {
+			if _, err := regexp.Compile(p.GetStringValue()); err != nil {
+				errs.Collect(errors.NewSyntaxError(node.GetId(), "RightValue", err))
+			}
+		}
+	default:
+		if leftType.GetCollectionType() != nil || leftType.GetMapValueType() != nil {
+			if !isSimpleType(rightType, flyte.SimpleType_INTEGER) {
+				errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue", rightType.String(), "INTEGER"))
+			}
+		} else if rightType.String() != leftType.String() {
+			errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue",
+				rightType.String(), leftType.String()))
+		}
+	}
+
+	return !errs.HasErrors()
+}
+
+func validateComparison(node c.NodeBuilder, comparison *flyte.ComparisonExpression, errs errors.CompileErrors) (ok bool) {
+	op := c.GetComparisonOperator(comparison)
+	if !op.IsKnown() {
+		errs.Collect(errors.NewUnrecognizedValueErr(node.GetId(), op.String()))
+		return false
+	}
+
+	if op.IsNullCheck() {
+		// Only the left value is checked
+		validateOperand(node, "LeftValue", comparison.GetLeftValue(), errs.NewScope())
+		return !errs.HasErrors()
+	}
+
+	op1Type, op1Valid := validateOperand(node, "RightValue",
+		comparison.GetRightValue(), errs.NewScope())
+	op2Type, op2Valid := validateOperand(node, "LeftValue",
+		comparison.GetLeftValue(), errs.NewScope())
+	if op1Valid && op2Valid {
+		if op1Type == nil || op2Type == nil {
+			if op1Type.String() != op2Type.String() {
+				errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue",
+					op1Type.String(), op2Type.String()))
+			}
+		} else {
+			validateComparisonTypes(node, comparison, op2Type, op1Type, errs.NewScope())
+		}
+	}
+
+	return !errs.HasErrors()
+}
+
 func ValidateBooleanExpression(node c.NodeBuilder, expr *flyte.BooleanExpression, errs errors.CompileErrors) (ok bool) {
 	if expr == nil {
 		errs.Collect(errors.NewBranchNodeHasNoCondition(node.GetId()))
 	} else {
 		if expr.GetComparison() != nil {
-			op1Type, op1Valid := validateOperand(node, "RightValue",
-				expr.GetComparison().GetRightValue(), errs.NewScope())
-			op2Type, op2Valid := validateOperand(node, "LeftValue",
-				expr.GetComparison().GetLeftValue(), errs.NewScope())
-			if op1Valid && op2Valid {
-				if op1Type.String() != op2Type.String() {
-					errs.Collect(errors.NewMismatchingTypesErr(node.GetId(), "RightValue",
-						op1Type.String(), op2Type.String()))
-				}
-			}
+			validateComparison(node, expr.GetComparison(), errs.NewScope())
 		} else if expr.GetConjunction() != nil {
 			ValidateBooleanExpression(node, expr.GetConjunction().LeftExpression, errs.NewScope())
 			ValidateBooleanExpression(node, expr.GetConjunction().RightExpression, errs.NewScope())
@@ -0,0 +1,94 @@
+package validators
+
+import (
+	"testing"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	c "github.com/lyft/flytepropeller/pkg/compiler/common"
+	"github.com/lyft/flytepropeller/pkg/compiler/common/mocks"
+	"github.com/lyft/flytepropeller/pkg/compiler/errors"
+	"github.com/lyft/flytepropeller/pkg/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConditionNode() *mocks.NodeBuilder {
+	n := &mocks.NodeBuilder{}
+	n.OnGetId().Return("node1")
+	n.OnGetInterface().Return(&core.TypedInterface{
+		Inputs: &core.VariableMap{
+			Variables: map[string]*core.Variable{
+				"xs": {Type: &core.LiteralType{Type: &core.LiteralType_CollectionType{
+					CollectionType: &core.LiteralType{Type: &core.LiteralType_Simple{Simple: core.SimpleType_INTEGER}}}}},
+				"m": {Type: &core.LiteralType{Type: &core.LiteralType_MapValueType{
+					MapValueType: &core.LiteralType{Type: &core.LiteralType_Simple{Simple: core.SimpleType_INTEGER}}}}},
+				"s": {Type: &core.LiteralType{Type: &core.LiteralType_Simple{Simple: core.SimpleType_STRING}}},
+				"i": {Type: &core.LiteralType{Type: &core.LiteralType_Simple{Simple: core.SimpleType_INTEGER}}},
+			},
+		},
+	})
+	return n
+}
+
+func newComparison(left string, op core.ComparisonExpression_Operator, right interface{}) *core.BooleanExpression {
+	cmp := &core.ComparisonExpression{
+		LeftValue: &core.Operand{Val: &core.Operand_Var{Var: left}},
+		Operator:  op,
+	}
+
+	if right != nil {
+		cmp.RightValue = &core.Operand{Val: &core.Operand_Primitive{Primitive: utils.MustMakePrimitive(right)}}
+	}
+
+	return &core.BooleanExpression{Expr: &core.BooleanExpression_Comparison{Comparison: cmp}}
+}
+
+func TestValidateBooleanExpression(t *testing.T) {
+	valid := map[string]*core.BooleanExpression{
+		"primitives":          newComparison("i", core.ComparisonExpression_GT, 1),
+		"collection-length":   newComparison("xs", core.ComparisonExpression_GTE, 1),
+		"map-length":          newComparison("m", core.ComparisonExpression_EQ, 0),
+		"collection-contains": newComparison("xs", c.ComparisonOperatorContains.ToCore(), 1),
+		"map-contains":        newComparison("m", c.ComparisonOperatorContains.ToCore(), "key"),
+		"string-contains":     newComparison("s", c.ComparisonOperatorContains.ToCore(), "sub"),
+		"starts-with":         newComparison("s", c.ComparisonOperatorStartsWith.ToCore(), "prefix"),
+		"matches":             newComparison("s", c.ComparisonOperatorMatches.ToCore(), "^a.*b$"),
+		"is-null":             newComparison("s", c.ComparisonOperatorIsNull.ToCore(), nil),
+		"is-not-null":         newComparison("xs", c.ComparisonOperatorIsNotNull.ToCore(), nil),
+	}
+
+	for name, expr := range valid {
+		t.Run(name, func(t *testing.T) {
+			errs := errors.NewCompileErrors()
+			assert.True(t, ValidateBooleanExpression(newConditionNode(), expr, errs))
+			assert.False(t, errs.HasErrors(), errs.Error())
+		})
+	}
+
+	invalid := map[string]struct {
+		expr *core.BooleanExpression
+		code errors.ErrorCode
+	}{
+		"mismatching-primitives": {newComparison("i", core.ComparisonExpression_GT, "1"), errors.MismatchingTypes},
+		"collection-length":      {newComparison("xs", core.ComparisonExpression_GTE, "1"), errors.MismatchingTypes},
+		"collection-contains":    {newComparison("xs", c.ComparisonOperatorContains.ToCore(), "1"), errors.MismatchingTypes},
+		"map-contains":           {newComparison("m", c.ComparisonOperatorContains.ToCore(), 1), errors.MismatchingTypes},
+		"integer-contains":       {newComparison("i", c.ComparisonOperatorContains.ToCore(), 1), errors.UnsupportedComparison},
+		"integer-starts-with":    {newComparison("i", c.ComparisonOperatorStartsWith.ToCore(), "1"), errors.UnsupportedComparison},
+		"starts-with-integer":    {newComparison("s", c.ComparisonOperatorStartsWith.ToCore(), 1), errors.MismatchingTypes},
+		"invalid-regex":          {newComparison("s", c.ComparisonOperatorMatches.ToCore(), "("), errors.SyntaxError},
+		"unknown-operator":       {newComparison("s", core.ComparisonExpression_Operator(42), "a"), errors.UnrecognizedValue},
+		"is-null-unknown-var":    {newComparison("x", c.ComparisonOperatorIsNull.ToCore(), nil), errors.VariableNameNotFound},
+	}
+
+	for name, test := range invalid {
+		t.Run(name, func(t *testing.T) {
+			errs := errors.NewCompileErrors()
+			assert.False(t, ValidateBooleanExpression(newConditionNode(), test.expr, errs))
+			if assert.Equal(t, 1, errs.ErrorCount()) {
+				for e := range *errs.Errors() {
+					assert.Equal(t, test.code, e.Code())
+				}
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
@@ -12,6 +13,18 @@ const controllerAgentName = "flyteworkflow-controller"
 const workflowTerminationStatusKey = "termination-status"
 const workflowTerminatedValue = "terminated"
 const hourOfDayCompletedKey = "hour-of-day"
+const dateCompletedKey = "completed-date"
+const phaseCompletedKey = "completed-phase"
+const dateCompletedFormat = "2006-01-02"
+
+// The hour-of-day label alone can only express retention periods shorter than a day
+const maxHourOfDayRetentionHours = 22
+
+// Workflows annotated with RetainWorkflowKey=true are never garbage collected. The annotation is mirrored to a label of
+// the same name when the workflow completes, so workflows completed before the annotation was set need the label
+// instead.
+const RetainWorkflowKey = "flyte.lyft.com/retain"
+const retainWorkflowValue = "true"
 
 // This function creates a label selector, that will ignore all objects (in this case workflow) that DOES NOT have a
 // label key=workflowTerminationStatusKey with a value=workflowTerminatedValue
@@ -42,6 +55,25 @@ func SetCompletedLabel(w *v1alpha1.FlyteWorkflow, currentTime time.Time) {
 	}
 	w.Labels[workflowTerminationStatusKey] = workflowTerminatedValue
 	w.Labels[hourOfDayCompletedKey] = strconv.Itoa(currentTime.Hour())
+	w.Labels[dateCompletedKey] = currentTime.Format(dateCompletedFormat)
+	w.Labels[phaseCompletedKey] = CompletedPhaseLabelValue(w.GetExecutionStatus().GetPhase())
+	if w.GetAnnotations()[RetainWorkflowKey] == retainWorkflowValue {
+		w.Labels[RetainWorkflowKey] = retainWorkflowValue
+	}
+}
+
+// Removes the labels set by SetCompletedLabel, e.g. from a copy of a completed workflow that is to be executed again.
+// Otherwise the copy is ignored by the controller and garbage collected as completed.
+func ClearCompletedLabels(w *v1alpha1.FlyteWorkflow) {
+	for _, key := range []string{workflowTerminationStatusKey, hourOfDayCompletedKey, dateCompletedKey, phaseCompletedKey,
+		RetainWorkflowKey} {
+		delete(w.Labels, key)
+	}
+}
+
+// Returns the value of the completed phase label for a workflow phase, e.g. succeeded, failed or aborted.
+func CompletedPhaseLabelValue(phase v1alpha1.WorkflowPhase) string {
+	return strings.ToLower(phase.String())
 }
 
 func HasCompletedLabel(w *v1alpha1.FlyteWorkflow) bool {
@@ -85,3 +117,77 @@ func CompletedWorkflowsSelectorOutsideRetentionPeriod(retentionPeriodHours int,
 	})
 	return s
 }
+
+// Creates the selectors that together select all completed workflows outside of the retention window, excluding
+// retained workflows. Unlike CompletedWorkflowsSelectorOutsideRetentionPeriod, the retention period may be longer than
+// a day: workflows that completed on a day before the oldest retained hour are selected by their completed date, and
+// those that completed on the same day by their completed hour. Workflows completed before the date label was
+// introduced fall back to the hour-of-day label, which retains them for at most a day.
+func CompletedWorkflowsSelectorsOutsideRetentionPeriod(retentionPeriodHours int, currentTime time.Time) []*v1.LabelSelector {
+	currentHour := time.Date(currentTime.Year(), currentTime.Month(), currentTime.Day(), currentTime.Hour(), 0, 0, 0,
+		currentTime.Location())
+	oldestRetainedHour := currentHour.Add(-time.Duration(retentionPeriodHours) * time.Hour)
+	oldestRetainedDay := time.Date(oldestRetainedHour.Year(), oldestRetainedHour.Month(), oldestRetainedHour.Day(), 0, 0, 0, 0,
+		oldestRetainedHour.Location())
+
+	var retainedDays []string
+	for d := oldestRetainedDay; !d.After(currentTime); d = d.AddDate(0, 0, 1) {
+		retainedDays = append(retainedDays, d.Format(dateCompletedFormat))
+	}
+
+	notRetained := v1.LabelSelectorRequirement{
+		Key:      RetainWorkflowKey,
+		Operator: v1.LabelSelectorOpNotIn,
+		Values:   []string{retainWorkflowValue},
+	}
+
+	previousDays := CompletedWorkflowsLabelSelector()
+	previousDays.MatchExpressions = append(previousDays.MatchExpressions,
+		v1.LabelSelectorRequirement{
+			Key:      dateCompletedKey,
+			Operator: v1.LabelSelectorOpExists,
+		},
+		v1.LabelSelectorRequirement{
+			Key:      dateCompletedKey,
+			Operator: v1.LabelSelectorOpNotIn,
+			Values:   retainedDays,
+		},
+		notRetained,
+	)
+	selectors := []*v1.LabelSelector{previousDays}
+
+	if oldestRetainedHour.Hour() > 0 {
+		hoursToDelete := make([]string, 0, oldestRetainedHour.Hour())
+		for i := 0; i < oldestRetainedHour.Hour(); i++ {
+			hoursToDelete = append(hoursToDelete, strconv.Itoa(i))
+		}
+
+		sameDay := CompletedWorkflowsLabelSelector()
+		sameDay.MatchLabels[dateCompletedKey] = oldestRetainedDay.Format(dateCompletedFormat)
+		sameDay.MatchExpressions = append(sameDay.MatchExpressions,
+			v1.LabelSelectorRequirement{
+				Key:      hourOfDayCompletedKey,
+				Operator: v1.LabelSelectorOpIn,
+				Values:   hoursToDelete,
+			},
+			notRetained,
+		)
+		selectors = append(selectors, sameDay)
+	}
+
+	legacyRetentionPeriodHours := retentionPeriodHours
+	if legacyRetentionPeriodHours > maxHourOfDayRetentionHours {
+		legacyRetentionPeriodHours = maxHourOfDayRetentionHours
+	}
+
+	legacy := CompletedWorkflowsSelectorOutsideRetentionPeriod(legacyRetentionPeriodHours, currentTime)
+	legacy.MatchExpressions = append(legacy.MatchExpressions,
+		v1.LabelSelectorRequirement{
+			Key:      dateCompletedKey,
+			Operator: v1.LabelSelectorOpDoesNotExist,
+		},
+		notRetained,
+	)
+
+	return append(selectors, legacy)
+}
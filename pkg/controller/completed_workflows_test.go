@@ -7,6 +7,7 @@ import (
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	"github.com/stretchr/testify/assert"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 func TestIgnoreCompletedWorkflowsLabelSelector(t *testing.T) {
@@ -44,6 +45,25 @@ func TestHasCompletedLabel(t *testing.T) {
 		assert.True(t, HasCompletedLabel(w))
 	})
 
+	t.Run("phase-and-date", func(t *testing.T) {
+		w := &v1alpha1.FlyteWorkflow{Status: v1alpha1.WorkflowStatus{Phase: v1alpha1.WorkflowPhaseFailed}}
+		SetCompletedLabel(w, n)
+		assert.Equal(t, "2009-11-10", w.Labels[dateCompletedKey])
+		assert.Equal(t, "23", w.Labels[hourOfDayCompletedKey])
+		assert.Equal(t, "failed", w.Labels[phaseCompletedKey])
+		assert.NotContains(t, w.Labels, RetainWorkflowKey)
+	})
+
+	t.Run("retained", func(t *testing.T) {
+		w := &v1alpha1.FlyteWorkflow{
+			ObjectMeta: v1.ObjectMeta{
+				Annotations: map[string]string{RetainWorkflowKey: "true"},
+			},
+		}
+		SetCompletedLabel(w, n)
+		assert.Equal(t, "true", w.Labels[RetainWorkflowKey])
+	})
+
 	t.Run("existing-lables", func(t *testing.T) {
 		w := &v1alpha1.FlyteWorkflow{
 			ObjectMeta: v1.ObjectMeta{
@@ -158,3 +178,68 @@ func TestCompletedWorkflowsSelectorOutsideRetentionPeriod(t *testing.T) {
 		"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11", "12", "13", "14", "15", "16", "17", "18", "19", "20",
 	}, r.Values)
 }
+
+// Returns true if any of the selectors selects a workflow with the given labels
+func anySelectorMatches(t *testing.T, selectors []*v1.LabelSelector, l map[string]string) bool {
+	for _, s := range selectors {
+		selector, err := v1.LabelSelectorAsSelector(s)
+		assert.NoError(t, err)
+		if selector.Matches(labels.Set(l)) {
+			return true
+		}
+	}
+	return false
+}
+
+func completedLabels(completedAt time.Time) map[string]string {
+	w := &v1alpha1.FlyteWorkflow{}
+	SetCompletedLabel(w, completedAt)
+	return w.Labels
+}
+
+func TestCompletedWorkflowsSelectorsOutsideRetentionPeriod(t *testing.T) {
+	n := time.Date(2009, time.November, 10, 5, 30, 0, 0, time.UTC)
+
+	t.Run("multiple-days", func(t *testing.T) {
+		// Retains the current hour and the 71 before it, i.e. everything completed since 2009-11-07 06:00
+		selectors := CompletedWorkflowsSelectorsOutsideRetentionPeriod(71, n)
+		assert.Len(t, selectors, 3)
+
+		assert.False(t, anySelectorMatches(t, selectors, completedLabels(n)))
+		assert.False(t, anySelectorMatches(t, selectors, completedLabels(n.Add(-24*time.Hour))))
+		assert.False(t, anySelectorMatches(t, selectors, completedLabels(time.Date(2009, time.November, 7, 6, 10, 0, 0, time.UTC))))
+		assert.True(t, anySelectorMatches(t, selectors, completedLabels(time.Date(2009, time.November, 7, 5, 59, 0, 0, time.UTC))))
+		assert.True(t, anySelectorMatches(t, selectors, completedLabels(time.Date(2009, time.November, 6, 23, 0, 0, 0, time.UTC))))
+		assert.True(t, anySelectorMatches(t, selectors, completedLabels(time.Date(2009, time.January, 1, 0, 0, 0, 0, time.UTC))))
+	})
+
+	t.Run("retention-ends-at-midnight", func(t *testing.T) {
+		// Retains everything completed since 2009-11-10 00:00, no workflow of that day is selected by its hour
+		selectors := CompletedWorkflowsSelectorsOutsideRetentionPeriod(5, n)
+		assert.Len(t, selectors, 2)
+		assert.False(t, anySelectorMatches(t, selectors, completedLabels(time.Date(2009, time.November, 10, 0, 0, 0, 0, time.UTC))))
+		assert.True(t, anySelectorMatches(t, selectors, completedLabels(time.Date(2009, time.November, 9, 23, 59, 0, 0, time.UTC))))
+	})
+
+	t.Run("retained", func(t *testing.T) {
+		selectors := CompletedWorkflowsSelectorsOutsideRetentionPeriod(1, n)
+		l := completedLabels(n.Add(-48 * time.Hour))
+		assert.True(t, anySelectorMatches(t, selectors, l))
+		l[RetainWorkflowKey] = "true"
+		assert.False(t, anySelectorMatches(t, selectors, l))
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		// Workflows without a completed date are retained for at most a day
+		selectors := CompletedWorkflowsSelectorsOutsideRetentionPeriod(71, n)
+		legacy := map[string]string{workflowTerminationStatusKey: workflowTerminatedValue, hourOfDayCompletedKey: "6"}
+		assert.True(t, anySelectorMatches(t, selectors, legacy))
+		legacy[hourOfDayCompletedKey] = "5"
+		assert.False(t, anySelectorMatches(t, selectors, legacy))
+	})
+
+	t.Run("running", func(t *testing.T) {
+		selectors := CompletedWorkflowsSelectorsOutsideRetentionPeriod(1, n)
+		assert.False(t, anySelectorMatches(t, selectors, map[string]string{}))
+	})
+}
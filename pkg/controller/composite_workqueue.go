@@ -145,10 +145,22 @@ func (b *BatchingWorkQueue) AddToSubQueueRateLimited(item interface{}) {
 	b.subQueue.AddRateLimited(item)
 }
 
-func NewCompositeWorkQueue(ctx context.Context, cfg config.CompositeQueueConfig, scope promutils.Scope) (CompositeWorkQueue, error) {
-	workQ, err := NewWorkQueue(ctx, cfg.Queue, scope.CurrentScope())
-	if err != nil {
-		return nil, errors.Wrapf(err, "failed to create WorkQueue in CompositeQueue type Batch")
+// Creates the top-level workqueue. If priority queues are enabled, the classifier decides the priority class of every
+// item added to the queue.
+func NewCompositeWorkQueue(ctx context.Context, cfg config.CompositeQueueConfig, classify PriorityClassifier, scope promutils.Scope) (CompositeWorkQueue, error) {
+	var workQ workqueue.RateLimitingInterface
+	if cfg.Priority.Enabled {
+		priorityQ, err := NewPriorityWorkQueue(ctx, cfg.Queue, cfg.Priority, classify, scope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create PriorityWorkQueue in CompositeQueue")
+		}
+		workQ = priorityQ
+	} else {
+		var err error
+		workQ, err = NewWorkQueue(ctx, cfg.Queue, scope.CurrentScope())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create WorkQueue in CompositeQueue type Batch")
+		}
 	}
 	switch cfg.Type {
 	case config.CompositeQueueBatch:
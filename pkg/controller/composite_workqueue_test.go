@@ -18,7 +18,7 @@ func TestNewCompositeWorkQueue(t *testing.T) {
 	t.Run("simple", func(t *testing.T) {
 		testScope := promutils.NewScope("test1")
 		cfg := config2.CompositeQueueConfig{}
-		q, err := NewCompositeWorkQueue(ctx, cfg, testScope)
+		q, err := NewCompositeWorkQueue(ctx, cfg, nil, testScope)
 		assert.NoError(t, err)
 		assert.NotNil(t, q)
 		switch q.(type) {
@@ -29,6 +29,23 @@ func TestNewCompositeWorkQueue(t *testing.T) {
 		}
 	})
 
+	t.Run("priority", func(t *testing.T) {
+		testScope := promutils.NewScope("test_priority")
+		cfg := config2.CompositeQueueConfig{
+			Priority: config2.PriorityQueueConfig{Enabled: true, CriticalWeight: 2, RegularWeight: 1},
+		}
+		q, err := NewCompositeWorkQueue(ctx, cfg, prefixClassifier, testScope)
+		assert.NoError(t, err)
+		switch sq := q.(type) {
+		case *SimpleWorkQueue:
+			_, ok := sq.RateLimitingInterface.(*PriorityWorkQueue)
+			assert.True(t, ok)
+			return
+		default:
+			assert.FailNow(t, "SimpleWorkQueue expected")
+		}
+	})
+
 	t.Run("batch", func(t *testing.T) {
 		testScope := promutils.NewScope("test2")
 		cfg := config2.CompositeQueueConfig{
@@ -36,7 +53,7 @@ func TestNewCompositeWorkQueue(t *testing.T) {
 			BatchSize:        -1,
 			BatchingInterval: config.Duration{Duration: time.Second * 1},
 		}
-		q, err := NewCompositeWorkQueue(ctx, cfg, testScope)
+		q, err := NewCompositeWorkQueue(ctx, cfg, nil, testScope)
 		assert.NoError(t, err)
 		assert.NotNil(t, q)
 		switch bq := q.(type) {
@@ -54,7 +71,7 @@ func TestSimpleWorkQueue(t *testing.T) {
 	ctx := context.TODO()
 	testScope := promutils.NewScope("test")
 	cfg := config2.CompositeQueueConfig{}
-	q, err := NewCompositeWorkQueue(ctx, cfg, testScope)
+	q, err := NewCompositeWorkQueue(ctx, cfg, nil, testScope)
 	assert.NoError(t, err)
 	assert.NotNil(t, q)
 
@@ -97,7 +114,7 @@ func TestBatchingQueue(t *testing.T) {
 		BatchSize:        -1,
 		BatchingInterval: config.Duration{Duration: time.Nanosecond * 1},
 	}
-	q, err := NewCompositeWorkQueue(ctx, cfg, testScope)
+	q, err := NewCompositeWorkQueue(ctx, cfg, nil, testScope)
 	assert.NoError(t, err)
 	assert.NotNil(t, q)
 
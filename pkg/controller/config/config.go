@@ -26,6 +26,10 @@ var (
 				Rate:      10,
 				Capacity:  100,
 			},
+			Priority: PriorityQueueConfig{
+				CriticalWeight: 4,
+				RegularWeight:  1,
+			},
 		},
 		KubeConfig: KubeClientConfig{
 			QPS:     5,
@@ -47,6 +51,20 @@ var (
 			MaxNodeRetriesOnSystemFailures: 3,
 			InterruptibleFailureThreshold:  1,
 		},
+		Sharding: ShardingConfig{
+			ShardCount:      1,
+			ShardIndex:      -1,
+			Strategy:        ShardingStrategyExecution,
+			RelabelInterval: config.Duration{Duration: time.Minute},
+		},
+		Archive: ArchiveConfig{
+			Prefix: "archive",
+		},
+		StuckWorkflows: StuckWorkflowsConfig{
+			Interval:    config.Duration{Duration: time.Minute * 5},
+			Threshold:   config.Duration{Duration: time.Hour * 2},
+			Remediation: StuckWorkflowRemediationNone,
+		},
 	}
 )
 
@@ -67,13 +85,18 @@ type Config struct {
 	MetricsPrefix          string               `json:"metrics-prefix" pflag:"\"flyte:\",An optional prefix for all published metrics."`
 	EnableAdminLauncher    bool                 `json:"enable-admin-launcher" pflag:"false, Enable remote Workflow launcher to Admin"`
 	MaxWorkflowRetries     int                  `json:"max-workflow-retries" pflag:"50,Maximum number of retries per workflow"`
-	MaxTTLInHours          int                  `json:"max-ttl-hours" pflag:"23,Maximum number of hours a completed workflow should be retained. Number between 1-23 hours"`
+	MaxTTLInHours          int                  `json:"max-ttl-hours" pflag:"23,Maximum number of hours a completed workflow should be retained. 0 disables garbage collection."`
 	GCInterval             config.Duration      `json:"gc-interval" pflag:"\"30m\",Run periodic GC every 30 minutes"`
 	LeaderElection         LeaderElectionConfig `json:"leader-election,omitempty" pflag:",Config for leader election."`
 	PublishK8sEvents       bool                 `json:"publish-k8s-events" pflag:",Enable events publishing to K8s events API."`
 	MaxDatasetSizeBytes    int64                `json:"max-output-size-bytes" pflag:",Maximum size of outputs per task"`
 	KubeConfig             KubeClientConfig     `json:"kube-client-config" pflag:",Configuration to control the Kubernetes client"`
 	NodeConfig             NodeConfig           `json:"node-config,omitempty" pflag:",config for a workflow node"`
+	ConcurrencyLimits      ConcurrencyLimits    `json:"concurrency-limits,omitempty" pflag:",Limits on the number of concurrently running workflows"`
+	Sharding               ShardingConfig       `json:"sharding,omitempty" pflag:",Configuration to split workflows across multiple propeller replicas"`
+	Archive                ArchiveConfig        `json:"archive,omitempty" pflag:",Configuration to archive completed workflows to blob storage before they are garbage collected"`
+	Retention              RetentionConfig      `json:"retention,omitempty" pflag:",Overrides of max-ttl-hours per namespace and per workflow phase"`
+	StuckWorkflows         StuckWorkflowsConfig `json:"stuck-workflows,omitempty" pflag:",Configuration to detect and remediate workflows that stopped making progress"`
 }
 
 type KubeClientConfig struct {
@@ -95,11 +118,21 @@ const (
 )
 
 type CompositeQueueConfig struct {
-	Type             CompositeQueueType `json:"type" pflag:",Type of composite queue to use for the WorkQueue"`
-	Queue            WorkqueueConfig    `json:"queue,omitempty" pflag:",Workflow workqueue configuration, affects the way the work is consumed from the queue."`
-	Sub              WorkqueueConfig    `json:"sub-queue,omitempty" pflag:",SubQueue configuration, affects the way the nodes cause the top-level Work to be re-evaluated."`
-	BatchingInterval config.Duration    `json:"batching-interval" pflag:",Duration for which downstream updates are buffered"`
-	BatchSize        int                `json:"batch-size" pflag:"-1,Number of downstream triggered top-level objects to re-enqueue every duration. -1 indicates all available."`
+	Type             CompositeQueueType  `json:"type" pflag:",Type of composite queue to use for the WorkQueue"`
+	Queue            WorkqueueConfig     `json:"queue,omitempty" pflag:",Workflow workqueue configuration, affects the way the work is consumed from the queue."`
+	Sub              WorkqueueConfig     `json:"sub-queue,omitempty" pflag:",SubQueue configuration, affects the way the nodes cause the top-level Work to be re-evaluated."`
+	BatchingInterval config.Duration     `json:"batching-interval" pflag:",Duration for which downstream updates are buffered"`
+	BatchSize        int                 `json:"batch-size" pflag:"-1,Number of downstream triggered top-level objects to re-enqueue every duration. -1 indicates all available."`
+	Priority         PriorityQueueConfig `json:"priority,omitempty" pflag:",Configuration of the weighted queues per workflow priority class."`
+}
+
+// Splits the top-level workqueue into a queue per workflow priority class. When workers are saturated, workflows are
+// picked from the queues in proportion to their weights.
+type PriorityQueueConfig struct {
+	Enabled         bool     `json:"enabled" pflag:",Enables separate queues per workflow priority class."`
+	CriticalWeight  int      `json:"critical-weight" pflag:",Relative share of the workers given to critical workflows."`
+	RegularWeight   int      `json:"regular-weight" pflag:",Relative share of the workers given to regular workflows."`
+	CriticalDomains []string `json:"critical-domains" pflag:",Domains whose workflows are critical unless the priority class annotation says otherwise."`
 }
 
 type WorkqueueType = string
@@ -135,6 +168,87 @@ type DefaultDeadlines struct {
 	DefaultWorkflowActiveDeadline config.Duration `json:"workflow-active-deadline" pflag:",Default value of workflow timeout"`
 }
 
+// Caps on the number of concurrently running workflows. Workflows that would exceed any of the limits are held in the
+// queued phase until enough running workflows complete. A limit of 0 means no limit. Running workflows are counted from
+// the informer cache, so when sharded, the limits apply to each shard separately, unless all the workflows of a
+// namespace, project or launch plan are in the same shard, e.g. with the namespace or project sharding strategy.
+type ConcurrencyLimits struct {
+	// Default limit for every namespace, NamespaceLimits overrides it for specific namespaces.
+	NamespaceLimit  int            `json:"namespace-limit" pflag:",Max number of concurrently running workflows per namespace. 0 means no limit."`
+	NamespaceLimits map[string]int `json:"namespace-limits" pflag:"-,Per namespace overrides of namespace-limit."`
+
+	// Default limit for every project, ProjectLimits overrides it for specific projects.
+	ProjectLimit  int            `json:"project-limit" pflag:",Max number of concurrently running workflows per project. 0 means no limit."`
+	ProjectLimits map[string]int `json:"project-limits" pflag:"-,Per project overrides of project-limit."`
+
+	// Workflows carrying the same value for this label are considered to be launched by the same launch plan.
+	LaunchPlanLabel  string         `json:"launch-plan-label" pflag:",Workflow label identifying the launch plan. Launch plan limits are disabled if empty."`
+	LaunchPlanLimit  int            `json:"launch-plan-limit" pflag:",Max number of concurrently running workflows per launch plan. 0 means no limit."`
+	LaunchPlanLimits map[string]int `json:"launch-plan-limits" pflag:"-,Per launch plan (label value) overrides of launch-plan-limit."`
+}
+
+type ShardingStrategy = string
+
+const (
+	ShardingStrategyNamespace ShardingStrategy = "namespace"
+	ShardingStrategyProject   ShardingStrategy = "project"
+	ShardingStrategyExecution ShardingStrategy = "execution"
+)
+
+// Splits workflows across multiple replicas of propeller. Every workflow is labeled with the shard keys of its
+// namespace, project and execution name, and every replica owns a contiguous range of shard keys and only lists the
+// workflows in its range. To rebalance after changing the number of replicas, restart all replicas with the new shard
+// count, workflows do not need to be relabeled. While replicas roll over their ranges may briefly overlap, which is safe
+// as workflow updates are guarded by their resource version. Workflows created without shard key labels, e.g. before
+// sharding was enabled, are not listed by any replica, so the first shard periodically labels them.
+// Every replica only sees its own workflows: concurrency limits are enforced and stuck workflows are detected per shard.
+type ShardingConfig struct {
+	ShardCount      int              `json:"shard-count" pflag:",Number of replicas workflows are split across. 1 disables sharding."`
+	ShardIndex      int              `json:"shard-index" pflag:",Index of this replica in [0, shard-count). -1 derives it from the ordinal suffix of the pod name, as assigned by a StatefulSet."`
+	Strategy        ShardingStrategy `json:"strategy" pflag:",Attribute of the workflows used to assign them to shards. One of namespace, project or execution."`
+	RelabelInterval config.Duration  `json:"relabel-interval" pflag:",Interval at which the first shard labels the workflows that have no shard key labels."`
+}
+
+type StuckWorkflowRemediation = string
+
+const (
+	// Only report stuck workflows, through metrics and k8s events
+	StuckWorkflowRemediationNone StuckWorkflowRemediation = "none"
+	// Add stuck workflows to the workqueue again
+	StuckWorkflowRemediationEnqueue StuckWorkflowRemediation = "enqueue"
+	// Fail stuck workflows with a system error
+	StuckWorkflowRemediationFail StuckWorkflowRemediation = "fail"
+)
+
+// A non-terminal workflow is stuck if neither its status nor the status of any of its nodes was updated within the
+// threshold. Workflows waiting for a gate or sleep node, or for the retry delay of a node to pass, or queued by
+// concurrency limits, are never stuck. As a task
+// that runs without changing phase does not update any status either, the threshold must be longer than the longest
+// expected task.
+type StuckWorkflowsConfig struct {
+	Enabled     bool                     `json:"enabled" pflag:",Enables periodically scanning for stuck workflows."`
+	Interval    config.Duration          `json:"interval" pflag:",Interval at which workflows are scanned."`
+	Threshold   config.Duration          `json:"threshold" pflag:",Time without any status update after which a running workflow is considered stuck."`
+	Remediation StuckWorkflowRemediation `json:"remediation" pflag:",Action taken on stuck workflows. One of none, enqueue or fail."`
+}
+
+// Overrides the number of hours completed workflows are retained for, before they are garbage collected. Phase TTLs
+// take precedence over namespace TTLs, which take precedence over max-ttl-hours. A TTL of 0 or less retains the
+// matching workflows forever.
+type RetentionConfig struct {
+	NamespaceTTLHours map[string]int `json:"namespace-ttl-hours" pflag:"-,Per namespace overrides of max-ttl-hours."`
+	// Keyed by the lower cased phase of completed workflows, i.e. succeeded, failed or aborted.
+	PhaseTTLHours map[string]int `json:"phase-ttl-hours" pflag:"-,Per phase (succeeded, failed or aborted) overrides of max-ttl-hours."`
+}
+
+// Completed workflows are written to blob storage before the garbage collector deletes them. They are stored as json
+// under <metadata-prefix>/<prefix>/<project>/<domain>/<yyyy-mm-dd>/<name>.json and can be read back using
+// kubectl-flyte get --archived.
+type ArchiveConfig struct {
+	Enabled bool   `json:"enabled" pflag:",Enables archiving completed workflows before they are garbage collected."`
+	Prefix  string `json:"prefix" pflag:",Prefix under the metadata prefix that workflows are archived to."`
+}
+
 // Contains leader election configuration.
 type LeaderElectionConfig struct {
 	// Enable or disable leader election.
@@ -63,10 +63,14 @@ func (cfg Config) GetPFlagSet(prefix string) *pflag.FlagSet {
 	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "queue.sub-queue.capacity"), defaultConfig.Queue.Sub.Capacity, "Bucket capacity as number of items")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "queue.batching-interval"), defaultConfig.Queue.BatchingInterval.String(), "Duration for which downstream updates are buffered")
 	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "queue.batch-size"), defaultConfig.Queue.BatchSize, "Number of downstream triggered top-level objects to re-enqueue every duration. -1 indicates all available.")
+	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "queue.priority.enabled"), defaultConfig.Queue.Priority.Enabled, "Enables separate queues per workflow priority class.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "queue.priority.critical-weight"), defaultConfig.Queue.Priority.CriticalWeight, "Relative share of the workers given to critical workflows.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "queue.priority.regular-weight"), defaultConfig.Queue.Priority.RegularWeight, "Relative share of the workers given to regular workflows.")
+	cmdFlags.StringSlice(fmt.Sprintf("%v%v", prefix, "queue.priority.critical-domains"), []string{}, "Domains whose workflows are critical unless the priority class annotation says otherwise.")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "metrics-prefix"), defaultConfig.MetricsPrefix, "An optional prefix for all published metrics.")
 	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "enable-admin-launcher"), defaultConfig.EnableAdminLauncher, " Enable remote Workflow launcher to Admin")
 	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "max-workflow-retries"), defaultConfig.MaxWorkflowRetries, "Maximum number of retries per workflow")
-	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "max-ttl-hours"), defaultConfig.MaxTTLInHours, "Maximum number of hours a completed workflow should be retained. Number between 1-23 hours")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "max-ttl-hours"), defaultConfig.MaxTTLInHours, "Maximum number of hours a completed workflow should be retained. 0 disables garbage collection.")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "gc-interval"), defaultConfig.GCInterval.String(), "Run periodic GC every 30 minutes")
 	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "leader-election.enabled"), defaultConfig.LeaderElection.Enabled, "Enables/Disables leader election.")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "leader-election.lock-config-map.Namespace"), defaultConfig.LeaderElection.LockConfigMap.Namespace, "")
@@ -83,5 +87,19 @@ func (cfg Config) GetPFlagSet(prefix string) *pflag.FlagSet {
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "node-config.default-deadlines.workflow-active-deadline"), defaultConfig.NodeConfig.DefaultDeadlines.DefaultWorkflowActiveDeadline.String(), "Default value of workflow timeout")
 	cmdFlags.Int64(fmt.Sprintf("%v%v", prefix, "node-config.max-node-retries-system-failures"), defaultConfig.NodeConfig.MaxNodeRetriesOnSystemFailures, "Maximum number of retries per node for node failure due to infra issues")
 	cmdFlags.Int64(fmt.Sprintf("%v%v", prefix, "node-config.interruptible-failure-threshold"), defaultConfig.NodeConfig.InterruptibleFailureThreshold, "number of failures for a node to be still considered interruptible'")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "concurrency-limits.namespace-limit"), defaultConfig.ConcurrencyLimits.NamespaceLimit, "Max number of concurrently running workflows per namespace. 0 means no limit.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "concurrency-limits.project-limit"), defaultConfig.ConcurrencyLimits.ProjectLimit, "Max number of concurrently running workflows per project. 0 means no limit.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "concurrency-limits.launch-plan-label"), defaultConfig.ConcurrencyLimits.LaunchPlanLabel, "Workflow label identifying the launch plan. Launch plan limits are disabled if empty.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "concurrency-limits.launch-plan-limit"), defaultConfig.ConcurrencyLimits.LaunchPlanLimit, "Max number of concurrently running workflows per launch plan. 0 means no limit.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "sharding.shard-count"), defaultConfig.Sharding.ShardCount, "Number of replicas workflows are split across. 1 disables sharding.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "sharding.shard-index"), defaultConfig.Sharding.ShardIndex, "Index of this replica in [0,  shard-count). -1 derives it from the ordinal suffix of the pod name,  as assigned by a StatefulSet.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "sharding.strategy"), defaultConfig.Sharding.Strategy, "Attribute of the workflows used to assign them to shards. One of namespace,  project or execution.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "sharding.relabel-interval"), defaultConfig.Sharding.RelabelInterval.String(), "Interval at which the first shard labels the workflows that have no shard key labels.")
+	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "archive.enabled"), defaultConfig.Archive.Enabled, "Enables archiving completed workflows before they are garbage collected.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "archive.prefix"), defaultConfig.Archive.Prefix, "Prefix under the metadata prefix that workflows are archived to.")
+	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "stuck-workflows.enabled"), defaultConfig.StuckWorkflows.Enabled, "Enables periodically scanning for stuck workflows.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "stuck-workflows.interval"), defaultConfig.StuckWorkflows.Interval.String(), "Interval at which workflows are scanned.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "stuck-workflows.threshold"), defaultConfig.StuckWorkflows.Threshold.String(), "Time without any status update after which a running workflow is considered stuck.")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "stuck-workflows.remediation"), defaultConfig.StuckWorkflows.Remediation, "Action taken on stuck workflows. One of none,  enqueue or fail.")
 	return cmdFlags
 }
@@ -583,6 +583,94 @@ func TestConfig_SetFlags(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Test_queue.priority.enabled", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vBool, err := cmdFlags.GetBool("queue.priority.enabled"); err == nil {
+				assert.Equal(t, bool(defaultConfig.Queue.Priority.Enabled), vBool)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("queue.priority.enabled", testValue)
+			if vBool, err := cmdFlags.GetBool("queue.priority.enabled"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vBool), &actual.Queue.Priority.Enabled)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_queue.priority.critical-weight", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("queue.priority.critical-weight"); err == nil {
+				assert.Equal(t, int(defaultConfig.Queue.Priority.CriticalWeight), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("queue.priority.critical-weight", testValue)
+			if vInt, err := cmdFlags.GetInt("queue.priority.critical-weight"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Queue.Priority.CriticalWeight)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_queue.priority.regular-weight", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("queue.priority.regular-weight"); err == nil {
+				assert.Equal(t, int(defaultConfig.Queue.Priority.RegularWeight), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("queue.priority.regular-weight", testValue)
+			if vInt, err := cmdFlags.GetInt("queue.priority.regular-weight"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Queue.Priority.RegularWeight)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_queue.priority.critical-domains", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vStringSlice, err := cmdFlags.GetStringSlice("queue.priority.critical-domains"); err == nil {
+				assert.Equal(t, []string([]string{}), vStringSlice)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := join_Config("1,1", ",")
+
+			cmdFlags.Set("queue.priority.critical-domains", testValue)
+			if vStringSlice, err := cmdFlags.GetStringSlice("queue.priority.critical-domains"); err == nil {
+				testDecodeSlice_Config(t, join_Config(vStringSlice, ","), &actual.Queue.Priority.CriticalDomains)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
 	t.Run("Test_metrics-prefix", func(t *testing.T) {
 		t.Run("DefaultValue", func(t *testing.T) {
 			// Test that default value is set properly
@@ -1023,4 +1111,312 @@ func TestConfig_SetFlags(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Test_concurrency-limits.namespace-limit", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("concurrency-limits.namespace-limit"); err == nil {
+				assert.Equal(t, int(defaultConfig.ConcurrencyLimits.NamespaceLimit), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("concurrency-limits.namespace-limit", testValue)
+			if vInt, err := cmdFlags.GetInt("concurrency-limits.namespace-limit"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.ConcurrencyLimits.NamespaceLimit)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_concurrency-limits.project-limit", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("concurrency-limits.project-limit"); err == nil {
+				assert.Equal(t, int(defaultConfig.ConcurrencyLimits.ProjectLimit), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("concurrency-limits.project-limit", testValue)
+			if vInt, err := cmdFlags.GetInt("concurrency-limits.project-limit"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.ConcurrencyLimits.ProjectLimit)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_concurrency-limits.launch-plan-label", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("concurrency-limits.launch-plan-label"); err == nil {
+				assert.Equal(t, string(defaultConfig.ConcurrencyLimits.LaunchPlanLabel), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("concurrency-limits.launch-plan-label", testValue)
+			if vString, err := cmdFlags.GetString("concurrency-limits.launch-plan-label"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.ConcurrencyLimits.LaunchPlanLabel)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_concurrency-limits.launch-plan-limit", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("concurrency-limits.launch-plan-limit"); err == nil {
+				assert.Equal(t, int(defaultConfig.ConcurrencyLimits.LaunchPlanLimit), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("concurrency-limits.launch-plan-limit", testValue)
+			if vInt, err := cmdFlags.GetInt("concurrency-limits.launch-plan-limit"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.ConcurrencyLimits.LaunchPlanLimit)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.shard-count", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("sharding.shard-count"); err == nil {
+				assert.Equal(t, int(defaultConfig.Sharding.ShardCount), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sharding.shard-count", testValue)
+			if vInt, err := cmdFlags.GetInt("sharding.shard-count"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Sharding.ShardCount)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.shard-index", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("sharding.shard-index"); err == nil {
+				assert.Equal(t, int(defaultConfig.Sharding.ShardIndex), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sharding.shard-index", testValue)
+			if vInt, err := cmdFlags.GetInt("sharding.shard-index"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.Sharding.ShardIndex)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.strategy", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("sharding.strategy"); err == nil {
+				assert.Equal(t, string(defaultConfig.Sharding.Strategy), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("sharding.strategy", testValue)
+			if vString, err := cmdFlags.GetString("sharding.strategy"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Sharding.Strategy)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_sharding.relabel-interval", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("sharding.relabel-interval"); err == nil {
+				assert.Equal(t, string(defaultConfig.Sharding.RelabelInterval.String()), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := defaultConfig.Sharding.RelabelInterval.String()
+
+			cmdFlags.Set("sharding.relabel-interval", testValue)
+			if vString, err := cmdFlags.GetString("sharding.relabel-interval"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Sharding.RelabelInterval)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_archive.enabled", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vBool, err := cmdFlags.GetBool("archive.enabled"); err == nil {
+				assert.Equal(t, bool(defaultConfig.Archive.Enabled), vBool)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("archive.enabled", testValue)
+			if vBool, err := cmdFlags.GetBool("archive.enabled"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vBool), &actual.Archive.Enabled)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_archive.prefix", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("archive.prefix"); err == nil {
+				assert.Equal(t, string(defaultConfig.Archive.Prefix), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("archive.prefix", testValue)
+			if vString, err := cmdFlags.GetString("archive.prefix"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Archive.Prefix)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_stuck-workflows.enabled", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vBool, err := cmdFlags.GetBool("stuck-workflows.enabled"); err == nil {
+				assert.Equal(t, bool(defaultConfig.StuckWorkflows.Enabled), vBool)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("stuck-workflows.enabled", testValue)
+			if vBool, err := cmdFlags.GetBool("stuck-workflows.enabled"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vBool), &actual.StuckWorkflows.Enabled)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_stuck-workflows.interval", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("stuck-workflows.interval"); err == nil {
+				assert.Equal(t, string(defaultConfig.StuckWorkflows.Interval.String()), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := defaultConfig.StuckWorkflows.Interval.String()
+
+			cmdFlags.Set("stuck-workflows.interval", testValue)
+			if vString, err := cmdFlags.GetString("stuck-workflows.interval"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.StuckWorkflows.Interval)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_stuck-workflows.threshold", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("stuck-workflows.threshold"); err == nil {
+				assert.Equal(t, string(defaultConfig.StuckWorkflows.Threshold.String()), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := defaultConfig.StuckWorkflows.Threshold.String()
+
+			cmdFlags.Set("stuck-workflows.threshold", testValue)
+			if vString, err := cmdFlags.GetString("stuck-workflows.threshold"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.StuckWorkflows.Threshold)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_stuck-workflows.remediation", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("stuck-workflows.remediation"); err == nil {
+				assert.Equal(t, string(defaultConfig.StuckWorkflows.Remediation), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("stuck-workflows.remediation", testValue)
+			if vString, err := cmdFlags.GetString("stuck-workflows.remediation"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.StuckWorkflows.Remediation)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
 }
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"runtime/pprof"
 	"time"
 
@@ -61,6 +62,8 @@ type Controller struct {
 	flyteworkflowSynced cache.InformerSynced
 	workQueue           CompositeWorkQueue
 	gc                  *GarbageCollector
+	shardRelabeler      *ShardRelabeler
+	stuckDetector       *StuckWorkflowDetector
 	numWorkers          int
 	workflowStore       workflowstore.FlyteWorkflow
 	// recorder is an event recorder for recording Event resources to the
@@ -93,9 +96,21 @@ func (c *Controller) run(ctx context.Context) error {
 	}
 
 	// Start the GC
-	if err := c.gc.StartGC(ctx); err != nil {
-		logger.Errorf(ctx, "failed to start background GC")
-		return err
+	if c.gc != nil {
+		if err := c.gc.StartGC(ctx); err != nil {
+			logger.Errorf(ctx, "failed to start background GC")
+			return err
+		}
+	}
+
+	// Start labeling the workflows that are not owned by any shard
+	if c.shardRelabeler != nil {
+		c.shardRelabeler.StartRelabeler(ctx)
+	}
+
+	// Start the stuck workflow detector
+	if c.stuckDetector != nil {
+		c.stuckDetector.StartDetector(ctx)
 	}
 
 	// Start the collector process
@@ -194,11 +209,34 @@ type ResourceLevelMonitor struct {
 	CollectorTimer promutils.StopWatch
 
 	// System Observability: This is a labeled gauge that emits the current number of FlyteWorkflow objects in the informer. It is used
-	// to monitor current levels. It currently only splits by project/domain, not workflow status.
+	// to monitor current levels. It only splits by project/domain, phaseLevels splits by workflow status as well.
 	levels labeled.Gauge
 
+	// Current number of workflows per project, domain and workflow phase
+	phaseLevels *prometheus.GaugeVec
+
+	// Current number of active (started and not yet terminal) nodes of non-terminal workflows, per project, domain and
+	// node phase. Nodes of sub-workflows and dynamic nodes are included, offloaded node statuses are not.
+	nodePhaseLevels *prometheus.GaugeVec
+
+	// Current distribution of the time since workflows were created, and since they were last updated, per project,
+	// domain and workflow phase. E.g. Running workflows not updated for an hour are likely stuck.
+	ageHistogram          *levelHistogram
+	sinceUpdatedHistogram *levelHistogram
+
 	// The thing that we want to measure the current levels of
 	lister lister.FlyteWorkflowLister
+
+	clk clock.Clock
+}
+
+func projectDomain(ctx context.Context, wf *v1alpha1.FlyteWorkflow) (project, domain string) {
+	execID := wf.GetExecutionID()
+	if execID.WorkflowExecutionIdentifier == nil {
+		logger.Warningf(ctx, "Workflow does not have an execution identifier! [%v]", wf)
+		return missing, missing
+	}
+	return wf.ExecutionID.Project, wf.ExecutionID.Domain
 }
 
 func (r *ResourceLevelMonitor) countList(ctx context.Context, workflows []*v1alpha1.FlyteWorkflow) map[string]map[string]int {
@@ -207,17 +245,7 @@ func (r *ResourceLevelMonitor) countList(ctx context.Context, workflows []*v1alp
 
 	// Collect all workflow metrics
 	for _, wf := range workflows {
-		execID := wf.GetExecutionID()
-		var project string
-		var domain string
-		if execID.WorkflowExecutionIdentifier == nil {
-			logger.Warningf(ctx, "Workflow does not have an execution identifier! [%v]", wf)
-			project = missing
-			domain = missing
-		} else {
-			project = wf.ExecutionID.Project
-			domain = wf.ExecutionID.Domain
-		}
+		project, domain := projectDomain(ctx, wf)
 		if _, ok := counts[project]; !ok {
 			counts[project] = map[string]int{}
 		}
@@ -227,6 +255,69 @@ func (r *ResourceLevelMonitor) countList(ctx context.Context, workflows []*v1alp
 	return counts
 }
 
+// Counts the active nodes, including nested ones, by node phase.
+func countActiveNodes(nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus, counts map[v1alpha1.NodePhase]int) {
+	for _, n := range nodeStatus {
+		if n == nil {
+			continue
+		}
+
+		if n.Phase != v1alpha1.NodePhaseNotYetStarted && !v1alpha1.IsPhaseTerminal(n.Phase) {
+			counts[n.Phase]++
+		}
+
+		countActiveNodes(n.SubNodeStatus, counts)
+	}
+}
+
+func (r *ResourceLevelMonitor) collectByPhase(ctx context.Context, workflows []*v1alpha1.FlyteWorkflow) {
+	now := r.clk.Now()
+	ages := r.ageHistogram.NewSnapshot()
+	sinceUpdated := r.sinceUpdatedHistogram.NewSnapshot()
+
+	// Keyed by project, domain and phase
+	phaseCounts := map[[3]string]int{}
+	nodePhaseCounts := map[[3]string]int{}
+	for _, wf := range workflows {
+		project, domain := projectDomain(ctx, wf)
+		phase := wf.GetExecutionStatus().GetPhase()
+		phaseCounts[[3]string{project, domain, phase.String()}]++
+
+		createdAt := wf.GetCreationTimestamp().Time
+		ages.Observe(now.Sub(createdAt).Seconds(), project, domain, phase.String())
+
+		updatedAt := createdAt
+		if lastUpdated := wf.Status.LastUpdatedAt; lastUpdated != nil {
+			updatedAt = lastUpdated.Time
+		}
+		sinceUpdated.Observe(now.Sub(updatedAt).Seconds(), project, domain, phase.String())
+
+		if v1alpha1.IsWorkflowPhaseTerminal(phase) {
+			continue
+		}
+
+		nodeCounts := map[v1alpha1.NodePhase]int{}
+		countActiveNodes(wf.Status.NodeStatus, nodeCounts)
+		for nodePhase, count := range nodeCounts {
+			nodePhaseCounts[[3]string{project, domain, nodePhase.String()}] += count
+		}
+	}
+
+	// Gauges of project/domain/phase combinations that no longer exist must not keep their last value
+	r.phaseLevels.Reset()
+	for k, count := range phaseCounts {
+		r.phaseLevels.WithLabelValues(k[:]...).Set(float64(count))
+	}
+
+	r.nodePhaseLevels.Reset()
+	for k, count := range nodePhaseCounts {
+		r.nodePhaseLevels.WithLabelValues(k[:]...).Set(float64(count))
+	}
+
+	ages.Publish()
+	sinceUpdated.Publish()
+}
+
 func (r *ResourceLevelMonitor) collect(ctx context.Context) {
 	// Emit gauges at both the project/domain level - aggregation to be handled by Prometheus
 	workflows, err := r.lister.List(labels.Everything())
@@ -243,6 +334,8 @@ func (r *ResourceLevelMonitor) collect(ctx context.Context) {
 			r.levels.Set(tempContext, float64(num))
 		}
 	}
+
+	r.collectByPhase(ctx, workflows)
 }
 
 func (r *ResourceLevelMonitor) RunCollector(ctx context.Context) {
@@ -269,7 +362,16 @@ func NewResourceLevelMonitor(scope promutils.Scope, lister lister.FlyteWorkflowL
 		Scope:          scope,
 		CollectorTimer: scope.MustNewStopWatch("collection_cycle", "Measures how long it takes to run a collection", time.Millisecond),
 		levels:         labeled.NewGauge("flyteworkflow", "Current FlyteWorkflow levels per instance of propeller", scope),
-		lister:         lister,
+		phaseLevels: scope.MustNewGaugeVec("flyteworkflow_phase", "Current FlyteWorkflow levels per workflow phase",
+			"project", "domain", "phase"),
+		nodePhaseLevels: scope.MustNewGaugeVec("flyteworkflow_node_phase", "Current number of active nodes of running FlyteWorkflows per node phase",
+			"project", "domain", "phase"),
+		ageHistogram: mustNewLevelHistogram(scope.NewScopedMetricName("flyteworkflow_age_seconds"),
+			"Current distribution of the time since FlyteWorkflows were created", workflowAgeBuckets, "project", "domain", "phase"),
+		sinceUpdatedHistogram: mustNewLevelHistogram(scope.NewScopedMetricName("flyteworkflow_since_updated_seconds"),
+			"Current distribution of the time since FlyteWorkflows were last updated", workflowAgeBuckets, "project", "domain", "phase"),
+		lister: lister,
+		clk:    clock.RealClock{},
 	}
 }
 
@@ -330,10 +432,17 @@ func New(ctx context.Context, cfg *config.Config, kubeclientset kubernetes.Inter
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to create EventSink [%v], error %v", events.GetConfig(ctx).Type, err)
 	}
-	gc, err := NewGarbageCollector(cfg, scope, clock.RealClock{}, kubeclientset.CoreV1().Namespaces(), flytepropellerClientset.FlyteworkflowV1alpha1())
-	if err != nil {
-		logger.Errorf(ctx, "failed to initialize GC for workflows")
-		return nil, errors.Wrapf(err, "failed to initialize WF GC")
+	shardIndex := 0
+	leaderElectionCfg := cfg.LeaderElection
+	if isShardingEnabled(cfg.Sharding) {
+		shardIndex, err = GetShardIndex(cfg.Sharding)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to determine the shard of the controller")
+		}
+
+		logger.Infof(ctx, "Serving shard [%d/%d], sharded by [%v]", shardIndex, cfg.Sharding.ShardCount, cfg.Sharding.Strategy)
+		// Replicas serving the same shard elect a leader among themselves
+		leaderElectionCfg.LockConfigMap.Name = fmt.Sprintf("%v-shard-%d", leaderElectionCfg.LockConfigMap.Name, shardIndex)
 	}
 
 	eventRecorder, err := newK8sEventRecorder(ctx, kubeclientset, cfg.PublishK8sEvents)
@@ -344,11 +453,10 @@ func New(ctx context.Context, cfg *config.Config, kubeclientset kubernetes.Inter
 	controller := &Controller{
 		metrics:    newControllerMetrics(scope),
 		recorder:   eventRecorder,
-		gc:         gc,
 		numWorkers: cfg.Workers,
 	}
 
-	lock, err := newResourceLock(kubeclientset.CoreV1(), kubeclientset.CoordinationV1(), eventRecorder, cfg.LeaderElection)
+	lock, err := newResourceLock(kubeclientset.CoreV1(), kubeclientset.CoordinationV1(), eventRecorder, leaderElectionCfg)
 	if err != nil {
 		logger.Errorf(ctx, "failed to initialize resource lock.")
 		return nil, errors.Wrapf(err, "failed to initialize resource lock.")
@@ -356,7 +464,7 @@ func New(ctx context.Context, cfg *config.Config, kubeclientset kubernetes.Inter
 
 	if lock != nil {
 		logger.Infof(ctx, "Creating leader elector for the controller.")
-		controller.leaderElector, err = newLeaderElector(lock, cfg.LeaderElection, controller.onStartedLeading, func() {
+		controller.leaderElector, err = newLeaderElector(lock, leaderElectionCfg, controller.onStartedLeading, func() {
 			logger.Fatal(ctx, "Lost leader state. Shutting down.")
 		})
 
@@ -385,23 +493,59 @@ func New(ctx context.Context, cfg *config.Config, kubeclientset kubernetes.Inter
 		return nil, errors.Wrapf(err, "Failed to create Metadata storage")
 	}
 
+	// Completed workflows of all shards are garbage collected, and unlabeled workflows labeled, by the first shard
+	if shardIndex == 0 {
+		var archive *workflowstore.WorkflowArchive
+		if cfg.Archive.Enabled {
+			archive, err = workflowstore.NewWorkflowArchive(ctx, store, cfg.MetadataPrefix, cfg.Archive.Prefix)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to initialize workflow archive")
+			}
+		}
+
+		controller.gc, err = NewGarbageCollector(cfg, scope, clock.RealClock{}, kubeclientset.CoreV1().Namespaces(), flytepropellerClientset.FlyteworkflowV1alpha1(), archive)
+		if err != nil {
+			logger.Errorf(ctx, "failed to initialize GC for workflows")
+			return nil, errors.Wrapf(err, "failed to initialize WF GC")
+		}
+
+		if isShardingEnabled(cfg.Sharding) {
+			controller.shardRelabeler, err = NewShardRelabeler(cfg, scope, clock.RealClock{}, flytepropellerClientset.FlyteworkflowV1alpha1())
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to initialize shard relabeler")
+			}
+		}
+	}
+
 	logger.Info(ctx, "Setting up Catalog client.")
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	if err := catalog.GetConfig().ValidateReservationHeartbeatInterval(cfg.WorkflowReEval.Duration); err != nil {
+		return nil, errors.Wrapf(err, "invalid catalog configuration")
+	}
+
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to create datacatalog client")
 	}
 
-	workQ, err := NewCompositeWorkQueue(ctx, cfg.Queue, scope)
+	workQ, err := NewCompositeWorkQueue(ctx, cfg.Queue, NewWorkflowPriorityClassifier(cfg.Queue.Priority, flyteworkflowInformer.Lister()), scope)
 	if err != nil {
 		return nil, errors.Wrapf(err, "Failed to create WorkQueue [%v]", scope.CurrentScope())
 	}
 	controller.workQueue = workQ
 
-	controller.workflowStore, err = workflowstore.NewWorkflowStore(ctx, workflowstore.GetConfig(), flyteworkflowInformer.Lister(), flytepropellerClientset.FlyteworkflowV1alpha1(), scope)
+	controller.workflowStore, err = workflowstore.NewWorkflowStore(ctx, workflowstore.GetConfig(), flyteworkflowInformer.Lister(), flytepropellerClientset.FlyteworkflowV1alpha1(), store, scope)
 	if err != nil {
 		return nil, stdErrs.Wrapf(errors3.CausedByError, err, "failed to initialize workflow store")
 	}
 
+	if cfg.StuckWorkflows.Enabled {
+		controller.stuckDetector, err = NewStuckWorkflowDetector(cfg.StuckWorkflows, scope, clock.RealClock{}, flyteworkflowInformer.Lister(),
+			controller.workflowStore, func(workflowID v1alpha1.WorkflowID) { controller.workQueue.Add(workflowID) }, controller.recorder)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to initialize stuck workflow detector")
+		}
+	}
+
 	controller.levelMonitor = NewResourceLevelMonitor(scope.NewSubScope("collector"), flyteworkflowInformer.Lister())
 
 	nodeExecutor, err := nodes.NewExecutor(ctx, cfg.NodeConfig, store, controller.enqueueWorkflowForNodeUpdates, eventSink,
@@ -411,7 +555,12 @@ func New(ctx context.Context, cfg *config.Config, kubeclientset kubernetes.Inter
 		return nil, errors.Wrapf(err, "Failed to create Controller.")
 	}
 
-	workflowExecutor, err := workflow.NewExecutor(ctx, store, controller.enqueueWorkflowForNodeUpdates, eventSink, controller.recorder, cfg.MetadataPrefix, nodeExecutor, scope)
+	limiter, err := workflow.NewConcurrencyLimiter(cfg.ConcurrencyLimits, flyteworkflowInformer.Informer(), scope)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to create Controller.")
+	}
+
+	workflowExecutor, err := workflow.NewExecutor(ctx, store, controller.enqueueWorkflowForNodeUpdates, eventSink, controller.recorder, cfg.MetadataPrefix, nodeExecutor, limiter, scope)
 	if err != nil {
 		return nil, err
 	}
@@ -14,7 +14,9 @@ import (
 	"github.com/lyft/flytestdlib/promutils"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
 )
 
 var wfs = []*v1alpha1.FlyteWorkflow{
@@ -66,10 +68,15 @@ func TestResourceLevelMonitor_collect(t *testing.T) {
 	scope := promutils.NewScope("testscope")
 	g := labeled.NewGauge("unittest", "testing", scope)
 	lm := &ResourceLevelMonitor{
-		Scope:          scope,
-		CollectorTimer: scope.MustNewStopWatch("collection_cycle", "Measures how long it takes to run a collection", time.Millisecond),
-		levels:         g,
-		lister:         mockWFLister{},
+		Scope:                 scope,
+		CollectorTimer:        scope.MustNewStopWatch("collection_cycle", "Measures how long it takes to run a collection", time.Millisecond),
+		levels:                g,
+		phaseLevels:           scope.MustNewGaugeVec("unittest_phase", "testing", "project", "domain", "phase"),
+		nodePhaseLevels:       scope.MustNewGaugeVec("unittest_node_phase", "testing", "project", "domain", "phase"),
+		ageHistogram:          newLevelHistogram("unittest_age", "testing", workflowAgeBuckets, "project", "domain", "phase"),
+		sinceUpdatedHistogram: newLevelHistogram("unittest_since_updated", "testing", workflowAgeBuckets, "project", "domain", "phase"),
+		lister:                mockWFLister{},
+		clk:                   clock.NewFakeClock(time.Now()),
 	}
 	lm.collect(context.Background())
 
@@ -83,3 +90,82 @@ func TestResourceLevelMonitor_collect(t *testing.T) {
 	err := testutil.CollectAndCompare(g.GaugeVec, strings.NewReader(expected))
 	assert.NoError(t, err)
 }
+
+func TestResourceLevelMonitor_collectByPhase(t *testing.T) {
+	now := time.Date(2020, time.March, 4, 12, 0, 0, 0, time.UTC)
+	newWorkflow := func(project string, phase v1alpha1.WorkflowPhase, age, sinceUpdated time.Duration,
+		nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus) *v1alpha1.FlyteWorkflow {
+
+		return &v1alpha1.FlyteWorkflow{
+			ObjectMeta: v1.ObjectMeta{CreationTimestamp: v1.NewTime(now.Add(-age))},
+			ExecutionID: v1alpha1.ExecutionID{
+				WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: project, Domain: "dev", Name: "name"},
+			},
+			Status: v1alpha1.WorkflowStatus{
+				Phase:         phase,
+				LastUpdatedAt: &v1.Time{Time: now.Add(-sinceUpdated)},
+				NodeStatus:    nodeStatus,
+			},
+		}
+	}
+
+	workflows := []*v1alpha1.FlyteWorkflow{
+		newWorkflow("proj", v1alpha1.WorkflowPhaseRunning, 2*time.Hour, 90*time.Minute, map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+			"n1": {Phase: v1alpha1.NodePhaseSucceeded},
+			"n2": {Phase: v1alpha1.NodePhaseRunning, SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				"n2-1": {Phase: v1alpha1.NodePhaseQueued},
+				"n2-2": {Phase: v1alpha1.NodePhaseRunning},
+			}},
+			"n3": {Phase: v1alpha1.NodePhaseNotYetStarted},
+		}),
+		newWorkflow("proj", v1alpha1.WorkflowPhaseRunning, 10*time.Minute, time.Minute, nil),
+		newWorkflow("proj", v1alpha1.WorkflowPhaseFailed, 3*time.Hour, 3*time.Hour, map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+			"n1": {Phase: v1alpha1.NodePhaseRunning},
+		}),
+	}
+
+	scope := promutils.NewTestScope()
+	lm := &ResourceLevelMonitor{
+		phaseLevels:           scope.MustNewGaugeVec("phase", "testing", "project", "domain", "phase"),
+		nodePhaseLevels:       scope.MustNewGaugeVec("node_phase", "testing", "project", "domain", "phase"),
+		ageHistogram:          newLevelHistogram("age", "testing", []float64{3600, 4 * 3600}, "project", "domain", "phase"),
+		sinceUpdatedHistogram: newLevelHistogram("since_updated", "testing", []float64{3600}, "project", "domain", "phase"),
+		clk:                   clock.NewFakeClock(now),
+	}
+	lm.collectByPhase(context.Background(), workflows)
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(lm.phaseLevels.WithLabelValues("proj", "dev", "Running")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(lm.phaseLevels.WithLabelValues("proj", "dev", "Failed")))
+	// Only nodes of running workflows are counted
+	assert.Equal(t, float64(2), testutil.ToFloat64(lm.nodePhaseLevels.WithLabelValues("proj", "dev", "Running")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(lm.nodePhaseLevels.WithLabelValues("proj", "dev", "Queued")))
+
+	var expected = `
+		# HELP since_updated testing
+		# TYPE since_updated histogram
+		since_updated_bucket{domain="dev",phase="Failed",project="proj",le="3600"} 0
+		since_updated_bucket{domain="dev",phase="Failed",project="proj",le="+Inf"} 1
+		since_updated_sum{domain="dev",phase="Failed",project="proj"} 10800
+		since_updated_count{domain="dev",phase="Failed",project="proj"} 1
+		since_updated_bucket{domain="dev",phase="Running",project="proj",le="3600"} 1
+		since_updated_bucket{domain="dev",phase="Running",project="proj",le="+Inf"} 2
+		since_updated_sum{domain="dev",phase="Running",project="proj"} 5460
+		since_updated_count{domain="dev",phase="Running",project="proj"} 2
+	`
+	assert.NoError(t, testutil.CollectAndCompare(lm.sinceUpdatedHistogram, strings.NewReader(expected)))
+
+	// Completed workflows are gone from the next snapshot
+	lm.collectByPhase(context.Background(), workflows[:1])
+	assert.Equal(t, float64(0), testutil.ToFloat64(lm.phaseLevels.WithLabelValues("proj", "dev", "Failed")))
+
+	expected = `
+		# HELP age testing
+		# TYPE age histogram
+		age_bucket{domain="dev",phase="Running",project="proj",le="3600"} 0
+		age_bucket{domain="dev",phase="Running",project="proj",le="14400"} 1
+		age_bucket{domain="dev",phase="Running",project="proj",le="+Inf"} 1
+		age_sum{domain="dev",phase="Running",project="proj"} 7200
+		age_count{domain="dev",phase="Running",project="proj"} 1
+	`
+	assert.NoError(t, testutil.CollectAndCompare(lm.ageHistogram, strings.NewReader(expected)))
+}
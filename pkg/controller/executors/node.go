@@ -85,6 +85,8 @@ type Node interface {
 type NodeStatus struct {
 	NodePhase NodePhase
 	Err       *core.ExecutionError
+	// Summary of the nodes that failed, set if NodePhase is NodePhaseFailed or NodePhaseTimedOut
+	Failures []v1alpha1.NodeFailure
 }
 
 func (n *NodeStatus) IsComplete() bool {
@@ -2,18 +2,23 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"runtime/pprof"
+	"sort"
 	"time"
 
 	"github.com/lyft/flytepropeller/pkg/controller/config"
 
 	"strings"
 
+	v1alpha12 "github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/typed/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
 	"github.com/lyft/flytestdlib/contextutils"
 	"github.com/lyft/flytestdlib/logger"
 	"github.com/lyft/flytestdlib/promutils"
 	"github.com/lyft/flytestdlib/promutils/labeled"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/clock"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -23,24 +28,90 @@ type gcMetrics struct {
 	gcRoundSuccess labeled.Counter
 	gcRoundFailure labeled.Counter
 	gcTime         labeled.StopWatch
+	archived       labeled.Counter
+	archiveFailure labeled.Counter
 }
 
+// Number of workflows listed at a time when archiving
+const gcArchiveBatchSize = 100
+
 // Garbage collector is an active background cleanup service, that deletes all workflows that are completed and older
 // than the configured TTL
 type GarbageCollector struct {
 	wfClient        v1alpha1.FlyteworkflowV1alpha1Interface
 	namespaceClient corev1.NamespaceInterface
 	ttlHours        int
+	namespaceTTLs   map[string]int
+	phaseTTLs       map[string]int
 	interval        time.Duration
 	clk             clock.Clock
 	metrics         *gcMetrics
 	namespace       string
+	// If set, completed workflows are archived before they are deleted
+	archive *workflowstore.WorkflowArchive
+}
+
+// Returns the selectors for the completed workflows of a namespace that are outside of their retention period. Workflows
+// that completed in a phase with its own TTL are selected separately from the rest of the namespace.
+func (g *GarbageCollector) selectorsForNamespace(namespace string, now time.Time) []*v1.LabelSelector {
+	phases := make([]string, 0, len(g.phaseTTLs))
+	for phase := range g.phaseTTLs {
+		phases = append(phases, phase)
+	}
+	sort.Strings(phases)
+
+	var selectors []*v1.LabelSelector
+	ttl := g.ttlHours
+	if namespaceTTL, ok := g.namespaceTTLs[namespace]; ok {
+		ttl = namespaceTTL
+	}
+
+	if ttl > 0 {
+		for _, s := range CompletedWorkflowsSelectorsOutsideRetentionPeriod(ttl-1, now) {
+			if len(phases) > 0 {
+				s.MatchExpressions = append(s.MatchExpressions, v1.LabelSelectorRequirement{
+					Key:      phaseCompletedKey,
+					Operator: v1.LabelSelectorOpNotIn,
+					Values:   phases,
+				})
+			}
+			selectors = append(selectors, s)
+		}
+	}
+
+	for _, phase := range phases {
+		if g.phaseTTLs[phase] <= 0 {
+			continue
+		}
+
+		for _, s := range CompletedWorkflowsSelectorsOutsideRetentionPeriod(g.phaseTTLs[phase]-1, now) {
+			s.MatchLabels[phaseCompletedKey] = phase
+			selectors = append(selectors, s)
+		}
+	}
+
+	return selectors
 }
 
-// Issues a background deletion command with label selector for all completed workflows outside of the retention period
+// Deletes the completed workflows of a namespace outside of their retention period.
+func (g *GarbageCollector) deleteExpiredWorkflowsForNamespace(ctx context.Context, namespace string, now time.Time) error {
+	var firstErr error
+	for _, s := range g.selectorsForNamespace(namespace, now) {
+		if err := g.deleteWorkflowsForNamespace(ctx, namespace, s); err != nil {
+			logger.Warnf(ctx, "Failed to delete workflows matching [%s]. Error: [%v]", v1.FormatLabelSelector(s), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return firstErr
+}
+
+// Issues background deletion commands with label selectors for all completed workflows outside of the retention period
 func (g *GarbageCollector) deleteWorkflows(ctx context.Context) error {
 
-	s := CompletedWorkflowsSelectorOutsideRetentionPeriod(g.ttlHours-1, g.clk.Now())
+	now := g.clk.Now()
 
 	// Delete doesn't support 'all' namespaces. Let's fetch namespaces and loop over each.
 	if g.namespace == "" || strings.ToLower(g.namespace) == "all" || strings.ToLower(g.namespace) == "all-namespaces" {
@@ -52,7 +123,7 @@ func (g *GarbageCollector) deleteWorkflows(ctx context.Context) error {
 			namespaceCtx := contextutils.WithNamespace(ctx, n.GetName())
 			logger.Infof(namespaceCtx, "Triggering Workflow delete for namespace: [%s]", n.GetName())
 
-			if err := g.deleteWorkflowsForNamespace(n.GetName(), s); err != nil {
+			if err := g.deleteExpiredWorkflowsForNamespace(namespaceCtx, n.GetName(), now); err != nil {
 				g.metrics.gcRoundFailure.Inc(namespaceCtx)
 				logger.Errorf(namespaceCtx, "Garbage collection failed for for namespace: [%s]. Error : [%v]", n.GetName(), err)
 			} else {
@@ -62,7 +133,7 @@ func (g *GarbageCollector) deleteWorkflows(ctx context.Context) error {
 	} else {
 		namespaceCtx := contextutils.WithNamespace(ctx, g.namespace)
 		logger.Infof(namespaceCtx, "Triggering Workflow delete for namespace: [%s]", g.namespace)
-		if err := g.deleteWorkflowsForNamespace(g.namespace, s); err != nil {
+		if err := g.deleteExpiredWorkflowsForNamespace(namespaceCtx, g.namespace, now); err != nil {
 			g.metrics.gcRoundFailure.Inc(namespaceCtx)
 			logger.Errorf(namespaceCtx, "Garbage collection failed for for namespace: [%s]. Error : [%v]", g.namespace, err)
 		} else {
@@ -72,19 +143,67 @@ func (g *GarbageCollector) deleteWorkflows(ctx context.Context) error {
 	return nil
 }
 
-func (g *GarbageCollector) deleteWorkflowsForNamespace(namespace string, labelSelector *v1.LabelSelector) error {
+func (g *GarbageCollector) deleteWorkflowsForNamespace(ctx context.Context, namespace string, labelSelector *v1.LabelSelector) error {
 	gracePeriodZero := int64(0)
 	propagation := v1.DeletePropagationBackground
+	deleteOptions := &v1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriodZero,
+		PropagationPolicy:  &propagation,
+	}
 
-	return g.wfClient.FlyteWorkflows(namespace).DeleteCollection(
-		&v1.DeleteOptions{
-			GracePeriodSeconds: &gracePeriodZero,
-			PropagationPolicy:  &propagation,
-		},
-		v1.ListOptions{
-			LabelSelector: v1.FormatLabelSelector(labelSelector),
-		},
-	)
+	listOptions := v1.ListOptions{
+		LabelSelector: v1.FormatLabelSelector(labelSelector),
+	}
+
+	if g.archive == nil {
+		return g.wfClient.FlyteWorkflows(namespace).DeleteCollection(deleteOptions, listOptions)
+	}
+
+	return g.archiveAndDeleteWorkflows(ctx, namespace, deleteOptions, listOptions)
+}
+
+// Archives and deletes the selected workflows one by one. Workflows that fail to be archived are not deleted, so that
+// they are retried in the next round. Workflows listed from the API server only reference node statuses that were
+// offloaded to blob storage, the archive reads and inlines them.
+func (g *GarbageCollector) archiveAndDeleteWorkflows(ctx context.Context, namespace string, deleteOptions *v1.DeleteOptions,
+	listOptions v1.ListOptions) error {
+
+	listOptions.Limit = gcArchiveBatchSize
+	failed := 0
+	for {
+		wList, err := g.wfClient.FlyteWorkflows(namespace).List(listOptions)
+		if err != nil {
+			return err
+		}
+
+		for i := range wList.Items {
+			w := &wList.Items[i]
+			ref, err := g.archive.Archive(ctx, w)
+			if err != nil {
+				failed++
+				g.metrics.archiveFailure.Inc(ctx)
+				logger.Errorf(ctx, "Failed to archive workflow [%s], skipping deletion. Error: [%v]", w.GetK8sWorkflowID(), err)
+				continue
+			}
+
+			g.metrics.archived.Inc(ctx)
+			logger.Debugf(ctx, "Archived workflow [%s] to [%s]", w.GetK8sWorkflowID(), ref)
+			if err := g.wfClient.FlyteWorkflows(namespace).Delete(w.GetName(), deleteOptions); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+		}
+
+		if wList.Continue == "" {
+			break
+		}
+		listOptions.Continue = wList.Continue
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to archive [%d] workflows", failed)
+	}
+
+	return nil
 }
 
 // A periodic GC running
@@ -122,24 +241,33 @@ func (g *GarbageCollector) StartGC(ctx context.Context) error {
 	return nil
 }
 
-func NewGarbageCollector(cfg *config.Config, scope promutils.Scope, clk clock.Clock, namespaceClient corev1.NamespaceInterface, wfClient v1alpha1.FlyteworkflowV1alpha1Interface) (*GarbageCollector, error) {
-	ttl := 23
-	if cfg.MaxTTLInHours < 23 {
-		ttl = cfg.MaxTTLInHours
-	} else {
-		logger.Warningf(context.TODO(), "defaulting max ttl for workflows to 23 hours, since configured duration is larger than 23 [%d]", cfg.MaxTTLInHours)
+func NewGarbageCollector(cfg *config.Config, scope promutils.Scope, clk clock.Clock, namespaceClient corev1.NamespaceInterface,
+	wfClient v1alpha1.FlyteworkflowV1alpha1Interface, archive *workflowstore.WorkflowArchive) (*GarbageCollector, error) {
+	for phase := range cfg.Retention.PhaseTTLHours {
+		switch phase {
+		case CompletedPhaseLabelValue(v1alpha12.WorkflowPhaseSuccess), CompletedPhaseLabelValue(v1alpha12.WorkflowPhaseFailed),
+			CompletedPhaseLabelValue(v1alpha12.WorkflowPhaseAborted):
+		default:
+			return nil, fmt.Errorf("unknown phase [%s] in retention config, expected one of succeeded, failed or aborted", phase)
+		}
 	}
+
 	return &GarbageCollector{
 		wfClient:        wfClient,
-		ttlHours:        ttl,
+		ttlHours:        cfg.MaxTTLInHours,
+		namespaceTTLs:   cfg.Retention.NamespaceTTLHours,
+		phaseTTLs:       cfg.Retention.PhaseTTLHours,
 		interval:        cfg.GCInterval.Duration,
 		namespaceClient: namespaceClient,
 		metrics: &gcMetrics{
 			gcTime:         labeled.NewStopWatch("gc_latency", "time taken to issue a delete for TTL'ed workflows", time.Millisecond, scope),
 			gcRoundSuccess: labeled.NewCounter("gc_success", "successful executions of delete request", scope),
 			gcRoundFailure: labeled.NewCounter("gc_failure", "failure to delete workflows", scope),
+			archived:       labeled.NewCounter("gc_archived", "workflows archived before deletion", scope),
+			archiveFailure: labeled.NewCounter("gc_archive_failure", "failure to archive workflows, which are kept until the next round", scope),
 		},
 		clk:       clk,
 		namespace: cfg.LimitNamespace,
+		archive:   archive,
 	}, nil
 }
@@ -6,11 +6,14 @@ import (
 	"testing"
 	"time"
 
+	v1alpha12 "github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	config2 "github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
 
 	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/typed/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytestdlib/config"
 	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
 	"github.com/stretchr/testify/assert"
 	corev1Types "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,7 +28,7 @@ func TestNewGarbageCollector(t *testing.T) {
 			MaxTTLInHours:  2,
 			LimitNamespace: "flyte",
 		}
-		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(time.Now()), nil, nil)
+		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(time.Now()), nil, nil, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, 2, gc.ttlHours)
 	})
@@ -36,9 +39,9 @@ func TestNewGarbageCollector(t *testing.T) {
 			MaxTTLInHours:  24,
 			LimitNamespace: "flyte",
 		}
-		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(time.Now()), nil, nil)
+		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(time.Now()), nil, nil, nil)
 		assert.NoError(t, err)
-		assert.Equal(t, 23, gc.ttlHours)
+		assert.Equal(t, 24, gc.ttlHours)
 	})
 
 	t.Run("ttl0", func(t *testing.T) {
@@ -47,7 +50,7 @@ func TestNewGarbageCollector(t *testing.T) {
 			MaxTTLInHours:  0,
 			LimitNamespace: "flyte",
 		}
-		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), nil, nil, nil)
+		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), nil, nil, nil, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, 0, gc.ttlHours)
 		assert.NoError(t, gc.StartGC(context.TODO()))
@@ -60,22 +63,75 @@ func TestNewGarbageCollector(t *testing.T) {
 			MaxTTLInHours:  -1,
 			LimitNamespace: "flyte",
 		}
-		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), nil, nil, nil)
+		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), nil, nil, nil, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, -1, gc.ttlHours)
 		assert.NoError(t, gc.StartGC(context.TODO()))
 	})
 }
 
+func TestGarbageCollector_selectorsForNamespace(t *testing.T) {
+	n := time.Date(2009, time.November, 10, 12, 0, 0, 0, time.UTC)
+	cfg := &config2.Config{
+		MaxTTLInHours: 24,
+		Retention: config2.RetentionConfig{
+			NamespaceTTLHours: map[string]int{"short": 2, "forever": 0},
+			PhaseTTLHours:     map[string]int{"failed": 24 * 7},
+		},
+	}
+
+	gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(n), nil, nil, nil)
+	assert.NoError(t, err)
+
+	completed := func(phase v1alpha12.WorkflowPhase, age time.Duration) map[string]string {
+		w := &v1alpha12.FlyteWorkflow{Status: v1alpha12.WorkflowStatus{Phase: phase}}
+		SetCompletedLabel(w, n.Add(-age))
+		return w.Labels
+	}
+
+	selectors := gc.selectorsForNamespace("default", n)
+	assert.False(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseSuccess, 20*time.Hour)))
+	assert.True(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseSuccess, 30*time.Hour)))
+	assert.False(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseFailed, 30*time.Hour)))
+	assert.True(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseFailed, 8*24*time.Hour)))
+
+	selectors = gc.selectorsForNamespace("short", n)
+	assert.True(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseSuccess, 3*time.Hour)))
+	assert.False(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseFailed, 3*time.Hour)))
+
+	selectors = gc.selectorsForNamespace("forever", n)
+	assert.False(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseSuccess, 30*24*time.Hour)))
+	assert.True(t, anySelectorMatches(t, selectors, completed(v1alpha12.WorkflowPhaseFailed, 30*24*time.Hour)))
+
+	t.Run("unknown-phase", func(t *testing.T) {
+		cfg := &config2.Config{
+			MaxTTLInHours: 24,
+			Retention:     config2.RetentionConfig{PhaseTTLHours: map[string]int{"running": 1}},
+		}
+		_, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(n), nil, nil, nil)
+		assert.Error(t, err)
+	})
+}
+
 type mockWfClient struct {
 	v1alpha1.FlyteWorkflowInterface
 	DeleteCollectionCb func(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	DeleteCb           func(name string, options *v1.DeleteOptions) error
+	ListCb             func(opts v1.ListOptions) (*v1alpha12.FlyteWorkflowList, error)
 }
 
 func (m *mockWfClient) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
 	return m.DeleteCollectionCb(options, listOptions)
 }
 
+func (m *mockWfClient) Delete(name string, options *v1.DeleteOptions) error {
+	return m.DeleteCb(name, options)
+}
+
+func (m *mockWfClient) List(opts v1.ListOptions) (*v1alpha12.FlyteWorkflowList, error) {
+	return m.ListCb(opts)
+}
+
 type mockClient struct {
 	v1alpha1.FlyteworkflowV1alpha1Client
 	FlyteWorkflowsCb func(namespace string) v1alpha1.FlyteWorkflowInterface
@@ -97,11 +153,15 @@ func (m *mockNamespaceClient) List(opts v1.ListOptions) (*corev1Types.NamespaceL
 func TestGarbageCollector_StartGC(t *testing.T) {
 	wg := sync.WaitGroup{}
 	b := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	lock := sync.Mutex{}
+	var selectors []string
 	mockWfClient := &mockWfClient{
 		DeleteCollectionCb: func(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
 			assert.NotNil(t, options)
 			assert.NotNil(t, listOptions)
-			assert.Equal(t, "hour-of-day in (0,1,10,11,12,13,14,15,16,17,18,19,2,20,21,3,4,5,6,7,8,9),termination-status=terminated", listOptions.LabelSelector)
+			lock.Lock()
+			selectors = append(selectors, listOptions.LabelSelector)
+			lock.Unlock()
 			wg.Done()
 			return nil
 		},
@@ -143,9 +203,10 @@ func TestGarbageCollector_StartGC(t *testing.T) {
 
 		fakeClock := clock.NewFakeClock(b)
 		mockNamespaceInvoked = false
-		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), fakeClock, mockNamespaceClient, mockClient)
+		selectors = nil
+		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), fakeClock, mockNamespaceClient, mockClient, nil)
 		assert.NoError(t, err)
-		wg.Add(1)
+		wg.Add(3)
 		ctx := context.TODO()
 		ctx, cancel := context.WithCancel(ctx)
 		assert.NoError(t, gc.StartGC(ctx))
@@ -153,6 +214,11 @@ func TestGarbageCollector_StartGC(t *testing.T) {
 		wg.Wait()
 		cancel()
 		assert.False(t, mockNamespaceInvoked)
+		assert.Equal(t, []string{
+			"completed-date,completed-date notin (2009-11-10),flyte.lyft.com/retain notin (true),termination-status=terminated",
+			"completed-date=2009-11-10,flyte.lyft.com/retain notin (true),hour-of-day in (0,1,10,11,12,13,14,15,16,17,18,19,2,20,21,3,4,5,6,7,8,9),termination-status=terminated",
+			"!completed-date,flyte.lyft.com/retain notin (true),hour-of-day in (0,1,10,11,12,13,14,15,16,17,18,19,2,20,21,3,4,5,6,7,8,9),termination-status=terminated",
+		}, selectors)
 	})
 
 	t.Run("all-namespace", func(t *testing.T) {
@@ -164,9 +230,9 @@ func TestGarbageCollector_StartGC(t *testing.T) {
 
 		fakeClock := clock.NewFakeClock(b)
 		mockNamespaceInvoked = false
-		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), fakeClock, mockNamespaceClient, mockClient)
+		gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), fakeClock, mockNamespaceClient, mockClient, nil)
 		assert.NoError(t, err)
-		wg.Add(2)
+		wg.Add(6)
 		ctx := context.TODO()
 		ctx, cancel := context.WithCancel(ctx)
 		assert.NoError(t, gc.StartGC(ctx))
@@ -176,3 +242,69 @@ func TestGarbageCollector_StartGC(t *testing.T) {
 		assert.True(t, mockNamespaceInvoked)
 	})
 }
+
+func TestGarbageCollector_ArchiveAndDelete(t *testing.T) {
+	ctx := context.TODO()
+	b := time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
+	cfg := &config2.Config{
+		GCInterval:     config.Duration{Duration: time.Minute * 30},
+		MaxTTLInHours:  2,
+		LimitNamespace: "flyte",
+	}
+
+	newWorkflow := func(name string) v1alpha12.FlyteWorkflow {
+		return v1alpha12.FlyteWorkflow{
+			ObjectMeta: v1.ObjectMeta{Namespace: "flyte", Name: name},
+			Status: v1alpha12.WorkflowStatus{
+				Phase:     v1alpha12.WorkflowPhaseSuccess,
+				StoppedAt: &v1.Time{Time: b},
+			},
+		}
+	}
+
+	dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+	assert.NoError(t, err)
+	archive, err := workflowstore.NewWorkflowArchive(ctx, dataStore, "s3://bucket/metadata", "archive")
+	assert.NoError(t, err)
+
+	var deleted []string
+	var listed []string
+	mockWfClient := &mockWfClient{
+		ListCb: func(opts v1.ListOptions) (*v1alpha12.FlyteWorkflowList, error) {
+			assert.Equal(t, "hour-of-day in (0,1,10,11,12,13,14,15,16,17,18,19,2,20,21,3,4,5,6,7,8,9),termination-status=terminated", opts.LabelSelector)
+			listed = append(listed, opts.Continue)
+			if opts.Continue == "" {
+				return &v1alpha12.FlyteWorkflowList{
+					ListMeta: v1.ListMeta{Continue: "next"},
+					Items:    []v1alpha12.FlyteWorkflow{newWorkflow("wf1")},
+				}, nil
+			}
+			return &v1alpha12.FlyteWorkflowList{Items: []v1alpha12.FlyteWorkflow{newWorkflow("wf2")}}, nil
+		},
+		DeleteCb: func(name string, options *v1.DeleteOptions) error {
+			assert.NotNil(t, options)
+			deleted = append(deleted, name)
+			return nil
+		},
+	}
+
+	mockClient := &mockClient{
+		FlyteWorkflowsCb: func(namespace string) v1alpha1.FlyteWorkflowInterface {
+			return mockWfClient
+		},
+	}
+
+	gc, err := NewGarbageCollector(cfg, promutils.NewTestScope(), clock.NewFakeClock(b), nil, mockClient, archive)
+	assert.NoError(t, err)
+	assert.NoError(t, gc.deleteWorkflowsForNamespace(ctx, "flyte", CompletedWorkflowsSelectorOutsideRetentionPeriod(1, b)))
+	assert.Equal(t, []string{"", "next"}, listed)
+	assert.Equal(t, []string{"wf1", "wf2"}, deleted)
+
+	for _, name := range deleted {
+		ref, err := archive.Reference(ctx, "unknown", "unknown", b, name)
+		assert.NoError(t, err)
+		w, err := archive.Get(ctx, ref)
+		assert.NoError(t, err)
+		assert.Equal(t, name, w.GetName())
+	}
+}
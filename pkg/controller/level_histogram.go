@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Default buckets, in seconds, for the age of workflows. From a minute to a week.
+var workflowAgeBuckets = []float64{60, 300, 900, 1800, 3600, 2 * 3600, 6 * 3600, 12 * 3600, 24 * 3600, 48 * 3600, 7 * 24 * 3600}
+
+// A levelHistogram is a histogram of the current state, rather than a history of observations. Every collection cycle
+// observes all workflows into a new snapshot, which replaces the previously published one. Unlike a prometheus
+// Histogram, the counts thus go down as workflows complete or get updated.
+type levelHistogram struct {
+	desc    *prometheus.Desc
+	buckets []float64
+	lock    sync.RWMutex
+	current map[string]*levelHistogramSeries
+}
+
+type levelHistogramSeries struct {
+	labelValues []string
+	count       uint64
+	sum         float64
+	// Cumulative counts, per upper bound
+	buckets map[float64]uint64
+}
+
+// Accumulates the observations of a collection cycle, published all at once by calling Publish.
+type levelHistogramSnapshot struct {
+	h      *levelHistogram
+	series map[string]*levelHistogramSeries
+}
+
+func (s *levelHistogramSnapshot) Observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "/")
+	series, ok := s.series[key]
+	if !ok {
+		series = &levelHistogramSeries{
+			labelValues: labelValues,
+			buckets:     make(map[float64]uint64, len(s.h.buckets)),
+		}
+		for _, b := range s.h.buckets {
+			series.buckets[b] = 0
+		}
+		s.series[key] = series
+	}
+
+	series.count++
+	series.sum += v
+	for _, b := range s.h.buckets {
+		if v <= b {
+			series.buckets[b]++
+		}
+	}
+}
+
+// Replaces the published state with this snapshot.
+func (s *levelHistogramSnapshot) Publish() {
+	s.h.lock.Lock()
+	defer s.h.lock.Unlock()
+	s.h.current = s.series
+}
+
+func (h *levelHistogram) NewSnapshot() *levelHistogramSnapshot {
+	return &levelHistogramSnapshot{
+		h:      h,
+		series: map[string]*levelHistogramSeries{},
+	}
+}
+
+func (h *levelHistogram) Describe(ch chan<- *prometheus.Desc) {
+	ch <- h.desc
+}
+
+func (h *levelHistogram) Collect(ch chan<- prometheus.Metric) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	keys := make([]string, 0, len(h.current))
+	for k := range h.current {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := h.current[k]
+		ch <- prometheus.MustNewConstHistogram(h.desc, s.count, s.sum, s.buckets, s.labelValues...)
+	}
+}
+
+func newLevelHistogram(name, description string, buckets []float64, labelNames ...string) *levelHistogram {
+	return &levelHistogram{
+		desc:    prometheus.NewDesc(name, description, labelNames, nil),
+		buckets: buckets,
+		current: map[string]*levelHistogramSeries{},
+	}
+}
+
+// Creates a levelHistogram and registers it with the default registry, the same way promutils registers metrics.
+func mustNewLevelHistogram(name, description string, buckets []float64, labelNames ...string) *levelHistogram {
+	h := newLevelHistogram(name, description, buckets, labelNames...)
+	prometheus.MustRegister(h)
+	return h
+}
@@ -2,9 +2,13 @@ package branch
 
 import (
 	"reflect"
+	"regexp"
+	"strings"
 
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
 	"github.com/lyft/flytestdlib/errors"
+
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
 )
 
 type comparator func(lValue *core.Primitive, rValue *core.Primitive) bool
@@ -14,6 +18,7 @@ type comparators struct {
 }
 
 var primitiveBooleanType = reflect.TypeOf(&core.Primitive_Boolean{}).String()
+var primitiveStringType = reflect.TypeOf(&core.Primitive_StringValue{}).String()
 
 var perTypeComparators = map[string]comparators{
 	reflect.TypeOf(&core.Primitive_FloatValue{}).String(): {
@@ -55,28 +60,57 @@ var perTypeComparators = map[string]comparators{
 	},
 	reflect.TypeOf(&core.Primitive_Datetime{}).String(): {
 		gt: func(lValue *core.Primitive, rValue *core.Primitive) bool {
-			return lValue.GetDatetime().GetSeconds() > rValue.GetDatetime().GetSeconds()
+			l, r := lValue.GetDatetime(), rValue.GetDatetime()
+			return l.GetSeconds() > r.GetSeconds() || (l.GetSeconds() == r.GetSeconds() && l.GetNanos() > r.GetNanos())
 		},
 		eq: func(lValue *core.Primitive, rValue *core.Primitive) bool {
-			return lValue.GetDatetime().GetSeconds() == rValue.GetDatetime().GetSeconds()
+			l, r := lValue.GetDatetime(), rValue.GetDatetime()
+			return l.GetSeconds() == r.GetSeconds() && l.GetNanos() == r.GetNanos()
 		},
 	},
 	reflect.TypeOf(&core.Primitive_Duration{}).String(): {
 		gt: func(lValue *core.Primitive, rValue *core.Primitive) bool {
-			return lValue.GetDuration().GetSeconds() > rValue.GetDuration().GetSeconds()
+			l, r := lValue.GetDuration(), rValue.GetDuration()
+			return l.GetSeconds() > r.GetSeconds() || (l.GetSeconds() == r.GetSeconds() && l.GetNanos() > r.GetNanos())
 		},
 		eq: func(lValue *core.Primitive, rValue *core.Primitive) bool {
-			return lValue.GetDuration().GetSeconds() == rValue.GetDuration().GetSeconds()
+			l, r := lValue.GetDuration(), rValue.GetDuration()
+			return l.GetSeconds() == r.GetSeconds() && l.GetNanos() == r.GetNanos()
 		},
 	},
 }
 
+// Evaluates the string operators, that are only defined for string operands.
+func evaluateStringOperator(lValue *core.Primitive, rValue *core.Primitive, op core.ComparisonExpression_Operator) (bool, error) {
+	if reflect.TypeOf(lValue.Value).String() != primitiveStringType {
+		return false, errors.Errorf(ErrorCodeMalformedBranch, "[%v] is only defined for string operands.", common.ComparisonOperator(op))
+	}
+
+	switch common.ComparisonOperator(op) {
+	case common.ComparisonOperatorContains:
+		return strings.Contains(lValue.GetStringValue(), rValue.GetStringValue()), nil
+	case common.ComparisonOperatorStartsWith:
+		return strings.HasPrefix(lValue.GetStringValue(), rValue.GetStringValue()), nil
+	case common.ComparisonOperatorMatches:
+		r, err := regexp.Compile(rValue.GetStringValue())
+		if err != nil {
+			return false, errors.Wrapf(ErrorCodeMalformedBranch, err, "Invalid regular expression [%v].", rValue.GetStringValue())
+		}
+		return r.MatchString(lValue.GetStringValue()), nil
+	}
+	return false, errors.Errorf(ErrorCodeMalformedBranch, "Unsupported operator type in Propeller. System error.")
+}
+
 func Evaluate(lValue *core.Primitive, rValue *core.Primitive, op core.ComparisonExpression_Operator) (bool, error) {
 	lValueType := reflect.TypeOf(lValue.Value)
 	rValueType := reflect.TypeOf(rValue.Value)
 	if lValueType != rValueType {
 		return false, errors.Errorf(ErrorCodeMalformedBranch, "Comparison between different primitives types. lVal[%v]:rVal[%v]", lValueType, rValueType)
 	}
+	switch common.ComparisonOperator(op) {
+	case common.ComparisonOperatorContains, common.ComparisonOperatorStartsWith, common.ComparisonOperatorMatches:
+		return evaluateStringOperator(lValue, rValue, op)
+	}
 	comps, ok := perTypeComparators[lValueType.String()]
 	if !ok {
 		return false, errors.Errorf("Comparator not defined for type: [%v]", lValueType.String())
@@ -114,26 +148,81 @@ func Evaluate(lValue *core.Primitive, rValue *core.Primitive, op core.Comparison
 	return false, errors.Errorf(ErrorCodeMalformedBranch, "Unsupported operator type in Propeller. System error.")
 }
 
-func Evaluate1(lValue *core.Primitive, rValue *core.Literal, op core.ComparisonExpression_Operator) (bool, error) {
-	if rValue.GetScalar() == nil || rValue.GetScalar().GetPrimitive() == nil {
-		return false, errors.Errorf(ErrorCodeMalformedBranch, "Only primitives can be compared. RHS Variable is non primitive.")
+func primitiveLiteral(p *core.Primitive) *core.Literal {
+	return &core.Literal{
+		Value: &core.Literal_Scalar{
+			Scalar: &core.Scalar{
+				Value: &core.Scalar_Primitive{Primitive: p},
+			},
+		},
 	}
-	return Evaluate(lValue, rValue.GetScalar().GetPrimitive(), op)
 }
 
-func Evaluate2(lValue *core.Literal, rValue *core.Primitive, op core.ComparisonExpression_Operator) (bool, error) {
-	if lValue.GetScalar() == nil || lValue.GetScalar().GetPrimitive() == nil {
-		return false, errors.Errorf(ErrorCodeMalformedBranch, "Only primitives can be compared. LHS Variable is non primitive.")
+func isNull(l *core.Literal) bool {
+	return l == nil || l.GetScalar().GetNoneType() != nil
+}
+
+// Collections and maps are compared by their length using the regular comparison operators, CONTAINS checks for an
+// element of a collection or a key of a map.
+func evaluateContainer(lValue *core.Literal, rValue *core.Primitive, op core.ComparisonExpression_Operator) (bool, error) {
+	if common.ComparisonOperator(op) == common.ComparisonOperatorContains {
+		if m := lValue.GetMap(); m != nil {
+			if _, ok := rValue.GetValue().(*core.Primitive_StringValue); !ok {
+				return false, errors.Errorf(ErrorCodeMalformedBranch, "Keys of a map can only be compared to strings.")
+			}
+			_, ok := m.GetLiterals()[rValue.GetStringValue()]
+			return ok, nil
+		}
+
+		for _, l := range lValue.GetCollection().GetLiterals() {
+			p := l.GetScalar().GetPrimitive()
+			if p == nil || reflect.TypeOf(p.Value) != reflect.TypeOf(rValue.Value) {
+				continue
+			}
+			if eq, err := Evaluate(p, rValue, core.ComparisonExpression_EQ); err != nil {
+				return false, err
+			} else if eq {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if !common.ComparisonOperator(op).IsCore() {
+		return false, errors.Errorf(ErrorCodeMalformedBranch, "[%v] not defined for collections and maps.", common.ComparisonOperator(op))
+	}
+
+	if _, ok := rValue.GetValue().(*core.Primitive_Integer); !ok {
+		return false, errors.Errorf(ErrorCodeMalformedBranch, "The length of collections and maps can only be compared to integers.")
 	}
-	return Evaluate(lValue.GetScalar().GetPrimitive(), rValue, op)
+
+	length := len(lValue.GetCollection().GetLiterals())
+	if lValue.GetMap() != nil {
+		length = len(lValue.GetMap().GetLiterals())
+	}
+	return Evaluate(&core.Primitive{Value: &core.Primitive_Integer{Integer: int64(length)}}, rValue, op)
+}
+
+func Evaluate1(lValue *core.Primitive, rValue *core.Literal, op core.ComparisonExpression_Operator) (bool, error) {
+	return EvaluateLiterals(primitiveLiteral(lValue), rValue, op)
+}
+
+func Evaluate2(lValue *core.Literal, rValue *core.Primitive, op core.ComparisonExpression_Operator) (bool, error) {
+	return EvaluateLiterals(lValue, primitiveLiteral(rValue), op)
 }
 
 func EvaluateLiterals(lValue *core.Literal, rValue *core.Literal, op core.ComparisonExpression_Operator) (bool, error) {
-	if lValue.GetScalar() == nil || lValue.GetScalar().GetPrimitive() == nil {
-		return false, errors.Errorf(ErrorCodeMalformedBranch, "Only primitives can be compared. LHS Variable is non primitive.")
+	if common.ComparisonOperator(op).IsNullCheck() {
+		return isNull(lValue) == (common.ComparisonOperator(op) == common.ComparisonOperatorIsNull), nil
 	}
 	if rValue.GetScalar() == nil || rValue.GetScalar().GetPrimitive() == nil {
 		return false, errors.Errorf(ErrorCodeMalformedBranch, "Only primitives can be compared. RHS Variable is non primitive")
 	}
+	if lValue.GetCollection() != nil || lValue.GetMap() != nil {
+		return evaluateContainer(lValue, rValue.GetScalar().GetPrimitive(), op)
+	}
+	if lValue.GetScalar() == nil || lValue.GetScalar().GetPrimitive() == nil {
+		return false, errors.Errorf(ErrorCodeMalformedBranch, "Only primitives, collections and maps can be compared. LHS Variable is non primitive.")
+	}
 	return Evaluate(lValue.GetScalar().GetPrimitive(), rValue.GetScalar().GetPrimitive(), op)
 }
@@ -5,7 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/ptypes/duration"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	"github.com/lyft/flytepropeller/pkg/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -401,3 +404,116 @@ func TestEvaluate_boolean(t *testing.T) {
 		assert.False(t, b)
 	}
 }
+
+func TestEvaluate_timePrecision(t *testing.T) {
+	t1 := &core.Primitive{Value: &core.Primitive_Datetime{Datetime: &timestamp.Timestamp{Seconds: 10, Nanos: 1}}}
+	t2 := &core.Primitive{Value: &core.Primitive_Datetime{Datetime: &timestamp.Timestamp{Seconds: 10, Nanos: 2}}}
+	b, err := Evaluate(t1, t2, core.ComparisonExpression_LT)
+	assert.NoError(t, err)
+	assert.True(t, b)
+	b, err = Evaluate(t1, t2, core.ComparisonExpression_EQ)
+	assert.NoError(t, err)
+	assert.False(t, b)
+
+	d1 := &core.Primitive{Value: &core.Primitive_Duration{Duration: &duration.Duration{Seconds: 1, Nanos: 500}}}
+	d2 := &core.Primitive{Value: &core.Primitive_Duration{Duration: &duration.Duration{Seconds: 1}}}
+	b, err = Evaluate(d1, d2, core.ComparisonExpression_GT)
+	assert.NoError(t, err)
+	assert.True(t, b)
+	b, err = Evaluate(d1, d1, core.ComparisonExpression_EQ)
+	assert.NoError(t, err)
+	assert.True(t, b)
+}
+
+func TestEvaluate_stringOperators(t *testing.T) {
+	s := utils.MustMakePrimitive("release-1.2.3")
+	{
+		b, err := Evaluate(s, utils.MustMakePrimitive("1.2"), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = Evaluate(s, utils.MustMakePrimitive("2.0"), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+	}
+	{
+		b, err := Evaluate(s, utils.MustMakePrimitive("release-"), common.ComparisonOperatorStartsWith.ToCore())
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = Evaluate(s, utils.MustMakePrimitive("1.2"), common.ComparisonOperatorStartsWith.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+	}
+	{
+		b, err := Evaluate(s, utils.MustMakePrimitive(`^release-\d+\.\d+\.\d+$`), common.ComparisonOperatorMatches.ToCore())
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = Evaluate(s, utils.MustMakePrimitive(`^v\d+`), common.ComparisonOperatorMatches.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+		_, err = Evaluate(s, utils.MustMakePrimitive(`(`), common.ComparisonOperatorMatches.ToCore())
+		assert.Error(t, err)
+	}
+	{
+		_, err := Evaluate(utils.MustMakePrimitive(1), utils.MustMakePrimitive(1), common.ComparisonOperatorContains.ToCore())
+		assert.Error(t, err)
+		_, err = Evaluate(s, utils.MustMakePrimitive(1), common.ComparisonOperatorStartsWith.ToCore())
+		assert.Error(t, err)
+	}
+}
+
+func TestEvaluateLiterals_containers(t *testing.T) {
+	collection := utils.MustMakeLiteral([]interface{}{1, 2, 3})
+	m := utils.MustMakeLiteral(map[string]interface{}{"a": 1})
+	{
+		b, err := EvaluateLiterals(collection, utils.MustMakePrimitiveLiteral(3), core.ComparisonExpression_EQ)
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = EvaluateLiterals(collection, utils.MustMakePrimitiveLiteral(3), core.ComparisonExpression_GT)
+		assert.NoError(t, err)
+		assert.False(t, b)
+		b, err = EvaluateLiterals(m, utils.MustMakePrimitiveLiteral(0), core.ComparisonExpression_GT)
+		assert.NoError(t, err)
+		assert.True(t, b)
+		_, err = EvaluateLiterals(collection, utils.MustMakePrimitiveLiteral("3"), core.ComparisonExpression_EQ)
+		assert.Error(t, err)
+	}
+	{
+		b, err := EvaluateLiterals(collection, utils.MustMakePrimitiveLiteral(2), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = EvaluateLiterals(collection, utils.MustMakePrimitiveLiteral(4), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+		b, err = EvaluateLiterals(collection, utils.MustMakePrimitiveLiteral("2"), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+	}
+	{
+		b, err := EvaluateLiterals(m, utils.MustMakePrimitiveLiteral("a"), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = EvaluateLiterals(m, utils.MustMakePrimitiveLiteral("b"), common.ComparisonOperatorContains.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+		_, err = EvaluateLiterals(m, utils.MustMakePrimitiveLiteral(1), common.ComparisonOperatorContains.ToCore())
+		assert.Error(t, err)
+		_, err = EvaluateLiterals(m, utils.MustMakePrimitiveLiteral("a"), common.ComparisonOperatorStartsWith.ToCore())
+		assert.Error(t, err)
+	}
+}
+
+func TestEvaluateLiterals_null(t *testing.T) {
+	void := &core.Literal{Value: &core.Literal_Scalar{Scalar: &core.Scalar{Value: &core.Scalar_NoneType{NoneType: &core.Void{}}}}}
+	for _, l := range []*core.Literal{void, nil} {
+		b, err := EvaluateLiterals(l, nil, common.ComparisonOperatorIsNull.ToCore())
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = EvaluateLiterals(l, nil, common.ComparisonOperatorIsNotNull.ToCore())
+		assert.NoError(t, err)
+		assert.False(t, b)
+	}
+
+	b, err := EvaluateLiterals(utils.MustMakePrimitiveLiteral(1), nil, common.ComparisonOperatorIsNull.ToCore())
+	assert.NoError(t, err)
+	assert.False(t, b)
+}
@@ -9,6 +9,7 @@ import (
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	"github.com/lyft/flytepropeller/pkg/controller/executors"
 )
 
@@ -22,6 +23,14 @@ func EvaluateComparison(expr *core.ComparisonExpression, nodeInputs *core.Litera
 	var lPrim *core.Primitive
 	var rPrim *core.Primitive
 
+	if common.GetComparisonOperator(expr).IsNullCheck() {
+		if p := expr.GetLeftValue().GetPrimitive(); p != nil {
+			return EvaluateLiterals(primitiveLiteral(p), nil, expr.GetOperator())
+		}
+		// Variables without a value are treated as null
+		return EvaluateLiterals(nodeInputs.GetLiterals()[expr.GetLeftValue().GetVar()], nil, expr.GetOperator())
+	}
+
 	if expr.GetLeftValue().GetPrimitive() == nil {
 		if nodeInputs == nil {
 			return false, errors.Errorf(ErrorCodeMalformedBranch, "Failed to find Value for Variable [%v]", expr.GetLeftValue().GetVar())
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
 	"github.com/lyft/flytepropeller/pkg/utils"
 )
 
@@ -194,6 +195,38 @@ func TestEvaluateComparison(t *testing.T) {
 
 }
 
+func TestEvaluateComparison_nullChecks(t *testing.T) {
+	exp := &core.ComparisonExpression{
+		LeftValue: &core.Operand{
+			Val: &core.Operand_Var{
+				Var: "x",
+			},
+		},
+		Operator: common.ComparisonOperatorIsNull.ToCore(),
+	}
+
+	t.Run("NotSet", func(t *testing.T) {
+		v, err := EvaluateComparison(exp, &core.LiteralMap{})
+		assert.NoError(t, err)
+		assert.True(t, v)
+
+		v, err = EvaluateComparison(exp, nil)
+		assert.NoError(t, err)
+		assert.True(t, v)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		inputs := &core.LiteralMap{
+			Literals: map[string]*core.Literal{
+				"x": utils.MustMakePrimitiveLiteral(1),
+			},
+		}
+		v, err := EvaluateComparison(exp, inputs)
+		assert.NoError(t, err)
+		assert.False(t, v)
+	})
+}
+
 func TestEvaluateBooleanExpression(t *testing.T) {
 	{
 		// Simple comparison only
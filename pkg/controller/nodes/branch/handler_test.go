@@ -42,6 +42,10 @@ func (t branchNodeStateHolder) PutWorkflowNodeState(s handler.WorkflowNodeState)
 	panic("not implemented")
 }
 
+func (t branchNodeStateHolder) PutMapNodeState(s handler.MapNodeState) error {
+	panic("not implemented")
+}
+
 func (t branchNodeStateHolder) PutDynamicNodeState(s handler.DynamicNodeState) error {
 	panic("not implemented")
 }
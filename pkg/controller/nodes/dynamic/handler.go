@@ -33,7 +33,7 @@ const dynamicNodeID = "dynamic-node"
 type TaskNodeHandler interface {
 	handler.Node
 	ValidateOutputAndCacheAdd(ctx context.Context, nodeID v1alpha1.NodeID, i io.InputReader, r io.OutputReader, outputCommitter io.OutputWriter,
-		tr pluginCore.TaskReader, m catalog.Metadata) (*io.ExecutionError, error)
+		tr pluginCore.TaskReader, m catalog.Metadata, cacheOverride v1alpha1.CacheOverride) (*io.ExecutionError, error)
 }
 
 type metrics struct {
@@ -117,7 +117,7 @@ func (d dynamicNodeTaskNodeHandler) handleDynamicSubNodes(ctx context.Context, n
 		outputReader := ioutils.NewRemoteFileOutputReader(ctx, nCtx.DataStore(), outputPaths, nCtx.MaxDatasetSizeBytes())
 		ee, err := d.TaskNodeHandler.ValidateOutputAndCacheAdd(ctx, nCtx.NodeID(), nCtx.InputReader(), outputReader, nil, nCtx.TaskReader(), catalog.Metadata{
 			TaskExecutionIdentifier: execID,
-		})
+		}, v1alpha1.GetCacheOverride(nCtx.NodeExecutionMetadata().GetAnnotations(), nCtx.NodeID()))
 
 		if err != nil {
 			return handler.UnknownTransition, prevState, err
@@ -47,6 +47,10 @@ func (t dynamicNodeStateHolder) PutWorkflowNodeState(s handler.WorkflowNodeState
 	panic("not implemented")
 }
 
+func (t dynamicNodeStateHolder) PutMapNodeState(s handler.MapNodeState) error {
+	panic("not implemented")
+}
+
 func (t *dynamicNodeStateHolder) PutDynamicNodeState(s handler.DynamicNodeState) error {
 	t.s = s
 	return nil
@@ -542,9 +546,9 @@ func Test_dynamicNodeHandler_Handle_SubTask(t *testing.T) {
 			mockLPLauncher := &lpMocks.Reader{}
 			h := &mocks.TaskNodeHandler{}
 			if tt.args.validErr != nil {
-				h.OnValidateOutputAndCacheAddMatch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.args.validErr, nil)
+				h.OnValidateOutputAndCacheAddMatch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tt.args.validErr, nil)
 			} else {
-				h.OnValidateOutputAndCacheAddMatch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+				h.OnValidateOutputAndCacheAddMatch(mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
 			}
 			n := &executorMocks.Node{}
 			if tt.args.isErr {
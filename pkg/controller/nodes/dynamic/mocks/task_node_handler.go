@@ -14,6 +14,8 @@ import (
 	io "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/io"
 
 	mock "github.com/stretchr/testify/mock"
+
+	v1alpha1 "github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 )
 
 // TaskNodeHandler is an autogenerated mock type for the TaskNodeHandler type
@@ -196,8 +198,8 @@ func (_m TaskNodeHandler_ValidateOutputAndCacheAdd) Return(_a0 *io.ExecutionErro
 	return &TaskNodeHandler_ValidateOutputAndCacheAdd{Call: _m.Call.Return(_a0, _a1)}
 }
 
-func (_m *TaskNodeHandler) OnValidateOutputAndCacheAdd(ctx context.Context, nodeID string, i io.InputReader, r io.OutputReader, outputCommitter io.OutputWriter, tr core.TaskReader, m catalog.Metadata) *TaskNodeHandler_ValidateOutputAndCacheAdd {
-	c := _m.On("ValidateOutputAndCacheAdd", ctx, nodeID, i, r, outputCommitter, tr, m)
+func (_m *TaskNodeHandler) OnValidateOutputAndCacheAdd(ctx context.Context, nodeID string, i io.InputReader, r io.OutputReader, outputCommitter io.OutputWriter, tr core.TaskReader, m catalog.Metadata, cacheOverride v1alpha1.CacheOverride) *TaskNodeHandler_ValidateOutputAndCacheAdd {
+	c := _m.On("ValidateOutputAndCacheAdd", ctx, nodeID, i, r, outputCommitter, tr, m, cacheOverride)
 	return &TaskNodeHandler_ValidateOutputAndCacheAdd{Call: c}
 }
 
@@ -206,13 +208,13 @@ func (_m *TaskNodeHandler) OnValidateOutputAndCacheAddMatch(matchers ...interfac
 	return &TaskNodeHandler_ValidateOutputAndCacheAdd{Call: c}
 }
 
-// ValidateOutputAndCacheAdd provides a mock function with given fields: ctx, nodeID, i, r, outputCommitter, tr, m
-func (_m *TaskNodeHandler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID string, i io.InputReader, r io.OutputReader, outputCommitter io.OutputWriter, tr core.TaskReader, m catalog.Metadata) (*io.ExecutionError, error) {
-	ret := _m.Called(ctx, nodeID, i, r, outputCommitter, tr, m)
+// ValidateOutputAndCacheAdd provides a mock function with given fields: ctx, nodeID, i, r, outputCommitter, tr, m, cacheOverride
+func (_m *TaskNodeHandler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID string, i io.InputReader, r io.OutputReader, outputCommitter io.OutputWriter, tr core.TaskReader, m catalog.Metadata, cacheOverride v1alpha1.CacheOverride) (*io.ExecutionError, error) {
+	ret := _m.Called(ctx, nodeID, i, r, outputCommitter, tr, m, cacheOverride)
 
 	var r0 *io.ExecutionError
-	if rf, ok := ret.Get(0).(func(context.Context, string, io.InputReader, io.OutputReader, io.OutputWriter, core.TaskReader, catalog.Metadata) *io.ExecutionError); ok {
-		r0 = rf(ctx, nodeID, i, r, outputCommitter, tr, m)
+	if rf, ok := ret.Get(0).(func(context.Context, string, io.InputReader, io.OutputReader, io.OutputWriter, core.TaskReader, catalog.Metadata, v1alpha1.CacheOverride) *io.ExecutionError); ok {
+		r0 = rf(ctx, nodeID, i, r, outputCommitter, tr, m, cacheOverride)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*io.ExecutionError)
@@ -220,8 +222,8 @@ func (_m *TaskNodeHandler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, string, io.InputReader, io.OutputReader, io.OutputWriter, core.TaskReader, catalog.Metadata) error); ok {
-		r1 = rf(ctx, nodeID, i, r, outputCommitter, tr, m)
+	if rf, ok := ret.Get(1).(func(context.Context, string, io.InputReader, io.OutputReader, io.OutputWriter, core.TaskReader, catalog.Metadata, v1alpha1.CacheOverride) error); ok {
+		r1 = rf(ctx, nodeID, i, r, outputCommitter, tr, m, cacheOverride)
 	} else {
 		r1 = ret.Error(1)
 	}
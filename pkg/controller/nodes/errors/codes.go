@@ -24,4 +24,9 @@ const (
 	StorageError                       ErrorCode = "StorageError"
 	EventRecordingFailed               ErrorCode = "EventRecordingFailed"
 	CatalogCallFailed                  ErrorCode = "CatalogCallFailed"
+	InvalidMapInputsError              ErrorCode = "InvalidMapInputs"
+	MapItemsFailedError                ErrorCode = "MapItemsFailed"
+	GateTimedOutError                  ErrorCode = "GateTimedOut"
+	InvalidSignalError                 ErrorCode = "InvalidSignal"
+	MultipleNodesFailedError           ErrorCode = "MultipleNodesFailed"
 )
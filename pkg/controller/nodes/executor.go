@@ -3,6 +3,9 @@ package nodes
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/ioutils"
@@ -23,6 +26,7 @@ import (
 	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/lyft/flytepropeller/pkg/controller/config"
 
@@ -46,6 +50,7 @@ type nodeMetrics struct {
 	ResolutionFailure             labeled.Counter
 	InputsWriteFailure            labeled.Counter
 	TimedOutFailure               labeled.Counter
+	RetryDelayed                  labeled.Counter
 
 	InterruptedThresholdHit labeled.Counter
 
@@ -73,6 +78,9 @@ type nodeExecutor struct {
 	interruptibleFailureThreshold   uint32
 	defaultDataSandbox              storage.DataReference
 	shardSelector                   ioutils.ShardSelector
+	// Re-enqueues of the workflows of nodes waiting for their retry delay, keyed by node execution id
+	retryTimers     map[string]*time.Timer
+	retryTimersLock sync.Mutex
 }
 
 func (c *nodeExecutor) RecordTransitionLatency(ctx context.Context, dag executors.DAGStructure, nl executors.NodeLookup, node v1alpha1.ExecutableNode, nodeStatus v1alpha1.ExecutableNodeStatus) {
@@ -204,6 +212,37 @@ func (c *nodeExecutor) isEligibleForRetry(nCtx *nodeExecContext, nodeStatus v1al
 	return
 }
 
+// Computes the duration to wait before the next attempt of a node is started. attempts is the number of attempts that
+// have already been made (starting at 0).
+func computeRetryDelay(strategy *v1alpha1.RetryStrategy, attempts uint32) time.Duration {
+	delay := strategy.GetRetryDelay()
+	if delay <= 0 {
+		return 0
+	}
+
+	maxDelay := strategy.GetMaxRetryDelay()
+	switch strategy.GetBackoffPolicy() {
+	case v1alpha1.RetryBackoffPolicyExponential, v1alpha1.RetryBackoffPolicyJittered:
+		for i := uint32(0); i < attempts; i++ {
+			// Stop doubling once we are past the cap (or about to overflow)
+			if (maxDelay > 0 && delay >= maxDelay) || delay > math.MaxInt64/2 {
+				break
+			}
+			delay *= 2
+		}
+	}
+
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if strategy.GetBackoffPolicy() == v1alpha1.RetryBackoffPolicyJittered {
+		delay = time.Duration(rand.Int63n(int64(delay) + 1))
+	}
+
+	return delay
+}
+
 func (c *nodeExecutor) execute(ctx context.Context, h handler.Node, nCtx *nodeExecContext, nodeStatus v1alpha1.ExecutableNodeStatus) (handler.PhaseInfo, error) {
 	logger.Debugf(ctx, "Executing node")
 	defer logger.Debugf(ctx, "Node execution round complete")
@@ -257,6 +296,7 @@ func (c *nodeExecutor) execute(ctx context.Context, h handler.Node, nCtx *nodeEx
 
 func (c *nodeExecutor) abort(ctx context.Context, h handler.Node, nCtx handler.NodeExecutionContext, reason string) error {
 	logger.Debugf(ctx, "Calling aborting & finalize")
+	c.cancelRetry(nCtx)
 	if err := h.Abort(ctx, nCtx, reason); err != nil {
 		finalizeErr := h.Finalize(ctx, nCtx)
 		if finalizeErr != nil {
@@ -425,13 +465,68 @@ func (c *nodeExecutor) handleQueuedOrRunningNode(ctx context.Context, nCtx *node
 	return finalStatus, nil
 }
 
+// Makes sure we come back to the workflow as soon as the retry delay of the node expires, instead of waiting for the
+// next periodic re-evaluation.
+func (c *nodeExecutor) scheduleRetry(ctx context.Context, nCtx handler.NodeExecutionContext, delay time.Duration) {
+	key := nCtx.NodeExecutionMetadata().GetNodeExecutionID().String()
+	enqueueOwner := nCtx.EnqueueOwnerFunc()
+	c.retryTimersLock.Lock()
+	defer c.retryTimersLock.Unlock()
+	if t, ok := c.retryTimers[key]; ok {
+		t.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		c.retryTimersLock.Lock()
+		if c.retryTimers[key] == timer {
+			delete(c.retryTimers, key)
+		}
+		c.retryTimersLock.Unlock()
+
+		if err := enqueueOwner(); err != nil {
+			logger.Warnf(ctx, "Failed to re-enqueue workflow after retry delay. Error [%v]", err)
+		}
+	})
+	c.retryTimers[key] = timer
+}
+
+// Stops the re-enqueue scheduled for the retry of the node, if any.
+func (c *nodeExecutor) cancelRetry(nCtx handler.NodeExecutionContext) {
+	key := nCtx.NodeExecutionMetadata().GetNodeExecutionID().String()
+	c.retryTimersLock.Lock()
+	defer c.retryTimersLock.Unlock()
+	if t, ok := c.retryTimers[key]; ok {
+		t.Stop()
+		delete(c.retryTimers, key)
+	}
+}
+
 func (c *nodeExecutor) handleRetryableFailure(ctx context.Context, nCtx *nodeExecContext, h handler.Node) (executors.NodeStatus, error) {
 	nodeStatus := nCtx.NodeStatus()
-	logger.Debugf(ctx, "node failed with retryable failure, aborting and finalizing, message: %s", nodeStatus.GetMessage())
-	if err := c.abort(ctx, h, nCtx, nodeStatus.GetMessage()); err != nil {
-		return executors.NodeStatusUndefined, err
+	if nextAttemptAt := nodeStatus.GetNextAttemptAt(); nextAttemptAt != nil {
+		// The previous attempt has already been aborted, we are only waiting for the retry delay to expire
+		if time.Now().Before(nextAttemptAt.Time) {
+			logger.Debugf(ctx, "node waiting for retry delay to expire, next attempt at [%v]", nextAttemptAt.Time)
+			return executors.NodeStatusPending, nil
+		}
+	} else {
+		logger.Debugf(ctx, "node failed with retryable failure, aborting and finalizing, message: %s", nodeStatus.GetMessage())
+		if err := c.abort(ctx, h, nCtx, nodeStatus.GetMessage()); err != nil {
+			return executors.NodeStatusUndefined, err
+		}
+
+		if delay := computeRetryDelay(nCtx.Node().GetRetryStrategy(), nodeStatus.GetAttempts()); delay > 0 {
+			logger.Infof(ctx, "node will be retried after [%v]", delay)
+			nodeStatus.SetNextAttemptAt(v1.NewTime(time.Now().Add(delay)))
+			c.metrics.RetryDelayed.Inc(ctx)
+			c.scheduleRetry(ctx, nCtx, delay)
+			return executors.NodeStatusPending, nil
+		}
 	}
 
+	c.cancelRetry(nCtx)
+	nodeStatus.ClearNextAttemptAt()
 	// NOTE: It is important to increment attempts only after abort has been called. Increment attempt mutates the state
 	// Attempt is used throughout the system to determine the idempotent resource version.
 	nodeStatus.IncrementAttempts()
@@ -441,6 +536,7 @@ func (c *nodeExecutor) handleRetryableFailure(ctx context.Context, nCtx *nodeExe
 	nodeStatus.ClearTaskStatus()
 	nodeStatus.ClearWorkflowStatus()
 	nodeStatus.ClearDynamicNodeStatus()
+	nodeStatus.ClearMapNodeStatus()
 	return executors.NodeStatusPending, nil
 }
 
@@ -528,6 +624,7 @@ func (c *nodeExecutor) handleDownstream(ctx context.Context, execContext executo
 	partialNodeCompletion := false
 	onFailurePolicy := execContext.GetOnFailurePolicy()
 	stateOnComplete := executors.NodeStatusComplete
+	var failures []v1alpha1.NodeFailure
 	for _, downstreamNodeName := range downstreamNodes {
 		downstreamNode, ok := nl.GetNode(downstreamNodeName)
 		if !ok {
@@ -547,9 +644,10 @@ func (c *nodeExecutor) handleDownstream(ctx context.Context, execContext executo
 			logger.Debugf(ctx, "Some downstream node has failed. Failed: [%v]. TimedOut: [%v]. Error: [%s]", state.HasFailed(), state.HasTimedOut(), state.Err)
 			if onFailurePolicy == v1alpha1.WorkflowOnFailurePolicy(core.WorkflowMetadata_FAIL_AFTER_EXECUTABLE_NODES_COMPLETE) {
 				// If the failure policy allows other nodes to continue running, do not exit the loop,
-				// Keep track of the last failed state in the loop since it'll be the one to return.
-				// TODO: If multiple nodes fail (which this mode allows), consolidate/summarize failure states in one.
+				// Keep track of the last failed state in the loop, and of the failures of all nodes, since they'll be
+				// consolidated in the state to return.
 				stateOnComplete = state
+				failures = mergeNodeFailures(failures, state.Failures...)
 			} else {
 				return state, nil
 			}
@@ -571,6 +669,10 @@ func (c *nodeExecutor) handleDownstream(ctx context.Context, execContext executo
 
 	if allCompleted {
 		logger.Debugf(ctx, "All downstream nodes completed")
+		if len(failures) > 0 {
+			return withNodeFailures(stateOnComplete, failures), nil
+		}
+
 		return stateOnComplete, nil
 	}
 
@@ -606,6 +708,46 @@ func (c *nodeExecutor) SetInputsForStartNode(ctx context.Context, execContext ex
 	return executors.NodeStatusComplete, nil
 }
 
+// Returns true if a node downstream of the given node, directly or transitively, has a trigger rule other than
+// all_success, i.e. it may run even though the given node did not succeed.
+func hasTriggeredDownstream(dag executors.DAGStructure, nl executors.NodeLookup, nodeID v1alpha1.NodeID) bool {
+	visited := sets.NewString(nodeID)
+	queue := []v1alpha1.NodeID{nodeID}
+	for len(queue) > 0 {
+		downstreamNodes, err := dag.FromNode(queue[0])
+		queue = queue[1:]
+		if err != nil {
+			continue
+		}
+
+		for _, downstreamNodeID := range downstreamNodes {
+			if visited.Has(downstreamNodeID) {
+				continue
+			}
+			visited.Insert(downstreamNodeID)
+
+			if n, ok := nl.GetNode(downstreamNodeID); ok && n.GetTriggerRule() != v1alpha1.TriggerRuleAllSuccess {
+				return true
+			}
+			queue = append(queue, downstreamNodeID)
+		}
+	}
+
+	return false
+}
+
+// A failed or timed out node propagates its failure only once the downstream nodes, that may be triggered by the
+// failure, completed. Otherwise failing the workflow would abort them.
+func waitForDownstream(dag executors.DAGStructure, nl executors.NodeLookup, currentNode v1alpha1.ExecutableNode,
+	downstreamStatus executors.NodeStatus) bool {
+
+	if downstreamStatus.IsComplete() || downstreamStatus.HasFailed() || downstreamStatus.HasTimedOut() {
+		return false
+	}
+
+	return hasTriggeredDownstream(dag, nl, currentNode.GetID())
+}
+
 func canHandleNode(phase v1alpha1.NodePhase) bool {
 	return phase == v1alpha1.NodePhaseNotYetStarted ||
 		phase == v1alpha1.NodePhaseQueued ||
@@ -648,11 +790,13 @@ func (c *nodeExecutor) RecursiveNodeHandler(ctx context.Context, execContext exe
 		if err != nil {
 			// NodeExecution creation failure is a permanent fail / system error.
 			// Should a system failure always return an err?
-			return executors.NodeStatusFailed(&core.ExecutionError{
+			execErr := &core.ExecutionError{
 				Code:    "InternalError",
 				Message: err.Error(),
 				Kind:    core.ExecutionError_SYSTEM,
-			}), nil
+			}
+			return withNodeFailures(executors.NodeStatusFailed(execErr),
+				[]v1alpha1.NodeFailure{toNodeFailure(currentNode.GetID(), nodeStatus, execErr)}), nil
 		}
 
 		// Now depending on the node type decide
@@ -661,7 +805,23 @@ func (c *nodeExecutor) RecursiveNodeHandler(ctx context.Context, execContext exe
 			return executors.NodeStatusUndefined, err
 		}
 
-		return c.handleNode(currentNodeCtx, dag, nCtx, h)
+		status, err := c.handleNode(currentNodeCtx, dag, nCtx, h)
+		if err != nil {
+			return executors.NodeStatusUndefined, err
+		}
+
+		if status.HasFailed() || status.HasTimedOut() {
+			status = withNodeFailures(status, []v1alpha1.NodeFailure{toNodeFailure(currentNode.GetID(), nodeStatus, status.Err)})
+		}
+
+		if (status.HasFailed() || status.HasTimedOut()) && hasTriggeredDownstream(dag, nl, currentNode.GetID()) {
+			// Same as a skipped node, the downstream nodes are visited in the next round, once the state is stored. The
+			// failure is propagated once the triggered downstream nodes complete.
+			logger.Infof(currentNodeCtx, "Node has [%v], downstream nodes with trigger rules will run before failing.", status.NodePhase)
+			return executors.NodeStatusSuccess, nil
+		}
+
+		return status, nil
 
 		// TODO we can optimize skip state handling by iterating down the graph and marking all as skipped
 		// Currently we treat either Skip or Success the same way. In this approach only one node will be skipped
@@ -671,20 +831,32 @@ func (c *nodeExecutor) RecursiveNodeHandler(ctx context.Context, execContext exe
 		return c.handleDownstream(ctx, execContext, dag, nl, currentNode)
 	} else if nodePhase == v1alpha1.NodePhaseFailed {
 		logger.Debugf(currentNodeCtx, "Node has failed, traversing downstream.")
-		_, err := c.handleDownstream(ctx, execContext, dag, nl, currentNode)
+		downstreamStatus, err := c.handleDownstream(ctx, execContext, dag, nl, currentNode)
 		if err != nil {
 			return executors.NodeStatusUndefined, err
 		}
 
-		return executors.NodeStatusFailed(nodeStatus.GetExecutionError()), nil
+		if waitForDownstream(dag, nl, currentNode, downstreamStatus) {
+			logger.Debugf(currentNodeCtx, "Node has failed, waiting for triggered downstream nodes to complete.")
+			return downstreamStatus, nil
+		}
+
+		return withNodeFailures(executors.NodeStatusFailed(nodeStatus.GetExecutionError()),
+			downstreamFailures(currentNode.GetID(), nodeStatus, nodeStatus.GetExecutionError(), downstreamStatus)), nil
 	} else if nodePhase == v1alpha1.NodePhaseTimedOut {
 		logger.Debugf(currentNodeCtx, "Node has timed out, traversing downstream.")
-		_, err := c.handleDownstream(ctx, execContext, dag, nl, currentNode)
+		downstreamStatus, err := c.handleDownstream(ctx, execContext, dag, nl, currentNode)
 		if err != nil {
 			return executors.NodeStatusUndefined, err
 		}
 
-		return executors.NodeStatusTimedOut, nil
+		if waitForDownstream(dag, nl, currentNode, downstreamStatus) {
+			logger.Debugf(currentNodeCtx, "Node has timed out, waiting for triggered downstream nodes to complete.")
+			return downstreamStatus, nil
+		}
+
+		return withNodeFailures(executors.NodeStatusTimedOut,
+			downstreamFailures(currentNode.GetID(), nodeStatus, nil, downstreamStatus)), nil
 	}
 
 	return executors.NodeStatusUndefined, errors.Errorf(errors.IllegalStateError, currentNode.GetID(),
@@ -855,6 +1027,7 @@ func NewExecutor(ctx context.Context, nodeConfig config.NodeConfig, store *stora
 		nodeRecorder:        events.NewNodeEventRecorder(eventSink, nodeScope),
 		taskRecorder:        events.NewTaskEventRecorder(eventSink, scope.NewSubScope("task")),
 		maxDatasetSizeBytes: maxDatasetSize,
+		retryTimers:         map[string]*time.Timer{},
 		metrics: &nodeMetrics{
 			Scope:                         nodeScope,
 			FailureDuration:               labeled.NewStopWatch("failure_duration", "Indicates the total execution time of a failed workflow.", time.Millisecond, nodeScope, labeled.EmitUnlabeledMetric),
@@ -867,6 +1040,7 @@ func NewExecutor(ctx context.Context, nodeConfig config.NodeConfig, store *stora
 			PermanentUnknownErrorDuration: labeled.NewStopWatch("perma_unknown_error_duration", "Indicates the total execution time before non recoverable unknown error", time.Millisecond, nodeScope, labeled.EmitUnlabeledMetric),
 			InputsWriteFailure:            labeled.NewCounter("inputs_write_fail", "Indicates failure in writing node inputs to metastore", nodeScope),
 			TimedOutFailure:               labeled.NewCounter("timeout_fail", "Indicates failure due to timeout", nodeScope),
+			RetryDelayed:                  labeled.NewCounter("retry_delayed", "Indicates a retry of a node that was held back by its retry delay", nodeScope),
 			InterruptedThresholdHit:       labeled.NewCounter("interrupted_threshold", "Indicates the node interruptible disabled because it hit max failure count", nodeScope),
 			ResolutionFailure:             labeled.NewCounter("input_resolve_fail", "Indicates failure in resolving node inputs", nodeScope),
 			TransitionLatency:             labeled.NewStopWatch("transition_latency", "Measures the latency between the last parent node stoppedAt time and current node's queued time.", time.Millisecond, nodeScope, labeled.EmitUnlabeledMetric),
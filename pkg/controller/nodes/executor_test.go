@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -559,6 +560,7 @@ func TestNodeExecutor_RecursiveNodeHandler_Recurse(t *testing.T) {
 			mockNode.OnGetTaskID().Return(&taskID)
 			mockNode.OnGetInputBindings().Return([]*v1alpha1.Binding{})
 			mockNode.OnIsInterruptible().Return(nil)
+			mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 
 			mockNodeN0 := &mocks.ExecutableNode{}
 			mockNodeN0.OnGetID().Return(nodeN0)
@@ -1217,6 +1219,7 @@ func TestNodeExecutor_RecursiveNodeHandler_BranchNode(t *testing.T) {
 				branchTakenNode.OnGetKind().Return(v1alpha1.NodeKindTask)
 				branchTakenNode.OnGetTaskID().Return(&tid)
 				branchTakenNode.OnIsInterruptible().Return(nil)
+				branchTakenNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 				branchTakenNode.OnIsStartNode().Return(false)
 				branchTakenNode.OnIsEndNode().Return(false)
 				branchTakenNode.OnGetInputBindings().Return(nil)
@@ -1486,7 +1489,7 @@ func Test_nodeExecutor_system_error(t *testing.T) {
 func Test_nodeExecutor_abort(t *testing.T) {
 	ctx := context.Background()
 	exec := nodeExecutor{}
-	nCtx := &nodeExecContext{}
+	nCtx := &nodeExecContext{md: nodeExecMetadata{nodeExecID: &core.NodeExecutionIdentifier{NodeId: "node"}}}
 
 	t.Run("abort error calls finalize", func(t *testing.T) {
 		h := &nodeHandlerMocks.Node{}
@@ -1562,3 +1565,415 @@ func TestNodeExecutor_FinalizeHandler(t *testing.T) {
 		assert.NoError(t, exec.FinalizeHandler(ctx, nil, nil, nl, n))
 	})
 }
+
+func Test_computeRetryDelay(t *testing.T) {
+	d := func(duration time.Duration) *v1.Duration {
+		return &v1.Duration{Duration: duration}
+	}
+
+	t.Run("no-strategy", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), computeRetryDelay(nil, 3))
+	})
+
+	t.Run("no-delay", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), computeRetryDelay(&v1alpha1.RetryStrategy{}, 3))
+	})
+
+	t.Run("fixed", func(t *testing.T) {
+		s := &v1alpha1.RetryStrategy{RetryDelay: d(time.Second)}
+		assert.Equal(t, time.Second, computeRetryDelay(s, 0))
+		assert.Equal(t, time.Second, computeRetryDelay(s, 5))
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		s := &v1alpha1.RetryStrategy{
+			RetryDelay:    d(time.Second),
+			MaxRetryDelay: d(5 * time.Second),
+			BackoffPolicy: v1alpha1.RetryBackoffPolicyExponential,
+		}
+		assert.Equal(t, time.Second, computeRetryDelay(s, 0))
+		assert.Equal(t, 2*time.Second, computeRetryDelay(s, 1))
+		assert.Equal(t, 4*time.Second, computeRetryDelay(s, 2))
+		assert.Equal(t, 5*time.Second, computeRetryDelay(s, 3))
+		assert.Equal(t, 5*time.Second, computeRetryDelay(s, 1000))
+	})
+
+	t.Run("exponential-unbounded", func(t *testing.T) {
+		s := &v1alpha1.RetryStrategy{
+			RetryDelay:    d(time.Second),
+			BackoffPolicy: v1alpha1.RetryBackoffPolicyExponential,
+		}
+		assert.True(t, computeRetryDelay(s, 1000) > 0)
+	})
+
+	t.Run("jittered", func(t *testing.T) {
+		s := &v1alpha1.RetryStrategy{
+			RetryDelay:    d(time.Second),
+			MaxRetryDelay: d(3 * time.Second),
+			BackoffPolicy: v1alpha1.RetryBackoffPolicyJittered,
+		}
+		for i := 0; i < 10; i++ {
+			delay := computeRetryDelay(s, 4)
+			assert.True(t, delay >= 0 && delay <= 3*time.Second, "unexpected delay %v", delay)
+		}
+	})
+}
+
+func Test_nodeExecutor_handleRetryableFailure_withDelay(t *testing.T) {
+	ctx := context.TODO()
+	retries := 3
+	node := &v1alpha1.NodeSpec{
+		ID:   "n1",
+		Kind: v1alpha1.NodeKindTask,
+		RetryStrategy: &v1alpha1.RetryStrategy{
+			MinAttempts: &retries,
+			RetryDelay:  &v1.Duration{Duration: time.Hour},
+		},
+	}
+
+	exec := &nodeExecutor{
+		metrics: &nodeMetrics{
+			RetryDelayed: labeled.NewCounter("retry_delayed", "", promutils.NewTestScope()),
+		},
+		retryTimers: map[string]*time.Timer{},
+	}
+	md := nodeExecMetadata{nodeExecID: &core.NodeExecutionIdentifier{NodeId: "n1"}}
+
+	h := &nodeHandlerMocks.Node{}
+	h.OnAbortMatch(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	h.OnFinalizeMatch(mock.Anything, mock.Anything).Return(nil)
+
+	t.Run("delay-not-expired", func(t *testing.T) {
+		ns := &v1alpha1.NodeStatus{Phase: v1alpha1.NodePhaseRetryableFailure}
+		nCtx := &nodeExecContext{node: node, nodeStatus: ns, md: md, enqueueOwner: func() error { return nil }}
+
+		s, err := exec.handleRetryableFailure(ctx, nCtx, h)
+		assert.NoError(t, err)
+		assert.Equal(t, executors.NodePhasePending, s.NodePhase)
+		assert.Equal(t, v1alpha1.NodePhaseRetryableFailure, ns.GetPhase())
+		assert.NotNil(t, ns.GetNextAttemptAt())
+		assert.Equal(t, uint32(0), ns.GetAttempts())
+		h.AssertNumberOfCalls(t, "Abort", 1)
+		assert.Len(t, exec.retryTimers, 1)
+
+		// Re-evaluation while still waiting should not abort again
+		s, err = exec.handleRetryableFailure(ctx, nCtx, h)
+		assert.NoError(t, err)
+		assert.Equal(t, executors.NodePhasePending, s.NodePhase)
+		assert.Equal(t, v1alpha1.NodePhaseRetryableFailure, ns.GetPhase())
+		h.AssertNumberOfCalls(t, "Abort", 1)
+
+		// The re-enqueue is stopped once the node is aborted while waiting
+		timer := exec.retryTimers[md.GetNodeExecutionID().String()]
+		assert.NoError(t, exec.abort(ctx, h, nCtx, "aborted"))
+		assert.Empty(t, exec.retryTimers)
+		assert.False(t, timer.Stop())
+	})
+
+	t.Run("delay-expired-before-re-enqueue", func(t *testing.T) {
+		ns := &v1alpha1.NodeStatus{Phase: v1alpha1.NodePhaseRetryableFailure}
+		nCtx := &nodeExecContext{node: node, nodeStatus: ns, md: md, enqueueOwner: func() error { return nil }}
+		_, err := exec.handleRetryableFailure(ctx, nCtx, h)
+		assert.NoError(t, err)
+		assert.Len(t, exec.retryTimers, 1)
+
+		// Re-evaluated once the delay expired, the re-enqueue is no longer needed
+		ns.SetNextAttemptAt(v1.NewTime(time.Now().Add(-time.Minute)))
+		_, err = exec.handleRetryableFailure(ctx, nCtx, h)
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.NodePhaseRunning, ns.GetPhase())
+		assert.Empty(t, exec.retryTimers)
+	})
+
+	t.Run("re-enqueued", func(t *testing.T) {
+		var enqueued int32
+		shortNode := &v1alpha1.NodeSpec{
+			ID:   "n1",
+			Kind: v1alpha1.NodeKindTask,
+			RetryStrategy: &v1alpha1.RetryStrategy{
+				MinAttempts: &retries,
+				RetryDelay:  &v1.Duration{Duration: 10 * time.Millisecond},
+			},
+		}
+		ns := &v1alpha1.NodeStatus{Phase: v1alpha1.NodePhaseRetryableFailure}
+		nCtx := &nodeExecContext{node: shortNode, nodeStatus: ns, md: md, enqueueOwner: func() error {
+			atomic.AddInt32(&enqueued, 1)
+			return nil
+		}}
+		_, err := exec.handleRetryableFailure(ctx, nCtx, h)
+		assert.NoError(t, err)
+
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&enqueued) == 1
+		}, time.Second, 5*time.Millisecond)
+		exec.retryTimersLock.Lock()
+		assert.Empty(t, exec.retryTimers)
+		exec.retryTimersLock.Unlock()
+	})
+
+	t.Run("delay-expired", func(t *testing.T) {
+		nextAttemptAt := v1.NewTime(time.Now().Add(-time.Minute))
+		ns := &v1alpha1.NodeStatus{Phase: v1alpha1.NodePhaseRetryableFailure, NextAttemptAt: &nextAttemptAt}
+		nCtx := &nodeExecContext{node: node, nodeStatus: ns, md: md, enqueueOwner: func() error { return nil }}
+
+		s, err := exec.handleRetryableFailure(ctx, nCtx, h)
+		assert.NoError(t, err)
+		assert.Equal(t, executors.NodePhasePending, s.NodePhase)
+		assert.Equal(t, v1alpha1.NodePhaseRunning, ns.GetPhase())
+		assert.Nil(t, ns.GetNextAttemptAt())
+		assert.Equal(t, uint32(1), ns.GetAttempts())
+	})
+}
+
+func TestNodeExecutor_RecursiveNodeHandler_TriggerRules(t *testing.T) {
+	ctx := context.Background()
+	enQWf := func(workflowID v1alpha1.WorkflowID) {
+	}
+	mockEventSink := events.NewMockEventSink().(*events.MockEventSink)
+
+	store := createInmemoryDataStore(t, promutils.NewTestScope())
+	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
+	execIface, err := NewExecutor(ctx, config.GetConfig().NodeConfig, store, enQWf, mockEventSink, adminClient,
+		adminClient, 10, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
+	assert.NoError(t, err)
+	exec := execIface.(*nodeExecutor)
+
+	hf := &mocks2.HandlerFactory{}
+	exec.nodeHandlerFactory = hf
+	h := &nodeHandlerMocks.Node{}
+	h.OnHandleMatch(mock.Anything, mock.Anything).Return(handler.UnknownTransition, fmt.Errorf("should not be called"))
+	h.OnFinalizeRequired().Return(false)
+	hf.OnGetHandler(v1alpha1.NodeKindTask).Return(h, nil)
+
+	taskID := "tID"
+	execErr := &core.ExecutionError{Code: "USER:Failed", Message: "n1 failed"}
+
+	// start -> n1 -> n2 -> cleanup, and n1 -> cleanup
+	createWf := func(cleanupRule v1alpha1.TriggerRule) *v1alpha1.FlyteWorkflow {
+		taskNode := func(id v1alpha1.NodeID) *v1alpha1.NodeSpec {
+			return &v1alpha1.NodeSpec{ID: id, TaskRef: &taskID, Kind: v1alpha1.NodeKindTask}
+		}
+		cleanup := taskNode("cleanup")
+		cleanup.TriggerRule = cleanupRule
+
+		return &v1alpha1.FlyteWorkflow{
+			Tasks: map[v1alpha1.TaskID]*v1alpha1.TaskSpec{
+				taskID: {TaskTemplate: &core.TaskTemplate{}},
+			},
+			Status: v1alpha1.WorkflowStatus{
+				NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+					v1alpha1.StartNodeID: {Phase: v1alpha1.NodePhaseSucceeded},
+					"n1":                 {Phase: v1alpha1.NodePhaseFailed, Error: &v1alpha1.ExecutionError{ExecutionError: execErr}},
+				},
+				DataDir: "data",
+			},
+			WorkflowSpec: &v1alpha1.WorkflowSpec{
+				ID: "wf",
+				Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+					v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+					"n1":                 taskNode("n1"),
+					"n2":                 taskNode("n2"),
+					"cleanup":            cleanup,
+				},
+				Connections: v1alpha1.Connections{
+					UpstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+						"n1":      {v1alpha1.StartNodeID},
+						"n2":      {"n1"},
+						"cleanup": {"n1", "n2"},
+					},
+					DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+						v1alpha1.StartNodeID: {"n1"},
+						"n1":                 {"n2", "cleanup"},
+						"n2":                 {"cleanup"},
+					},
+				},
+			},
+			DataReferenceConstructor: store,
+		}
+	}
+
+	resetDirty := func(w *v1alpha1.FlyteWorkflow) {
+		for _, s := range w.Status.NodeStatus {
+			s.ResetDirty()
+		}
+	}
+
+	t.Run("allSuccess", func(t *testing.T) {
+		w := createWf("")
+		n1, _ := w.GetNode("n1")
+		s, err := exec.RecursiveNodeHandler(ctx, w, w, w, n1)
+		assert.NoError(t, err)
+		assert.Equal(t, executors.NodePhaseFailed, s.NodePhase)
+		assert.Equal(t, execErr, s.Err)
+	})
+
+	t.Run("allDone", func(t *testing.T) {
+		w := createWf(v1alpha1.TriggerRuleAllDone)
+		n1, _ := w.GetNode("n1")
+
+		// n2 is skipped, the failure is not propagated while cleanup may still run
+		s, err := exec.RecursiveNodeHandler(ctx, w, w, w, n1)
+		assert.NoError(t, err)
+		assert.Equal(t, executors.NodePhaseSuccess, s.NodePhase)
+		assert.Equal(t, v1alpha1.NodePhaseSkipped, w.Status.NodeStatus["n2"].GetPhase())
+		resetDirty(w)
+
+		s, err = exec.RecursiveNodeHandler(ctx, w, w, w, n1)
+		assert.NoError(t, err)
+		assert.False(t, s.HasFailed())
+		assert.Equal(t, v1alpha1.NodePhaseQueued, w.Status.NodeStatus["cleanup"].GetPhase())
+		resetDirty(w)
+
+		w.Status.NodeStatus["cleanup"].Phase = v1alpha1.NodePhaseSucceeded
+		s, err = exec.RecursiveNodeHandler(ctx, w, w, w, n1)
+		assert.NoError(t, err)
+		assert.Equal(t, executors.NodePhaseFailed, s.NodePhase)
+		assert.Equal(t, execErr, s.Err)
+	})
+}
+
+func TestNodeExecutor_RecursiveNodeHandler_CacheOverride(t *testing.T) {
+	ctx := context.Background()
+	enQWf := func(workflowID v1alpha1.WorkflowID) {
+	}
+	mockEventSink := events.NewMockEventSink().(*events.MockEventSink)
+
+	store := createInmemoryDataStore(t, promutils.NewTestScope())
+	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
+	execIface, err := NewExecutor(ctx, config.GetConfig().NodeConfig, store, enQWf, mockEventSink, adminClient,
+		adminClient, 10, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
+	assert.NoError(t, err)
+	exec := execIface.(*nodeExecutor)
+
+	hf := &mocks2.HandlerFactory{}
+	exec.nodeHandlerFactory = hf
+	h := &nodeHandlerMocks.Node{}
+	h.OnHandleMatch(mock.Anything, mock.Anything).Return(handler.DoTransition(handler.TransitionTypeEphemeral,
+		handler.PhaseInfoSuccess(&handler.ExecutionInfo{
+			TaskNodeInfo: &handler.TaskNodeInfo{CacheOverride: v1alpha1.CacheOverrideOverwrite},
+		})), nil)
+	h.OnFinalizeRequired().Return(false)
+	hf.OnGetHandler(v1alpha1.NodeKindTask).Return(h, nil)
+
+	taskID := "tID"
+	w := &v1alpha1.FlyteWorkflow{
+		Tasks: map[v1alpha1.TaskID]*v1alpha1.TaskSpec{
+			taskID: {TaskTemplate: &core.TaskTemplate{}},
+		},
+		Status: v1alpha1.WorkflowStatus{
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				v1alpha1.StartNodeID: {Phase: v1alpha1.NodePhaseSucceeded},
+				"n1":                 {Phase: v1alpha1.NodePhaseRunning},
+			},
+			DataDir: "data",
+		},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+				"n1":                 {ID: "n1", TaskRef: &taskID, Kind: v1alpha1.NodeKindTask},
+			},
+			Connections: v1alpha1.Connections{
+				UpstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+					"n1": {v1alpha1.StartNodeID},
+				},
+				DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+					v1alpha1.StartNodeID: {"n1"},
+				},
+			},
+		},
+		DataReferenceConstructor: store,
+	}
+
+	n1, _ := w.GetNode("n1")
+	_, err = exec.RecursiveNodeHandler(ctx, w, w, w, n1)
+	assert.NoError(t, err)
+	s := w.Status.NodeStatus["n1"]
+	assert.Equal(t, v1alpha1.NodePhaseSucceeded, s.GetPhase())
+	if assert.NotNil(t, s.GetTaskNodeStatus()) {
+		assert.Equal(t, v1alpha1.CacheOverrideOverwrite, s.GetTaskNodeStatus().GetCacheOverride())
+	}
+}
+
+func TestNodeExecutor_RecursiveNodeHandler_AggregatedFailures(t *testing.T) {
+	ctx := context.Background()
+	enQWf := func(workflowID v1alpha1.WorkflowID) {
+	}
+	mockEventSink := events.NewMockEventSink().(*events.MockEventSink)
+
+	store := createInmemoryDataStore(t, promutils.NewTestScope())
+	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
+	execIface, err := NewExecutor(ctx, config.GetConfig().NodeConfig, store, enQWf, mockEventSink, adminClient,
+		adminClient, 10, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
+	assert.NoError(t, err)
+	exec := execIface.(*nodeExecutor)
+
+	taskID := "tID"
+	n1Err := &core.ExecutionError{Code: "USER:Failed", Message: "n1 failed", Kind: core.ExecutionError_USER}
+	n2Err := &core.ExecutionError{Code: "InternalError", Message: "n2 failed", Kind: core.ExecutionError_SYSTEM}
+
+	// start -> (n1, n2, n3), n1 and n2 failed
+	createWf := func(policy core.WorkflowMetadata_OnFailurePolicy) *v1alpha1.FlyteWorkflow {
+		taskNode := func(id v1alpha1.NodeID) *v1alpha1.NodeSpec {
+			return &v1alpha1.NodeSpec{ID: id, TaskRef: &taskID, Kind: v1alpha1.NodeKindTask}
+		}
+
+		return &v1alpha1.FlyteWorkflow{
+			Status: v1alpha1.WorkflowStatus{
+				NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+					v1alpha1.StartNodeID: {Phase: v1alpha1.NodePhaseSucceeded},
+					"n1":                 {Phase: v1alpha1.NodePhaseFailed, Attempts: 2, Error: &v1alpha1.ExecutionError{ExecutionError: n1Err}},
+					"n2":                 {Phase: v1alpha1.NodePhaseFailed, Attempts: 1, Error: &v1alpha1.ExecutionError{ExecutionError: n2Err}},
+					"n3":                 {Phase: v1alpha1.NodePhaseSucceeded},
+				},
+				DataDir: "data",
+			},
+			WorkflowSpec: &v1alpha1.WorkflowSpec{
+				ID: "wf",
+				Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+					v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+					"n1":                 taskNode("n1"),
+					"n2":                 taskNode("n2"),
+					"n3":                 taskNode("n3"),
+				},
+				Connections: v1alpha1.Connections{
+					UpstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+						"n1": {v1alpha1.StartNodeID},
+						"n2": {v1alpha1.StartNodeID},
+						"n3": {v1alpha1.StartNodeID},
+					},
+					DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+						v1alpha1.StartNodeID: {"n1", "n2", "n3"},
+					},
+				},
+				OnFailurePolicy: v1alpha1.WorkflowOnFailurePolicy(policy),
+			},
+			DataReferenceConstructor: store,
+		}
+	}
+
+	t.Run("failImmediately", func(t *testing.T) {
+		w := createWf(core.WorkflowMetadata_FAIL_IMMEDIATELY)
+		s, err := exec.RecursiveNodeHandler(ctx, w, w, w, w.StartNode())
+		assert.NoError(t, err)
+		assert.True(t, s.HasFailed())
+		assert.Equal(t, n1Err, s.Err)
+		assert.Equal(t, []v1alpha1.NodeFailure{
+			{NodeID: "n1", Code: "USER:Failed", Kind: "USER", Message: "n1 failed", Attempt: 2},
+		}, s.Failures)
+	})
+
+	t.Run("failAfterExecutableNodesComplete", func(t *testing.T) {
+		w := createWf(core.WorkflowMetadata_FAIL_AFTER_EXECUTABLE_NODES_COMPLETE)
+		s, err := exec.RecursiveNodeHandler(ctx, w, w, w, w.StartNode())
+		assert.NoError(t, err)
+		assert.True(t, s.HasFailed())
+		assert.Equal(t, []v1alpha1.NodeFailure{
+			{NodeID: "n1", Code: "USER:Failed", Kind: "USER", Message: "n1 failed", Attempt: 2},
+			{NodeID: "n2", Code: "InternalError", Kind: "SYSTEM", Message: "n2 failed", Attempt: 1},
+		}, s.Failures)
+		assert.Equal(t, "MultipleNodesFailed", s.Err.Code)
+		assert.Equal(t, core.ExecutionError_SYSTEM, s.Err.Kind)
+		assert.Contains(t, s.Err.Message, "[n2] attempt [1] InternalError: n2 failed")
+	})
+}
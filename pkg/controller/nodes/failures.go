@@ -0,0 +1,99 @@
+package nodes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/executors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+)
+
+// Returns the summary of the failure of a node. err is nil if the node timed out.
+func toNodeFailure(nodeID v1alpha1.NodeID, nodeStatus v1alpha1.ExecutableNodeStatus, err *core.ExecutionError) v1alpha1.NodeFailure {
+	if err == nil {
+		err = &core.ExecutionError{
+			Code:    "TimedOut",
+			Message: "node timed out",
+			Kind:    core.ExecutionError_USER,
+		}
+	}
+
+	return v1alpha1.NodeFailure{
+		NodeID:  nodeID,
+		Code:    err.GetCode(),
+		Kind:    err.GetKind().String(),
+		Message: err.GetMessage(),
+		Attempt: nodeStatus.GetAttempts(),
+	}
+}
+
+// Appends the failures that are not yet in the list, a failed node may be reached through multiple upstream nodes.
+func mergeNodeFailures(failures []v1alpha1.NodeFailure, more ...v1alpha1.NodeFailure) []v1alpha1.NodeFailure {
+	for _, f := range more {
+		found := false
+		for _, existing := range failures {
+			if existing.NodeID == f.NodeID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			failures = append(failures, f)
+		}
+	}
+
+	return failures
+}
+
+// Summarizes the failures of multiple nodes in a single error. The error is a system error if any of the nodes failed
+// with a system error.
+func aggregateNodeFailures(failures []v1alpha1.NodeFailure) *core.ExecutionError {
+	kind := core.ExecutionError_USER
+	b := strings.Builder{}
+	b.WriteString(fmt.Sprintf("[%d] nodes failed", len(failures)))
+	for _, f := range failures {
+		if f.Kind == core.ExecutionError_SYSTEM.String() {
+			kind = core.ExecutionError_SYSTEM
+		}
+
+		b.WriteString(fmt.Sprintf("\n[%s] attempt [%d] %s: %s", f.NodeID, f.Attempt, f.Code, f.Message))
+	}
+
+	return &core.ExecutionError{
+		Code:    errors.MultipleNodesFailedError,
+		Message: b.String(),
+		Kind:    kind,
+	}
+}
+
+// Returns the state of a failed or timed out node with the given failures. If more than one node failed, the state is
+// failed with an error aggregating all the failures.
+func withNodeFailures(state executors.NodeStatus, failures []v1alpha1.NodeFailure) executors.NodeStatus {
+	if len(failures) > 1 {
+		return executors.NodeStatus{
+			NodePhase: executors.NodePhaseFailed,
+			Err:       aggregateNodeFailures(failures),
+			Failures:  failures,
+		}
+	}
+
+	state.Failures = failures
+	return state
+}
+
+// Returns the failure of a failed or timed out node, followed by the failures of the downstream nodes that were
+// triggered by the failure and failed as well.
+func downstreamFailures(nodeID v1alpha1.NodeID, nodeStatus v1alpha1.ExecutableNodeStatus, err *core.ExecutionError,
+	downstreamStatus executors.NodeStatus) []v1alpha1.NodeFailure {
+
+	failures := []v1alpha1.NodeFailure{toNodeFailure(nodeID, nodeStatus, err)}
+	if downstreamStatus.HasFailed() || downstreamStatus.HasTimedOut() {
+		failures = mergeNodeFailures(failures, downstreamStatus.Failures...)
+	}
+
+	return failures
+}
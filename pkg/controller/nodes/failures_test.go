@@ -0,0 +1,52 @@
+package nodes
+
+import (
+	"testing"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/executors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+)
+
+func TestToNodeFailure(t *testing.T) {
+	ns := &v1alpha1.NodeStatus{Attempts: 2}
+	assert.Equal(t, v1alpha1.NodeFailure{NodeID: "n1", Code: "USER:Failed", Kind: "USER", Message: "failed", Attempt: 2},
+		toNodeFailure("n1", ns, &core.ExecutionError{Code: "USER:Failed", Message: "failed", Kind: core.ExecutionError_USER}))
+	assert.Equal(t, v1alpha1.NodeFailure{NodeID: "n1", Code: "TimedOut", Kind: "USER", Message: "node timed out", Attempt: 2},
+		toNodeFailure("n1", ns, nil))
+}
+
+func TestMergeNodeFailures(t *testing.T) {
+	failures := mergeNodeFailures(nil, v1alpha1.NodeFailure{NodeID: "n1"}, v1alpha1.NodeFailure{NodeID: "n2"})
+	failures = mergeNodeFailures(failures, v1alpha1.NodeFailure{NodeID: "n2", Code: "other"}, v1alpha1.NodeFailure{NodeID: "n3"})
+	assert.Equal(t, []v1alpha1.NodeFailure{{NodeID: "n1"}, {NodeID: "n2"}, {NodeID: "n3"}}, failures)
+}
+
+func TestWithNodeFailures(t *testing.T) {
+	n1 := v1alpha1.NodeFailure{NodeID: "n1", Code: "USER:Failed", Kind: "USER", Message: "n1 failed", Attempt: 1}
+	n2 := v1alpha1.NodeFailure{NodeID: "n2", Code: "TimedOut", Kind: "USER", Message: "node timed out"}
+
+	t.Run("single", func(t *testing.T) {
+		s := withNodeFailures(executors.NodeStatusTimedOut, []v1alpha1.NodeFailure{n2})
+		assert.True(t, s.HasTimedOut())
+		assert.Equal(t, []v1alpha1.NodeFailure{n2}, s.Failures)
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		s := withNodeFailures(executors.NodeStatusTimedOut, []v1alpha1.NodeFailure{n1, n2})
+		assert.True(t, s.HasFailed())
+		assert.Equal(t, []v1alpha1.NodeFailure{n1, n2}, s.Failures)
+		assert.Equal(t, errors.MultipleNodesFailedError, s.Err.Code)
+		assert.Equal(t, core.ExecutionError_USER, s.Err.Kind)
+		assert.Equal(t, "[2] nodes failed\n[n1] attempt [1] USER:Failed: n1 failed\n[n2] attempt [0] TimedOut: node timed out", s.Err.Message)
+	})
+
+	t.Run("system", func(t *testing.T) {
+		n3 := v1alpha1.NodeFailure{NodeID: "n3", Code: "InternalError", Kind: "SYSTEM"}
+		s := withNodeFailures(executors.NodeStatusFailed(nil), []v1alpha1.NodeFailure{n1, n3})
+		assert.Equal(t, core.ExecutionError_SYSTEM, s.Err.Kind)
+	})
+}
@@ -0,0 +1,136 @@
+package gate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/lyft/flytestdlib/storage"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/validators"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+)
+
+type metrics struct {
+	scope    promutils.Scope
+	approved labeled.Counter
+	timedOut labeled.Counter
+	rejected labeled.Counter
+}
+
+// Handler for gate nodes. A gate node stays in running until its signal file appears in the data directory of the
+// node. The node is re-evaluated whenever the workflow is, so the signal is picked up at the latest after the workflow
+// re-evaluation interval.
+type gateHandler struct {
+	metrics metrics
+	now     func() time.Time
+}
+
+func (g *gateHandler) FinalizeRequired() bool {
+	return false
+}
+
+func (g *gateHandler) Setup(ctx context.Context, _ handler.SetupContext) error {
+	logger.Debugf(ctx, "GateNode::Setup: nothing to do")
+	return nil
+}
+
+// Verifies that the signal supplies all the expected variables with the right types and returns the outputs of the
+// node. Literals not declared by the gate are dropped.
+func validateSignal(expected *core.VariableMap, signal *core.LiteralMap) (*core.LiteralMap, error) {
+	if expected == nil {
+		return signal, nil
+	}
+
+	outputs := &core.LiteralMap{Literals: make(map[string]*core.Literal, len(expected.GetVariables()))}
+	for name, v := range expected.GetVariables() {
+		l, ok := signal.GetLiterals()[name]
+		if !ok {
+			return nil, fmt.Errorf("signal is missing variable [%s]", name)
+		}
+
+		if t := validators.LiteralTypeForLiteral(l); !validators.AreTypesCastable(t, v.GetType()) {
+			return nil, fmt.Errorf("variable [%s] of the signal is of type [%s], expected [%s]", name, t.String(), v.GetType().String())
+		}
+
+		outputs.Literals[name] = l
+	}
+
+	return outputs, nil
+}
+
+func (g *gateHandler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext) (handler.Transition, error) {
+	gateNode := nCtx.Node().GetGateNode()
+	if gateNode == nil {
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_SYSTEM, errors.IllegalStateError, "Invoked gate handler, for a non gate node.", nil)), nil
+	}
+
+	signalFile := v1alpha1.GetSignalFile(nCtx.NodeStatus().GetDataDir())
+	metadata, err := nCtx.DataStore().Head(ctx, signalFile)
+	if err != nil {
+		return handler.UnknownTransition, errors.Wrapf(errors.StorageError, nCtx.NodeID(), err, "failed to look up signal [%s]", signalFile)
+	}
+
+	if !metadata.Exists() {
+		if timeout := gateNode.GetTimeout(); timeout != nil {
+			startedAt := nCtx.NodeStatus().GetStartedAt()
+			if startedAt != nil && g.now().Sub(startedAt.Time) > *timeout {
+				g.metrics.timedOut.Inc(ctx)
+				errMsg := fmt.Sprintf("No signal received within [%v]", *timeout)
+				return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_USER, errors.GateTimedOutError, errMsg, nil)), nil
+			}
+		}
+
+		logger.Debugf(ctx, "Gate node is waiting for signal [%s]", signalFile)
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoRunning(nil)), nil
+	}
+
+	signal := &core.LiteralMap{}
+	if err := nCtx.DataStore().ReadProtobuf(ctx, signalFile, signal); err != nil {
+		return handler.UnknownTransition, errors.Wrapf(errors.StorageError, nCtx.NodeID(), err, "failed to read signal [%s]", signalFile)
+	}
+
+	outputs, err := validateSignal(gateNode.GetSignal(), signal)
+	if err != nil {
+		g.metrics.rejected.Inc(ctx)
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_USER, errors.InvalidSignalError, err.Error(), nil)), nil
+	}
+
+	outputFile := v1alpha1.GetOutputsFile(nCtx.NodeStatus().GetOutputDir())
+	if err := nCtx.DataStore().WriteProtobuf(ctx, outputFile, storage.Options{}, outputs); err != nil {
+		return handler.UnknownTransition, errors.Wrapf(errors.StorageError, nCtx.NodeID(), err, "failed to write outputs of the gate node")
+	}
+
+	logger.Infof(ctx, "Gate node received signal [%s]", signalFile)
+	g.metrics.approved.Inc(ctx)
+	return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoSuccess(&handler.ExecutionInfo{
+		OutputInfo: &handler.OutputInfo{OutputURI: outputFile},
+	})), nil
+}
+
+func (g *gateHandler) Abort(_ context.Context, _ handler.NodeExecutionContext, _ string) error {
+	return nil
+}
+
+func (g *gateHandler) Finalize(_ context.Context, _ handler.NodeExecutionContext) error {
+	return nil
+}
+
+func New(scope promutils.Scope) handler.Node {
+	gateScope := scope.NewSubScope("gate")
+	return &gateHandler{
+		metrics: metrics{
+			scope:    gateScope,
+			approved: labeled.NewCounter("approved", "Number of gate nodes that received a valid signal", gateScope),
+			timedOut: labeled.NewCounter("timed_out", "Number of gate nodes that timed out waiting for a signal", gateScope),
+			rejected: labeled.NewCounter("invalid_signal", "Number of gate nodes that received an invalid signal", gateScope),
+		},
+		now: time.Now,
+	}
+}
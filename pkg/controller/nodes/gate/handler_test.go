@@ -0,0 +1,151 @@
+package gate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler/mocks"
+	"github.com/lyft/flytepropeller/pkg/utils"
+)
+
+func init() {
+	labeled.SetMetricKeys(contextutils.NodeIDKey)
+}
+
+var startedAt = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func createNodeContext(t *testing.T, gateNode *v1alpha1.GateNodeSpec, store *storage.DataStore) *mocks.NodeExecutionContext {
+	nCtx := &mocks.NodeExecutionContext{}
+	nCtx.OnNode().Return(&v1alpha1.NodeSpec{ID: "n", Kind: v1alpha1.NodeKindGate, GateNode: gateNode})
+	nCtx.OnNodeID().Return("n")
+	nCtx.OnDataStore().Return(store)
+	t0 := v1.NewTime(startedAt)
+	nCtx.OnNodeStatus().Return(&v1alpha1.NodeStatus{
+		DataDir:   "s3://bucket/n",
+		OutputDir: "s3://bucket/n/0",
+		StartedAt: &t0,
+	})
+	return nCtx
+}
+
+func newTestHandler(now time.Time) *gateHandler {
+	h := New(promutils.NewTestScope()).(*gateHandler)
+	h.now = func() time.Time {
+		return now
+	}
+	return h
+}
+
+func newTestStore(t *testing.T) *storage.DataStore {
+	store, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+	assert.NoError(t, err)
+	return store
+}
+
+func signalVars() *v1alpha1.OutputVarMap {
+	return &v1alpha1.OutputVarMap{VariableMap: &core.VariableMap{
+		Variables: map[string]*core.Variable{
+			"approver": {Type: &core.LiteralType{Type: &core.LiteralType_Simple{Simple: core.SimpleType_STRING}}},
+		},
+	}}
+}
+
+func TestGateHandler_Handle(t *testing.T) {
+	ctx := context.TODO()
+	timeout := &v1.Duration{Duration: time.Hour}
+
+	t.Run("waiting", func(t *testing.T) {
+		nCtx := createNodeContext(t, &v1alpha1.GateNodeSpec{Timeout: timeout}, newTestStore(t))
+		trns, err := newTestHandler(startedAt.Add(time.Minute)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+	})
+
+	t.Run("timed-out", func(t *testing.T) {
+		nCtx := createNodeContext(t, &v1alpha1.GateNodeSpec{Timeout: timeout}, newTestStore(t))
+		trns, err := newTestHandler(startedAt.Add(2*time.Hour)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseFailed, trns.Info().GetPhase())
+		assert.Equal(t, errors.GateTimedOutError, trns.Info().GetErr().Code)
+	})
+
+	t.Run("no-timeout", func(t *testing.T) {
+		nCtx := createNodeContext(t, &v1alpha1.GateNodeSpec{}, newTestStore(t))
+		trns, err := newTestHandler(startedAt.Add(24*time.Hour)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+	})
+
+	t.Run("signalled", func(t *testing.T) {
+		store := newTestStore(t)
+		signal, err := utils.MakeLiteralMap(map[string]interface{}{"approver": "alice", "extra": 1})
+		assert.NoError(t, err)
+		assert.NoError(t, store.WriteProtobuf(ctx, "s3://bucket/n/signal.pb", storage.Options{}, signal))
+
+		nCtx := createNodeContext(t, &v1alpha1.GateNodeSpec{Signal: signalVars(), Timeout: timeout}, store)
+		// A signal is honored even after the timeout elapsed
+		trns, err := newTestHandler(startedAt.Add(2*time.Hour)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		assert.Equal(t, v1alpha1.DataReference("s3://bucket/n/0/outputs.pb"), trns.Info().GetInfo().OutputInfo.OutputURI)
+
+		outputs := &core.LiteralMap{}
+		assert.NoError(t, store.ReadProtobuf(ctx, "s3://bucket/n/0/outputs.pb", outputs))
+		assert.Len(t, outputs.Literals, 1)
+		assert.Equal(t, "alice", outputs.Literals["approver"].GetScalar().GetPrimitive().GetStringValue())
+	})
+
+	t.Run("invalid-signal", func(t *testing.T) {
+		store := newTestStore(t)
+		signal, err := utils.MakeLiteralMap(map[string]interface{}{"approver": 1})
+		assert.NoError(t, err)
+		assert.NoError(t, store.WriteProtobuf(ctx, "s3://bucket/n/signal.pb", storage.Options{}, signal))
+
+		nCtx := createNodeContext(t, &v1alpha1.GateNodeSpec{Signal: signalVars()}, store)
+		trns, err := newTestHandler(startedAt).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseFailed, trns.Info().GetPhase())
+		assert.Equal(t, errors.InvalidSignalError, trns.Info().GetErr().Code)
+	})
+
+	t.Run("not-a-gate", func(t *testing.T) {
+		nCtx := createNodeContext(t, nil, newTestStore(t))
+		trns, err := newTestHandler(startedAt).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseFailed, trns.Info().GetPhase())
+	})
+}
+
+func TestValidateSignal(t *testing.T) {
+	signal, err := utils.MakeLiteralMap(map[string]interface{}{"approver": "alice"})
+	assert.NoError(t, err)
+
+	t.Run("untyped", func(t *testing.T) {
+		outputs, err := validateSignal(nil, signal)
+		assert.NoError(t, err)
+		assert.Equal(t, signal, outputs)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := validateSignal(signalVars().VariableMap, &core.LiteralMap{})
+		assert.Error(t, err)
+	})
+
+	t.Run("typed", func(t *testing.T) {
+		outputs, err := validateSignal(signalVars().VariableMap, signal)
+		assert.NoError(t, err)
+		assert.Len(t, outputs.Literals, 1)
+	})
+}
@@ -76,6 +76,38 @@ func (_m *NodeStateReader) GetDynamicNodeState() handler.DynamicNodeState {
 	return r0
 }
 
+type NodeStateReader_GetMapNodeState struct {
+	*mock.Call
+}
+
+func (_m NodeStateReader_GetMapNodeState) Return(_a0 handler.MapNodeState) *NodeStateReader_GetMapNodeState {
+	return &NodeStateReader_GetMapNodeState{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *NodeStateReader) OnGetMapNodeState() *NodeStateReader_GetMapNodeState {
+	c := _m.On("GetMapNodeState")
+	return &NodeStateReader_GetMapNodeState{Call: c}
+}
+
+func (_m *NodeStateReader) OnGetMapNodeStateMatch(matchers ...interface{}) *NodeStateReader_GetMapNodeState {
+	c := _m.On("GetMapNodeState", matchers...)
+	return &NodeStateReader_GetMapNodeState{Call: c}
+}
+
+// GetMapNodeState provides a mock function with given fields: 
+func (_m *NodeStateReader) GetMapNodeState() handler.MapNodeState {
+	ret := _m.Called()
+
+	var r0 handler.MapNodeState
+	if rf, ok := ret.Get(0).(func() handler.MapNodeState); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(handler.MapNodeState)
+	}
+
+	return r0
+}
+
 type NodeStateReader_GetTaskNodeState struct {
 	*mock.Call
 }
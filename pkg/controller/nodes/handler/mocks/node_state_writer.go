@@ -76,6 +76,38 @@ func (_m *NodeStateWriter) PutDynamicNodeState(s handler.DynamicNodeState) error
 	return r0
 }
 
+type NodeStateWriter_PutMapNodeState struct {
+	*mock.Call
+}
+
+func (_m NodeStateWriter_PutMapNodeState) Return(_a0 error) *NodeStateWriter_PutMapNodeState {
+	return &NodeStateWriter_PutMapNodeState{Call: _m.Call.Return(_a0)}
+}
+
+func (_m *NodeStateWriter) OnPutMapNodeState(s handler.MapNodeState) *NodeStateWriter_PutMapNodeState {
+	c := _m.On("PutMapNodeState", s)
+	return &NodeStateWriter_PutMapNodeState{Call: c}
+}
+
+func (_m *NodeStateWriter) OnPutMapNodeStateMatch(matchers ...interface{}) *NodeStateWriter_PutMapNodeState {
+	c := _m.On("PutMapNodeState", matchers...)
+	return &NodeStateWriter_PutMapNodeState{Call: c}
+}
+
+// PutMapNodeState provides a mock function with given fields: s
+func (_m *NodeStateWriter) PutMapNodeState(s handler.MapNodeState) error {
+	ret := _m.Called(s)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(handler.MapNodeState) error); ok {
+		r0 = rf(s)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type NodeStateWriter_PutTaskNodeState struct {
 	*mock.Call
 }
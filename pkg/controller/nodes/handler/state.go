@@ -39,11 +39,17 @@ type WorkflowNodeState struct {
 	Error *core.ExecutionError
 }
 
+type MapNodeState struct {
+	Phase v1alpha1.MapNodePhase
+	Items []v1alpha1.MapItemStatus
+}
+
 type NodeStateWriter interface {
 	PutTaskNodeState(s TaskNodeState) error
 	PutBranchNode(s BranchNodeState) error
 	PutDynamicNodeState(s DynamicNodeState) error
 	PutWorkflowNodeState(s WorkflowNodeState) error
+	PutMapNodeState(s MapNodeState) error
 }
 
 type NodeStateReader interface {
@@ -51,4 +57,5 @@ type NodeStateReader interface {
 	GetBranchNode() BranchNodeState
 	GetDynamicNodeState() DynamicNodeState
 	GetWorkflowNodeState() WorkflowNodeState
+	GetMapNodeState() MapNodeState
 }
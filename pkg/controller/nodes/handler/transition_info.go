@@ -5,6 +5,8 @@ import (
 
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
 	"github.com/lyft/flytestdlib/storage"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 )
 
 //go:generate enumer --type=EPhase --trimprefix=EPhase
@@ -43,6 +45,8 @@ type BranchNodeInfo struct {
 
 type TaskNodeInfo struct {
 	CacheHit bool
+	// Set if the workflow overrides how the node uses the catalog cache, which explains a cache miss
+	CacheOverride v1alpha1.CacheOverride
 	// TaskPhase etc
 }
 
@@ -15,7 +15,10 @@ import (
 	"github.com/lyft/flytepropeller/pkg/controller/executors"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/branch"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/end"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/gate"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/mapnode"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/sleep"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/start"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/subworkflow"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/subworkflow/launchplan"
@@ -65,6 +68,9 @@ func NewHandlerFactory(ctx context.Context, executor executors.Node, workflowLau
 			v1alpha1.NodeKindWorkflow: subworkflow.New(executor, workflowLauncher, scope),
 			v1alpha1.NodeKindStart:    start.New(),
 			v1alpha1.NodeKindEnd:      end.New(),
+			v1alpha1.NodeKindMap:      mapnode.New(t, scope),
+			v1alpha1.NodeKindGate:     gate.New(scope),
+			v1alpha1.NodeKindSleep:    sleep.New(scope),
 		},
 	}
 
@@ -0,0 +1,435 @@
+package mapnode
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/ioutils"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/lyft/flytestdlib/storage"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+)
+
+type metrics struct {
+	scope          promutils.Scope
+	itemsStarted   labeled.Counter
+	itemsSucceeded labeled.Counter
+	itemsFailed    labeled.Counter
+	itemRetries    labeled.Counter
+}
+
+// Handler for map nodes. A map node executes its task once per item of its inputs (all of which are collections of the
+// same length), using the task handler to drive every item. Items are tracked in the MapNodeStatus of the node, instead
+// of creating a node (and node status) per item, which keeps the CRD small for large fan-outs.
+type mapNodeHandler struct {
+	taskHandler handler.Node
+	metrics     metrics
+}
+
+func (m *mapNodeHandler) FinalizeRequired() bool {
+	return true
+}
+
+func (m *mapNodeHandler) Setup(ctx context.Context, _ handler.SetupContext) error {
+	// The task handler is shared with task nodes and is set up as part of those
+	logger.Debugf(ctx, "MapNode::Setup: nothing to do")
+	return nil
+}
+
+func isCollection(l *core.Literal) bool {
+	return l != nil && l.GetCollection() != nil
+}
+
+// Splits the inputs of the map node into the inputs of the individual items.
+func splitInputs(inputs *core.LiteralMap) ([]*core.LiteralMap, error) {
+	if len(inputs.GetLiterals()) == 0 {
+		return nil, fmt.Errorf("map node requires at least one collection input")
+	}
+
+	size := -1
+	for name, l := range inputs.GetLiterals() {
+		if !isCollection(l) {
+			return nil, fmt.Errorf("input [%s] of the map node is not a collection", name)
+		}
+
+		n := len(l.GetCollection().GetLiterals())
+		if size >= 0 && n != size {
+			return nil, fmt.Errorf("all inputs of a map node should be of the same length, input [%s] has [%d] items, expected [%d]", name, n, size)
+		}
+		size = n
+	}
+
+	items := make([]*core.LiteralMap, size)
+	for i := range items {
+		items[i] = &core.LiteralMap{Literals: make(map[string]*core.Literal, len(inputs.GetLiterals()))}
+		for name, l := range inputs.GetLiterals() {
+			items[i].Literals[name] = l.GetCollection().GetLiterals()[i]
+		}
+	}
+
+	return items, nil
+}
+
+func maxItemAttempts(node v1alpha1.ExecutableNode) uint32 {
+	if node.GetRetryStrategy() != nil && node.GetRetryStrategy().MinAttempts != nil && *node.GetRetryStrategy().MinAttempts > 0 {
+		return uint32(*node.GetRetryStrategy().MinAttempts)
+	}
+	return 1
+}
+
+// Number of items that may fail, before the map node as a whole is considered failed.
+func allowedFailures(total int, minSuccessRatio float32) int {
+	minSuccesses := int(math.Ceil(float64(total) * float64(minSuccessRatio)))
+	if minSuccesses > total {
+		minSuccesses = total
+	}
+	return total - minSuccesses
+}
+
+func itemDataDir(ctx context.Context, nCtx handler.NodeExecutionContext, index int) (v1alpha1.DataReference, error) {
+	return nCtx.DataStore().ConstructReference(ctx, nCtx.NodeStatus().GetOutputDir(), strconv.Itoa(index))
+}
+
+func itemOutputDir(ctx context.Context, nCtx handler.NodeExecutionContext, dataDir v1alpha1.DataReference, item v1alpha1.MapItemStatus) (v1alpha1.DataReference, error) {
+	return nCtx.DataStore().ConstructReference(ctx, dataDir, strconv.FormatUint(uint64(item.Attempts), 10))
+}
+
+func (m *mapNodeHandler) newItemExecContext(ctx context.Context, nCtx handler.NodeExecutionContext, index int, item v1alpha1.MapItemStatus) (itemExecContext, error) {
+	dataDir, err := itemDataDir(ctx, nCtx, index)
+	if err != nil {
+		return itemExecContext{}, err
+	}
+
+	outputDir, err := itemOutputDir(ctx, nCtx, dataDir, item)
+	if err != nil {
+		return itemExecContext{}, err
+	}
+
+	inputs := ioutils.NewCachedInputReader(ctx, ioutils.NewRemoteFileInputReader(ctx, nCtx.DataStore(),
+		ioutils.NewInputFilePaths(ctx, nCtx.DataStore(), dataDir)))
+
+	return newItemExecContext(nCtx, index, item, inputs, dataDir, outputDir), nil
+}
+
+// Drives a single running item one step forward and returns its updated status.
+func (m *mapNodeHandler) handleItem(ctx context.Context, nCtx handler.NodeExecutionContext, index int, item v1alpha1.MapItemStatus,
+	maxAttempts uint32) (v1alpha1.MapItemStatus, error) {
+
+	iCtx, err := m.newItemExecContext(ctx, nCtx, index, item)
+	if err != nil {
+		return item, err
+	}
+
+	trns, err := m.taskHandler.Handle(ctx, iCtx)
+	if err != nil {
+		return item, err
+	}
+
+	ts := iCtx.taskNodeState()
+	item.PluginPhase = int(ts.PluginPhase)
+	item.PluginPhaseVersion = ts.PluginPhaseVersion
+	item.PluginState = ts.PluginState
+	item.PluginStateVersion = ts.PluginStateVersion
+	item.BarrierClockTick = ts.BarrierClockTick
+
+	p := trns.Info()
+	switch p.GetPhase() {
+	case handler.EPhaseSuccess:
+		m.metrics.itemsSucceeded.Inc(ctx)
+		item.Phase = v1alpha1.MapItemPhaseSucceeded
+		return item, m.taskHandler.Finalize(ctx, iCtx)
+	case handler.EPhaseRetryableFailure:
+		if err := m.abortItem(ctx, iCtx, "retrying"); err != nil {
+			return item, err
+		}
+
+		if item.Attempts+1 < maxAttempts {
+			m.metrics.itemRetries.Inc(ctx)
+			logger.Infof(ctx, "Map item [%d] failed, retrying. Error [%v]", index, p.GetErr())
+			return v1alpha1.MapItemStatus{
+				Phase:    v1alpha1.MapItemPhaseNotYetStarted,
+				Attempts: item.Attempts + 1,
+			}, nil
+		}
+
+		m.metrics.itemsFailed.Inc(ctx)
+		item.Phase = v1alpha1.MapItemPhaseFailed
+		return item, nil
+	case handler.EPhaseFailed, handler.EPhaseTimedout, handler.EPhaseSkip:
+		logger.Infof(ctx, "Map item [%d] failed. Error [%v]", index, p.GetErr())
+		m.metrics.itemsFailed.Inc(ctx)
+		item.Phase = v1alpha1.MapItemPhaseFailed
+		return item, m.taskHandler.Finalize(ctx, iCtx)
+	}
+
+	item.Phase = v1alpha1.MapItemPhaseRunning
+	return item, nil
+}
+
+func (m *mapNodeHandler) abortItem(ctx context.Context, iCtx itemExecContext, reason string) error {
+	if err := m.taskHandler.Abort(ctx, iCtx, reason); err != nil {
+		if finalizeErr := m.taskHandler.Finalize(ctx, iCtx); finalizeErr != nil {
+			return errors.ErrorCollection{Errors: []error{err, finalizeErr}}
+		}
+		return err
+	}
+
+	return m.taskHandler.Finalize(ctx, iCtx)
+}
+
+// Writes the inputs of an item, before it is started.
+func (m *mapNodeHandler) writeItemInputs(ctx context.Context, nCtx handler.NodeExecutionContext, index int, inputs *core.LiteralMap) error {
+	dataDir, err := itemDataDir(ctx, nCtx, index)
+	if err != nil {
+		return err
+	}
+
+	return nCtx.DataStore().WriteProtobuf(ctx, v1alpha1.GetInputsFile(dataDir), storage.Options{}, inputs)
+}
+
+// Collects the outputs of all items into a collection per output variable. Outputs of failed items are gathered as void.
+func (m *mapNodeHandler) gatherOutputs(ctx context.Context, nCtx handler.NodeExecutionContext, items []v1alpha1.MapItemStatus) (*core.LiteralMap, error) {
+	tk, err := nCtx.TaskReader().Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := &core.LiteralMap{Literals: map[string]*core.Literal{}}
+	vars := tk.GetInterface().GetOutputs().GetVariables()
+	for name := range vars {
+		outputs.Literals[name] = &core.Literal{
+			Value: &core.Literal_Collection{
+				Collection: &core.LiteralCollection{Literals: make([]*core.Literal, len(items))},
+			},
+		}
+	}
+
+	for i, item := range items {
+		itemOutputs := &core.LiteralMap{}
+		if item.Phase == v1alpha1.MapItemPhaseSucceeded && len(vars) > 0 {
+			dataDir, err := itemDataDir(ctx, nCtx, i)
+			if err != nil {
+				return nil, err
+			}
+
+			outputDir, err := itemOutputDir(ctx, nCtx, dataDir, item)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := nCtx.DataStore().ReadProtobuf(ctx, v1alpha1.GetOutputsFile(outputDir), itemOutputs); err != nil {
+				return nil, err
+			}
+		}
+
+		for name := range vars {
+			l, ok := itemOutputs.GetLiterals()[name]
+			if !ok {
+				l = &core.Literal{Value: &core.Literal_Scalar{Scalar: &core.Scalar{Value: &core.Scalar_NoneType{NoneType: &core.Void{}}}}}
+			}
+			outputs.Literals[name].GetCollection().Literals[i] = l
+		}
+	}
+
+	return outputs, nil
+}
+
+func (m *mapNodeHandler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext) (handler.Transition, error) {
+	mapNode := nCtx.Node().GetMapNode()
+	if mapNode == nil {
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_SYSTEM, errors.IllegalStateError, "Invoked map handler, for a non map node.", nil)), nil
+	}
+
+	var itemInputs []*core.LiteralMap
+	readItemInputs := func() ([]*core.LiteralMap, error) {
+		if itemInputs != nil {
+			return itemInputs, nil
+		}
+
+		inputs, err := nCtx.InputReader().Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		itemInputs, err = splitInputs(inputs)
+		return itemInputs, err
+	}
+
+	state := nCtx.NodeStateReader().GetMapNodeState()
+	if state.Phase == v1alpha1.MapNodePhaseUndefined {
+		inputs, err := nCtx.InputReader().Get(ctx)
+		if err != nil {
+			errMsg := fmt.Sprintf("Failed to read input. Error [%s]", err)
+			return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_SYSTEM, errors.RuntimeExecutionError, errMsg, nil)), nil
+		}
+
+		itemInputs, err = splitInputs(inputs)
+		if err != nil {
+			return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_USER, errors.InvalidMapInputsError, err.Error(), nil)), nil
+		}
+
+		state = handler.MapNodeState{
+			Phase: v1alpha1.MapNodePhaseExecuting,
+			Items: make([]v1alpha1.MapItemStatus, len(itemInputs)),
+		}
+	}
+
+	// Items are owned by the node status, they should only be mutated on a copy
+	items := make([]v1alpha1.MapItemStatus, len(state.Items))
+	for i := range state.Items {
+		state.Items[i].DeepCopyInto(&items[i])
+	}
+
+	parallelism := int(mapNode.GetParallelism())
+	maxAttempts := maxItemAttempts(nCtx.Node())
+	running := 0
+	for _, item := range items {
+		if item.Phase == v1alpha1.MapItemPhaseRunning {
+			running++
+		}
+	}
+
+	for i := range items {
+		switch items[i].Phase {
+		case v1alpha1.MapItemPhaseNotYetStarted:
+			if parallelism > 0 && running >= parallelism {
+				continue
+			}
+
+			inputs, err := readItemInputs()
+			if err != nil {
+				return handler.UnknownTransition, err
+			}
+
+			if err := m.writeItemInputs(ctx, nCtx, i, inputs[i]); err != nil {
+				return handler.UnknownTransition, err
+			}
+
+			m.metrics.itemsStarted.Inc(ctx)
+			running++
+		case v1alpha1.MapItemPhaseRunning:
+		default:
+			continue
+		}
+
+		item, err := m.handleItem(ctx, nCtx, i, items[i], maxAttempts)
+		if err != nil {
+			return handler.UnknownTransition, err
+		}
+
+		if item.Phase != v1alpha1.MapItemPhaseRunning {
+			running--
+		}
+		items[i] = item
+	}
+
+	succeeded, failed := 0, 0
+	for _, item := range items {
+		switch item.Phase {
+		case v1alpha1.MapItemPhaseSucceeded:
+			succeeded++
+		case v1alpha1.MapItemPhaseFailed:
+			failed++
+		}
+	}
+
+	var phase handler.PhaseInfo
+	if failed > allowedFailures(len(items), mapNode.GetMinSuccessRatio()) {
+		for i := range items {
+			if items[i].Phase != v1alpha1.MapItemPhaseRunning {
+				continue
+			}
+
+			iCtx, err := m.newItemExecContext(ctx, nCtx, i, items[i])
+			if err != nil {
+				return handler.UnknownTransition, err
+			}
+
+			if err := m.abortItem(ctx, iCtx, "map node failed"); err != nil {
+				return handler.UnknownTransition, err
+			}
+			items[i].Phase = v1alpha1.MapItemPhaseFailed
+		}
+
+		errMsg := fmt.Sprintf("[%d/%d] items of the map node failed, min success ratio [%v] not met", failed, len(items), mapNode.GetMinSuccessRatio())
+		phase = handler.PhaseInfoFailure(core.ExecutionError_USER, errors.MapItemsFailedError, errMsg, nil)
+	} else if succeeded+failed == len(items) {
+		outputs, err := m.gatherOutputs(ctx, nCtx, items)
+		if err != nil {
+			return handler.UnknownTransition, err
+		}
+
+		outputFile := v1alpha1.GetOutputsFile(nCtx.NodeStatus().GetOutputDir())
+		if err := nCtx.DataStore().WriteProtobuf(ctx, outputFile, storage.Options{}, outputs); err != nil {
+			return handler.UnknownTransition, err
+		}
+
+		phase = handler.PhaseInfoSuccess(&handler.ExecutionInfo{
+			OutputInfo: &handler.OutputInfo{OutputURI: outputFile},
+		})
+	} else {
+		phase = handler.PhaseInfoRunning(nil)
+	}
+
+	state.Items = items
+	if err := nCtx.NodeStateWriter().PutMapNodeState(state); err != nil {
+		logger.Errorf(ctx, "Failed to store MapNode state, err :%s", err.Error())
+		return handler.UnknownTransition, err
+	}
+
+	return handler.DoTransition(handler.TransitionTypeEphemeral, phase), nil
+}
+
+// Calls fn for every item that has been started but has not completed yet.
+func (m *mapNodeHandler) visitActiveItems(ctx context.Context, nCtx handler.NodeExecutionContext, fn func(itemExecContext) error) error {
+	for i, item := range nCtx.NodeStateReader().GetMapNodeState().Items {
+		if item.Phase != v1alpha1.MapItemPhaseRunning {
+			continue
+		}
+
+		iCtx, err := m.newItemExecContext(ctx, nCtx, i, item)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(iCtx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *mapNodeHandler) Abort(ctx context.Context, nCtx handler.NodeExecutionContext, reason string) error {
+	return m.visitActiveItems(ctx, nCtx, func(iCtx itemExecContext) error {
+		return m.taskHandler.Abort(ctx, iCtx, reason)
+	})
+}
+
+func (m *mapNodeHandler) Finalize(ctx context.Context, nCtx handler.NodeExecutionContext) error {
+	return m.visitActiveItems(ctx, nCtx, func(iCtx itemExecContext) error {
+		return m.taskHandler.Finalize(ctx, iCtx)
+	})
+}
+
+func New(taskHandler handler.Node, scope promutils.Scope) handler.Node {
+	mapScope := scope.NewSubScope("map")
+	return &mapNodeHandler{
+		taskHandler: taskHandler,
+		metrics: metrics{
+			scope:          mapScope,
+			itemsStarted:   labeled.NewCounter("items_started", "Number of map items started", mapScope),
+			itemsSucceeded: labeled.NewCounter("items_succeeded", "Number of map items that succeeded", mapScope),
+			itemsFailed:    labeled.NewCounter("items_failed", "Number of map items that failed after exhausting retries", mapScope),
+			itemRetries:    labeled.NewCounter("item_retries", "Number of map item retries", mapScope),
+		},
+	}
+}
@@ -0,0 +1,388 @@
+package mapnode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	ioMocks "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/io/mocks"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler/mocks"
+	"github.com/lyft/flytepropeller/pkg/utils"
+)
+
+func init() {
+	labeled.SetMetricKeys(contextutils.NodeIDKey)
+}
+
+type mapNodeStateHolder struct {
+	m handler.MapNodeState
+}
+
+func (t *mapNodeStateHolder) PutTaskNodeState(s handler.TaskNodeState) error {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) PutBranchNode(s handler.BranchNodeState) error {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) PutDynamicNodeState(s handler.DynamicNodeState) error {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) PutWorkflowNodeState(s handler.WorkflowNodeState) error {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) PutMapNodeState(s handler.MapNodeState) error {
+	t.m = s
+	return nil
+}
+
+func (t *mapNodeStateHolder) GetTaskNodeState() handler.TaskNodeState {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) GetBranchNode() handler.BranchNodeState {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) GetDynamicNodeState() handler.DynamicNodeState {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) GetWorkflowNodeState() handler.WorkflowNodeState {
+	panic("not implemented")
+}
+
+func (t *mapNodeStateHolder) GetMapNodeState() handler.MapNodeState {
+	return t.m
+}
+
+// A task handler that doubles its input "x" into the output "y". Every item runs for one round before it completes.
+// Items with x == failValue fail, for failAttempts attempts.
+type fakeTaskHandler struct {
+	failValue    int64
+	failAttempts uint32
+	retryable    bool
+	aborted      []v1alpha1.NodeID
+}
+
+func (f *fakeTaskHandler) FinalizeRequired() bool {
+	return true
+}
+
+func (f *fakeTaskHandler) Setup(ctx context.Context, setupContext handler.SetupContext) error {
+	return nil
+}
+
+func (f *fakeTaskHandler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext) (handler.Transition, error) {
+	ts := nCtx.NodeStateReader().GetTaskNodeState()
+	if ts.PluginPhaseVersion == 0 {
+		ts.PluginPhaseVersion = 1
+		if err := nCtx.NodeStateWriter().PutTaskNodeState(ts); err != nil {
+			return handler.UnknownTransition, err
+		}
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoRunning(nil)), nil
+	}
+
+	inputs, err := nCtx.InputReader().Get(ctx)
+	if err != nil {
+		return handler.UnknownTransition, err
+	}
+
+	x := inputs.Literals["x"].GetScalar().GetPrimitive().GetInteger()
+	if x == f.failValue && nCtx.CurrentAttempt() < f.failAttempts {
+		if f.retryable {
+			return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoRetryableFailure(core.ExecutionError_USER, "x", "failed", nil)), nil
+		}
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_USER, "x", "failed", nil)), nil
+	}
+
+	outputs := &core.LiteralMap{Literals: map[string]*core.Literal{"y": utils.MustMakeLiteral(x * 2)}}
+	if err := nCtx.DataStore().WriteProtobuf(ctx, v1alpha1.GetOutputsFile(nCtx.NodeStatus().GetOutputDir()), storage.Options{}, outputs); err != nil {
+		return handler.UnknownTransition, err
+	}
+
+	return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoSuccess(nil)), nil
+}
+
+func (f *fakeTaskHandler) Abort(ctx context.Context, nCtx handler.NodeExecutionContext, reason string) error {
+	f.aborted = append(f.aborted, nCtx.NodeID())
+	return nil
+}
+
+func (f *fakeTaskHandler) Finalize(ctx context.Context, nCtx handler.NodeExecutionContext) error {
+	return nil
+}
+
+func createNodeContext(t *testing.T, n v1alpha1.ExecutableNode, state *mapNodeStateHolder, dataStore *storage.DataStore, xs interface{}) *mocks.NodeExecutionContext {
+	nCtx := &mocks.NodeExecutionContext{}
+	nCtx.OnNode().Return(n)
+	nCtx.OnNodeID().Return(n.GetID())
+	nCtx.OnDataStore().Return(dataStore)
+	nCtx.OnNodeStateReader().Return(state)
+	nCtx.OnNodeStateWriter().Return(state)
+	nCtx.OnNodeStatus().Return(&v1alpha1.NodeStatus{OutputDir: "s3://bucket/n/data/0"})
+
+	nm := &mocks.NodeExecutionMetadata{}
+	nm.OnGetNodeExecutionID().Return(&core.NodeExecutionIdentifier{NodeId: n.GetID()})
+	nCtx.OnNodeExecutionMetadata().Return(nm)
+
+	ir := &ioMocks.InputReader{}
+	inputs, err := utils.MakeLiteralMap(map[string]interface{}{"x": xs})
+	assert.NoError(t, err)
+	ir.OnGetMatch(mock.Anything).Return(inputs, nil)
+	nCtx.OnInputReader().Return(ir)
+
+	tr := &mocks.TaskReader{}
+	tr.OnReadMatch(mock.Anything).Return(&core.TaskTemplate{
+		Interface: &core.TypedInterface{
+			Outputs: &core.VariableMap{
+				Variables: map[string]*core.Variable{
+					"y": {Type: &core.LiteralType{Type: &core.LiteralType_Simple{Simple: core.SimpleType_INTEGER}}},
+				},
+			},
+		},
+	}, nil)
+	nCtx.OnTaskReader().Return(tr)
+	return nCtx
+}
+
+func newMapNode(parallelism uint32, minSuccessRatio *float32, minAttempts *int) *v1alpha1.NodeSpec {
+	n := &v1alpha1.NodeSpec{
+		ID:   "n",
+		Kind: v1alpha1.NodeKindMap,
+		MapNode: &v1alpha1.MapNodeSpec{
+			Parallelism:     parallelism,
+			MinSuccessRatio: minSuccessRatio,
+		},
+	}
+
+	if minAttempts != nil {
+		n.RetryStrategy = &v1alpha1.RetryStrategy{MinAttempts: minAttempts}
+	}
+
+	return n
+}
+
+func readOutputs(t *testing.T, dataStore *storage.DataStore) []*core.Literal {
+	outputs := &core.LiteralMap{}
+	assert.NoError(t, dataStore.ReadProtobuf(context.TODO(), "s3://bucket/n/data/0/outputs.pb", outputs))
+	return outputs.Literals["y"].GetCollection().GetLiterals()
+}
+
+func TestSplitInputs(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		inputs, err := utils.MakeLiteralMap(map[string]interface{}{"x": []interface{}{1, 2}, "y": []interface{}{"a", "b"}})
+		assert.NoError(t, err)
+		items, err := splitInputs(inputs)
+		assert.NoError(t, err)
+		assert.Len(t, items, 2)
+		assert.Equal(t, int64(2), items[1].Literals["x"].GetScalar().GetPrimitive().GetInteger())
+		assert.Equal(t, "b", items[1].Literals["y"].GetScalar().GetPrimitive().GetStringValue())
+	})
+
+	t.Run("length-mismatch", func(t *testing.T) {
+		inputs, err := utils.MakeLiteralMap(map[string]interface{}{"x": []interface{}{1, 2}, "y": []interface{}{"a"}})
+		assert.NoError(t, err)
+		_, err = splitInputs(inputs)
+		assert.Error(t, err)
+	})
+
+	t.Run("not-a-collection", func(t *testing.T) {
+		inputs, err := utils.MakeLiteralMap(map[string]interface{}{"x": 1})
+		assert.NoError(t, err)
+		_, err = splitInputs(inputs)
+		assert.Error(t, err)
+	})
+
+	t.Run("no-inputs", func(t *testing.T) {
+		_, err := splitInputs(&core.LiteralMap{})
+		assert.Error(t, err)
+	})
+}
+
+func TestAllowedFailures(t *testing.T) {
+	assert.Equal(t, 0, allowedFailures(10, 1.0))
+	assert.Equal(t, 5, allowedFailures(10, 0.5))
+	assert.Equal(t, 3, allowedFailures(10, 0.65))
+	assert.Equal(t, 10, allowedFailures(10, 0))
+	assert.Equal(t, 0, allowedFailures(0, 1.0))
+}
+
+func TestMapNodeHandler_Handle(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("all-succeed", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		nCtx := createNodeContext(t, newMapNode(0, nil, nil), state, dataStore, []interface{}{1, 2, 3})
+		h := New(&fakeTaskHandler{failValue: -1}, promutils.NewTestScope())
+
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+		assert.Len(t, state.m.Items, 3)
+		for _, item := range state.m.Items {
+			assert.Equal(t, v1alpha1.MapItemPhaseRunning, item.Phase)
+		}
+
+		trns, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		outputs := readOutputs(t, dataStore)
+		if assert.Len(t, outputs, 3) {
+			assert.Equal(t, int64(2), outputs[0].GetScalar().GetPrimitive().GetInteger())
+			assert.Equal(t, int64(4), outputs[1].GetScalar().GetPrimitive().GetInteger())
+			assert.Equal(t, int64(6), outputs[2].GetScalar().GetPrimitive().GetInteger())
+		}
+	})
+
+	t.Run("parallelism", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		nCtx := createNodeContext(t, newMapNode(1, nil, nil), state, dataStore, []interface{}{1, 2})
+		h := New(&fakeTaskHandler{failValue: -1}, promutils.NewTestScope())
+
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+		assert.Equal(t, v1alpha1.MapItemPhaseRunning, state.m.Items[0].Phase)
+		assert.Equal(t, v1alpha1.MapItemPhaseNotYetStarted, state.m.Items[1].Phase)
+
+		trns, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+		assert.Equal(t, v1alpha1.MapItemPhaseSucceeded, state.m.Items[0].Phase)
+		assert.Equal(t, v1alpha1.MapItemPhaseRunning, state.m.Items[1].Phase)
+
+		trns, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		assert.Len(t, readOutputs(t, dataStore), 2)
+	})
+
+	t.Run("min-success-ratio-met", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		ratio := float32(0.5)
+		nCtx := createNodeContext(t, newMapNode(0, &ratio, nil), state, dataStore, []interface{}{1, 2})
+		h := New(&fakeTaskHandler{failValue: 2, failAttempts: 1}, promutils.NewTestScope())
+
+		_, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		outputs := readOutputs(t, dataStore)
+		if assert.Len(t, outputs, 2) {
+			assert.Equal(t, int64(2), outputs[0].GetScalar().GetPrimitive().GetInteger())
+			assert.NotNil(t, outputs[1].GetScalar().GetNoneType())
+		}
+	})
+
+	t.Run("min-success-ratio-not-met", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		nCtx := createNodeContext(t, newMapNode(1, nil, nil), state, dataStore, []interface{}{2, 1})
+		taskHandler := &fakeTaskHandler{failValue: 2, failAttempts: 1}
+		h := New(taskHandler, promutils.NewTestScope())
+
+		_, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseFailed, trns.Info().GetPhase())
+		assert.Equal(t, "MapItemsFailed", trns.Info().GetErr().Code)
+		// The second item was started in the same round and is aborted
+		assert.Equal(t, []v1alpha1.NodeID{"n-1"}, taskHandler.aborted)
+		assert.Equal(t, v1alpha1.MapItemPhaseFailed, state.m.Items[1].Phase)
+	})
+
+	t.Run("item-retried", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		attempts := 2
+		nCtx := createNodeContext(t, newMapNode(0, nil, &attempts), state, dataStore, []interface{}{1})
+		h := New(&fakeTaskHandler{failValue: 1, failAttempts: 1, retryable: true}, promutils.NewTestScope())
+
+		_, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+		assert.Equal(t, v1alpha1.MapItemPhaseNotYetStarted, state.m.Items[0].Phase)
+		assert.Equal(t, uint32(1), state.m.Items[0].Attempts)
+
+		_, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		trns, err = h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		outputs := readOutputs(t, dataStore)
+		if assert.Len(t, outputs, 1) {
+			assert.Equal(t, int64(2), outputs[0].GetScalar().GetPrimitive().GetInteger())
+		}
+	})
+
+	t.Run("invalid-inputs", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		nCtx := createNodeContext(t, newMapNode(0, nil, nil), state, dataStore, 1)
+		h := New(&fakeTaskHandler{}, promutils.NewTestScope())
+
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseFailed, trns.Info().GetPhase())
+		assert.Equal(t, "InvalidMapInputs", trns.Info().GetErr().Code)
+	})
+
+	t.Run("empty-inputs", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		state := &mapNodeStateHolder{}
+		nCtx := createNodeContext(t, newMapNode(0, nil, nil), state, dataStore, []interface{}{})
+		h := New(&fakeTaskHandler{}, promutils.NewTestScope())
+
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		assert.Len(t, readOutputs(t, dataStore), 0)
+	})
+}
+
+func TestMapNodeHandler_Abort(t *testing.T) {
+	ctx := context.TODO()
+	dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+	assert.NoError(t, err)
+	state := &mapNodeStateHolder{m: handler.MapNodeState{
+		Phase: v1alpha1.MapNodePhaseExecuting,
+		Items: []v1alpha1.MapItemStatus{
+			{Phase: v1alpha1.MapItemPhaseSucceeded},
+			{Phase: v1alpha1.MapItemPhaseRunning},
+			{Phase: v1alpha1.MapItemPhaseNotYetStarted},
+		},
+	}}
+	nCtx := createNodeContext(t, newMapNode(0, nil, nil), state, dataStore, []interface{}{1, 2, 3})
+	taskHandler := &fakeTaskHandler{}
+	h := New(taskHandler, promutils.NewTestScope())
+
+	assert.NoError(t, h.Abort(ctx, nCtx, "aborted"))
+	assert.Equal(t, []v1alpha1.NodeID{"n-1"}, taskHandler.aborted)
+}
@@ -0,0 +1,163 @@
+package mapnode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lyft/flyteidl/clients/go/events"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/event"
+	pluginCore "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/core"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/io"
+	"github.com/pkg/errors"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+)
+
+// Task events of individual items are not recorded. The items do not exist as node executions in admin, the progress
+// of the map node is reported through the events of the map node itself.
+type nopTaskEventRecorder struct{}
+
+func (nopTaskEventRecorder) RecordTaskEvent(_ context.Context, _ *event.TaskExecutionEvent) error {
+	return nil
+}
+
+type itemExecMetadata struct {
+	handler.NodeExecutionMetadata
+	nodeExecID *core.NodeExecutionIdentifier
+}
+
+func (m itemExecMetadata) GetNodeExecutionID() *core.NodeExecutionIdentifier {
+	return m.nodeExecID
+}
+
+// Holds the task state of a single item. Items only ever run tasks, all other node states are unsupported.
+type itemStateManager struct {
+	t handler.TaskNodeState
+}
+
+func (s *itemStateManager) PutTaskNodeState(t handler.TaskNodeState) error {
+	s.t = t
+	return nil
+}
+
+func (s *itemStateManager) PutBranchNode(handler.BranchNodeState) error {
+	return errors.New("branch node state is not supported for map items")
+}
+
+func (s *itemStateManager) PutDynamicNodeState(handler.DynamicNodeState) error {
+	return errors.New("dynamic node state is not supported for map items")
+}
+
+func (s *itemStateManager) PutWorkflowNodeState(handler.WorkflowNodeState) error {
+	return errors.New("workflow node state is not supported for map items")
+}
+
+func (s *itemStateManager) PutMapNodeState(handler.MapNodeState) error {
+	return errors.New("map node state is not supported for map items")
+}
+
+func (s *itemStateManager) GetTaskNodeState() handler.TaskNodeState {
+	return s.t
+}
+
+func (s *itemStateManager) GetBranchNode() handler.BranchNodeState {
+	return handler.BranchNodeState{}
+}
+
+func (s *itemStateManager) GetDynamicNodeState() handler.DynamicNodeState {
+	return handler.DynamicNodeState{}
+}
+
+func (s *itemStateManager) GetWorkflowNodeState() handler.WorkflowNodeState {
+	return handler.WorkflowNodeState{}
+}
+
+func (s *itemStateManager) GetMapNodeState() handler.MapNodeState {
+	return handler.MapNodeState{}
+}
+
+// The execution context for a single item of a map node. It looks like a task node to the task handler, with its own
+// node ID (used to generate unique resource names), inputs, output location, attempts and task state.
+type itemExecContext struct {
+	handler.NodeExecutionContext
+	nodeID     v1alpha1.NodeID
+	md         itemExecMetadata
+	inputs     io.InputReader
+	nodeStatus *v1alpha1.NodeStatus
+	state      *itemStateManager
+}
+
+func (i itemExecContext) NodeID() v1alpha1.NodeID {
+	return i.nodeID
+}
+
+func (i itemExecContext) NodeExecutionMetadata() handler.NodeExecutionMetadata {
+	return i.md
+}
+
+func (i itemExecContext) InputReader() io.InputReader {
+	return i.inputs
+}
+
+func (i itemExecContext) EventsRecorder() events.TaskEventRecorder {
+	return nopTaskEventRecorder{}
+}
+
+func (i itemExecContext) CurrentAttempt() uint32 {
+	return i.nodeStatus.GetAttempts()
+}
+
+func (i itemExecContext) NodeStatus() v1alpha1.ExecutableNodeStatus {
+	return i.nodeStatus
+}
+
+func (i itemExecContext) NodeStateReader() handler.NodeStateReader {
+	return i.state
+}
+
+func (i itemExecContext) NodeStateWriter() handler.NodeStateWriter {
+	return i.state
+}
+
+func (i itemExecContext) taskNodeState() handler.TaskNodeState {
+	return i.state.t
+}
+
+func itemNodeID(nodeID v1alpha1.NodeID, index int) v1alpha1.NodeID {
+	return fmt.Sprintf("%s-%d", nodeID, index)
+}
+
+func newItemExecContext(nCtx handler.NodeExecutionContext, index int, item v1alpha1.MapItemStatus,
+	inputs io.InputReader, dataDir, outputDir v1alpha1.DataReference) itemExecContext {
+
+	nodeID := itemNodeID(nCtx.NodeID(), index)
+	parentExecID := nCtx.NodeExecutionMetadata().GetNodeExecutionID()
+	return itemExecContext{
+		NodeExecutionContext: nCtx,
+		nodeID:               nodeID,
+		md: itemExecMetadata{
+			NodeExecutionMetadata: nCtx.NodeExecutionMetadata(),
+			nodeExecID: &core.NodeExecutionIdentifier{
+				NodeId:      nodeID,
+				ExecutionId: parentExecID.GetExecutionId(),
+			},
+		},
+		inputs: inputs,
+		nodeStatus: &v1alpha1.NodeStatus{
+			DataDir:   dataDir,
+			OutputDir: outputDir,
+			Attempts:  item.Attempts,
+		},
+		state: &itemStateManager{
+			t: handler.TaskNodeState{
+				PluginPhase:        pluginCore.Phase(item.PluginPhase),
+				PluginPhaseVersion: item.PluginPhaseVersion,
+				PluginState:        item.PluginState,
+				PluginStateVersion: item.PluginStateVersion,
+				BarrierClockTick:   item.BarrierClockTick,
+			},
+		},
+	}
+}
@@ -182,7 +182,7 @@ func (c *nodeExecutor) newNodeExecContextDefault(ctx context.Context, currentNod
 	}
 
 	var tr handler.TaskReader
-	if n.GetKind() == v1alpha1.NodeKindTask {
+	if n.GetKind() == v1alpha1.NodeKindTask || n.GetKind() == v1alpha1.NodeKindMap {
 		if n.GetTaskID() == nil {
 			return nil, fmt.Errorf("bad state, no task-id defined for node [%s]", n.GetID())
 		}
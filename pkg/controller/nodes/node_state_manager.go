@@ -15,6 +15,7 @@ type nodeStateManager struct {
 	b          *handler.BranchNodeState
 	d          *handler.DynamicNodeState
 	w          *handler.WorkflowNodeState
+	m          *handler.MapNodeState
 }
 
 func (n *nodeStateManager) PutTaskNodeState(s handler.TaskNodeState) error {
@@ -37,6 +38,11 @@ func (n *nodeStateManager) PutWorkflowNodeState(s handler.WorkflowNodeState) err
 	return nil
 }
 
+func (n *nodeStateManager) PutMapNodeState(s handler.MapNodeState) error {
+	n.m = &s
+	return nil
+}
+
 func (n nodeStateManager) GetTaskNodeState() handler.TaskNodeState {
 	tn := n.nodeStatus.GetTaskNodeStatus()
 	if tn != nil {
@@ -83,11 +89,22 @@ func (n nodeStateManager) GetWorkflowNodeState() handler.WorkflowNodeState {
 	return ws
 }
 
+func (n nodeStateManager) GetMapNodeState() handler.MapNodeState {
+	mn := n.nodeStatus.GetMapNodeStatus()
+	ms := handler.MapNodeState{}
+	if mn != nil {
+		ms.Phase = mn.GetMapNodePhase()
+		ms.Items = mn.GetItems()
+	}
+	return ms
+}
+
 func (n nodeStateManager) clearNodeStatus() {
 	n.t = nil
 	n.b = nil
 	n.d = nil
 	n.w = nil
+	n.m = nil
 	n.nodeStatus.ClearLastAttemptStartedAt()
 }
 
@@ -2,6 +2,7 @@ package nodes
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/lyft/flytestdlib/logger"
@@ -20,7 +21,8 @@ const (
 	PredicatePhaseNotReady PredicatePhase = iota
 	// Indicates node is ready to be executed - execution should proceed
 	PredicatePhaseReady
-	// Indicates that the node execution should be skipped as one of its parents was skipped or the branch was not taken
+	// Indicates that the node execution should be skipped as its trigger rule can no longer be satisfied, e.g. one of its
+	// parents was skipped or the branch was not taken
 	PredicatePhaseSkip
 	// Indicates failure during Predicate check
 	PredicatePhaseUndefined
@@ -38,7 +40,92 @@ func (p PredicatePhase) String() string {
 	return "undefined"
 }
 
-func CanExecute(ctx context.Context, dag executors.DAGStructure, nl executors.NodeLookup, node v1alpha1.BaseNode) (PredicatePhase, error) {
+// Counts of the phases of the upstream nodes of a node, used to evaluate its trigger rule
+type upstreamPhases struct {
+	total     int
+	succeeded int
+	failed    int
+	skipped   int
+}
+
+func (u upstreamPhases) done() bool {
+	return u.succeeded+u.failed+u.skipped == u.total
+}
+
+// Evaluates the trigger rule of a node given the phases of its upstream nodes
+func evaluateTriggerRule(rule v1alpha1.TriggerRule, u upstreamPhases) (PredicatePhase, error) {
+	switch rule {
+	case v1alpha1.TriggerRuleAllSuccess:
+		if u.done() && u.succeeded == u.total {
+			return PredicatePhaseReady, nil
+		} else if u.done() {
+			return PredicatePhaseSkip, nil
+		}
+	case v1alpha1.TriggerRuleAllDone:
+		if u.done() {
+			return PredicatePhaseReady, nil
+		}
+	case v1alpha1.TriggerRuleOneFailed:
+		if u.failed > 0 {
+			return PredicatePhaseReady, nil
+		} else if u.done() {
+			return PredicatePhaseSkip, nil
+		}
+	case v1alpha1.TriggerRuleOneSuccess:
+		if u.succeeded > 0 {
+			return PredicatePhaseReady, nil
+		} else if u.done() {
+			return PredicatePhaseSkip, nil
+		}
+	case v1alpha1.TriggerRuleNoneFailed:
+		if u.done() && u.failed == 0 {
+			return PredicatePhaseReady, nil
+		} else if u.done() {
+			return PredicatePhaseSkip, nil
+		}
+	default:
+		return PredicatePhaseUndefined, fmt.Errorf("unknown trigger rule [%v]", rule)
+	}
+
+	return PredicatePhaseNotReady, nil
+}
+
+// Returns true if the skipped node was skipped because a trigger rule was not met, rather than because of a failure.
+// That is the case for nodes with a trigger rule other than all_success, and for the nodes that were skipped because
+// all their skipped upstream nodes were, e.g. a notification node downstream of a cleanup node that only runs on
+// failures.
+func isSkippedByTriggerRule(ctx context.Context, dag executors.DAGStructure, nl executors.NodeLookup, nodeID v1alpha1.NodeID) bool {
+	n, ok := nl.GetNode(nodeID)
+	if !ok {
+		return false
+	}
+
+	if n.GetTriggerRule() != v1alpha1.TriggerRuleAllSuccess {
+		return true
+	}
+
+	upstreamNodes, err := dag.ToNode(nodeID)
+	if err != nil {
+		return false
+	}
+
+	skipped := false
+	for _, upstreamNodeID := range upstreamNodes {
+		switch nl.GetNodeExecutionStatus(ctx, upstreamNodeID).GetPhase() {
+		case v1alpha1.NodePhaseFailed, v1alpha1.NodePhaseTimedOut:
+			return false
+		case v1alpha1.NodePhaseSkipped:
+			if !isSkippedByTriggerRule(ctx, dag, nl, upstreamNodeID) {
+				return false
+			}
+			skipped = true
+		}
+	}
+
+	return skipped
+}
+
+func CanExecute(ctx context.Context, dag executors.DAGStructure, nl executors.NodeLookup, node v1alpha1.ExecutableNode) (PredicatePhase, error) {
 	nodeID := node.GetID()
 	if nodeID == v1alpha1.StartNodeID {
 		logger.Debugf(ctx, "Start Node id is assumed to be ready.")
@@ -52,7 +139,7 @@ func CanExecute(ctx context.Context, dag executors.DAGStructure, nl executors.No
 		return PredicatePhaseUndefined, errors.Errorf(errors.BadSpecificationError, nodeID, "Unable to find upstream nodes for Node")
 	}
 
-	skipped := false
+	phases := upstreamPhases{}
 	for _, upstreamNodeID := range upstreamNodes {
 		upstreamNodeStatus := nl.GetNodeExecutionStatus(ctx, upstreamNodeID)
 
@@ -75,20 +162,29 @@ func CanExecute(ctx context.Context, dag executors.DAGStructure, nl executors.No
 			continue
 		}
 
-		if upstreamNodeStatus.GetPhase() == v1alpha1.NodePhaseSkipped ||
-			upstreamNodeStatus.GetPhase() == v1alpha1.NodePhaseFailed ||
-			upstreamNodeStatus.GetPhase() == v1alpha1.NodePhaseTimedOut {
-			skipped = true
-		} else if upstreamNodeStatus.GetPhase() != v1alpha1.NodePhaseSucceeded {
-			return PredicatePhaseNotReady, nil
+		phases.total++
+		switch upstreamNodeStatus.GetPhase() {
+		case v1alpha1.NodePhaseSucceeded:
+			phases.succeeded++
+		case v1alpha1.NodePhaseFailed, v1alpha1.NodePhaseTimedOut:
+			phases.failed++
+		case v1alpha1.NodePhaseSkipped:
+			if nodeID == v1alpha1.EndNodeID && isSkippedByTriggerRule(ctx, dag, nl, upstreamNodeID) {
+				// Nodes with a trigger rule are expected to be skipped when their rule is not met, e.g. a cleanup node
+				// after a successful run. The workflow still completes, failures are propagated by the failed nodes.
+				phases.succeeded++
+			} else {
+				phases.skipped++
+			}
 		}
 	}
 
-	if skipped {
-		return PredicatePhaseSkip, nil
+	p, err := evaluateTriggerRule(node.GetTriggerRule(), phases)
+	if err != nil {
+		return PredicatePhaseUndefined, errors.Wrapf(errors.BadSpecificationError, nodeID, err, "Unable to evaluate trigger rule")
 	}
 
-	return PredicatePhaseReady, nil
+	return p, nil
 }
 
 func GetParentNodeMaxEndTime(ctx context.Context, dag executors.DAGStructure, nl executors.NodeLookup, node v1alpha1.BaseNode) (t v1.Time, err error) {
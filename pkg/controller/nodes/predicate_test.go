@@ -20,7 +20,8 @@ func TestCanExecute(t *testing.T) {
 	// Table tests are not really helpful here, so we decided against it
 
 	t.Run("startNode", func(t *testing.T) {
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(v1alpha1.StartNodeID)
 		p, err := CanExecute(ctx, nil, nil, mockNode)
 		assert.NoError(t, err)
@@ -32,7 +33,8 @@ func TestCanExecute(t *testing.T) {
 		mockNodeStatus := &mocks.ExecutableNodeStatus{}
 		// No parent node
 		mockNodeStatus.OnGetParentNodeID().Return(nil)
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.OnGetID().Return(nodeN2)
 		mockWf := &mocks.ExecutableWorkflow{}
 		mockWf.OnGetNodeExecutionStatus(ctx, nodeN2).Return(mockNodeStatus)
@@ -50,7 +52,8 @@ func TestCanExecute(t *testing.T) {
 		// No parent node
 		mockN2Status.OnGetParentNodeID().Return(nil)
 		mockN2Status.OnIsDirty().Return(false)
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.OnGetID().Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -80,7 +83,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -110,7 +114,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -141,7 +146,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -171,7 +177,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -201,7 +208,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -231,7 +239,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -262,7 +271,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(nil)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -295,7 +305,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Status := &mocks.ExecutableNodeStatus{}
@@ -327,7 +338,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0Node := &mocks.ExecutableNode{}
@@ -361,7 +373,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0BranchStatus := &mocks.MutableBranchNodeStatus{}
@@ -399,7 +412,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0BranchStatus := &mocks.MutableBranchNodeStatus{}
@@ -437,7 +451,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0BranchStatus := &mocks.MutableBranchNodeStatus{}
@@ -476,7 +491,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0BranchStatus := &mocks.MutableBranchNodeStatus{}
@@ -515,7 +531,8 @@ func TestCanExecute(t *testing.T) {
 		mockN2Status.On("GetParentNodeID").Return(&nodeN0)
 		mockN2Status.On("IsDirty").Return(false)
 
-		mockNode := &mocks.BaseNode{}
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
 		mockNode.On("GetID").Return(nodeN2)
 
 		mockN0BranchStatus := &mocks.MutableBranchNodeStatus{}
@@ -546,3 +563,122 @@ func TestCanExecute(t *testing.T) {
 		assert.Equal(t, PredicatePhaseNotReady, p)
 	})
 }
+
+func TestCanExecute_TriggerRules(t *testing.T) {
+	ctx := context.Background()
+	nodeN2 := "n2"
+
+	canExecute := func(t *testing.T, rule v1alpha1.TriggerRule, upstream ...v1alpha1.NodePhase) PredicatePhase {
+		mockN2Status := &mocks.ExecutableNodeStatus{}
+		mockN2Status.OnGetParentNodeID().Return(nil)
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetID().Return(nodeN2)
+		mockNode.OnGetTriggerRule().Return(rule)
+
+		mockWf := &mocks.ExecutableWorkflow{}
+		mockWf.OnGetNodeExecutionStatus(ctx, nodeN2).Return(mockN2Status)
+		upstreamIDs := make([]v1alpha1.NodeID, 0, len(upstream))
+		for i, phase := range upstream {
+			id := fmt.Sprintf("u%d", i)
+			upstreamIDs = append(upstreamIDs, id)
+			s := &mocks.ExecutableNodeStatus{}
+			s.OnGetPhase().Return(phase)
+			s.OnIsDirty().Return(false)
+			mockWf.OnGetNodeExecutionStatus(ctx, id).Return(s)
+		}
+		mockWf.OnToNode(nodeN2).Return(upstreamIDs, nil)
+
+		p, err := CanExecute(ctx, mockWf, mockWf, mockNode)
+		assert.NoError(t, err)
+		return p
+	}
+
+	succeeded := v1alpha1.NodePhaseSucceeded
+	failed := v1alpha1.NodePhaseFailed
+	timedOut := v1alpha1.NodePhaseTimedOut
+	skipped := v1alpha1.NodePhaseSkipped
+	running := v1alpha1.NodePhaseRunning
+
+	t.Run("allSuccess", func(t *testing.T) {
+		assert.Equal(t, PredicatePhaseReady, canExecute(t, v1alpha1.TriggerRuleAllSuccess, succeeded, succeeded))
+		assert.Equal(t, PredicatePhaseNotReady, canExecute(t, v1alpha1.TriggerRuleAllSuccess, failed, running))
+		assert.Equal(t, PredicatePhaseSkip, canExecute(t, v1alpha1.TriggerRuleAllSuccess, succeeded, skipped))
+	})
+
+	t.Run("allDone", func(t *testing.T) {
+		assert.Equal(t, PredicatePhaseReady, canExecute(t, v1alpha1.TriggerRuleAllDone, failed, skipped, timedOut))
+		assert.Equal(t, PredicatePhaseNotReady, canExecute(t, v1alpha1.TriggerRuleAllDone, failed, running))
+	})
+
+	t.Run("oneFailed", func(t *testing.T) {
+		assert.Equal(t, PredicatePhaseReady, canExecute(t, v1alpha1.TriggerRuleOneFailed, running, timedOut))
+		assert.Equal(t, PredicatePhaseNotReady, canExecute(t, v1alpha1.TriggerRuleOneFailed, succeeded, running))
+		assert.Equal(t, PredicatePhaseSkip, canExecute(t, v1alpha1.TriggerRuleOneFailed, succeeded, skipped))
+	})
+
+	t.Run("oneSuccess", func(t *testing.T) {
+		assert.Equal(t, PredicatePhaseReady, canExecute(t, v1alpha1.TriggerRuleOneSuccess, running, succeeded))
+		assert.Equal(t, PredicatePhaseNotReady, canExecute(t, v1alpha1.TriggerRuleOneSuccess, failed, running))
+		assert.Equal(t, PredicatePhaseSkip, canExecute(t, v1alpha1.TriggerRuleOneSuccess, failed, skipped))
+	})
+
+	t.Run("noneFailed", func(t *testing.T) {
+		assert.Equal(t, PredicatePhaseReady, canExecute(t, v1alpha1.TriggerRuleNoneFailed, succeeded, skipped))
+		assert.Equal(t, PredicatePhaseNotReady, canExecute(t, v1alpha1.TriggerRuleNoneFailed, failed, running))
+		assert.Equal(t, PredicatePhaseSkip, canExecute(t, v1alpha1.TriggerRuleNoneFailed, succeeded, failed))
+	})
+
+	t.Run("endNode", func(t *testing.T) {
+		// The end node runs after n1 and cleanup, which was skipped and runs after check
+		canExecuteEnd := func(t *testing.T, skippedRule v1alpha1.TriggerRule, checkPhase v1alpha1.NodePhase, checkRule v1alpha1.TriggerRule) PredicatePhase {
+			mockEndStatus := &mocks.ExecutableNodeStatus{}
+			mockEndStatus.OnGetParentNodeID().Return(nil)
+			mockNode := &mocks.ExecutableNode{}
+			mockNode.OnGetID().Return(v1alpha1.EndNodeID)
+			mockNode.OnGetTriggerRule().Return(v1alpha1.TriggerRuleAllSuccess)
+
+			mockWf := &mocks.ExecutableWorkflow{}
+			mockWf.OnGetNodeExecutionStatus(ctx, v1alpha1.EndNodeID).Return(mockEndStatus)
+			for id, phase := range map[v1alpha1.NodeID]v1alpha1.NodePhase{"n1": succeeded, "cleanup": skipped, "check": checkPhase} {
+				s := &mocks.ExecutableNodeStatus{}
+				s.OnGetPhase().Return(phase)
+				s.OnIsDirty().Return(false)
+				mockWf.OnGetNodeExecutionStatus(ctx, id).Return(s)
+			}
+			for id, rule := range map[v1alpha1.NodeID]v1alpha1.TriggerRule{"cleanup": skippedRule, "check": checkRule} {
+				n := &mocks.ExecutableNode{}
+				n.OnGetTriggerRule().Return(rule)
+				mockWf.OnGetNode(id).Return(n, true)
+			}
+			mockWf.OnToNode(v1alpha1.EndNodeID).Return([]v1alpha1.NodeID{"n1", "cleanup"}, nil)
+			mockWf.OnToNode("cleanup").Return([]v1alpha1.NodeID{"check"}, nil)
+			mockWf.OnToNode("check").Return([]v1alpha1.NodeID{}, nil)
+
+			p, err := CanExecute(ctx, mockWf, mockWf, mockNode)
+			assert.NoError(t, err)
+			return p
+		}
+
+		// A leaf node skipped by its trigger rule does not skip the end node
+		assert.Equal(t, PredicatePhaseReady, canExecuteEnd(t, v1alpha1.TriggerRuleOneFailed, succeeded, v1alpha1.TriggerRuleAllSuccess))
+		// Nor does a leaf node skipped because its upstream node was skipped by its trigger rule
+		assert.Equal(t, PredicatePhaseReady, canExecuteEnd(t, v1alpha1.TriggerRuleAllSuccess, skipped, v1alpha1.TriggerRuleOneFailed))
+		assert.Equal(t, PredicatePhaseSkip, canExecuteEnd(t, v1alpha1.TriggerRuleAllSuccess, skipped, v1alpha1.TriggerRuleAllSuccess))
+		assert.Equal(t, PredicatePhaseSkip, canExecuteEnd(t, v1alpha1.TriggerRuleAllSuccess, failed, v1alpha1.TriggerRuleAllSuccess))
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		mockNode := &mocks.ExecutableNode{}
+		mockNode.OnGetID().Return(nodeN2)
+		mockNode.OnGetTriggerRule().Return("all_failed")
+		mockN2Status := &mocks.ExecutableNodeStatus{}
+		mockN2Status.OnGetParentNodeID().Return(nil)
+		mockWf := &mocks.ExecutableWorkflow{}
+		mockWf.OnGetNodeExecutionStatus(ctx, nodeN2).Return(mockN2Status)
+		mockWf.OnToNode(nodeN2).Return([]v1alpha1.NodeID{}, nil)
+
+		p, err := CanExecute(ctx, mockWf, mockWf, mockNode)
+		assert.Error(t, err)
+		assert.Equal(t, PredicatePhaseUndefined, p)
+	})
+}
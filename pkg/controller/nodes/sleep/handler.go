@@ -0,0 +1,197 @@
+package sleep
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+)
+
+const (
+	durationInputVar = "duration"
+	untilInputVar    = "until"
+)
+
+type metrics struct {
+	scope            promutils.Scope
+	wakeUpsScheduled labeled.Counter
+	completed        labeled.Counter
+}
+
+// A re-enqueue of the workflow scheduled at the wake-up time of a sleep node.
+type wakeUp struct {
+	at    time.Time
+	timer *time.Timer
+}
+
+// Handler for sleep nodes. A sleep node stays in running until its wake-up time, at which point the workflow is
+// re-enqueued so that downstream nodes start without waiting for the next workflow re-evaluation.
+type sleepHandler struct {
+	metrics metrics
+	now     func() time.Time
+	lock    sync.Mutex
+	// Re-enqueues scheduled for sleeping nodes, keyed by node execution id. Avoids scheduling a timer on every
+	// evaluation of a sleeping node, the timer is stopped once the node wakes up or is aborted.
+	scheduled map[string]*wakeUp
+}
+
+func (s *sleepHandler) FinalizeRequired() bool {
+	return false
+}
+
+func (s *sleepHandler) Setup(ctx context.Context, _ handler.SetupContext) error {
+	logger.Debugf(ctx, "SleepNode::Setup: nothing to do")
+	return nil
+}
+
+// Returns the duration and the absolute wake-up time of the node, only one of which is set. Values bound through the
+// inputs of the node replace the ones in the spec.
+func (s *sleepHandler) resolveSleep(ctx context.Context, nCtx handler.NodeExecutionContext) (*time.Duration, *time.Time, error) {
+	var duration *time.Duration
+	var until *time.Time
+	sleepNode := nCtx.Node().GetSleepNode()
+	if len(nCtx.Node().GetInputBindings()) > 0 {
+		inputs, err := nCtx.InputReader().Get(ctx)
+		if err != nil {
+			return nil, nil, errors.Wrapf(errors.RuntimeExecutionError, nCtx.NodeID(), err, "failed to read inputs")
+		}
+
+		if l, ok := inputs.GetLiterals()[durationInputVar]; ok {
+			d, err := ptypes.Duration(l.GetScalar().GetPrimitive().GetDuration())
+			if err != nil {
+				return nil, nil, errors.Wrapf(errors.BadSpecificationError, nCtx.NodeID(), err, "input [%s] is not a valid duration", durationInputVar)
+			}
+			duration = &d
+		}
+
+		if l, ok := inputs.GetLiterals()[untilInputVar]; ok {
+			t, err := ptypes.Timestamp(l.GetScalar().GetPrimitive().GetDatetime())
+			if err != nil {
+				return nil, nil, errors.Wrapf(errors.BadSpecificationError, nCtx.NodeID(), err, "input [%s] is not a valid datetime", untilInputVar)
+			}
+			until = &t
+		}
+	}
+
+	if duration == nil && until == nil {
+		duration = sleepNode.GetDuration()
+		if u := sleepNode.GetUntil(); u != nil {
+			until = &u.Time
+		}
+	}
+
+	if (duration == nil) == (until == nil) {
+		return nil, nil, errors.Errorf(errors.BadSpecificationError, nCtx.NodeID(), "exactly one of [%s] or [%s] should be specified for a sleep node", durationInputVar, untilInputVar)
+	}
+
+	return duration, until, nil
+}
+
+// Re-enqueues the workflow at the wake-up time, unless a re-enqueue at (or before) that time is already scheduled.
+func (s *sleepHandler) scheduleWakeUp(ctx context.Context, nCtx handler.NodeExecutionContext, wakeUpAt time.Time) {
+	key := nCtx.NodeExecutionMetadata().GetNodeExecutionID().String()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	existing, ok := s.scheduled[key]
+	if ok && !existing.at.After(wakeUpAt) {
+		return
+	}
+	if ok {
+		existing.timer.Stop()
+	}
+
+	w := &wakeUp{at: wakeUpAt}
+	s.scheduled[key] = w
+	s.metrics.wakeUpsScheduled.Inc(ctx)
+	enqueueOwner := nCtx.EnqueueOwnerFunc()
+	w.timer = time.AfterFunc(wakeUpAt.Sub(s.now()), func() {
+		s.lock.Lock()
+		if s.scheduled[key] == w {
+			delete(s.scheduled, key)
+		}
+		s.lock.Unlock()
+
+		if err := enqueueOwner(); err != nil {
+			logger.Warnf(ctx, "Failed to re-enqueue workflow after sleep. Error [%v]", err)
+		}
+	})
+}
+
+// Stops the re-enqueue scheduled for the node, if any.
+func (s *sleepHandler) cancelWakeUp(nCtx handler.NodeExecutionContext) {
+	key := nCtx.NodeExecutionMetadata().GetNodeExecutionID().String()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if w, ok := s.scheduled[key]; ok {
+		w.timer.Stop()
+		delete(s.scheduled, key)
+	}
+}
+
+func (s *sleepHandler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext) (handler.Transition, error) {
+	if nCtx.Node().GetSleepNode() == nil {
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_SYSTEM, errors.IllegalStateError, "Invoked sleep handler, for a non sleep node.", nil)), nil
+	}
+
+	duration, until, err := s.resolveSleep(ctx, nCtx)
+	if err != nil {
+		if errors.Matches(err, errors.BadSpecificationError) {
+			return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoFailure(core.ExecutionError_USER, errors.BadSpecificationError, err.Error(), nil)), nil
+		}
+		return handler.UnknownTransition, err
+	}
+
+	now := s.now()
+	var wakeUpAt time.Time
+	if until != nil {
+		wakeUpAt = *until
+	} else {
+		// The node is not yet marked as started the first time it is evaluated
+		startedAt := now
+		if t := nCtx.NodeStatus().GetStartedAt(); t != nil {
+			startedAt = t.Time
+		}
+		wakeUpAt = startedAt.Add(*duration)
+	}
+
+	if !now.Before(wakeUpAt) {
+		logger.Debugf(ctx, "Sleep node woke up, wake-up time [%v]", wakeUpAt)
+		s.metrics.completed.Inc(ctx)
+		s.cancelWakeUp(nCtx)
+		return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoSuccess(nil)), nil
+	}
+
+	s.scheduleWakeUp(ctx, nCtx, wakeUpAt)
+	return handler.DoTransition(handler.TransitionTypeEphemeral, handler.PhaseInfoRunning(nil)), nil
+}
+
+func (s *sleepHandler) Abort(_ context.Context, nCtx handler.NodeExecutionContext, _ string) error {
+	s.cancelWakeUp(nCtx)
+	return nil
+}
+
+func (s *sleepHandler) Finalize(_ context.Context, nCtx handler.NodeExecutionContext) error {
+	s.cancelWakeUp(nCtx)
+	return nil
+}
+
+func New(scope promutils.Scope) handler.Node {
+	sleepScope := scope.NewSubScope("sleep")
+	return &sleepHandler{
+		metrics: metrics{
+			scope:            sleepScope,
+			wakeUpsScheduled: labeled.NewCounter("wake_ups_scheduled", "Number of workflow re-enqueues scheduled for sleep nodes", sleepScope),
+			completed:        labeled.NewCounter("completed", "Number of sleep nodes that woke up", sleepScope),
+		},
+		now:       time.Now,
+		scheduled: map[string]*wakeUp{},
+	}
+}
@@ -0,0 +1,174 @@
+package sleep
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	ioMocks "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/io/mocks"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler/mocks"
+	"github.com/lyft/flytepropeller/pkg/utils"
+)
+
+func init() {
+	labeled.SetMetricKeys(contextutils.NodeIDKey)
+}
+
+var startedAt = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func createNodeContext(t *testing.T, sleepNode *v1alpha1.SleepNodeSpec, inputs map[string]interface{}, started time.Time,
+	enqueued *int32) *mocks.NodeExecutionContext {
+
+	n := &v1alpha1.NodeSpec{ID: "n", Kind: v1alpha1.NodeKindSleep, SleepNode: sleepNode}
+	nCtx := &mocks.NodeExecutionContext{}
+	nCtx.OnNodeID().Return("n")
+	t0 := v1.NewTime(started)
+	nCtx.OnNodeStatus().Return(&v1alpha1.NodeStatus{StartedAt: &t0})
+
+	if inputs != nil {
+		n.InputBindings = []*v1alpha1.Binding{{Binding: &core.Binding{Var: "x"}}}
+		literals, err := utils.MakeLiteralMap(inputs)
+		assert.NoError(t, err)
+		ir := &ioMocks.InputReader{}
+		ir.OnGetMatch(mock.Anything).Return(literals, nil)
+		nCtx.OnInputReader().Return(ir)
+	}
+	nCtx.OnNode().Return(n)
+
+	nm := &mocks.NodeExecutionMetadata{}
+	nm.OnGetNodeExecutionID().Return(&core.NodeExecutionIdentifier{NodeId: "n"})
+	nCtx.OnNodeExecutionMetadata().Return(nm)
+	nCtx.OnEnqueueOwnerFunc().Return(func() error {
+		atomic.AddInt32(enqueued, 1)
+		return nil
+	})
+	return nCtx
+}
+
+func newTestHandler(now time.Time) *sleepHandler {
+	h := New(promutils.NewTestScope()).(*sleepHandler)
+	h.now = func() time.Time {
+		return now
+	}
+	return h
+}
+
+func TestSleepHandler_Handle(t *testing.T) {
+	ctx := context.TODO()
+	hour := &v1.Duration{Duration: time.Hour}
+
+	t.Run("duration-sleeping", func(t *testing.T) {
+		var enqueued int32
+		nCtx := createNodeContext(t, &v1alpha1.SleepNodeSpec{Duration: hour}, nil, startedAt, &enqueued)
+		h := newTestHandler(startedAt.Add(time.Minute))
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+
+		w, ok := h.scheduled[nCtx.NodeExecutionMetadata().GetNodeExecutionID().String()]
+		if assert.True(t, ok) {
+			assert.Equal(t, startedAt.Add(time.Hour), w.at)
+		}
+
+		// The re-enqueue is stopped once the node is aborted
+		assert.NoError(t, h.Abort(ctx, nCtx, "aborted"))
+		assert.Empty(t, h.scheduled)
+		assert.False(t, w.timer.Stop())
+	})
+
+	t.Run("duration-elapsed", func(t *testing.T) {
+		var enqueued int32
+		nCtx := createNodeContext(t, &v1alpha1.SleepNodeSpec{Duration: hour}, nil, startedAt, &enqueued)
+		trns, err := newTestHandler(startedAt.Add(time.Hour)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+	})
+
+	t.Run("until", func(t *testing.T) {
+		var enqueued int32
+		until := v1.NewTime(startedAt.Add(2 * time.Hour))
+		nCtx := createNodeContext(t, &v1alpha1.SleepNodeSpec{Until: &until}, nil, startedAt, &enqueued)
+		trns, err := newTestHandler(startedAt.Add(time.Hour)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+
+		trns, err = newTestHandler(startedAt.Add(2*time.Hour)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+	})
+
+	t.Run("bound-from-inputs", func(t *testing.T) {
+		var enqueued int32
+		nCtx := createNodeContext(t, &v1alpha1.SleepNodeSpec{Duration: hour}, map[string]interface{}{"duration": time.Minute}, startedAt, &enqueued)
+		trns, err := newTestHandler(startedAt.Add(time.Minute)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+
+		nCtx = createNodeContext(t, &v1alpha1.SleepNodeSpec{}, map[string]interface{}{"until": startedAt.Add(time.Hour)}, startedAt, &enqueued)
+		trns, err = newTestHandler(startedAt.Add(time.Minute)).Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+	})
+
+	t.Run("bad-spec", func(t *testing.T) {
+		var enqueued int32
+		until := v1.NewTime(startedAt)
+		for _, spec := range []*v1alpha1.SleepNodeSpec{{}, {Duration: hour, Until: &until}} {
+			nCtx := createNodeContext(t, spec, nil, startedAt, &enqueued)
+			trns, err := newTestHandler(startedAt).Handle(ctx, nCtx)
+			assert.NoError(t, err)
+			assert.Equal(t, handler.EPhaseFailed, trns.Info().GetPhase())
+			assert.Equal(t, errors.BadSpecificationError, trns.Info().GetErr().Code)
+		}
+	})
+
+	t.Run("wakes-up-workflow", func(t *testing.T) {
+		var enqueued int32
+		now := time.Now()
+		nCtx := createNodeContext(t, &v1alpha1.SleepNodeSpec{Duration: &v1.Duration{Duration: 10 * time.Millisecond}}, nil, now, &enqueued)
+
+		h := newTestHandler(now)
+		for i := 0; i < 3; i++ {
+			trns, err := h.Handle(ctx, nCtx)
+			assert.NoError(t, err)
+			assert.Equal(t, handler.EPhaseRunning, trns.Info().GetPhase())
+		}
+
+		// Only a single re-enqueue is scheduled across evaluations
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&enqueued) == 1
+		}, time.Second, 5*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&enqueued))
+	})
+
+	t.Run("woke-up", func(t *testing.T) {
+		var enqueued int32
+		nCtx := createNodeContext(t, &v1alpha1.SleepNodeSpec{Duration: hour}, nil, startedAt, &enqueued)
+		h := newTestHandler(startedAt.Add(time.Minute))
+		_, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Len(t, h.scheduled, 1)
+
+		// Re-evaluated at the wake-up time before the timer fired, the re-enqueue is no longer needed
+		h.now = func() time.Time {
+			return startedAt.Add(time.Hour)
+		}
+		trns, err := h.Handle(ctx, nCtx)
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseSuccess, trns.Info().GetPhase())
+		assert.Empty(t, h.scheduled)
+	})
+}
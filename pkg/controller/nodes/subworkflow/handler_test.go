@@ -44,6 +44,10 @@ func (t *workflowNodeStateHolder) PutWorkflowNodeState(s handler.WorkflowNodeSta
 	return nil
 }
 
+func (t *workflowNodeStateHolder) PutMapNodeState(s handler.MapNodeState) error {
+	panic("not implemented")
+}
+
 func (t workflowNodeStateHolder) PutDynamicNodeState(s handler.DynamicNodeState) error {
 	panic("not implemented")
 }
@@ -3,11 +3,14 @@ package catalog
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
 	"github.com/lyft/flytestdlib/config"
+	"github.com/lyft/flytestdlib/storage"
 
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/datacatalog"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/datastore"
 )
 
 //go:generate pflags Config --default-var defaultConfig
@@ -16,7 +19,8 @@ const ConfigSectionKey = "catalog-cache"
 
 var (
 	defaultConfig = &Config{
-		Type: NoOpDiscoveryType,
+		Type:          NoOpDiscoveryType,
+		StoragePrefix: "catalog",
 	}
 
 	configSection = config.MustRegisterSection(ConfigSectionKey, defaultConfig)
@@ -27,13 +31,27 @@ type DiscoveryType = string
 const (
 	NoOpDiscoveryType DiscoveryType = "noop"
 	DataCatalogType   DiscoveryType = "datacatalog"
+	StorageType       DiscoveryType = "storage"
 )
 
 type Config struct {
-	Type        DiscoveryType   `json:"type" pflag:"\"noop\", Catalog Implementation to use"`
-	Endpoint    string          `json:"endpoint" pflag:"\"\", Endpoint for catalog service"`
-	Insecure    bool            `json:"insecure" pflag:"false, Use insecure grpc connection"`
-	MaxCacheAge config.Duration `json:"max-cache-age" pflag:", Cache entries past this age will incur cache miss. 0 means cache never expires"`
+	Type                         DiscoveryType   `json:"type" pflag:"\"noop\", Catalog Implementation to use"`
+	Endpoint                     string          `json:"endpoint" pflag:"\"\", Endpoint for catalog service"`
+	Insecure                     bool            `json:"insecure" pflag:"false, Use insecure grpc connection"`
+	MaxCacheAge                  config.Duration `json:"max-cache-age" pflag:", Cache entries past this age will incur cache miss. 0 means cache never expires"`
+	StoragePrefix                string          `json:"storage-prefix" pflag:", Prefix to store cache entries under in the metadata storage, for the storage catalog type"`
+	ReservationHeartbeatInterval config.Duration `json:"reservation-heartbeat-interval" pflag:", Interval in which executions of cached tasks extend their cache reservation, so that concurrent executions with the same inputs wait for their results. 0 disables reservations. Only supported by the storage catalog type, other types fail to start with a non-zero interval"`
+}
+
+// Reservations are extended every time the workflow of their owner is evaluated, and expire after two heartbeat
+// intervals without extension. Workflows are evaluated at least once per resync period, so a shorter heartbeat
+// interval lets the reservations of running tasks expire.
+func (c *Config) ValidateReservationHeartbeatInterval(resyncPeriod time.Duration) error {
+	if c.ReservationHeartbeatInterval.Duration > 0 && c.ReservationHeartbeatInterval.Duration < resyncPeriod {
+		return fmt.Errorf("reservation heartbeat interval [%v] is shorter than the workflow resync period [%v]",
+			c.ReservationHeartbeatInterval.Duration, resyncPeriod)
+	}
+	return nil
 }
 
 // Gets loaded config for Discovery
@@ -41,12 +59,15 @@ func GetConfig() *Config {
 	return configSection.GetConfig().(*Config)
 }
 
-func NewCatalogClient(ctx context.Context) (catalog.Client, error) {
+// Creates the catalog client of the configured type. The store is used by the storage catalog type only.
+func NewCatalogClient(ctx context.Context, store *storage.DataStore) (catalog.Client, error) {
 	catalogConfig := GetConfig()
 
 	switch catalogConfig.Type {
 	case DataCatalogType:
 		return datacatalog.NewDataCatalog(ctx, catalogConfig.Endpoint, catalogConfig.Insecure, catalogConfig.MaxCacheAge.Duration)
+	case StorageType:
+		return datastore.NewStorageCatalog(ctx, store, catalogConfig.StoragePrefix, catalogConfig.MaxCacheAge.Duration)
 	case NoOpDiscoveryType, "":
 		return NOOPCatalog{}, nil
 	}
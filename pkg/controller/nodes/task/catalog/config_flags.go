@@ -45,5 +45,7 @@ func (cfg Config) GetPFlagSet(prefix string) *pflag.FlagSet {
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "endpoint"), defaultConfig.Endpoint, " Endpoint for catalog service")
 	cmdFlags.Bool(fmt.Sprintf("%v%v", prefix, "insecure"), defaultConfig.Insecure, " Use insecure grpc connection")
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "max-cache-age"), defaultConfig.MaxCacheAge.String(), " Cache entries past this age will incur cache miss. 0 means cache never expires")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "storage-prefix"), defaultConfig.StoragePrefix, " Prefix to store cache entries under in the metadata storage, for the storage catalog type")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "reservation-heartbeat-interval"), defaultConfig.ReservationHeartbeatInterval.String(), " Interval in which executions of cached tasks extend their cache reservation, so that concurrent executions with the same inputs wait for their results. 0 disables reservations. Only supported by the storage catalog type, other types fail to start with a non-zero interval")
 	return cmdFlags
 }
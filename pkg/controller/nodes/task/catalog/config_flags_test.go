@@ -187,4 +187,48 @@ func TestConfig_SetFlags(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Test_storage-prefix", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("storage-prefix"); err == nil {
+				assert.Equal(t, string(defaultConfig.StoragePrefix), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("storage-prefix", testValue)
+			if vString, err := cmdFlags.GetString("storage-prefix"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.StoragePrefix)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_reservation-heartbeat-interval", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("reservation-heartbeat-interval"); err == nil {
+				assert.Equal(t, string(defaultConfig.ReservationHeartbeatInterval.String()), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := defaultConfig.ReservationHeartbeatInterval.String()
+
+			cmdFlags.Set("reservation-heartbeat-interval", testValue)
+			if vString, err := cmdFlags.GetString("reservation-heartbeat-interval"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.ReservationHeartbeatInterval)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
 }
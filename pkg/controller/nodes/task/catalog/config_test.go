@@ -0,0 +1,20 @@
+package catalog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lyft/flytestdlib/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfig_ValidateReservationHeartbeatInterval(t *testing.T) {
+	newConfig := func(heartbeatInterval time.Duration) *Config {
+		return &Config{ReservationHeartbeatInterval: config.Duration{Duration: heartbeatInterval}}
+	}
+
+	assert.NoError(t, newConfig(0).ValidateReservationHeartbeatInterval(time.Minute))
+	assert.NoError(t, newConfig(time.Minute).ValidateReservationHeartbeatInterval(time.Minute))
+	assert.NoError(t, newConfig(time.Hour).ValidateReservationHeartbeatInterval(time.Minute))
+	assert.Error(t, newConfig(time.Second).ValidateReservationHeartbeatInterval(time.Minute))
+}
@@ -0,0 +1,239 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	datacatalog "github.com/lyft/datacatalog/protos/gen"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/io"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/ioutils"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/uuid"
+
+	catalogIdl "github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/datacatalog"
+)
+
+const (
+	taskVersionKey   = "task-version"
+	wfExecNameKey    = "execution-name"
+	wfExecProjectKey = "execution-project"
+	wfExecDomainKey  = "execution-domain"
+	nodeIDKey        = "node-id"
+	retryAttemptKey  = "retry-attempt"
+	entryExtension   = ".pb"
+)
+
+var (
+	_ catalog.Client = &CatalogClient{}
+)
+
+// This is the client that caches task executions directly in blob storage, without a DataCatalog service. Cache
+// entries are stored as DataCatalog artifacts, so that they are keyed and validated the same way, under
+// <prefix>/<project>/<domain>/<dataset name>/<dataset version>/<input hash tag>.pb
+type CatalogClient struct {
+	store       *storage.DataStore
+	prefix      storage.DataReference
+	maxCacheAge time.Duration
+}
+
+func readInputs(ctx context.Context, key catalog.Key) (*core.LiteralMap, error) {
+	if key.TypedInterface.Inputs == nil || len(key.TypedInterface.Inputs.Variables) == 0 {
+		return &core.LiteralMap{}, nil
+	}
+
+	inputs, err := key.InputReader.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read inputs")
+	}
+
+	return inputs, nil
+}
+
+// Returns the reference of the cache entry of a task execution, given its inputs.
+func (m *CatalogClient) entryReference(ctx context.Context, key catalog.Key, inputs *core.LiteralMap) (
+	*datacatalog.DatasetID, storage.DataReference, error) {
+	return m.reference(ctx, key, inputs, entryExtension)
+}
+
+func (m *CatalogClient) reference(ctx context.Context, key catalog.Key, inputs *core.LiteralMap, extension string) (
+	*datacatalog.DatasetID, storage.DataReference, error) {
+
+	datasetID, err := catalogIdl.GenerateDatasetIDForTask(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tag, err := catalogIdl.GenerateArtifactTagName(ctx, inputs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	ref, err := m.store.ConstructReference(ctx, m.prefix, datasetID.Project, datasetID.Domain, datasetID.Name,
+		datasetID.Version, tag+extension)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return datasetID, ref, nil
+}
+
+// Get the cached task execution from storage. A missing or expired entry is reported as a NotFound error, the same
+// way as by DataCatalog.
+func (m *CatalogClient) Get(ctx context.Context, key catalog.Key) (io.OutputReader, error) {
+	inputs, err := readInputs(ctx, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read inputs when trying to query catalog")
+	}
+
+	_, ref, err := m.entryReference(ctx, key, inputs)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to generate cache entry reference for ID %s, err: %+v", key.Identifier.String(), err)
+		return nil, err
+	}
+
+	artifact := &datacatalog.Artifact{}
+	if err := m.store.ReadProtobuf(ctx, ref, artifact); err != nil {
+		if storage.IsNotFound(err) {
+			logger.Debugf(ctx, "Cache entry [%v] not found", ref)
+			return nil, status.Errorf(codes.NotFound, "cache entry [%v] not found", ref)
+		}
+
+		return nil, errors.Wrapf(err, "failed to read cache entry [%v]", ref)
+	}
+
+	if m.maxCacheAge > time.Duration(0) {
+		createdAt, err := ptypes.Timestamp(artifact.CreatedAt)
+		if err != nil {
+			logger.Errorf(ctx, "Cache entry [%v] has invalid createdAt %+v, err: %+v", ref, artifact.CreatedAt, err)
+			return nil, err
+		}
+
+		if time.Since(createdAt) > m.maxCacheAge {
+			logger.Warningf(ctx, "Expired cache entry [%v] created on %v, older than max age %v",
+				ref, createdAt.String(), m.maxCacheAge)
+			return nil, status.Error(codes.NotFound, "Cache entry over age limit")
+		}
+	}
+
+	outputs, err := catalogIdl.GenerateTaskOutputsFromArtifact(key.Identifier, key.TypedInterface, artifact)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to get outputs from cache entry [%v], err: %+v", ref, err)
+		return nil, err
+	}
+
+	logger.Infof(ctx, "Retrieved %v outputs from cache entry [%v]", len(outputs.Literals), ref)
+	return ioutils.NewInMemoryOutputReader(outputs, nil), nil
+}
+
+// Catalog the task execution by writing its outputs, along with metadata about the execution that produced them, to
+// storage. An existing entry for the same inputs is overwritten.
+func (m *CatalogClient) Put(ctx context.Context, key catalog.Key, reader io.OutputReader, metadata catalog.Metadata) error {
+	inputs, err := readInputs(ctx, key)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to read inputs err: %s", err)
+		return err
+	}
+
+	outputs := &core.LiteralMap{}
+	if key.TypedInterface.Outputs != nil && len(key.TypedInterface.Outputs.Variables) != 0 {
+		retOutputs, retErr, err := reader.Read(ctx)
+		if err != nil {
+			logger.Errorf(ctx, "Failed to read outputs err: %s", err)
+			return err
+		}
+		if retErr != nil {
+			logger.Errorf(ctx, "Failed to read outputs, err :%s", retErr.Message)
+			return errors.Errorf("Failed to read outputs. EC: %s, Msg: %s", retErr.Code, retErr.Message)
+		}
+		outputs = retOutputs
+	}
+
+	datasetID, ref, err := m.entryReference(ctx, key, inputs)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to generate cache entry reference for ID %s, err: %+v", key.Identifier.String(), err)
+		return err
+	}
+
+	md := &datacatalog.Metadata{
+		KeyMap: map[string]string{
+			taskVersionKey: key.Identifier.Version,
+		},
+	}
+	nodeExecID := metadata.NodeExecutionIdentifier
+	if taskExecID := metadata.TaskExecutionIdentifier; taskExecID != nil {
+		nodeExecID = taskExecID.NodeExecutionId
+		md.KeyMap[retryAttemptKey] = strconv.Itoa(int(taskExecID.RetryAttempt))
+	}
+	execID := metadata.WorkflowExecutionIdentifier
+	if nodeExecID != nil {
+		md.KeyMap[nodeIDKey] = nodeExecID.NodeId
+		if execID == nil {
+			execID = nodeExecID.ExecutionId
+		}
+	}
+	if execID != nil {
+		md.KeyMap[wfExecProjectKey] = execID.Project
+		md.KeyMap[wfExecDomainKey] = execID.Domain
+		md.KeyMap[wfExecNameKey] = execID.Name
+	}
+
+	artifactData := make([]*datacatalog.ArtifactData, 0, len(outputs.Literals))
+	for name, value := range outputs.Literals {
+		artifactData = append(artifactData, &datacatalog.ArtifactData{
+			Name:  name,
+			Value: value,
+		})
+	}
+
+	artifact := &datacatalog.Artifact{
+		Id:        string(uuid.NewUUID()),
+		Dataset:   datasetID,
+		Data:      artifactData,
+		Metadata:  md,
+		CreatedAt: ptypes.TimestampNow(),
+	}
+
+	if err := m.store.WriteProtobuf(ctx, ref, storage.Options{}, artifact); err != nil {
+		logger.Errorf(ctx, "Failed to write cache entry [%v], err: %v", ref, err)
+		return errors.Wrapf(err, "failed to write cache entry for ID %s", key.Identifier.String())
+	}
+
+	logger.Infof(ctx, "Cached exec [%v], task: %v", ref, key.Identifier)
+	return nil
+}
+
+// Replaces the cached results of a task execution. Entries in the store are written unconditionally, so this is the
+// same as Put, it exists to make it explicit that this catalog supports overwriting entries.
+func (m *CatalogClient) Overwrite(ctx context.Context, key catalog.Key, reader io.OutputReader, metadata catalog.Metadata) error {
+	return m.Put(ctx, key, reader, metadata)
+}
+
+// Create a new client for task execution caching, storing cache entries under <base container>/<prefix> of the store.
+func NewStorageCatalog(ctx context.Context, store *storage.DataStore, prefix string, maxCacheAge time.Duration) (*CatalogClient, error) {
+	if store == nil {
+		return nil, errors.New("a data store is required for the storage catalog")
+	}
+
+	ref := store.GetBaseContainerFQN(ctx)
+	if prefix != "" {
+		var err error
+		ref, err = store.ConstructReference(ctx, ref, prefix)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to construct catalog prefix")
+		}
+	}
+
+	return &CatalogClient{
+		store:       store,
+		prefix:      ref,
+		maxCacheAge: maxCacheAge,
+	}, nil
+}
@@ -0,0 +1,187 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	datacatalog "github.com/lyft/datacatalog/protos/gen"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/io/mocks"
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/ioutils"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func init() {
+	labeled.SetMetricKeys(contextutils.ProjectKey, contextutils.DomainKey, contextutils.WorkflowIDKey, contextutils.TaskIDKey)
+}
+
+func newStringLiteral(value string) *core.Literal {
+	return &core.Literal{
+		Value: &core.Literal_Scalar{
+			Scalar: &core.Scalar{
+				Value: &core.Scalar_Primitive{
+					Primitive: &core.Primitive{
+						Value: &core.Primitive_StringValue{
+							StringValue: value,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+var variableMap = &core.VariableMap{
+	Variables: map[string]*core.Variable{
+		"x": {
+			Type: &core.LiteralType{
+				Type: &core.LiteralType_Simple{
+					Simple: core.SimpleType_STRING,
+				},
+			},
+		},
+	},
+}
+
+func newKey(input string) catalog.Key {
+	ir := &mocks.InputReader{}
+	ir.On("Get", mock.Anything).Return(&core.LiteralMap{Literals: map[string]*core.Literal{
+		"x": newStringLiteral(input),
+	}}, nil)
+
+	return catalog.Key{
+		Identifier:     core.Identifier{Project: "project", Domain: "domain", Name: "name", Version: "v1"},
+		CacheVersion:   "1.0.0",
+		TypedInterface: core.TypedInterface{Inputs: variableMap, Outputs: variableMap},
+		InputReader:    ir,
+	}
+}
+
+func newTestCatalog(t *testing.T, maxCacheAge time.Duration) (*CatalogClient, *storage.DataStore) {
+	store, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+	assert.NoError(t, err)
+
+	c, err := NewStorageCatalog(context.TODO(), store, "catalog", maxCacheAge)
+	assert.NoError(t, err)
+	return c, store
+}
+
+var metadata = catalog.Metadata{
+	TaskExecutionIdentifier: &core.TaskExecutionIdentifier{
+		NodeExecutionId: &core.NodeExecutionIdentifier{
+			NodeId:      "n0",
+			ExecutionId: &core.WorkflowExecutionIdentifier{Project: "project", Domain: "domain", Name: "exec"},
+		},
+		RetryAttempt: 1,
+	},
+}
+
+func TestCatalog_GetPut(t *testing.T) {
+	ctx := context.TODO()
+	c, store := newTestCatalog(t, 0)
+	outputs := &core.LiteralMap{Literals: map[string]*core.Literal{"x": newStringLiteral("out")}}
+
+	t.Run("miss", func(t *testing.T) {
+		_, err := c.Get(ctx, newKey("in"))
+		assert.Error(t, err)
+		assert.True(t, catalog.IsNotFound(err))
+	})
+
+	t.Run("hit", func(t *testing.T) {
+		assert.NoError(t, c.Put(ctx, newKey("in"), ioutils.NewInMemoryOutputReader(outputs, nil), metadata))
+
+		r, err := c.Get(ctx, newKey("in"))
+		assert.NoError(t, err)
+		actual, execErr, err := r.Read(ctx)
+		assert.NoError(t, err)
+		assert.Nil(t, execErr)
+		assert.Equal(t, "out", actual.Literals["x"].GetScalar().GetPrimitive().GetStringValue())
+	})
+
+	t.Run("source-metadata", func(t *testing.T) {
+		_, ref, err := c.entryReference(ctx, newKey("in"), &core.LiteralMap{Literals: map[string]*core.Literal{
+			"x": newStringLiteral("in"),
+		}})
+		assert.NoError(t, err)
+
+		artifact := &datacatalog.Artifact{}
+		assert.NoError(t, store.ReadProtobuf(ctx, ref, artifact))
+		assert.Equal(t, "exec", artifact.Metadata.KeyMap[wfExecNameKey])
+		assert.Equal(t, "n0", artifact.Metadata.KeyMap[nodeIDKey])
+		assert.Equal(t, "1", artifact.Metadata.KeyMap[retryAttemptKey])
+		assert.Equal(t, "v1", artifact.Metadata.KeyMap[taskVersionKey])
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		k := newKey("overwritten")
+		assert.NoError(t, c.Put(ctx, k, ioutils.NewInMemoryOutputReader(outputs, nil), metadata))
+		newOutputs := &core.LiteralMap{Literals: map[string]*core.Literal{"x": newStringLiteral("new")}}
+		assert.NoError(t, c.Overwrite(ctx, k, ioutils.NewInMemoryOutputReader(newOutputs, nil), metadata))
+
+		r, err := c.Get(ctx, k)
+		assert.NoError(t, err)
+		actual, _, err := r.Read(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "new", actual.Literals["x"].GetScalar().GetPrimitive().GetStringValue())
+	})
+
+	t.Run("different-inputs", func(t *testing.T) {
+		_, err := c.Get(ctx, newKey("other"))
+		assert.True(t, catalog.IsNotFound(err))
+	})
+
+	t.Run("different-version", func(t *testing.T) {
+		k := newKey("in")
+		k.CacheVersion = "2.0.0"
+		_, err := c.Get(ctx, k)
+		assert.True(t, catalog.IsNotFound(err))
+	})
+
+	t.Run("empty-version", func(t *testing.T) {
+		k := newKey("in")
+		k.CacheVersion = ""
+		_, err := c.Get(ctx, k)
+		assert.Error(t, err)
+		assert.False(t, catalog.IsNotFound(err))
+	})
+}
+
+func TestCatalog_MaxCacheAge(t *testing.T) {
+	ctx := context.TODO()
+	c, store := newTestCatalog(t, time.Hour)
+	outputs := &core.LiteralMap{Literals: map[string]*core.Literal{"x": newStringLiteral("out")}}
+	assert.NoError(t, c.Put(ctx, newKey("in"), ioutils.NewInMemoryOutputReader(outputs, nil), metadata))
+
+	_, err := c.Get(ctx, newKey("in"))
+	assert.NoError(t, err)
+
+	_, ref, err := c.entryReference(ctx, newKey("in"), &core.LiteralMap{Literals: map[string]*core.Literal{
+		"x": newStringLiteral("in"),
+	}})
+	assert.NoError(t, err)
+	artifact := &datacatalog.Artifact{}
+	assert.NoError(t, store.ReadProtobuf(ctx, ref, artifact))
+	artifact.CreatedAt, err = ptypes.TimestampProto(time.Now().Add(-2 * time.Hour))
+	assert.NoError(t, err)
+	assert.NoError(t, store.WriteProtobuf(ctx, ref, storage.Options{}, artifact))
+
+	_, err = c.Get(ctx, newKey("in"))
+	taskStatus, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.NotFound, taskStatus.Code())
+}
+
+func TestNewStorageCatalog(t *testing.T) {
+	_, err := NewStorageCatalog(context.TODO(), nil, "catalog", 0)
+	assert.Error(t, err)
+}
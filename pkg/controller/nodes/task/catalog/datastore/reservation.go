@@ -0,0 +1,137 @@
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/pkg/errors"
+
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/reservation"
+)
+
+const (
+	reservationExtension = ".reservation.json"
+	// A reservation expires when its owner missed this many heartbeats.
+	reservationExpiryHeartbeats = 2
+)
+
+var (
+	_ reservation.Client = &CatalogClient{}
+)
+
+func (m *CatalogClient) reservationReference(ctx context.Context, key catalog.Key) (storage.DataReference, error) {
+	inputs, err := readInputs(ctx, key)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read inputs when trying to reserve catalog entry")
+	}
+
+	_, ref, err := m.reference(ctx, key, inputs, reservationExtension)
+	return ref, err
+}
+
+// Reads the reservation stored at the reference, returns nil if there is none.
+func (m *CatalogClient) readReservation(ctx context.Context, ref storage.DataReference) (*reservation.Reservation, error) {
+	rc, err := m.store.ReadRaw(ctx, ref)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read reservation [%v]", ref)
+	}
+
+	defer func() {
+		if err := rc.Close(); err != nil {
+			logger.Warnf(ctx, "Failed to close reader for [%v]. Error [%v]", ref, err)
+		}
+	}()
+
+	raw, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read reservation [%v]", ref)
+	}
+
+	r := &reservation.Reservation{}
+	if err := json.Unmarshal(raw, r); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal reservation [%v]", ref)
+	}
+
+	return r, nil
+}
+
+func (m *CatalogClient) writeReservation(ctx context.Context, ref storage.DataReference, r *reservation.Reservation) error {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal reservation [%v]", ref)
+	}
+
+	if err := m.store.WriteRaw(ctx, ref, int64(len(raw)), storage.Options{}, bytes.NewReader(raw)); err != nil {
+		return errors.Wrapf(err, "failed to write reservation [%v]", ref)
+	}
+
+	return nil
+}
+
+// Acquires or extends the reservation of a cache entry, stored next to the entry. Blob stores do not support
+// conditional writes, the reservation is read back after it is written so that of concurrent owners, only the last
+// writer proceeds. This is best effort: in rare races two owners may both run the task, which only costs the duplicate
+// execution.
+func (m *CatalogClient) GetOrExtendReservation(ctx context.Context, key catalog.Key, ownerID string,
+	heartbeatInterval time.Duration) (*reservation.Reservation, error) {
+
+	ref, err := m.reservationReference(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := m.readReservation(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if current != nil && !current.IsOwnedBy(ownerID) && current.OwnerID != "" && now.Before(current.ExpiresAt) {
+		logger.Debugf(ctx, "Reservation [%v] is held by [%v] until [%v]", ref, current.OwnerID, current.ExpiresAt)
+		return current, nil
+	}
+
+	r := &reservation.Reservation{
+		OwnerID:   ownerID,
+		ExpiresAt: now.Add(reservationExpiryHeartbeats * heartbeatInterval),
+	}
+	if err := m.writeReservation(ctx, ref, r); err != nil {
+		return nil, err
+	}
+
+	if current.IsOwnedBy(ownerID) {
+		return r, nil
+	}
+
+	// Newly acquired, an owner that acquired it concurrently may have overwritten it.
+	return m.readReservation(ctx, ref)
+}
+
+// Releases the reservation of a cache entry by expiring it, if it is held by the owner.
+func (m *CatalogClient) ReleaseReservation(ctx context.Context, key catalog.Key, ownerID string, _ time.Duration) error {
+	ref, err := m.reservationReference(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	current, err := m.readReservation(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	if !current.IsOwnedBy(ownerID) {
+		logger.Debugf(ctx, "Reservation [%v] is not held by [%v], nothing to release", ref, ownerID)
+		return nil
+	}
+
+	return m.writeReservation(ctx, ref, &reservation.Reservation{ExpiresAt: time.Now()})
+}
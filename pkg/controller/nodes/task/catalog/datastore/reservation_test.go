@@ -0,0 +1,60 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog_GetOrExtendReservation(t *testing.T) {
+	ctx := context.TODO()
+	heartbeat := time.Minute
+
+	t.Run("acquire-extend-release", func(t *testing.T) {
+		c, _ := newTestCatalog(t, 0)
+
+		r, err := c.GetOrExtendReservation(ctx, newKey("in"), "owner1", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner1"))
+		assert.True(t, r.ExpiresAt.After(time.Now().Add(heartbeat)))
+
+		r, err = c.GetOrExtendReservation(ctx, newKey("in"), "owner2", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner1"))
+
+		// Reservations are per inputs
+		r, err = c.GetOrExtendReservation(ctx, newKey("other"), "owner2", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner2"))
+
+		r, err = c.GetOrExtendReservation(ctx, newKey("in"), "owner1", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner1"))
+
+		assert.NoError(t, c.ReleaseReservation(ctx, newKey("in"), "owner2", heartbeat))
+		r, err = c.GetOrExtendReservation(ctx, newKey("in"), "owner2", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner1"))
+
+		assert.NoError(t, c.ReleaseReservation(ctx, newKey("in"), "owner1", heartbeat))
+		r, err = c.GetOrExtendReservation(ctx, newKey("in"), "owner2", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner2"))
+	})
+
+	t.Run("expires", func(t *testing.T) {
+		c, _ := newTestCatalog(t, 0)
+		heartbeat := 10 * time.Millisecond
+
+		r, err := c.GetOrExtendReservation(ctx, newKey("in"), "owner1", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner1"))
+
+		time.Sleep(3 * heartbeat)
+		r, err = c.GetOrExtendReservation(ctx, newKey("in"), "owner2", heartbeat)
+		assert.NoError(t, err)
+		assert.True(t, r.IsOwnedBy("owner2"))
+	})
+}
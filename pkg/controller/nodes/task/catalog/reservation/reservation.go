@@ -0,0 +1,36 @@
+// Reservations deduplicate concurrent executions of a cached task with the same inputs. The first execution to acquire
+// the reservation of a cache key runs, the others wait for its results to be cached. Reservations are leases, they
+// expire unless their owner extends them every heartbeat interval.
+package reservation
+
+import (
+	"context"
+	"time"
+
+	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
+)
+
+//go:generate mockery -all -case=underscore
+
+// The current reservation of a cache key.
+type Reservation struct {
+	// Unique id of the task execution that holds the reservation.
+	OwnerID string `json:"ownerId"`
+	// The reservation expires at this time unless it is extended by its owner.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Implemented by the catalog clients that support reservations.
+type Client interface {
+	// Acquires the reservation of the key for the owner if there is none or it expired, extends it if the owner already
+	// holds it. Returns the current reservation, which is held by another owner if it could not be acquired.
+	GetOrExtendReservation(ctx context.Context, key catalog.Key, ownerID string, heartbeatInterval time.Duration) (*Reservation, error)
+	// Releases the reservation of the key if it is held by the owner, so that it can be acquired by another owner
+	// without waiting for it to expire.
+	ReleaseReservation(ctx context.Context, key catalog.Key, ownerID string, heartbeatInterval time.Duration) error
+}
+
+// Returns true if the reservation is held by the owner.
+func (r *Reservation) IsOwnedBy(ownerID string) bool {
+	return r != nil && r.OwnerID == ownerID
+}
@@ -24,19 +24,27 @@ import (
 	"github.com/lyft/flytestdlib/promutils/labeled"
 	"github.com/lyft/flytestdlib/storage"
 	regErrors "github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/cache"
 
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/resourcemanager"
 	rmConfig "github.com/lyft/flytepropeller/pkg/controller/nodes/task/resourcemanager/config"
 
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytepropeller/pkg/controller/executors"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
+	catalogConfig "github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/reservation"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/config"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/secretmanager"
 )
 
 const pluginContextKey = contextutils.Key("plugin")
 
+// Upper bound of the number of reservations that are remembered as held, reservations that are not remembered are
+// extended on the next evaluation of their owner.
+const heldReservationsCacheSize = 10000
+
 type metrics struct {
 	pluginPanics           labeled.Counter
 	unsupportedTaskType    labeled.Counter
@@ -45,8 +53,12 @@ type metrics struct {
 	catalogPutSuccessCount labeled.Counter
 	catalogMissCount       labeled.Counter
 	catalogHitCount        labeled.Counter
+	catalogSkipCount       labeled.Counter
 	pluginExecutionLatency labeled.StopWatch
-	pluginQueueLatency     labeled.StopWatch
+	// Reservations
+	catalogReservationWaitCount    labeled.Counter
+	catalogReservationFailureCount labeled.Counter
+	pluginQueueLatency             labeled.StopWatch
 
 	// TODO We should have a metric to capture custom state size
 	scope promutils.Scope
@@ -82,6 +94,25 @@ func (p *pluginRequestedTransition) CacheHit(outputPath storage.DataReference) {
 	p.ObserveSuccess(outputPath)
 }
 
+func (p *pluginRequestedTransition) ObserveCacheOverride(cacheOverride v1alpha1.CacheOverride) {
+	if p.execInfo.TaskNodeInfo == nil {
+		p.execInfo.TaskNodeInfo = &handler.TaskNodeInfo{}
+	}
+	p.execInfo.TaskNodeInfo.CacheOverride = cacheOverride
+}
+
+// Returns the execution info of transitions that do not report outputs, if it carries task node info.
+func (p *pluginRequestedTransition) taskNodeInfo() *handler.ExecutionInfo {
+	if p.execInfo.TaskNodeInfo == nil {
+		return nil
+	}
+	return &handler.ExecutionInfo{TaskNodeInfo: p.execInfo.TaskNodeInfo}
+}
+
+func (p *pluginRequestedTransition) IsCacheHit() bool {
+	return p.execInfo.TaskNodeInfo != nil && p.execInfo.TaskNodeInfo.CacheHit
+}
+
 func (p *pluginRequestedTransition) ObservedTransitionAndState(trns pluginCore.Transition, pluginStateVersion uint32, pluginState []byte) {
 	p.ttype = ToTransitionType(trns.Type())
 	p.pInfo = trns.Info()
@@ -124,16 +155,16 @@ func (p *pluginRequestedTransition) FinalTransition(ctx context.Context) (handle
 		return handler.DoTransition(p.ttype, handler.PhaseInfoSuccess(&p.execInfo)), nil
 	case pluginCore.PhaseRetryableFailure:
 		logger.Debugf(ctx, "Transitioning to RetryableFailure")
-		return handler.DoTransition(p.ttype, handler.PhaseInfoRetryableFailureErr(p.pInfo.Err(), nil)), nil
+		return handler.DoTransition(p.ttype, handler.PhaseInfoRetryableFailureErr(p.pInfo.Err(), p.taskNodeInfo())), nil
 	case pluginCore.PhasePermanentFailure:
 		logger.Debugf(ctx, "Transitioning to Failure")
-		return handler.DoTransition(p.ttype, handler.PhaseInfoFailureErr(p.pInfo.Err(), nil)), nil
+		return handler.DoTransition(p.ttype, handler.PhaseInfoFailureErr(p.pInfo.Err(), p.taskNodeInfo())), nil
 	case pluginCore.PhaseUndefined:
 		return handler.UnknownTransition, fmt.Errorf("error converting plugin phase, received [Undefined]")
 	}
 
 	logger.Debugf(ctx, "Task still running")
-	return handler.DoTransition(p.ttype, handler.PhaseInfoRunning(nil)), nil
+	return handler.DoTransition(p.ttype, handler.PhaseInfoRunning(p.taskNodeInfo())), nil
 }
 
 // The plugin interface available especially for testing.
@@ -142,20 +173,32 @@ type PluginRegistryIface interface {
 	GetK8sPlugins() []pluginK8s.PluginEntry
 }
 
+// Implemented by the catalog clients that can replace the cached results of a task execution.
+type catalogOverwriter interface {
+	Overwrite(ctx context.Context, key catalog.Key, reader io.OutputReader, metadata catalog.Metadata) error
+}
+
 type Handler struct {
-	catalog         catalog.Client
-	asyncCatalog    catalog.AsyncClient
-	plugins         map[pluginCore.TaskType]pluginCore.Plugin
-	taskMetricsMap  map[MetricKey]*taskMetrics
-	defaultPlugin   pluginCore.Plugin
-	metrics         *metrics
-	pluginRegistry  PluginRegistryIface
-	kubeClient      pluginCore.KubeClient
-	secretManager   pluginCore.SecretManager
-	resourceManager resourcemanager.BaseResourceManager
-	barrierCache    *barrier
-	cfg             *config.Config
-	pluginScope     promutils.Scope
+	catalog      catalog.Client
+	asyncCatalog catalog.AsyncClient
+	// nil if the catalog does not support overwriting cached results
+	overwriter catalogOverwriter
+	// nil if the catalog does not support reservations or they are disabled
+	reservations                 reservation.Client
+	reservationHeartbeatInterval time.Duration
+	plugins                      map[pluginCore.TaskType]pluginCore.Plugin
+	taskMetricsMap               map[MetricKey]*taskMetrics
+	defaultPlugin                pluginCore.Plugin
+	metrics                      *metrics
+	pluginRegistry               PluginRegistryIface
+	kubeClient                   pluginCore.KubeClient
+	secretManager                pluginCore.SecretManager
+	resourceManager              resourcemanager.BaseResourceManager
+	barrierCache                 *barrier
+	cfg                          *config.Config
+	pluginScope                  promutils.Scope
+	// Reservations held by task executions that do not need to be extended yet, keyed by owner id
+	heldReservations *cache.LRUExpireCache
 }
 
 func (t *Handler) FinalizeRequired() bool {
@@ -370,7 +413,7 @@ func (t Handler) invokePlugin(ctx context.Context, p pluginCore.Plugin, tCtx *ta
 		execID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetID()
 		ee, err := t.ValidateOutputAndCacheAdd(ctx, tCtx.NodeID(), tCtx.InputReader(), tCtx.ow.GetReader(), outputCommitter, tCtx.tr, catalog.Metadata{
 			TaskExecutionIdentifier: &execID,
-		})
+		}, v1alpha1.GetCacheOverride(tCtx.NodeExecutionMetadata().GetAnnotations(), tCtx.NodeID()))
 		if err != nil {
 			return nil, err
 		}
@@ -384,6 +427,17 @@ func (t Handler) invokePlugin(ctx context.Context, p pluginCore.Plugin, tCtx *ta
 	return pluginTrns, nil
 }
 
+// Returns the cache override applied for the override requested by the workflow. Catalogs that cannot replace cached
+// results keep the existing ones, so an overwrite is applied, and reported, as skipping the lookup.
+func (t Handler) applicableCacheOverride(ctx context.Context, cacheOverride v1alpha1.CacheOverride) v1alpha1.CacheOverride {
+	if cacheOverride == v1alpha1.CacheOverrideOverwrite && t.overwriter == nil {
+		logger.Debugf(ctx, "Catalog [%T] does not support overwriting cached results, applying cache override [%v] instead of [%v].",
+			t.catalog, v1alpha1.CacheOverrideSkipLookup, cacheOverride)
+		return v1alpha1.CacheOverrideSkipLookup
+	}
+	return cacheOverride
+}
+
 func (t Handler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext) (handler.Transition, error) {
 	ttype := nCtx.TaskReader().GetTaskType()
 	ctx = contextutils.WithTaskType(ctx, ttype)
@@ -397,6 +451,10 @@ func (t Handler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext)
 		logger.Debug(ctx, "Node level caching is disabled. Skipping catalog read.")
 	}
 
+	// Executions that skip the cache lookup do not wait for, nor hold, the reservation of the cached results
+	cacheOverride := t.applicableCacheOverride(ctx, v1alpha1.GetCacheOverride(nCtx.NodeExecutionMetadata().GetAnnotations(), nCtx.NodeID()))
+	reserve := t.reservations != nil && checkCatalog && !cacheOverride.SkipsLookup()
+
 	tCtx, err := t.newTaskExecutionContext(ctx, nCtx, p.GetID())
 	if err != nil {
 		return handler.UnknownTransition, errors.Wrapf(errors.IllegalStateError, nCtx.NodeID(), err, "unable to create Handler execution context")
@@ -405,6 +463,7 @@ func (t Handler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext)
 	ts := nCtx.NodeStateReader().GetTaskNodeState()
 
 	var pluginTrns *pluginRequestedTransition
+	lookupSkipped := false
 
 	// NOTE: Ideally we should use a taskExecution state for this handler. But, doing that will make it completely backwards incompatible
 	// So now we will derive this from the plugin phase
@@ -413,7 +472,8 @@ func (t Handler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext)
 	// STEP 1: Check Cache
 	if ts.PluginPhase == pluginCore.PhaseUndefined && checkCatalog {
 		// This is assumed to be first time. we will check catalog and call handle
-		if ok, err := t.CheckCatalogCache(ctx, tCtx.tr, nCtx.InputReader(), tCtx.ow); err != nil {
+		lookupSkipped = cacheOverride.SkipsLookup()
+		if ok, err := t.CheckCatalogCache(ctx, tCtx.tr, nCtx.InputReader(), tCtx.ow, cacheOverride); err != nil {
 			logger.Errorf(ctx, "failed to check catalog cache with error")
 			return handler.UnknownTransition, err
 		} else if ok {
@@ -439,6 +499,30 @@ func (t Handler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext)
 				logger.Errorf(ctx, "no output reader found after a catalog cache hit!")
 			}
 		}
+
+		// STEP 1.1: On a cache miss, wait for the execution that holds the reservation to cache its results instead of
+		// executing the task again
+		if pluginTrns == nil && reserve {
+			ownerID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetGeneratedName()
+			if r, err := t.GetOrExtendCatalogReservation(ctx, ownerID, tCtx.tr, nCtx.InputReader()); err != nil {
+				logger.Warnf(ctx, "failed to acquire catalog reservation, executing task. err: %v", err)
+			} else if r != nil && !r.IsOwnedBy(ownerID) {
+				logger.Infof(ctx, "Catalog reservation is held by [%s] until [%v], waiting for its results", r.OwnerID, r.ExpiresAt)
+				t.metrics.catalogReservationWaitCount.Inc(ctx)
+				return handler.DoTransition(handler.TransitionTypeEphemeral,
+					handler.PhaseInfoQueued(fmt.Sprintf("waiting for cache reservation held by [%s]", r.OwnerID))), nil
+			}
+		}
+	} else if reserve && !ts.PluginPhase.IsTerminal() {
+		// Heartbeat, so that the reservation does not expire while the task executes
+		ownerID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetGeneratedName()
+		if _, held := t.heldReservations.Get(ownerID); held {
+			logger.Debugf(ctx, "Catalog reservation does not expire within a heartbeat interval, not extending it")
+		} else if r, err := t.GetOrExtendCatalogReservation(ctx, ownerID, tCtx.tr, nCtx.InputReader()); err != nil {
+			logger.Warnf(ctx, "failed to extend catalog reservation. err: %v", err)
+		} else if r != nil && !r.IsOwnedBy(ownerID) {
+			logger.Warnf(ctx, "Catalog reservation expired and was acquired by [%s]", r.OwnerID)
+		}
 	}
 
 	barrierTick := uint32(0)
@@ -490,6 +574,19 @@ func (t Handler) Handle(ctx context.Context, nCtx handler.NodeExecutionContext)
 		return handler.UnknownTransition, errors.Errorf(errors.IllegalStateError, nCtx.NodeID(), "plugin transition is not observed and no error as well.")
 	}
 
+	// Record the cache override with the first transition of the task, whatever its outcome
+	if lookupSkipped {
+		pluginTrns.ObserveCacheOverride(cacheOverride)
+	}
+
+	// Release the reservation once the task completed, its results are cached if it succeeded
+	if reserve && pluginTrns.pInfo.Phase().IsTerminal() && !pluginTrns.IsCacheHit() {
+		ownerID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetGeneratedName()
+		if err := t.ReleaseCatalogReservation(ctx, ownerID, tCtx.tr, nCtx.InputReader()); err != nil {
+			logger.Warnf(ctx, "failed to release catalog reservation. err: %v", err)
+		}
+	}
+
 	execID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetID()
 	// STEP 4: Send buffered events!
 	logger.Debugf(ctx, "Sending buffered Task events.")
@@ -580,6 +677,14 @@ func (t Handler) Abort(ctx context.Context, nCtx handler.NodeExecutionContext, r
 		logger.Errorf(ctx, "Abort failed when calling plugin abort.")
 		return err
 	}
+
+	if t.reservations != nil && !p.GetProperties().DisableNodeLevelCaching {
+		ownerID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetGeneratedName()
+		if err := t.ReleaseCatalogReservation(ctx, ownerID, tCtx.tr, nCtx.InputReader()); err != nil {
+			logger.Warnf(ctx, "failed to release catalog reservation. err: %v", err)
+		}
+	}
+
 	taskExecID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetID()
 	evRecorder := nCtx.EventsRecorder()
 	if err := evRecorder.RecordTaskEvent(ctx, &event.TaskExecutionEvent{
@@ -639,30 +744,52 @@ func New(ctx context.Context, kubeClient executors.Client, client catalog.Client
 		return nil, err
 	}
 
+	var overwriter catalogOverwriter
+	if o, ok := client.(catalogOverwriter); ok {
+		overwriter = o
+	}
+
+	var reservations reservation.Client
+	heartbeatInterval := catalogConfig.GetConfig().ReservationHeartbeatInterval.Duration
+	if heartbeatInterval > 0 {
+		r, ok := client.(reservation.Client)
+		if !ok {
+			return nil, fmt.Errorf("catalog client [%T] does not support reservations, the reservation heartbeat interval must be 0", client)
+		}
+		reservations = r
+	}
+
 	cfg := config.GetConfig()
 	return &Handler{
 		pluginRegistry: pluginMachinery.PluginRegistry(),
 		plugins:        make(map[pluginCore.TaskType]pluginCore.Plugin),
 		taskMetricsMap: make(map[MetricKey]*taskMetrics),
 		metrics: &metrics{
-			pluginPanics:           labeled.NewCounter("plugin_panic", "Task plugin paniced when trying to execute a Handler.", scope),
-			unsupportedTaskType:    labeled.NewCounter("unsupported_tasktype", "No Handler plugin configured for Handler type", scope),
-			catalogHitCount:        labeled.NewCounter("discovery_hit_count", "Task cached in Discovery", scope),
-			catalogMissCount:       labeled.NewCounter("discovery_miss_count", "Task not cached in Discovery", scope),
-			catalogPutSuccessCount: labeled.NewCounter("discovery_put_success_count", "Discovery Put success count", scope),
-			catalogPutFailureCount: labeled.NewCounter("discovery_put_failure_count", "Discovery Put failure count", scope),
-			catalogGetFailureCount: labeled.NewCounter("discovery_get_failure_count", "Discovery Get faillure count", scope),
-			pluginExecutionLatency: labeled.NewStopWatch("plugin_exec_latency", "Time taken to invoke plugin for one round", time.Microsecond, scope),
-			pluginQueueLatency:     labeled.NewStopWatch("plugin_queue_latency", "Time spent by plugin in queued phase", time.Microsecond, scope),
-			scope:                  scope,
+			pluginPanics:                   labeled.NewCounter("plugin_panic", "Task plugin paniced when trying to execute a Handler.", scope),
+			unsupportedTaskType:            labeled.NewCounter("unsupported_tasktype", "No Handler plugin configured for Handler type", scope),
+			catalogHitCount:                labeled.NewCounter("discovery_hit_count", "Task cached in Discovery", scope),
+			catalogMissCount:               labeled.NewCounter("discovery_miss_count", "Task not cached in Discovery", scope),
+			catalogSkipCount:               labeled.NewCounter("discovery_skip_count", "Discovery lookup skipped by a cache override of the workflow", scope),
+			catalogPutSuccessCount:         labeled.NewCounter("discovery_put_success_count", "Discovery Put success count", scope),
+			catalogPutFailureCount:         labeled.NewCounter("discovery_put_failure_count", "Discovery Put failure count", scope),
+			catalogGetFailureCount:         labeled.NewCounter("discovery_get_failure_count", "Discovery Get faillure count", scope),
+			pluginExecutionLatency:         labeled.NewStopWatch("plugin_exec_latency", "Time taken to invoke plugin for one round", time.Microsecond, scope),
+			pluginQueueLatency:             labeled.NewStopWatch("plugin_queue_latency", "Time spent by plugin in queued phase", time.Microsecond, scope),
+			catalogReservationWaitCount:    labeled.NewCounter("discovery_reservation_wait_count", "Task waiting for the results of an execution holding the Discovery reservation", scope),
+			catalogReservationFailureCount: labeled.NewCounter("discovery_reservation_failure_count", "Discovery reservation failure count", scope),
+			scope:                          scope,
 		},
-		pluginScope:     scope.NewSubScope("plugin"),
-		kubeClient:      kubeClient,
-		catalog:         client,
-		asyncCatalog:    async,
-		resourceManager: nil,
-		secretManager:   secretmanager.NewFileEnvSecretManager(secretmanager.GetConfig()),
-		barrierCache:    newLRUBarrier(ctx, cfg.BarrierConfig),
-		cfg:             cfg,
+		pluginScope:                  scope.NewSubScope("plugin"),
+		kubeClient:                   kubeClient,
+		catalog:                      client,
+		asyncCatalog:                 async,
+		overwriter:                   overwriter,
+		reservations:                 reservations,
+		reservationHeartbeatInterval: heartbeatInterval,
+		heldReservations:             cache.NewLRUExpireCache(heldReservationsCacheSize),
+		resourceManager:              nil,
+		secretManager:                secretmanager.NewFileEnvSecretManager(secretmanager.GetConfig()),
+		barrierCache:                 newLRUBarrier(ctx, cfg.BarrierConfig),
+		cfg:                          cfg,
 	}, nil
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/event"
 	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery"
+	pluginCatalog "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
 	pluginCatalogMocks "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog/mocks"
 	pluginCore "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/core"
 	pluginCoreMocks "github.com/lyft/flyteplugins/go/tasks/pluginmachinery/core/mocks"
@@ -33,10 +34,13 @@ import (
 	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	flyteMocks "github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1/mocks"
 	"github.com/lyft/flytepropeller/pkg/controller/executors/mocks"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/handler"
 	nodeMocks "github.com/lyft/flytepropeller/pkg/controller/nodes/handler/mocks"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/reservation"
+	reservationMocks "github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/reservation/mocks"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/codex"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/config"
 	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/fakeplugins"
@@ -283,6 +287,15 @@ func (f *fakeBufferedTaskEventRecorder) RecordTaskEvent(ctx context.Context, ev
 	return nil
 }
 
+type fakeCatalogOverwriter struct {
+	overwritten bool
+}
+
+func (f *fakeCatalogOverwriter) Overwrite(ctx context.Context, key pluginCatalog.Key, reader io.OutputReader, metadata pluginCatalog.Metadata) error {
+	f.overwritten = true
+	return nil
+}
+
 type taskNodeStateHolder struct {
 	s handler.TaskNodeState
 }
@@ -300,6 +313,10 @@ func (t taskNodeStateHolder) PutWorkflowNodeState(s handler.WorkflowNodeState) e
 	panic("not implemented")
 }
 
+func (t taskNodeStateHolder) PutMapNodeState(s handler.MapNodeState) error {
+	panic("not implemented")
+}
+
 func (t taskNodeStateHolder) PutDynamicNodeState(s handler.DynamicNodeState) error {
 	panic("not implemented")
 }
@@ -629,7 +646,7 @@ func Test_task_Handle_NoCatalog(t *testing.T) {
 
 func Test_task_Handle_Catalog(t *testing.T) {
 
-	createNodeContext := func(recorder events.TaskEventRecorder, ttype string, s *taskNodeStateHolder) *nodeMocks.NodeExecutionContext {
+	createNodeContext := func(recorder events.TaskEventRecorder, ttype string, s *taskNodeStateHolder, annotations map[string]string) *nodeMocks.NodeExecutionContext {
 		wfExecID := &core.WorkflowExecutionIdentifier{
 			Project: "project",
 			Domain:  "domain",
@@ -639,7 +656,7 @@ func Test_task_Handle_Catalog(t *testing.T) {
 		nodeID := "n1"
 
 		nm := &nodeMocks.NodeExecutionMetadata{}
-		nm.OnGetAnnotations().Return(map[string]string{})
+		nm.OnGetAnnotations().Return(annotations)
 		nm.OnGetNodeExecutionID().Return(&core.NodeExecutionIdentifier{
 			NodeId:      nodeID,
 			ExecutionId: wfExecID,
@@ -790,7 +807,7 @@ func Test_task_Handle_Catalog(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			state := &taskNodeStateHolder{}
 			ev := &fakeBufferedTaskEventRecorder{}
-			nCtx := createNodeContext(ev, "test", state)
+			nCtx := createNodeContext(ev, "test", state, map[string]string{})
 			c := &pluginCatalogMocks.Client{}
 			if tt.args.catalogFetch {
 				or := &ioMocks.OutputReader{}
@@ -841,6 +858,224 @@ func Test_task_Handle_Catalog(t *testing.T) {
 			}
 		})
 	}
+
+	t.Run("reservation", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			owner        string
+			reserveErr   error
+			handlerPhase handler.EPhase
+			executed     bool
+		}{
+			"held-by-other": {owner: "other", handlerPhase: handler.EPhaseQueued},
+			"owned":         {owner: "self", handlerPhase: handler.EPhaseSuccess, executed: true},
+			"error":         {reserveErr: fmt.Errorf("failed to reserve"), handlerPhase: handler.EPhaseSuccess, executed: true},
+		} {
+			t.Run(name, func(t *testing.T) {
+				state := &taskNodeStateHolder{}
+				ev := &fakeBufferedTaskEventRecorder{}
+				nCtx := createNodeContext(ev, "test", state, map[string]string{})
+				c := &pluginCatalogMocks.Client{}
+				c.On("Get", mock.Anything, mock.Anything).Return(nil, nil)
+				c.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				tk, err := New(context.TODO(), mocks.NewFakeKubeClient(), c, promutils.NewTestScope())
+				assert.NoError(t, err)
+				tk.plugins = map[pluginCore.TaskType]pluginCore.Plugin{
+					"test": fakeplugins.NewPhaseBasedPlugin(),
+				}
+				tk.resourceManager = noopRm
+
+				tCtx, err := tk.newTaskExecutionContext(context.TODO(), nCtx, "test")
+				assert.NoError(t, err)
+				ownerID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetGeneratedName()
+				if tc.owner == "self" {
+					tc.owner = ownerID
+				}
+
+				r := &reservationMocks.Client{}
+				if tc.reserveErr != nil {
+					r.OnGetOrExtendReservationMatch(mock.Anything, mock.Anything, ownerID, time.Minute).Return(nil, tc.reserveErr)
+				} else {
+					r.OnGetOrExtendReservationMatch(mock.Anything, mock.Anything, ownerID, time.Minute).Return(
+						&reservation.Reservation{OwnerID: tc.owner, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+				}
+				r.OnReleaseReservationMatch(mock.Anything, mock.Anything, ownerID, time.Minute).Return(nil)
+				tk.reservations = r
+				tk.reservationHeartbeatInterval = time.Minute
+
+				got, err := tk.Handle(context.TODO(), nCtx)
+				assert.NoError(t, err)
+				assert.Equal(t, tc.handlerPhase.String(), got.Info().GetPhase().String())
+				if tc.executed {
+					assert.Equal(t, pluginCore.PhaseSuccess.String(), state.s.PluginPhase.String())
+					c.AssertCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+					r.AssertCalled(t, "ReleaseReservation", mock.Anything, mock.Anything, ownerID, time.Minute)
+				} else {
+					assert.Empty(t, ev.evs)
+					assert.Equal(t, pluginCore.PhaseUndefined.String(), state.s.PluginPhase.String())
+					c.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+					r.AssertNotCalled(t, "ReleaseReservation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				}
+			})
+		}
+	})
+
+	t.Run("heartbeat", func(t *testing.T) {
+		for name, held := range map[string]bool{
+			"extend": false,
+			"held":   true,
+		} {
+			t.Run(name, func(t *testing.T) {
+				state := &taskNodeStateHolder{}
+				ev := &fakeBufferedTaskEventRecorder{}
+				nCtx := createNodeContext(ev, "test", state, map[string]string{})
+				c := &pluginCatalogMocks.Client{}
+				c.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				tk, err := New(context.TODO(), mocks.NewFakeKubeClient(), c, promutils.NewTestScope())
+				assert.NoError(t, err)
+				tk.plugins = map[pluginCore.TaskType]pluginCore.Plugin{
+					"test": fakeplugins.NewPhaseBasedPlugin(),
+				}
+				tk.resourceManager = noopRm
+
+				tCtx, err := tk.newTaskExecutionContext(context.TODO(), nCtx, "test")
+				assert.NoError(t, err)
+				ownerID := tCtx.TaskExecutionMetadata().GetTaskExecutionID().GetGeneratedName()
+
+				r := &reservationMocks.Client{}
+				r.OnGetOrExtendReservationMatch(mock.Anything, mock.Anything, ownerID, time.Minute).Return(
+					&reservation.Reservation{OwnerID: ownerID, ExpiresAt: time.Now().Add(2 * time.Minute)}, nil)
+				r.OnReleaseReservationMatch(mock.Anything, mock.Anything, ownerID, time.Minute).Return(nil)
+				tk.reservations = r
+				tk.reservationHeartbeatInterval = time.Minute
+				if held {
+					tk.heldReservations.Add(ownerID, time.Now().Add(2*time.Minute), time.Minute)
+				}
+
+				st := bytes.NewBuffer([]byte{})
+				assert.NoError(t, codex.GobStateCodec{}.Encode(&fakeplugins.NextPhaseState{
+					Phase:        pluginCore.PhaseSuccess,
+					OutputExists: true,
+				}, st))
+				nr := &nodeMocks.NodeStateReader{}
+				nr.OnGetTaskNodeState().Return(handler.TaskNodeState{
+					PluginPhase: pluginCore.PhaseRunning,
+					PluginState: st.Bytes(),
+				})
+
+				got, err := tk.Handle(context.TODO(), runningNodeExecutionContext{NodeExecutionContext: nCtx, nr: nr})
+				assert.NoError(t, err)
+				assert.Equal(t, handler.EPhaseSuccess.String(), got.Info().GetPhase().String())
+				if held {
+					r.AssertNotCalled(t, "GetOrExtendReservation", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				} else {
+					r.AssertCalled(t, "GetOrExtendReservation", mock.Anything, mock.Anything, ownerID, time.Minute)
+				}
+				r.AssertCalled(t, "ReleaseReservation", mock.Anything, mock.Anything, ownerID, time.Minute)
+				_, stillHeld := tk.heldReservations.Get(ownerID)
+				assert.False(t, stillHeld)
+			})
+		}
+	})
+
+	t.Run("cache-override", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			annotations   map[string]string
+			overwriter    bool
+			cacheOverride v1alpha1.CacheOverride
+			lookup        bool
+			put           bool
+			overwrite     bool
+		}{
+			"other-node":          {annotations: map[string]string{v1alpha1.CacheDisableAnnotationKey: "n2"}, lookup: true},
+			"skip-lookup":         {annotations: map[string]string{v1alpha1.CacheSkipLookupAnnotationKey: "n1"}, cacheOverride: v1alpha1.CacheOverrideSkipLookup, put: true},
+			"overwrite":           {annotations: map[string]string{v1alpha1.CacheOverwriteAnnotationKey: "*"}, overwriter: true, cacheOverride: v1alpha1.CacheOverrideOverwrite, overwrite: true},
+			"overwrite-fallback":  {annotations: map[string]string{v1alpha1.CacheOverwriteAnnotationKey: "n1"}, cacheOverride: v1alpha1.CacheOverrideSkipLookup, put: true},
+			"disable":             {annotations: map[string]string{v1alpha1.CacheDisableAnnotationKey: "n0,n1"}, cacheOverride: v1alpha1.CacheOverrideDisable},
+			"disable-over-others": {annotations: map[string]string{v1alpha1.CacheDisableAnnotationKey: "n1", v1alpha1.CacheOverwriteAnnotationKey: "n1"}, overwriter: true, cacheOverride: v1alpha1.CacheOverrideDisable},
+		} {
+			t.Run(name, func(t *testing.T) {
+				state := &taskNodeStateHolder{}
+				ev := &fakeBufferedTaskEventRecorder{}
+				nCtx := createNodeContext(ev, "test", state, tc.annotations)
+				c := &pluginCatalogMocks.Client{}
+				or := &ioMocks.OutputReader{}
+				or.On("Read", mock.Anything).Return(&core.LiteralMap{}, nil, nil)
+				c.On("Get", mock.Anything, mock.Anything).Return(or, nil)
+				c.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				tk, err := New(context.TODO(), mocks.NewFakeKubeClient(), c, promutils.NewTestScope())
+				assert.NoError(t, err)
+				tk.plugins = map[pluginCore.TaskType]pluginCore.Plugin{
+					"test": fakeplugins.NewPhaseBasedPlugin(),
+				}
+				tk.resourceManager = noopRm
+				o := &fakeCatalogOverwriter{}
+				if tc.overwriter {
+					tk.overwriter = o
+				}
+				r := &reservationMocks.Client{}
+				tk.reservations = r
+				tk.reservationHeartbeatInterval = time.Minute
+
+				got, err := tk.Handle(context.TODO(), nCtx)
+				assert.NoError(t, err)
+				assert.Equal(t, handler.EPhaseSuccess.String(), got.Info().GetPhase().String())
+				if tc.lookup {
+					c.AssertCalled(t, "Get", mock.Anything, mock.Anything)
+					assert.True(t, got.Info().GetInfo().TaskNodeInfo.CacheHit)
+					return
+				}
+
+				c.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+				assert.Empty(t, r.Calls)
+				assert.Equal(t, pluginCore.PhaseSuccess.String(), state.s.PluginPhase.String())
+				if assert.NotNil(t, got.Info().GetInfo().TaskNodeInfo) {
+					assert.False(t, got.Info().GetInfo().TaskNodeInfo.CacheHit)
+					assert.Equal(t, tc.cacheOverride, got.Info().GetInfo().TaskNodeInfo.CacheOverride)
+				}
+				if tc.put {
+					c.AssertCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				} else {
+					c.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				}
+				assert.Equal(t, tc.overwrite, o.overwritten)
+			})
+		}
+	})
+
+	t.Run("cache-override-running", func(t *testing.T) {
+		nCtx := createNodeContext(&fakeBufferedTaskEventRecorder{}, "test", &taskNodeStateHolder{},
+			map[string]string{v1alpha1.CacheSkipLookupAnnotationKey: "n1"})
+		st := bytes.NewBuffer([]byte{})
+		assert.NoError(t, codex.GobStateCodec{}.Encode(&fakeplugins.NextPhaseState{Phase: pluginCore.PhaseRunning}, st))
+		nr := &nodeMocks.NodeStateReader{}
+		nr.OnGetTaskNodeState().Return(handler.TaskNodeState{PluginState: st.Bytes()})
+		c := &pluginCatalogMocks.Client{}
+		tk, err := New(context.TODO(), mocks.NewFakeKubeClient(), c, promutils.NewTestScope())
+		assert.NoError(t, err)
+		tk.plugins = map[pluginCore.TaskType]pluginCore.Plugin{
+			"test": fakeplugins.NewPhaseBasedPlugin(),
+		}
+		tk.resourceManager = noopRm
+
+		// The override is recorded when the lookup is skipped, not only once the task succeeds
+		got, err := tk.Handle(context.TODO(), runningNodeExecutionContext{NodeExecutionContext: nCtx, nr: nr})
+		assert.NoError(t, err)
+		assert.Equal(t, handler.EPhaseRunning.String(), got.Info().GetPhase().String())
+		c.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+		if assert.NotNil(t, got.Info().GetInfo()) && assert.NotNil(t, got.Info().GetInfo().TaskNodeInfo) {
+			assert.Equal(t, v1alpha1.CacheOverrideSkipLookup, got.Info().GetInfo().TaskNodeInfo.CacheOverride)
+		}
+	})
+}
+
+// Node execution context of a task node whose plugin is already running.
+type runningNodeExecutionContext struct {
+	*nodeMocks.NodeExecutionContext
+	nr handler.NodeStateReader
+}
+
+func (r runningNodeExecutionContext) NodeStateReader() handler.NodeStateReader {
+	return r.nr
 }
 
 func Test_task_Handle_Barrier(t *testing.T) {
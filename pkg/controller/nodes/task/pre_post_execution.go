@@ -2,6 +2,7 @@ package task
 
 import (
 	"context"
+	"time"
 
 	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
 	"github.com/lyft/flyteplugins/go/tasks/pluginmachinery/catalog"
@@ -14,15 +15,23 @@ import (
 
 	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
 	errors2 "github.com/lyft/flytepropeller/pkg/controller/nodes/errors"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog/reservation"
 )
 
-func (t *Handler) CheckCatalogCache(ctx context.Context, tr pluginCore.TaskReader, inputReader io.InputReader, outputWriter io.OutputWriter) (bool, error) {
+func (t *Handler) CheckCatalogCache(ctx context.Context, tr pluginCore.TaskReader, inputReader io.InputReader, outputWriter io.OutputWriter,
+	cacheOverride v1alpha1.CacheOverride) (bool, error) {
 	tk, err := tr.Read(ctx)
 	if err != nil {
 		logger.Errorf(ctx, "Failed to read TaskTemplate, error :%s", err.Error())
 		return false, err
 	}
 	if tk.Metadata.Discoverable {
+		if cacheOverride.SkipsLookup() {
+			t.metrics.catalogSkipCount.Inc(ctx)
+			logger.Infof(ctx, "Catalog lookup skipped by cache override [%v]. Executing Task.", cacheOverride)
+			return false, nil
+		}
+
 		key := catalog.Key{
 			Identifier:     *tk.Id,
 			CacheVersion:   tk.Metadata.DiscoveryVersion,
@@ -61,7 +70,7 @@ func (t *Handler) CheckCatalogCache(ctx context.Context, tr pluginCore.TaskReade
 }
 
 func (t *Handler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID v1alpha1.NodeID, i io.InputReader, r io.OutputReader,
-	outputCommitter io.OutputWriter, tr pluginCore.TaskReader, m catalog.Metadata) (*io.ExecutionError, error) {
+	outputCommitter io.OutputWriter, tr pluginCore.TaskReader, m catalog.Metadata, cacheOverride v1alpha1.CacheOverride) (*io.ExecutionError, error) {
 
 	tk, err := tr.Read(ctx)
 	if err != nil {
@@ -148,6 +157,11 @@ func (t *Handler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID v1alpha1
 				return nil, nil
 			}
 
+			if cacheOverride.SkipsWrite() {
+				logger.Infof(ctx, "Caching is disabled by cache override. Skipping catalog write.")
+				return nil, nil
+			}
+
 			cacheVersion := "0"
 			if tk.Metadata != nil {
 				cacheVersion = tk.Metadata.DiscoveryVersion
@@ -159,7 +173,12 @@ func (t *Handler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID v1alpha1
 				TypedInterface: *tk.Interface,
 				InputReader:    i,
 			}
-			if err2 := t.catalog.Put(ctx, key, r, m); err2 != nil {
+			put := t.catalog.Put
+			if t.applicableCacheOverride(ctx, cacheOverride) == v1alpha1.CacheOverrideOverwrite {
+				put = t.overwriter.Overwrite
+			}
+
+			if err2 := put(ctx, key, r, m); err2 != nil {
 				t.metrics.catalogPutFailureCount.Inc(ctx)
 				logger.Errorf(ctx, "Failed to write results to catalog for Task [%v]. Error: %v", tk.GetId(), err2)
 			} else {
@@ -171,3 +190,71 @@ func (t *Handler) ValidateOutputAndCacheAdd(ctx context.Context, nodeID v1alpha1
 
 	return nil, nil
 }
+
+// Returns the catalog key of a task if it is discoverable and the catalog supports reservations.
+func (t *Handler) reservationKey(ctx context.Context, tr pluginCore.TaskReader, inputReader io.InputReader) (*catalog.Key, error) {
+	if t.reservations == nil {
+		return nil, nil
+	}
+
+	tk, err := tr.Read(ctx)
+	if err != nil {
+		logger.Errorf(ctx, "Failed to read TaskTemplate, error :%s", err.Error())
+		return nil, err
+	}
+
+	if !tk.GetMetadata().GetDiscoverable() {
+		return nil, nil
+	}
+
+	return &catalog.Key{
+		Identifier:     *tk.Id,
+		CacheVersion:   tk.Metadata.DiscoveryVersion,
+		TypedInterface: *tk.Interface,
+		InputReader:    inputReader,
+	}, nil
+}
+
+// Acquires or extends the reservation of the cached results of a task for the task execution. Returns nil if the task
+// is not discoverable or reservations are disabled.
+func (t *Handler) GetOrExtendCatalogReservation(ctx context.Context, ownerID string, tr pluginCore.TaskReader,
+	inputReader io.InputReader) (*reservation.Reservation, error) {
+
+	key, err := t.reservationKey(ctx, tr, inputReader)
+	if err != nil || key == nil {
+		return nil, err
+	}
+
+	r, err := t.reservations.GetOrExtendReservation(ctx, *key, ownerID, t.reservationHeartbeatInterval)
+	if err != nil {
+		t.metrics.catalogReservationFailureCount.Inc(ctx)
+		return nil, errors.Wrapf(err, "failed to get or extend catalog reservation")
+	}
+
+	// The reservation does not need to be extended until it expires within a heartbeat interval
+	if r.IsOwnedBy(ownerID) {
+		if ttl := time.Until(r.ExpiresAt) - t.reservationHeartbeatInterval; ttl > 0 {
+			t.heldReservations.Add(ownerID, r.ExpiresAt, ttl)
+		}
+	}
+
+	return r, nil
+}
+
+// Releases the reservation of the cached results of a task held by the task execution, if any.
+func (t *Handler) ReleaseCatalogReservation(ctx context.Context, ownerID string, tr pluginCore.TaskReader,
+	inputReader io.InputReader) error {
+
+	key, err := t.reservationKey(ctx, tr, inputReader)
+	if err != nil || key == nil {
+		return err
+	}
+
+	t.heldReservations.Remove(ownerID)
+	if err := t.reservations.ReleaseReservation(ctx, *key, ownerID, t.reservationHeartbeatInterval); err != nil {
+		t.metrics.catalogReservationFailureCount.Inc(ctx)
+		return errors.Wrapf(err, "failed to release catalog reservation")
+	}
+
+	return nil
+}
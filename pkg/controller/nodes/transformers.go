@@ -139,6 +139,11 @@ func UpdateNodeStatus(np v1alpha1.NodePhase, p handler.PhaseInfo, n *nodeStateMa
 		t.SetBarrierClockTick(n.t.BarrierClockTick)
 	}
 
+	// Record the cache override applied to the task, which explains why its results were not read from the cache
+	if info := p.GetInfo(); info != nil && info.TaskNodeInfo != nil && info.TaskNodeInfo.CacheOverride != v1alpha1.CacheOverrideNone {
+		s.GetOrCreateTaskStatus().SetCacheOverride(info.TaskNodeInfo.CacheOverride)
+	}
+
 	// Update dynamic node status
 	if n.d != nil {
 		t := s.GetOrCreateDynamicNodeStatus()
@@ -165,4 +170,11 @@ func UpdateNodeStatus(np v1alpha1.NodePhase, p handler.PhaseInfo, n *nodeStateMa
 		t.SetWorkflowNodePhase(n.w.Phase)
 		t.SetExecutionError(n.w.Error)
 	}
+
+	// Update map node status
+	if n.m != nil {
+		t := s.GetOrCreateMapNodeStatus()
+		t.SetMapNodePhase(n.m.Phase)
+		t.SetItems(n.m.Items)
+	}
 }
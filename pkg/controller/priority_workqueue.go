@@ -0,0 +1,292 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	lister "github.com/lyft/flytepropeller/pkg/client/listers/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+// Returns the priority class of an item added to the workqueue.
+type PriorityClassifier func(item interface{}) workflowstore.PriorityClass
+
+// A PriorityWorkQueue holds a separate rate limited k8s workqueue per priority class, which take care of
+// deduplication, delayed adds, rate limiting and metrics. Get picks from the non-empty queues using a smooth weighted
+// round robin, so that a lower priority class is never starved but gets a smaller share of the workers. An item is
+// expected to keep its priority class while it is queued.
+type PriorityWorkQueue struct {
+	cond     *sync.Cond
+	classify PriorityClassifier
+	weights  []int
+	credits  []int
+	queues   []workqueue.RateLimitingInterface
+	// The next item of each priority class, fetched from its queue so that Get does not block on any single queue
+	ready map[workflowstore.PriorityClass]interface{}
+	// Whether the next item of a priority class is being fetched from its queue
+	fetching []bool
+	// The priority class of the items being processed
+	processing   map[interface{}]workflowstore.PriorityClass
+	shuttingDown bool
+}
+
+// Returns the queue an item is added to. An item that is being processed stays in the queue it was handed out from,
+// so that it is never handed out to more than one worker at a time.
+func (p *PriorityWorkQueue) queueFor(item interface{}) workqueue.RateLimitingInterface {
+	p.cond.L.Lock()
+	class, ok := p.processing[item]
+	p.cond.L.Unlock()
+	if !ok {
+		class = p.classify(item)
+	}
+	return p.queues[class]
+}
+
+func (p *PriorityWorkQueue) Add(item interface{}) {
+	p.queueFor(item).Add(item)
+}
+
+func (p *PriorityWorkQueue) AddAfter(item interface{}, duration time.Duration) {
+	p.queueFor(item).AddAfter(item, duration)
+}
+
+func (p *PriorityWorkQueue) AddRateLimited(item interface{}) {
+	p.queueFor(item).AddRateLimited(item)
+}
+
+func (p *PriorityWorkQueue) Forget(item interface{}) {
+	for _, q := range p.queues {
+		q.Forget(item)
+	}
+}
+
+func (p *PriorityWorkQueue) NumRequeues(item interface{}) int {
+	return p.queueFor(item).NumRequeues(item)
+}
+
+func (p *PriorityWorkQueue) Len() int {
+	p.cond.L.Lock()
+	defer p.cond.L.Unlock()
+	l := len(p.ready)
+	for _, q := range p.queues {
+		l += q.Len()
+	}
+	return l
+}
+
+// Returns the priority classes that have an item to hand out. Must be called with the lock held.
+func (p *PriorityWorkQueue) nonEmpty() []workflowstore.PriorityClass {
+	var classes []workflowstore.PriorityClass
+	for i, q := range p.queues {
+		class := workflowstore.PriorityClass(i)
+		if _, ok := p.ready[class]; ok || p.fetching[i] || q.Len() > 0 {
+			classes = append(classes, class)
+		}
+	}
+	return classes
+}
+
+// Picks the next priority class to serve among the non-empty ones. Must be called with the lock held.
+func (p *PriorityWorkQueue) next(classes []workflowstore.PriorityClass) workflowstore.PriorityClass {
+	selected := classes[0]
+	for _, class := range classes[1:] {
+		if p.credits[class]+p.weights[class] > p.credits[selected]+p.weights[selected] {
+			selected = class
+		}
+	}
+	return selected
+}
+
+// Hands out the ready item of the selected priority class and updates the credits of the non-empty ones. Must be
+// called with the lock held.
+func (p *PriorityWorkQueue) take(selected workflowstore.PriorityClass, classes []workflowstore.PriorityClass) interface{} {
+	total := 0
+	for _, class := range classes {
+		p.credits[class] += p.weights[class]
+		total += p.weights[class]
+	}
+	p.credits[selected] -= total
+
+	item := p.ready[selected]
+	delete(p.ready, selected)
+	p.processing[item] = selected
+	// Lets the fetcher of the priority class fetch its next item
+	p.cond.Broadcast()
+	return item
+}
+
+func (p *PriorityWorkQueue) Get() (item interface{}, shutdown bool) {
+	p.cond.L.Lock()
+	defer p.cond.L.Unlock()
+	for {
+		if p.shuttingDown {
+			// Hands out the items fetched before shutting down
+			for class := range p.ready {
+				return p.take(class, nil), false
+			}
+			return nil, true
+		}
+
+		if classes := p.nonEmpty(); len(classes) > 0 {
+			// If the selected priority class has no ready item yet, it is being fetched
+			if class := p.next(classes); p.hasReady(class) {
+				return p.take(class, classes), false
+			}
+		}
+
+		p.cond.Wait()
+	}
+}
+
+// Must be called with the lock held
+func (p *PriorityWorkQueue) hasReady(class workflowstore.PriorityClass) bool {
+	_, ok := p.ready[class]
+	return ok
+}
+
+// Fetches the items of a priority class from its queue, one at a time.
+func (p *PriorityWorkQueue) fetch(class workflowstore.PriorityClass) {
+	q := p.queues[class]
+	for {
+		p.cond.L.Lock()
+		for p.hasReady(class) && !p.shuttingDown {
+			p.cond.Wait()
+		}
+
+		if p.shuttingDown {
+			p.cond.L.Unlock()
+			return
+		}
+
+		// This is the only consumer of the queue, so the item is fetched right away if the queue is not empty
+		p.fetching[class] = q.Len() > 0
+		p.cond.L.Unlock()
+
+		item, shutdown := q.Get()
+		if shutdown {
+			return
+		}
+
+		p.cond.L.Lock()
+		p.fetching[class] = false
+		p.ready[class] = item
+		p.cond.Broadcast()
+		p.cond.L.Unlock()
+	}
+}
+
+func (p *PriorityWorkQueue) Done(item interface{}) {
+	p.cond.L.Lock()
+	defer p.cond.L.Unlock()
+	// The item is done in its queue before Add can classify it again, otherwise it could be added to the queue of
+	// another priority class and handed out to a second worker while the first one still processes it
+	if class, ok := p.processing[item]; ok {
+		p.queues[class].Done(item)
+		delete(p.processing, item)
+	}
+}
+
+func (p *PriorityWorkQueue) ShutDown() {
+	p.cond.L.Lock()
+	p.shuttingDown = true
+	p.cond.Broadcast()
+	p.cond.L.Unlock()
+
+	for _, q := range p.queues {
+		q.ShutDown()
+	}
+}
+
+func (p *PriorityWorkQueue) ShuttingDown() bool {
+	p.cond.L.Lock()
+	defer p.cond.L.Unlock()
+	return p.shuttingDown
+}
+
+func NewPriorityWorkQueue(ctx context.Context, cfg config.WorkqueueConfig, priorityCfg config.PriorityQueueConfig,
+	classify PriorityClassifier, scope promutils.Scope) (*PriorityWorkQueue, error) {
+
+	weights := []int{
+		workflowstore.PriorityClassCritical: priorityCfg.CriticalWeight,
+		workflowstore.PriorityClassRegular:  priorityCfg.RegularWeight,
+	}
+
+	queues := make([]workqueue.RateLimitingInterface, 0, len(weights))
+	for i, w := range weights {
+		class := workflowstore.PriorityClass(i)
+		if w <= 0 {
+			logger.Warnf(ctx, "Invalid weight [%v] for priority class [%v], using 1", w, class)
+			weights[i] = 1
+		}
+
+		q, err := NewWorkQueue(ctx, cfg, scope.NewSubScope(class.String()).CurrentScope())
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create WorkQueue for priority class [%v]", class)
+		}
+		queues = append(queues, q)
+	}
+
+	p := &PriorityWorkQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		classify:   classify,
+		weights:    weights,
+		credits:    make([]int, len(weights)),
+		queues:     queues,
+		ready:      map[workflowstore.PriorityClass]interface{}{},
+		fetching:   make([]bool, len(weights)),
+		processing: map[interface{}]workflowstore.PriorityClass{},
+	}
+
+	for i := range queues {
+		go p.fetch(workflowstore.PriorityClass(i))
+	}
+
+	return p, nil
+}
+
+// Returns a classifier that looks up the workflow of a queued key and classifies it by its priority class annotation,
+// falling back to its domain. Workflows that are not found are regular.
+func NewWorkflowPriorityClassifier(cfg config.PriorityQueueConfig, lister lister.FlyteWorkflowLister) PriorityClassifier {
+	criticalDomains := make(map[string]bool, len(cfg.CriticalDomains))
+	for _, d := range cfg.CriticalDomains {
+		criticalDomains[d] = true
+	}
+
+	return func(item interface{}) workflowstore.PriorityClass {
+		key, ok := item.(string)
+		if !ok {
+			return workflowstore.PriorityClassRegular
+		}
+
+		namespace, name, err := cache.SplitMetaNamespaceKey(key)
+		if err != nil {
+			return workflowstore.PriorityClassRegular
+		}
+
+		w, err := lister.FlyteWorkflows(namespace).Get(name)
+		if err != nil {
+			return workflowstore.PriorityClassRegular
+		}
+
+		if name, ok := w.GetAnnotations()[workflowstore.PriorityClassAnnotationKey]; ok {
+			if class, ok := workflowstore.ParsePriorityClass(name); ok {
+				return class
+			}
+			logger.Warnf(context.TODO(), "Unknown priority class [%v] on workflow [%v]", name, key)
+		}
+
+		if execID := w.GetExecutionID(); execID.WorkflowExecutionIdentifier != nil && criticalDomains[execID.GetDomain()] {
+			return workflowstore.PriorityClassCritical
+		}
+
+		return workflowstore.PriorityClassRegular
+	}
+}
@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	lister "github.com/lyft/flytepropeller/pkg/client/listers/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+// Items prefixed with "c" are critical
+func prefixClassifier(item interface{}) workflowstore.PriorityClass {
+	if strings.HasPrefix(item.(string), "c") {
+		return workflowstore.PriorityClassCritical
+	}
+	return workflowstore.PriorityClassRegular
+}
+
+func newTestPriorityWorkQueue(t *testing.T) *PriorityWorkQueue {
+	q, err := NewPriorityWorkQueue(context.TODO(), config.WorkqueueConfig{},
+		config.PriorityQueueConfig{Enabled: true, CriticalWeight: 3, RegularWeight: 1}, prefixClassifier, promutils.NewTestScope())
+	assert.NoError(t, err)
+	return q
+}
+
+// Items are fetched from the queue of their priority class asynchronously, so the length settles eventually
+func assertLen(t *testing.T, q *PriorityWorkQueue, l int) {
+	assert.Eventually(t, func() bool {
+		return q.Len() == l
+	}, time.Second, time.Millisecond)
+}
+
+func getN(t *testing.T, q *PriorityWorkQueue, n int) []string {
+	items := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		item, shutdown := q.Get()
+		assert.False(t, shutdown)
+		items = append(items, item.(string))
+		q.Done(item)
+	}
+	return items
+}
+
+func TestPriorityWorkQueue(t *testing.T) {
+	t.Run("weighted", func(t *testing.T) {
+		q := newTestPriorityWorkQueue(t)
+		for _, item := range []string{"r1", "r2", "r3", "c1", "c2", "c3", "c4", "c5", "c6"} {
+			q.Add(item)
+		}
+		assertLen(t, q, 9)
+
+		assert.Equal(t, []string{"c1", "c2", "r1", "c3", "c4", "c5", "r2", "c6", "r3"}, getN(t, q, 9))
+		assertLen(t, q, 0)
+	})
+
+	t.Run("only-regular", func(t *testing.T) {
+		q := newTestPriorityWorkQueue(t)
+		q.Add("r1")
+		q.Add("r2")
+		assert.Equal(t, []string{"r1", "r2"}, getN(t, q, 2))
+	})
+
+	t.Run("deduplicated", func(t *testing.T) {
+		q := newTestPriorityWorkQueue(t)
+		q.Add("c1")
+		q.Add("c1")
+		assertLen(t, q, 1)
+
+		item, _ := q.Get()
+		// Re-added while processing, the item is only queued again once done
+		q.Add("c1")
+		assertLen(t, q, 0)
+		q.Done(item)
+		assertLen(t, q, 1)
+	})
+
+	t.Run("add-after", func(t *testing.T) {
+		q := newTestPriorityWorkQueue(t)
+		q.AddAfter("r1", 100*time.Millisecond)
+		// Delayed adds of the same item are deduplicated, keeping the earliest
+		q.AddAfter("r1", 50*time.Millisecond)
+		assert.Equal(t, 0, q.Len())
+		item, shutdown := q.Get()
+		assert.False(t, shutdown)
+		assert.Equal(t, "r1", item)
+		q.Done(item)
+
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, 0, q.Len())
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		q := newTestPriorityWorkQueue(t)
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			q.ShutDown()
+		}()
+		_, shutdown := q.Get()
+		assert.True(t, shutdown)
+		assert.True(t, q.ShuttingDown())
+
+		q.Add("c1")
+		assert.Equal(t, 0, q.Len())
+	})
+}
+
+func TestNewWorkflowPriorityClassifier(t *testing.T) {
+	newWorkflow := func(name, domain string, annotations map[string]string) *v1alpha1.FlyteWorkflow {
+		return &v1alpha1.FlyteWorkflow{
+			ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: name, Annotations: annotations},
+			ExecutionID: v1alpha1.WorkflowExecutionIdentifier{
+				WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: domain, Name: name},
+			},
+		}
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.NoError(t, indexer.Add(newWorkflow("prod", "production", nil)))
+	assert.NoError(t, indexer.Add(newWorkflow("dev", "development", nil)))
+	assert.NoError(t, indexer.Add(newWorkflow("dev-critical", "development",
+		map[string]string{workflowstore.PriorityClassAnnotationKey: "critical"})))
+	assert.NoError(t, indexer.Add(newWorkflow("prod-regular", "production",
+		map[string]string{workflowstore.PriorityClassAnnotationKey: "regular"})))
+	assert.NoError(t, indexer.Add(newWorkflow("prod-unknown", "production",
+		map[string]string{workflowstore.PriorityClassAnnotationKey: "urgent"})))
+
+	classify := NewWorkflowPriorityClassifier(config.PriorityQueueConfig{CriticalDomains: []string{"production"}},
+		lister.NewFlyteWorkflowLister(indexer))
+	assert.Equal(t, workflowstore.PriorityClassCritical, classify("ns/prod"))
+	assert.Equal(t, workflowstore.PriorityClassRegular, classify("ns/dev"))
+	assert.Equal(t, workflowstore.PriorityClassCritical, classify("ns/dev-critical"))
+	assert.Equal(t, workflowstore.PriorityClassRegular, classify("ns/prod-regular"))
+	assert.Equal(t, workflowstore.PriorityClassCritical, classify("ns/prod-unknown"))
+	assert.Equal(t, workflowstore.PriorityClassRegular, classify("ns/missing"))
+	assert.Equal(t, workflowstore.PriorityClassRegular, classify(1))
+}
@@ -0,0 +1,245 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/prometheus/client_golang/prometheus"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/typed/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/compiler/transformers/k8s"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+)
+
+// Number of workflows listed at a time when labeling workflows with their shard keys
+const shardRelabelBatchSize = 100
+
+func isShardingEnabled(cfg config.ShardingConfig) bool {
+	return cfg.ShardCount > 1
+}
+
+func shardKeyLabel(strategy config.ShardingStrategy) (string, error) {
+	switch strategy {
+	case config.ShardingStrategyNamespace:
+		return k8s.NamespaceShardKeyLabel, nil
+	case config.ShardingStrategyProject:
+		return k8s.ProjectShardKeyLabel, nil
+	case config.ShardingStrategyExecution:
+		return k8s.ExecutionShardKeyLabel, nil
+	}
+	return "", fmt.Errorf("unknown sharding strategy [%v]", strategy)
+}
+
+// Returns the index of the shard owned by this replica. Unless configured explicitly, it is the ordinal suffix of the
+// pod name, e.g. flytepropeller-2 owns shard 2.
+func GetShardIndex(cfg config.ShardingConfig) (int, error) {
+	index := cfg.ShardIndex
+	if index < 0 {
+		podName, found := os.LookupEnv(podNameEnvVar)
+		if !found {
+			return 0, fmt.Errorf("shard index not configured and env var [%v] not set", podNameEnvVar)
+		}
+
+		var err error
+		index, err = strconv.Atoi(podName[strings.LastIndex(podName, "-")+1:])
+		if err != nil {
+			return 0, fmt.Errorf("failed to derive shard index from pod name [%v]: %v", podName, err)
+		}
+	}
+
+	if index >= cfg.ShardCount {
+		return 0, fmt.Errorf("shard index [%v] out of range for shard count [%v]", index, cfg.ShardCount)
+	}
+
+	return index, nil
+}
+
+// Returns the shard keys owned by a shard. The key space is split in contiguous ranges whose sizes differ by at most
+// one.
+func ownedShardKeys(index, count int) []string {
+	start := index * k8s.ShardKeySpaceSize / count
+	end := (index + 1) * k8s.ShardKeySpaceSize / count
+	keys := make([]string, 0, end-start)
+	for k := start; k < end; k++ {
+		keys = append(keys, strconv.Itoa(k))
+	}
+	return keys
+}
+
+// Creates a label selector that selects the workflows owned by this replica, or nil if sharding is disabled.
+func NewShardLabelSelector(cfg config.ShardingConfig) (*v1.LabelSelector, error) {
+	if !isShardingEnabled(cfg) {
+		return nil, nil
+	}
+
+	if cfg.ShardCount > k8s.ShardKeySpaceSize {
+		return nil, fmt.Errorf("shard count [%v] exceeds the number of shard keys [%v]", cfg.ShardCount, k8s.ShardKeySpaceSize)
+	}
+
+	label, err := shardKeyLabel(cfg.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := GetShardIndex(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.LabelSelector{
+		MatchExpressions: []v1.LabelSelectorRequirement{
+			{
+				Key:      label,
+				Operator: v1.LabelSelectorOpIn,
+				Values:   ownedShardKeys(index, cfg.ShardCount),
+			},
+		},
+	}, nil
+}
+
+type shardRelabelerMetrics struct {
+	relabeled      prometheus.Counter
+	relabelFailure prometheus.Counter
+	roundTime      promutils.StopWatch
+}
+
+// The shard relabeler is an active background service, run by the first shard, that labels the non completed workflows
+// without shard key labels, e.g. created before sharding was enabled. Otherwise they would not be listed by any replica.
+// Once labeled, a workflow is picked up by the replica that owns its shard key.
+type ShardRelabeler struct {
+	wfClient  v1alpha1.FlyteworkflowV1alpha1Interface
+	namespace string
+	label     string
+	interval  time.Duration
+	clk       clock.Clock
+	metrics   *shardRelabelerMetrics
+}
+
+// Selects the non completed workflows that are missing the shard key label of the configured strategy.
+func (r *ShardRelabeler) selector() *v1.LabelSelector {
+	selector := IgnoreCompletedWorkflowsLabelSelector()
+	selector.MatchExpressions = append(selector.MatchExpressions, v1.LabelSelectorRequirement{
+		Key:      r.label,
+		Operator: v1.LabelSelectorOpDoesNotExist,
+	})
+	return selector
+}
+
+// Labels all the selected workflows with their shard keys. Workflows that fail to be labeled, e.g. as they were updated
+// concurrently, are retried in the next round.
+func (r *ShardRelabeler) relabelWorkflows(ctx context.Context) error {
+	listOptions := v1.ListOptions{
+		LabelSelector: v1.FormatLabelSelector(r.selector()),
+		Limit:         shardRelabelBatchSize,
+	}
+
+	failed := 0
+	for {
+		wList, err := r.wfClient.FlyteWorkflows(r.namespace).List(listOptions)
+		if err != nil {
+			return err
+		}
+
+		for i := range wList.Items {
+			w := &wList.Items[i]
+			if w.Labels == nil {
+				w.Labels = map[string]string{}
+			}
+			k8s.SetShardKeyLabels(w, w.GetExecutionID().GetProject())
+
+			if _, err := r.wfClient.FlyteWorkflows(w.Namespace).Update(w); err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+
+				failed++
+				r.metrics.relabelFailure.Inc()
+				logger.Warnf(ctx, "Failed to label workflow [%s] with its shard keys. Error: [%v]", w.GetK8sWorkflowID(), err)
+				continue
+			}
+
+			r.metrics.relabeled.Inc()
+			logger.Infof(ctx, "Labeled workflow [%s] with shard key [%s]", w.GetK8sWorkflowID(), w.Labels[r.label])
+		}
+
+		if wList.Continue == "" {
+			break
+		}
+		listOptions.Continue = wList.Continue
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to label [%d] workflows", failed)
+	}
+
+	return nil
+}
+
+func (r *ShardRelabeler) runRelabeler(ctx context.Context, ticker clock.Ticker) {
+	logger.Infof(ctx, "Background shard relabeling started, with interval [%s]", r.interval.String())
+
+	ctx = contextutils.WithGoroutineLabel(ctx, "shard-relabeler")
+	pprof.SetGoroutineLabels(ctx)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			t := r.metrics.roundTime.Start()
+			if err := r.relabelWorkflows(ctx); err != nil {
+				logger.Errorf(ctx, "Shard relabeling failed in this round. Error : [%v]", err)
+			}
+			t.Stop()
+		case <-ctx.Done():
+			logger.Infof(ctx, "Shard relabeler stopping")
+			return
+		}
+	}
+}
+
+// Use this method to start the background relabeling. Use the context to signal an exit signal
+func (r *ShardRelabeler) StartRelabeler(ctx context.Context) {
+	go r.runRelabeler(ctx, r.clk.NewTicker(r.interval))
+}
+
+func NewShardRelabeler(cfg *config.Config, scope promutils.Scope, clk clock.Clock,
+	wfClient v1alpha1.FlyteworkflowV1alpha1Interface) (*ShardRelabeler, error) {
+
+	label, err := shardKeyLabel(cfg.Sharding.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Sharding.RelabelInterval.Duration <= 0 {
+		return nil, fmt.Errorf("shard relabel interval [%v] must be positive", cfg.Sharding.RelabelInterval.Duration)
+	}
+
+	namespace := cfg.LimitNamespace
+	if strings.ToLower(namespace) == "all" || strings.ToLower(namespace) == "all-namespaces" {
+		namespace = v1.NamespaceAll
+	}
+
+	scope = scope.NewSubScope("shard")
+	return &ShardRelabeler{
+		wfClient:  wfClient,
+		namespace: namespace,
+		label:     label,
+		interval:  cfg.Sharding.RelabelInterval.Duration,
+		clk:       clk,
+		metrics: &shardRelabelerMetrics{
+			relabeled:      scope.MustNewCounter("relabeled", "Number of workflows labeled with their shard keys"),
+			relabelFailure: scope.MustNewCounter("relabel_failure", "Failures to label a workflow with its shard keys"),
+			roundTime:      scope.MustNewStopWatch("relabel", "Time taken to label the workflows without shard keys", time.Millisecond),
+		},
+	}, nil
+}
@@ -0,0 +1,186 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	stdConfig "github.com/lyft/flytestdlib/config"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clienttesting "k8s.io/client-go/testing"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/client/clientset/versioned/fake"
+	"github.com/lyft/flytepropeller/pkg/compiler/transformers/k8s"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+)
+
+func TestOwnedShardKeys(t *testing.T) {
+	for _, count := range []int{1, 3, 7, 100} {
+		owners := map[string]int{}
+		for index := 0; index < count; index++ {
+			keys := ownedShardKeys(index, count)
+			assert.NotEmpty(t, keys)
+			for _, k := range keys {
+				prev, ok := owners[k]
+				assert.False(t, ok, "key [%v] owned by shards [%v] and [%v]", k, prev, index)
+				owners[k] = index
+			}
+		}
+
+		assert.Len(t, owners, k8s.ShardKeySpaceSize)
+		for k := 0; k < k8s.ShardKeySpaceSize; k++ {
+			assert.Contains(t, owners, strconv.Itoa(k))
+		}
+	}
+}
+
+func TestGetShardIndex(t *testing.T) {
+	t.Run("configured", func(t *testing.T) {
+		index, err := GetShardIndex(config.ShardingConfig{ShardCount: 3, ShardIndex: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, index)
+	})
+
+	t.Run("out-of-range", func(t *testing.T) {
+		_, err := GetShardIndex(config.ShardingConfig{ShardCount: 3, ShardIndex: 3})
+		assert.Error(t, err)
+	})
+
+	t.Run("pod-name", func(t *testing.T) {
+		assert.NoError(t, os.Setenv(podNameEnvVar, "flytepropeller-1"))
+		defer func() { assert.NoError(t, os.Unsetenv(podNameEnvVar)) }()
+
+		index, err := GetShardIndex(config.ShardingConfig{ShardCount: 3, ShardIndex: -1})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, index)
+	})
+
+	t.Run("bad-pod-name", func(t *testing.T) {
+		assert.NoError(t, os.Setenv(podNameEnvVar, "flytepropeller-abc"))
+		defer func() { assert.NoError(t, os.Unsetenv(podNameEnvVar)) }()
+
+		_, err := GetShardIndex(config.ShardingConfig{ShardCount: 3, ShardIndex: -1})
+		assert.Error(t, err)
+	})
+
+	t.Run("no-pod-name", func(t *testing.T) {
+		_, err := GetShardIndex(config.ShardingConfig{ShardCount: 3, ShardIndex: -1})
+		assert.Error(t, err)
+	})
+}
+
+func TestNewShardLabelSelector(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		selector, err := NewShardLabelSelector(config.ShardingConfig{ShardCount: 1, ShardIndex: -1})
+		assert.NoError(t, err)
+		assert.Nil(t, selector)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		selector, err := NewShardLabelSelector(config.ShardingConfig{
+			ShardCount: 4,
+			ShardIndex: 1,
+			Strategy:   config.ShardingStrategyProject,
+		})
+		assert.NoError(t, err)
+		if assert.NotNil(t, selector) && assert.Len(t, selector.MatchExpressions, 1) {
+			req := selector.MatchExpressions[0]
+			assert.Equal(t, k8s.ProjectShardKeyLabel, req.Key)
+			assert.Equal(t, v1.LabelSelectorOpIn, req.Operator)
+			assert.Len(t, req.Values, 25)
+			assert.Equal(t, "25", req.Values[0])
+			assert.Equal(t, "49", req.Values[24])
+		}
+	})
+
+	t.Run("unknown-strategy", func(t *testing.T) {
+		_, err := NewShardLabelSelector(config.ShardingConfig{ShardCount: 2, ShardIndex: 0, Strategy: "random"})
+		assert.Error(t, err)
+	})
+
+	t.Run("too-many-shards", func(t *testing.T) {
+		_, err := NewShardLabelSelector(config.ShardingConfig{
+			ShardCount: k8s.ShardKeySpaceSize + 1,
+			ShardIndex: 0,
+			Strategy:   config.ShardingStrategyExecution,
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestShardRelabeler(t *testing.T) {
+	ctx := context.TODO()
+	newWorkflow := func(name string, labels map[string]string) *v1alpha1.FlyteWorkflow {
+		return &v1alpha1.FlyteWorkflow{
+			ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: name, Labels: labels},
+			ExecutionID: v1alpha1.WorkflowExecutionIdentifier{
+				WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: "d", Name: name},
+			},
+		}
+	}
+
+	completed := newWorkflow("completed", nil)
+	SetCompletedLabel(completed, time.Now())
+	labeled := newWorkflow("labeled", map[string]string{k8s.ExecutionShardKeyLabel: "0"})
+	workflows := []*v1alpha1.FlyteWorkflow{newWorkflow("legacy", map[string]string{k8s.ExecutionIDLabel: "legacy"}),
+		newWorkflow("no-labels", nil), labeled, completed}
+	client := fake.NewSimpleClientset()
+	for _, w := range workflows {
+		_, err := client.FlyteworkflowV1alpha1().FlyteWorkflows(w.Namespace).Create(w.DeepCopy())
+		assert.NoError(t, err)
+	}
+
+	// The object tracker of the fake clientset cannot list flyteworkflows, serve the list from the tracked objects
+	client.PrependReactor("list", "flyteworkflows", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		selector := action.(clienttesting.ListAction).GetListRestrictions().Labels
+		list := &v1alpha1.FlyteWorkflowList{}
+		for _, w := range workflows {
+			obj, err := client.Tracker().Get(action.GetResource(), w.Namespace, w.Name)
+			if err == nil && selector.Matches(k8slabels.Set(obj.(*v1alpha1.FlyteWorkflow).Labels)) {
+				list.Items = append(list.Items, *obj.(*v1alpha1.FlyteWorkflow))
+			}
+		}
+		return true, list, nil
+	})
+
+	cfg := &config.Config{
+		LimitNamespace: "all",
+		Sharding: config.ShardingConfig{
+			ShardCount:      2,
+			Strategy:        config.ShardingStrategyExecution,
+			RelabelInterval: stdConfig.Duration{Duration: time.Minute},
+		},
+	}
+	r, err := NewShardRelabeler(cfg, promutils.NewTestScope(), clock.NewFakeClock(time.Now()), client.FlyteworkflowV1alpha1())
+	assert.NoError(t, err)
+	assert.NoError(t, r.relabelWorkflows(ctx))
+
+	get := func(name string) *v1alpha1.FlyteWorkflow {
+		w, err := client.FlyteworkflowV1alpha1().FlyteWorkflows("ns").Get(name, v1.GetOptions{})
+		assert.NoError(t, err)
+		return w
+	}
+
+	legacy := get("legacy")
+	assert.Equal(t, strconv.Itoa(k8s.ComputeShardKey("legacy")), legacy.Labels[k8s.ExecutionShardKeyLabel])
+	assert.Equal(t, strconv.Itoa(k8s.ComputeShardKey("p")), legacy.Labels[k8s.ProjectShardKeyLabel])
+	assert.Equal(t, strconv.Itoa(k8s.ComputeShardKey("ns")), legacy.Labels[k8s.NamespaceShardKeyLabel])
+	assert.Equal(t, strconv.Itoa(k8s.ComputeShardKey("no-labels")), get("no-labels").Labels[k8s.ExecutionShardKeyLabel])
+	assert.Equal(t, labeled.Labels, get("labeled").Labels)
+	assert.NotContains(t, get("completed").Labels, k8s.ExecutionShardKeyLabel)
+
+	t.Run("bad-interval", func(t *testing.T) {
+		cfg := &config.Config{Sharding: config.ShardingConfig{ShardCount: 2, Strategy: config.ShardingStrategyExecution}}
+		_, err := NewShardRelabeler(cfg, promutils.NewTestScope(), clock.NewFakeClock(time.Now()), client.FlyteworkflowV1alpha1())
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,278 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/contextutils"
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	lister "github.com/lyft/flytepropeller/pkg/client/listers/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+const (
+	stuckWorkflowEventReason = "StuckWorkflow"
+	stuckWorkflowErrorCode   = "StuckWorkflow"
+)
+
+type stuckWorkflowMetrics struct {
+	stuck       *prometheus.GaugeVec
+	detected    labeled.Counter
+	enqueued    labeled.Counter
+	failed      labeled.Counter
+	failFailure labeled.Counter
+	scanTime    promutils.StopWatch
+}
+
+// The stuck workflow detector is an active background service, that periodically scans the informer cache for
+// running workflows that have not made any progress within the configured threshold. Stuck workflows are reported
+// through metrics and k8s events and, depending on the configured remediation, re-enqueued or failed.
+type StuckWorkflowDetector struct {
+	cfg      config.StuckWorkflowsConfig
+	lister   lister.FlyteWorkflowLister
+	wfStore  workflowstore.FlyteWorkflow
+	enqueue  v1alpha1.EnqueueWorkflow
+	recorder record.EventRecorder
+	clk      clock.Clock
+	metrics  *stuckWorkflowMetrics
+}
+
+func latestTime(latest time.Time, t *time.Time) time.Time {
+	if t != nil && t.After(latest) {
+		return *t
+	}
+	return latest
+}
+
+// Returns the last time the status of the workflow, or of any of its nodes, was updated, and whether any of its
+// nodes is legitimately waiting (i.e. an active gate or sleep node, or a node waiting for the retry delay of its next
+// attempt to pass). Workflows are read from the informer cache, so the
+// node statuses of workflows that were offloaded to blob storage are not available. Their progress is only tracked by
+// the workflow status, and they are never considered to be waiting.
+func lastProgress(w *v1alpha1.FlyteWorkflow, now time.Time) (time.Time, bool) {
+	waitingNodes := map[v1alpha1.NodeID]bool{}
+	specs := []*v1alpha1.WorkflowSpec{w.WorkflowSpec}
+	for _, sw := range w.SubWorkflows {
+		specs = append(specs, sw)
+	}
+
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+
+		for id, n := range spec.Nodes {
+			if kind := n.GetKind(); kind == v1alpha1.NodeKindGate || kind == v1alpha1.NodeKindSleep {
+				waitingNodes[id] = true
+			}
+		}
+	}
+
+	latest := w.GetCreationTimestamp().Time
+	if w.Status.LastUpdatedAt != nil {
+		latest = latestTime(latest, &w.Status.LastUpdatedAt.Time)
+	}
+
+	waiting := false
+	var visit func(nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus)
+	visit = func(nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus) {
+		for id, n := range nodeStatus {
+			if n == nil {
+				continue
+			}
+
+			if n.LastUpdatedAt != nil {
+				latest = latestTime(latest, &n.LastUpdatedAt.Time)
+			}
+
+			if n.TaskNodeStatus != nil {
+				latest = latestTime(latest, &n.TaskNodeStatus.LastPhaseUpdatedAt)
+			}
+
+			if waitingNodes[id] && n.Phase != v1alpha1.NodePhaseNotYetStarted && !v1alpha1.IsPhaseTerminal(n.Phase) {
+				waiting = true
+			}
+
+			// The status of a node is not updated until its next attempt starts
+			if nextAttemptAt := n.GetNextAttemptAt(); nextAttemptAt != nil && nextAttemptAt.Time.After(now) {
+				waiting = true
+			}
+
+			visit(n.SubNodeStatus)
+		}
+	}
+	visit(w.Status.NodeStatus)
+
+	return latest, waiting
+}
+
+// Returns how long the workflow has been stuck for, if it is stuck.
+func (d *StuckWorkflowDetector) stuckFor(w *v1alpha1.FlyteWorkflow, now time.Time) (time.Duration, bool) {
+	phase := w.GetExecutionStatus().GetPhase()
+	if v1alpha1.IsWorkflowPhaseTerminal(phase) || phase == v1alpha1.WorkflowPhaseQueued || w.GetDeletionTimestamp() != nil {
+		return 0, false
+	}
+
+	latest, waiting := lastProgress(w, now)
+	if waiting {
+		return 0, false
+	}
+
+	since := now.Sub(latest)
+	return since, since > d.cfg.Threshold.Duration
+}
+
+// Fails the workflow with a system error. The workflow is moved to failing, so that the workflow executor aborts its
+// running nodes and reports the failure the same way as for any other failure.
+func (d *StuckWorkflowDetector) fail(ctx context.Context, w *v1alpha1.FlyteWorkflow, msg string) error {
+	// The informer cache may lag behind, re-check the latest version of the workflow before failing it
+	latest, err := d.wfStore.Get(ctx, w.GetNamespace(), w.GetName())
+	if err != nil {
+		return err
+	}
+
+	if _, stuck := d.stuckFor(latest, d.clk.Now()); !stuck {
+		logger.Infof(ctx, "Workflow [%s] made progress, not failing it", w.GetK8sWorkflowID())
+		return nil
+	}
+
+	failing := latest.DeepCopy()
+	failing.Status.UpdatePhase(v1alpha1.WorkflowPhaseFailing, msg, &core.ExecutionError{
+		Code:    stuckWorkflowErrorCode,
+		Message: msg,
+		Kind:    core.ExecutionError_SYSTEM,
+	})
+
+	_, err = d.wfStore.Update(ctx, failing, workflowstore.PriorityClassCritical)
+	return err
+}
+
+func (d *StuckWorkflowDetector) remediate(ctx context.Context, w *v1alpha1.FlyteWorkflow, msg string) {
+	switch d.cfg.Remediation {
+	case config.StuckWorkflowRemediationEnqueue:
+		d.metrics.enqueued.Inc(ctx)
+		d.enqueue(w.GetK8sWorkflowID().String())
+	case config.StuckWorkflowRemediationFail:
+		if err := d.fail(ctx, w, msg); err != nil {
+			d.metrics.failFailure.Inc(ctx)
+			logger.Errorf(ctx, "Failed to fail stuck workflow [%s]. Error: [%v]", w.GetK8sWorkflowID(), err)
+			return
+		}
+
+		d.metrics.failed.Inc(ctx)
+		d.enqueue(w.GetK8sWorkflowID().String())
+	}
+}
+
+// Scans all workflows in the informer cache once, and returns the number of stuck workflows found.
+func (d *StuckWorkflowDetector) detect(ctx context.Context) int {
+	workflows, err := d.lister.List(labels.Everything())
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list workflows to detect stuck workflows. Error: [%v]", err)
+		return 0
+	}
+
+	now := d.clk.Now()
+	counts := map[[2]string]int{}
+	total := 0
+	for _, w := range workflows {
+		since, stuck := d.stuckFor(w, now)
+		if !stuck {
+			continue
+		}
+
+		project, domain := projectDomain(ctx, w)
+		wfCtx := contextutils.WithProjectDomain(ctx, project, domain)
+		wfCtx = contextutils.WithWorkflowID(wfCtx, w.GetID())
+		counts[[2]string{project, domain}]++
+		total++
+
+		msg := fmt.Sprintf("Workflow has not made progress for [%v] while [%v]", since.Truncate(time.Second), w.GetExecutionStatus().GetPhase())
+		logger.Warnf(wfCtx, "Detected stuck workflow [%s]. %s", w.GetK8sWorkflowID(), msg)
+		d.metrics.detected.Inc(wfCtx)
+		d.recorder.Event(w, corev1.EventTypeWarning, stuckWorkflowEventReason, msg)
+		d.remediate(wfCtx, w, msg)
+	}
+
+	d.metrics.stuck.Reset()
+	for k, count := range counts {
+		d.metrics.stuck.WithLabelValues(k[:]...).Set(float64(count))
+	}
+
+	return total
+}
+
+func (d *StuckWorkflowDetector) runDetector(ctx context.Context, ticker clock.Ticker) {
+	logger.Infof(ctx, "Background stuck workflow detection started, with interval [%s], threshold [%s], remediation [%s]",
+		d.cfg.Interval.String(), d.cfg.Threshold.String(), d.cfg.Remediation)
+
+	ctx = contextutils.WithGoroutineLabel(ctx, "stuck-workflow-detector")
+	pprof.SetGoroutineLabels(ctx)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+			t := d.metrics.scanTime.Start()
+			d.detect(ctx)
+			t.Stop()
+		case <-ctx.Done():
+			logger.Infof(ctx, "Stuck workflow detector stopping")
+			return
+		}
+	}
+}
+
+// Use this method to start the background stuck workflow detection. Use the context to signal an exit signal
+func (d *StuckWorkflowDetector) StartDetector(ctx context.Context) {
+	if !d.cfg.Enabled {
+		logger.Infof(ctx, "Stuck workflow detection is disabled")
+		return
+	}
+
+	go d.runDetector(ctx, d.clk.NewTicker(d.cfg.Interval.Duration))
+}
+
+func NewStuckWorkflowDetector(cfg config.StuckWorkflowsConfig, scope promutils.Scope, clk clock.Clock, lister lister.FlyteWorkflowLister,
+	wfStore workflowstore.FlyteWorkflow, enqueue v1alpha1.EnqueueWorkflow, recorder record.EventRecorder) (*StuckWorkflowDetector, error) {
+
+	switch cfg.Remediation {
+	case config.StuckWorkflowRemediationNone, config.StuckWorkflowRemediationEnqueue, config.StuckWorkflowRemediationFail:
+	default:
+		return nil, fmt.Errorf("unknown stuck workflow remediation [%s], expected one of none, enqueue or fail", cfg.Remediation)
+	}
+
+	if cfg.Enabled && (cfg.Interval.Duration <= 0 || cfg.Threshold.Duration <= 0) {
+		return nil, fmt.Errorf("stuck workflow interval [%v] and threshold [%v] must be positive", cfg.Interval.Duration, cfg.Threshold.Duration)
+	}
+
+	scope = scope.NewSubScope("stuck")
+	return &StuckWorkflowDetector{
+		cfg:      cfg,
+		lister:   lister,
+		wfStore:  wfStore,
+		enqueue:  enqueue,
+		recorder: recorder,
+		clk:      clk,
+		metrics: &stuckWorkflowMetrics{
+			stuck:       scope.MustNewGaugeVec("workflows", "Current number of stuck workflows", "project", "domain"),
+			detected:    labeled.NewCounter("detected", "Number of times a workflow was found stuck", scope),
+			enqueued:    labeled.NewCounter("enqueued", "Number of times a stuck workflow was re-enqueued", scope),
+			failed:      labeled.NewCounter("failed", "Number of stuck workflows failed", scope),
+			failFailure: labeled.NewCounter("fail_failure", "Failures to fail a stuck workflow", scope),
+			scanTime:    scope.MustNewStopWatch("scan", "Time taken to scan for stuck workflows", time.Millisecond),
+		},
+	}, nil
+}
@@ -0,0 +1,212 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	stdConfig "github.com/lyft/flytestdlib/config"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	lister "github.com/lyft/flytepropeller/pkg/client/listers/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/workflowstore"
+)
+
+func newStuckTestWorkflow(name string, phase v1alpha1.WorkflowPhase, lastUpdated time.Time) *v1alpha1.FlyteWorkflow {
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace:         "ns",
+			Name:              name,
+			CreationTimestamp: v1.Time{Time: lastUpdated.Add(-time.Hour)},
+		},
+		ExecutionID: v1alpha1.WorkflowExecutionIdentifier{
+			WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: "d", Name: name},
+		},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: name,
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				"task": {ID: "task", Kind: v1alpha1.NodeKindTask},
+				"gate": {ID: "gate", Kind: v1alpha1.NodeKindGate},
+			},
+		},
+		Status: v1alpha1.WorkflowStatus{
+			Phase:         phase,
+			LastUpdatedAt: &v1.Time{Time: lastUpdated},
+		},
+	}
+}
+
+func newTestStuckWorkflowDetector(t *testing.T, remediation config.StuckWorkflowRemediation, now time.Time,
+	workflows ...*v1alpha1.FlyteWorkflow) (*StuckWorkflowDetector, workflowstore.FlyteWorkflow, *[]string) {
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	wfStore := workflowstore.NewInMemoryWorkflowStore()
+	for _, w := range workflows {
+		assert.NoError(t, indexer.Add(w))
+		assert.NoError(t, wfStore.Create(context.TODO(), w.DeepCopy()))
+	}
+
+	enqueued := &[]string{}
+	d, err := NewStuckWorkflowDetector(config.StuckWorkflowsConfig{
+		Enabled:     true,
+		Interval:    stdConfig.Duration{Duration: time.Minute},
+		Threshold:   stdConfig.Duration{Duration: time.Hour},
+		Remediation: remediation,
+	}, promutils.NewTestScope(), clock.NewFakeClock(now), lister.NewFlyteWorkflowLister(indexer), wfStore,
+		func(workflowID v1alpha1.WorkflowID) { *enqueued = append(*enqueued, workflowID) }, record.NewFakeRecorder(10))
+	assert.NoError(t, err)
+	return d, wfStore, enqueued
+}
+
+func TestLastProgress(t *testing.T) {
+	now := time.Now()
+
+	t.Run("workflow", func(t *testing.T) {
+		w := newStuckTestWorkflow("w", v1alpha1.WorkflowPhaseRunning, now)
+		latest, waiting := lastProgress(w, now)
+		assert.Equal(t, now, latest)
+		assert.False(t, waiting)
+	})
+
+	t.Run("nested-node", func(t *testing.T) {
+		w := newStuckTestWorkflow("w", v1alpha1.WorkflowPhaseRunning, now.Add(-3*time.Hour))
+		w.Status.NodeStatus = map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+			"task": {
+				Phase:         v1alpha1.NodePhaseRunning,
+				LastUpdatedAt: &v1.Time{Time: now.Add(-2 * time.Hour)},
+				SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+					"sub": {
+						Phase:          v1alpha1.NodePhaseRunning,
+						TaskNodeStatus: &v1alpha1.TaskNodeStatus{LastPhaseUpdatedAt: now.Add(-time.Minute)},
+					},
+				},
+			},
+		}
+
+		latest, waiting := lastProgress(w, now)
+		assert.Equal(t, now.Add(-time.Minute), latest)
+		assert.False(t, waiting)
+	})
+
+	t.Run("waiting-gate", func(t *testing.T) {
+		w := newStuckTestWorkflow("w", v1alpha1.WorkflowPhaseRunning, now)
+		w.Status.NodeStatus = map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+			"gate": {Phase: v1alpha1.NodePhaseRunning},
+		}
+
+		_, waiting := lastProgress(w, now)
+		assert.True(t, waiting)
+
+		w.Status.NodeStatus["gate"].Phase = v1alpha1.NodePhaseSucceeded
+		_, waiting = lastProgress(w, now)
+		assert.False(t, waiting)
+	})
+
+	t.Run("waiting-retry", func(t *testing.T) {
+		w := newStuckTestWorkflow("w", v1alpha1.WorkflowPhaseRunning, now.Add(-2*time.Hour))
+		w.Status.NodeStatus = map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+			"task": {
+				Phase:         v1alpha1.NodePhaseRetryableFailure,
+				LastUpdatedAt: &v1.Time{Time: now.Add(-2 * time.Hour)},
+				NextAttemptAt: &v1.Time{Time: now.Add(time.Hour)},
+			},
+		}
+
+		_, waiting := lastProgress(w, now)
+		assert.True(t, waiting)
+
+		// Once the retry delay passed, the node is expected to make progress
+		_, waiting = lastProgress(w, now.Add(2*time.Hour))
+		assert.False(t, waiting)
+	})
+}
+
+func TestStuckWorkflowDetector_detect(t *testing.T) {
+	ctx := context.TODO()
+	now := time.Now()
+
+	gated := newStuckTestWorkflow("gated", v1alpha1.WorkflowPhaseRunning, now.Add(-3*time.Hour))
+	gated.Status.NodeStatus = map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+		"gate": {Phase: v1alpha1.NodePhaseRunning},
+	}
+
+	// The retry delay of the task is longer than the threshold
+	backingOff := newStuckTestWorkflow("backing-off", v1alpha1.WorkflowPhaseRunning, now.Add(-2*time.Hour))
+	backingOff.Status.NodeStatus = map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+		"task": {Phase: v1alpha1.NodePhaseRetryableFailure, NextAttemptAt: &v1.Time{Time: now.Add(time.Hour)}},
+	}
+
+	workflows := []*v1alpha1.FlyteWorkflow{
+		newStuckTestWorkflow("stuck", v1alpha1.WorkflowPhaseRunning, now.Add(-2*time.Hour)),
+		newStuckTestWorkflow("recent", v1alpha1.WorkflowPhaseRunning, now.Add(-time.Minute)),
+		newStuckTestWorkflow("queued", v1alpha1.WorkflowPhaseQueued, now.Add(-2*time.Hour)),
+		newStuckTestWorkflow("done", v1alpha1.WorkflowPhaseSuccess, now.Add(-2*time.Hour)),
+		gated,
+		backingOff,
+	}
+
+	t.Run("none", func(t *testing.T) {
+		d, wfStore, enqueued := newTestStuckWorkflowDetector(t, config.StuckWorkflowRemediationNone, now, workflows...)
+		assert.Equal(t, 1, d.detect(ctx))
+		assert.Empty(t, *enqueued)
+
+		w, err := wfStore.Get(ctx, "ns", "stuck")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseRunning, w.Status.Phase)
+	})
+
+	t.Run("enqueue", func(t *testing.T) {
+		d, _, enqueued := newTestStuckWorkflowDetector(t, config.StuckWorkflowRemediationEnqueue, now, workflows...)
+		assert.Equal(t, 1, d.detect(ctx))
+		assert.Equal(t, []string{"ns/stuck"}, *enqueued)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		d, wfStore, enqueued := newTestStuckWorkflowDetector(t, config.StuckWorkflowRemediationFail, now, workflows...)
+		assert.Equal(t, 1, d.detect(ctx))
+		assert.Equal(t, []string{"ns/stuck"}, *enqueued)
+
+		w, err := wfStore.Get(ctx, "ns", "stuck")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseFailing, w.Status.Phase)
+		if assert.NotNil(t, w.Status.Error) {
+			assert.Equal(t, stuckWorkflowErrorCode, w.Status.Error.Code)
+		}
+
+		w, err = wfStore.Get(ctx, "ns", "backing-off")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseRunning, w.Status.Phase)
+	})
+
+	t.Run("fail-made-progress", func(t *testing.T) {
+		d, wfStore, enqueued := newTestStuckWorkflowDetector(t, config.StuckWorkflowRemediationFail, now, workflows...)
+		// The informer cache is stale, the workflow progressed since
+		w, err := wfStore.Get(ctx, "ns", "stuck")
+		assert.NoError(t, err)
+		w.Status.LastUpdatedAt = &v1.Time{Time: now}
+
+		assert.Equal(t, 1, d.detect(ctx))
+		w, err = wfStore.Get(ctx, "ns", "stuck")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseRunning, w.Status.Phase)
+		assert.Equal(t, []string{"ns/stuck"}, *enqueued)
+	})
+}
+
+func TestNewStuckWorkflowDetector(t *testing.T) {
+	_, err := NewStuckWorkflowDetector(config.StuckWorkflowsConfig{Remediation: "restart"}, promutils.NewTestScope(),
+		clock.NewFakeClock(time.Now()), nil, nil, nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewStuckWorkflowDetector(config.StuckWorkflowsConfig{Enabled: true, Remediation: config.StuckWorkflowRemediationNone},
+		promutils.NewTestScope(), clock.NewFakeClock(time.Now()), nil, nil, nil, nil)
+	assert.Error(t, err)
+}
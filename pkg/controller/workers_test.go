@@ -28,7 +28,7 @@ func (t *testHandler) Handle(ctx context.Context, namespace, key string) error {
 
 func simpleWorkQ(ctx context.Context, t *testing.T, testScope promutils.Scope) CompositeWorkQueue {
 	cfg := config.CompositeQueueConfig{}
-	q, err := NewCompositeWorkQueue(ctx, cfg, testScope)
+	q, err := NewCompositeWorkQueue(ctx, cfg, nil, testScope)
 	assert.NoError(t, err)
 	assert.NotNil(t, q)
 	return q
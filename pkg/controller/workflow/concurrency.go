@@ -0,0 +1,271 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+)
+
+const (
+	limitKindNamespace  = "namespace"
+	limitKindProject    = "project"
+	limitKindLaunchPlan = "launch_plan"
+)
+
+// ConcurrencyLimiter decides whether a ready (or queued) workflow may start running.
+type ConcurrencyLimiter interface {
+	// Returns true if the workflow may start running. Otherwise returns false and a message describing the limit that
+	// holds the workflow back.
+	Admit(ctx context.Context, w *v1alpha1.FlyteWorkflow) (bool, string)
+}
+
+type noopConcurrencyLimiter struct{}
+
+func (noopConcurrencyLimiter) Admit(_ context.Context, _ *v1alpha1.FlyteWorkflow) (bool, string) {
+	return true, ""
+}
+
+// Returns a limiter that admits every workflow.
+func NewNoopConcurrencyLimiter() ConcurrencyLimiter {
+	return noopConcurrencyLimiter{}
+}
+
+type concurrencyLimit struct {
+	kind  string
+	key   string
+	limit int
+}
+
+type concurrencyMetrics struct {
+	admitted   prometheus.Counter
+	held       prometheus.Counter
+	queueDepth *prometheus.GaugeVec
+}
+
+// Limits the number of concurrently running workflows per namespace, project and launch plan. Running workflows are
+// counted from the informer cache, which is indexed by the key of each kind of limit so that only the workflows sharing
+// a limit with the admitted workflow are visited.
+type concurrencyLimiter struct {
+	cfg     config.ConcurrencyLimits
+	indexer cache.Indexer
+	metrics concurrencyMetrics
+	lock    sync.Mutex
+	// The informer cache lags behind the updates propeller makes. Workflows admitted by this limiter are tracked, keyed
+	// by their k8s id, until they show up as started in the cache so that they count against the limits right away.
+	admitted map[string]bool
+}
+
+func limitOrDefault(limits map[string]int, key string, defaultLimit int) int {
+	if l, ok := limits[key]; ok {
+		return l
+	}
+	return defaultLimit
+}
+
+// Name of the informer index of the workflows by the key of a kind of limit
+func limitIndexName(kind string) string {
+	return "concurrency-" + kind
+}
+
+// Returns the key of the limit of the kind that applies to the workflow, false if the workflow is not subject to any
+// limit of the kind.
+func (l *concurrencyLimiter) limitKey(kind string, w *v1alpha1.FlyteWorkflow) (string, bool) {
+	switch kind {
+	case limitKindNamespace:
+		return w.GetNamespace(), true
+	case limitKindProject:
+		if execID := w.GetExecutionID(); execID.WorkflowExecutionIdentifier != nil {
+			return execID.GetProject(), true
+		}
+	case limitKindLaunchPlan:
+		if l.cfg.LaunchPlanLabel != "" {
+			lp, ok := w.GetLabels()[l.cfg.LaunchPlanLabel]
+			return lp, ok
+		}
+	}
+	return "", false
+}
+
+func (l *concurrencyLimiter) limitsFor(w *v1alpha1.FlyteWorkflow) []concurrencyLimit {
+	var limits []concurrencyLimit
+	for _, kind := range []string{limitKindNamespace, limitKindProject, limitKindLaunchPlan} {
+		key, ok := l.limitKey(kind, w)
+		if !ok {
+			continue
+		}
+
+		var limit int
+		switch kind {
+		case limitKindNamespace:
+			limit = limitOrDefault(l.cfg.NamespaceLimits, key, l.cfg.NamespaceLimit)
+		case limitKindProject:
+			limit = limitOrDefault(l.cfg.ProjectLimits, key, l.cfg.ProjectLimit)
+		case limitKindLaunchPlan:
+			limit = limitOrDefault(l.cfg.LaunchPlanLimits, key, l.cfg.LaunchPlanLimit)
+		}
+
+		if limit > 0 {
+			limits = append(limits, concurrencyLimit{kind: kind, key: key, limit: limit})
+		}
+	}
+
+	return limits
+}
+
+// Returns the indexes of the workflows by the key of each kind of limit
+func (l *concurrencyLimiter) indexers() cache.Indexers {
+	indexers := cache.Indexers{}
+	for _, kind := range []string{limitKindNamespace, limitKindProject, limitKindLaunchPlan} {
+		kind := kind
+		indexers[limitIndexName(kind)] = func(obj interface{}) ([]string, error) {
+			w, ok := obj.(*v1alpha1.FlyteWorkflow)
+			if !ok {
+				return nil, nil
+			}
+
+			if key, ok := l.limitKey(kind, w); ok {
+				return []string{key}, nil
+			}
+			return nil, nil
+		}
+	}
+	return indexers
+}
+
+// Stops tracking an admitted workflow once it shows up as started in the cache.
+func (l *concurrencyLimiter) onUpdate(obj interface{}) {
+	w, ok := obj.(*v1alpha1.FlyteWorkflow)
+	if !ok {
+		return
+	}
+
+	phase := w.GetExecutionStatus().GetPhase()
+	if phase != v1alpha1.WorkflowPhaseReady && phase != v1alpha1.WorkflowPhaseQueued {
+		l.forget(obj)
+	}
+}
+
+// Stops tracking an admitted workflow, e.g. when it is deleted before it started.
+func (l *concurrencyLimiter) forget(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.admitted, key)
+}
+
+func (l *concurrencyLimiter) Admit(ctx context.Context, w *v1alpha1.FlyteWorkflow) (bool, string) {
+	limits := l.limitsFor(w)
+	if len(limits) == 0 {
+		return true, ""
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	key := w.GetK8sWorkflowID().String()
+	if l.admitted[key] {
+		return true, ""
+	}
+
+	running := make([]int, len(limits))
+	queued := make([]int, len(limits))
+	for i, limit := range limits {
+		workflows, err := l.indexer.ByIndex(limitIndexName(limit.kind), limit.key)
+		if err != nil {
+			// Rather than blocking all executions on a broken cache, admit the workflow
+			logger.Errorf(ctx, "Failed to list workflows to enforce concurrency limits, admitting workflow. Error [%v]", err)
+			return true, ""
+		}
+
+		for _, obj := range workflows {
+			other, ok := obj.(*v1alpha1.FlyteWorkflow)
+			if !ok {
+				continue
+			}
+
+			otherKey := other.GetK8sWorkflowID().String()
+			phase := other.GetExecutionStatus().GetPhase()
+			if otherKey == key || v1alpha1.IsWorkflowPhaseTerminal(phase) {
+				continue
+			}
+
+			notStarted := phase == v1alpha1.WorkflowPhaseReady || phase == v1alpha1.WorkflowPhaseQueued
+			if !notStarted || l.admitted[otherKey] {
+				running[i]++
+			} else if phase == v1alpha1.WorkflowPhaseQueued {
+				queued[i]++
+			}
+		}
+	}
+
+	for i, limit := range limits {
+		if running[i] >= limit.limit {
+			// The held workflow counts towards the queue of every limit that applies to it
+			for j, queuedLimit := range limits {
+				l.metrics.queueDepth.WithLabelValues(queuedLimit.kind, queuedLimit.key).Set(float64(queued[j] + 1))
+			}
+			l.metrics.held.Inc()
+			return false, fmt.Sprintf("Workflow is queued, [%d/%d] workflows are running for %s [%s]", running[i], limit.limit, limit.kind, limit.key)
+		}
+	}
+
+	for i, limit := range limits {
+		l.metrics.queueDepth.WithLabelValues(limit.kind, limit.key).Set(float64(queued[i]))
+	}
+
+	l.admitted[key] = true
+	l.metrics.admitted.Inc()
+	return true, ""
+}
+
+func newConcurrencyLimiter(cfg config.ConcurrencyLimits, scope promutils.Scope) *concurrencyLimiter {
+	concurrencyScope := scope.NewSubScope("concurrency")
+	return &concurrencyLimiter{
+		cfg: cfg,
+		metrics: concurrencyMetrics{
+			admitted:   concurrencyScope.MustNewCounter("admitted", "Number of workflows admitted by the concurrency limiter"),
+			held:       concurrencyScope.MustNewCounter("held", "Number of times a workflow was held back by a concurrency limit"),
+			queueDepth: concurrencyScope.MustNewGaugeVec("queue_depth", "Number of workflows queued per concurrency limit", "limit", "key"),
+		},
+		admitted: map[string]bool{},
+	}
+}
+
+// Returns a limiter enforcing the configured concurrency limits, or one that admits every workflow if no limits are
+// configured. The limiter adds its indexes to the workflow informer, which must not be started yet.
+func NewConcurrencyLimiter(cfg config.ConcurrencyLimits, informer cache.SharedIndexInformer, scope promutils.Scope) (
+	ConcurrencyLimiter, error) {
+
+	if cfg.NamespaceLimit <= 0 && len(cfg.NamespaceLimits) == 0 && cfg.ProjectLimit <= 0 && len(cfg.ProjectLimits) == 0 &&
+		(cfg.LaunchPlanLabel == "" || (cfg.LaunchPlanLimit <= 0 && len(cfg.LaunchPlanLimits) == 0)) {
+		return NewNoopConcurrencyLimiter(), nil
+	}
+
+	l := newConcurrencyLimiter(cfg, scope)
+	if err := informer.AddIndexers(l.indexers()); err != nil {
+		return nil, errors.Wrapf(err, "failed to index workflows for concurrency limits")
+	}
+	l.indexer = informer.GetIndexer()
+
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, newObj interface{}) {
+			l.onUpdate(newObj)
+		},
+		DeleteFunc: l.forget,
+	})
+
+	return l, nil
+}
@@ -0,0 +1,199 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lyft/flyteidl/clients/go/events"
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/yamlutils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+	"github.com/lyft/flytepropeller/pkg/controller/config"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/subworkflow/launchplan"
+	"github.com/lyft/flytepropeller/pkg/controller/nodes/task/catalog"
+)
+
+func newLimitedWorkflow(namespace, name, project, launchPlan string, phase v1alpha1.WorkflowPhase) *v1alpha1.FlyteWorkflow {
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{"launch-plan": launchPlan},
+		},
+		ExecutionID: v1alpha1.WorkflowExecutionIdentifier{
+			WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: project, Domain: "development", Name: name},
+		},
+		Status: v1alpha1.WorkflowStatus{Phase: phase},
+	}
+}
+
+func newTestLimiter(t *testing.T, cfg config.ConcurrencyLimits, workflows ...*v1alpha1.FlyteWorkflow) (ConcurrencyLimiter, cache.Indexer) {
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &v1alpha1.FlyteWorkflow{}, 0,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	l, err := NewConcurrencyLimiter(cfg, informer, promutils.NewTestScope())
+	assert.NoError(t, err)
+
+	indexer := informer.GetIndexer()
+	for _, w := range workflows {
+		assert.NoError(t, indexer.Add(w))
+	}
+	return l, indexer
+}
+
+func TestConcurrencyLimiter_Admit(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("no-limits", func(t *testing.T) {
+		l, err := NewConcurrencyLimiter(config.ConcurrencyLimits{LaunchPlanLimit: 1}, nil, promutils.NewTestScope())
+		assert.NoError(t, err)
+		admitted, _ := l.Admit(ctx, newLimitedWorkflow("ns", "w", "p", "lp", v1alpha1.WorkflowPhaseReady))
+		assert.True(t, admitted)
+	})
+
+	t.Run("namespace", func(t *testing.T) {
+		cfg := config.ConcurrencyLimits{NamespaceLimit: 2, NamespaceLimits: map[string]int{"other": 5}}
+		l, _ := newTestLimiter(t, cfg,
+			newLimitedWorkflow("ns", "running", "p", "lp", v1alpha1.WorkflowPhaseRunning),
+			newLimitedWorkflow("ns", "failing", "p", "lp", v1alpha1.WorkflowPhaseFailing),
+			newLimitedWorkflow("ns", "done", "p", "lp", v1alpha1.WorkflowPhaseSuccess),
+			newLimitedWorkflow("other", "running", "p", "lp", v1alpha1.WorkflowPhaseRunning),
+		)
+		admitted, msg := l.Admit(ctx, newLimitedWorkflow("ns", "w", "p", "lp", v1alpha1.WorkflowPhaseReady))
+		assert.False(t, admitted)
+		assert.Equal(t, "Workflow is queued, [2/2] workflows are running for namespace [ns]", msg)
+
+		admitted, _ = l.Admit(ctx, newLimitedWorkflow("other", "w", "p", "lp", v1alpha1.WorkflowPhaseReady))
+		assert.True(t, admitted)
+	})
+
+	t.Run("project", func(t *testing.T) {
+		l, _ := newTestLimiter(t, config.ConcurrencyLimits{ProjectLimits: map[string]int{"p": 2}},
+			newLimitedWorkflow("ns1", "running", "p", "lp", v1alpha1.WorkflowPhaseRunning),
+			newLimitedWorkflow("ns2", "running", "p", "lp", v1alpha1.WorkflowPhaseRunning),
+		)
+		admitted, _ := l.Admit(ctx, newLimitedWorkflow("ns3", "w", "p", "lp", v1alpha1.WorkflowPhaseReady))
+		assert.False(t, admitted)
+
+		admitted, _ = l.Admit(ctx, newLimitedWorkflow("ns3", "w", "p2", "lp", v1alpha1.WorkflowPhaseReady))
+		assert.True(t, admitted)
+	})
+
+	t.Run("launch-plan", func(t *testing.T) {
+		cfg := config.ConcurrencyLimits{LaunchPlanLabel: "launch-plan", LaunchPlanLimit: 1}
+		l, _ := newTestLimiter(t, cfg,
+			newLimitedWorkflow("ns", "running", "p", "lp", v1alpha1.WorkflowPhaseRunning),
+		)
+		admitted, msg := l.Admit(ctx, newLimitedWorkflow("ns", "w", "p", "lp", v1alpha1.WorkflowPhaseQueued))
+		assert.False(t, admitted)
+		assert.Equal(t, "Workflow is queued, [1/1] workflows are running for launch_plan [lp]", msg)
+
+		admitted, _ = l.Admit(ctx, newLimitedWorkflow("ns", "w", "p", "lp2", v1alpha1.WorkflowPhaseQueued))
+		assert.True(t, admitted)
+	})
+
+	t.Run("admitted-before-cache-update", func(t *testing.T) {
+		first := newLimitedWorkflow("ns", "first", "p", "lp", v1alpha1.WorkflowPhaseReady)
+		second := newLimitedWorkflow("ns", "second", "p", "lp", v1alpha1.WorkflowPhaseReady)
+		l, indexer := newTestLimiter(t, config.ConcurrencyLimits{NamespaceLimit: 1}, first, second)
+		admitted, _ := l.Admit(ctx, first)
+		assert.True(t, admitted)
+		// Re-evaluating an admitted workflow does not count it against itself
+		admitted, _ = l.Admit(ctx, first)
+		assert.True(t, admitted)
+
+		// The first workflow is still ready in the cache, but was admitted
+		admitted, _ = l.Admit(ctx, second)
+		assert.False(t, admitted)
+
+		// Once the first workflow completes, the second one is admitted
+		completed := first.DeepCopy()
+		completed.Status.Phase = v1alpha1.WorkflowPhaseSuccess
+		assert.NoError(t, indexer.Update(completed))
+		admitted, _ = l.Admit(ctx, second)
+		assert.True(t, admitted)
+	})
+
+	t.Run("forget", func(t *testing.T) {
+		w := newLimitedWorkflow("ns", "w", "p", "lp", v1alpha1.WorkflowPhaseReady)
+		l, _ := newTestLimiter(t, config.ConcurrencyLimits{NamespaceLimit: 1}, w)
+		limiter := l.(*concurrencyLimiter)
+		admitted, _ := l.Admit(ctx, w)
+		assert.True(t, admitted)
+
+		// Still not started in the cache
+		limiter.onUpdate(w)
+		assert.True(t, limiter.admitted["ns/w"])
+
+		running := w.DeepCopy()
+		running.Status.Phase = v1alpha1.WorkflowPhaseRunning
+		limiter.onUpdate(running)
+		assert.Empty(t, limiter.admitted)
+
+		// Deleted before it started
+		admitted, _ = l.Admit(ctx, w)
+		assert.True(t, admitted)
+		limiter.forget(w)
+		assert.Empty(t, limiter.admitted)
+
+		admitted, _ = l.Admit(ctx, w)
+		assert.True(t, admitted)
+		limiter.forget(cache.DeletedFinalStateUnknown{Key: "ns/w", Obj: w})
+		assert.Empty(t, limiter.admitted)
+	})
+}
+
+type fakeConcurrencyLimiter struct {
+	admit bool
+}
+
+func (f *fakeConcurrencyLimiter) Admit(_ context.Context, _ *v1alpha1.FlyteWorkflow) (bool, string) {
+	return f.admit, "queued"
+}
+
+func TestWorkflowExecutor_HandleFlyteWorkflow_Queued(t *testing.T) {
+	ctx := context.Background()
+	store := createInmemoryDataStore(t, promutils.NewTestScope())
+	recorder := StdOutEventRecorder()
+	enqueueWorkflow := func(workflowId v1alpha1.WorkflowID) {}
+
+	wJSON, err := yamlutils.ReadYamlFileAsJSON("testdata/benchmark_wf.yaml")
+	assert.NoError(t, err)
+
+	eventSink := events.NewMockEventSink()
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
+	assert.NoError(t, err)
+
+	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
+	nodeExec, err := nodes.NewExecutor(ctx, config.GetConfig().NodeConfig, store, enqueueWorkflow, eventSink, adminClient,
+		adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
+	assert.NoError(t, err)
+
+	limiter := &fakeConcurrencyLimiter{}
+	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, limiter, promutils.NewTestScope())
+	assert.NoError(t, err)
+	assert.NoError(t, executor.Initialize(ctx))
+
+	w := &v1alpha1.FlyteWorkflow{}
+	assert.NoError(t, json.Unmarshal(wJSON, w))
+
+	assert.NoError(t, executor.HandleFlyteWorkflow(ctx, w))
+	assert.Equal(t, v1alpha1.WorkflowPhaseQueued, w.GetExecutionStatus().GetPhase())
+	assert.Equal(t, "queued", w.GetExecutionStatus().GetMessage())
+	assert.Nil(t, w.GetExecutionStatus().GetStartedAt())
+
+	// Stays queued while the limit is reached
+	assert.NoError(t, executor.HandleFlyteWorkflow(ctx, w))
+	assert.Equal(t, v1alpha1.WorkflowPhaseQueued, w.GetExecutionStatus().GetPhase())
+
+	limiter.admit = true
+	assert.NoError(t, executor.HandleFlyteWorkflow(ctx, w))
+	assert.Equal(t, v1alpha1.WorkflowPhaseRunning, w.GetExecutionStatus().GetPhase())
+	assert.NotNil(t, w.GetExecutionStatus().GetStartedAt())
+}
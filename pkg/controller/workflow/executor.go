@@ -24,6 +24,7 @@ import (
 
 type workflowMetrics struct {
 	AcceptedWorkflows         labeled.Counter
+	QueuedWorkflows           labeled.Counter
 	FailureDuration           labeled.StopWatch
 	SuccessDuration           labeled.StopWatch
 	IncompleteWorkflowAborted labeled.Counter
@@ -37,6 +38,7 @@ type workflowMetrics struct {
 type Status struct {
 	TransitionToPhase v1alpha1.WorkflowPhase
 	Err               *core.ExecutionError
+	Message           string
 }
 
 var StatusReady = Status{TransitionToPhase: v1alpha1.WorkflowPhaseReady}
@@ -63,6 +65,7 @@ type workflowExecutor struct {
 	k8sRecorder     record.EventRecorder
 	metadataPrefix  storage.DataReference
 	nodeExecutor    executors.Node
+	limiter         ConcurrencyLimiter
 	metrics         *workflowMetrics
 }
 
@@ -142,6 +145,9 @@ func (c *workflowExecutor) handleRunningWorkflow(ctx context.Context, w *v1alpha
 	if err != nil {
 		return StatusRunning, err
 	}
+	if state.HasFailed() || state.HasTimedOut() {
+		w.GetExecutionStatus().SetNodeFailures(state.Failures)
+	}
 	if state.HasFailed() {
 		logger.Infof(ctx, "Workflow has failed. Error [%s]", state.Err.String())
 		return StatusFailing(state.Err), nil
@@ -270,6 +276,10 @@ func (c *workflowExecutor) TransitionToPhase(ctx context.Context, execID *core.W
 		case v1alpha1.WorkflowPhaseReady:
 			// Do nothing
 			return nil
+		case v1alpha1.WorkflowPhaseQueued:
+			wfEvent.Phase = core.WorkflowExecution_QUEUED
+			wStatus.UpdatePhase(v1alpha1.WorkflowPhaseQueued, toStatus.Message, nil)
+			wfEvent.OccurredAt = utils.GetProtoTime(nil)
 		case v1alpha1.WorkflowPhaseRunning:
 			wfEvent.Phase = core.WorkflowExecution_RUNNING
 			wStatus.UpdatePhase(v1alpha1.WorkflowPhaseRunning, fmt.Sprintf("Workflow Started"), nil)
@@ -350,7 +360,23 @@ func (c *workflowExecutor) HandleFlyteWorkflow(ctx context.Context, w *v1alpha1.
 	wStatus := w.GetExecutionStatus()
 	// Initialize the Status if not already initialized
 	switch wStatus.GetPhase() {
-	case v1alpha1.WorkflowPhaseReady:
+	case v1alpha1.WorkflowPhaseReady, v1alpha1.WorkflowPhaseQueued:
+		if admitted, msg := c.limiter.Admit(ctx, w); !admitted {
+			// Queued workflows are re-evaluated on every workflow re-evaluation round
+			logger.Infof(ctx, "Holding workflow back. %s", msg)
+			if wStatus.GetPhase() == v1alpha1.WorkflowPhaseQueued {
+				wStatus.SetMessage(msg)
+				return nil
+			}
+
+			c.metrics.QueuedWorkflows.Inc(ctx)
+			if err := c.TransitionToPhase(ctx, w.ExecutionID.WorkflowExecutionIdentifier, wStatus, Status{TransitionToPhase: v1alpha1.WorkflowPhaseQueued, Message: msg}); err != nil {
+				return err
+			}
+			c.k8sRecorder.Event(w, corev1.EventTypeNormal, v1alpha1.WorkflowPhaseQueued.String(), msg)
+			return nil
+		}
+
 		newStatus, err := c.handleReadyWorkflow(ctx, w)
 		if err != nil {
 			return err
@@ -472,7 +498,7 @@ func (c *workflowExecutor) cleanupRunningNodes(ctx context.Context, w v1alpha1.E
 	return nil
 }
 
-func NewExecutor(ctx context.Context, store *storage.DataStore, enQWorkflow v1alpha1.EnqueueWorkflow, eventSink events.EventSink, k8sEventRecorder record.EventRecorder, metadataPrefix string, nodeExecutor executors.Node, scope promutils.Scope) (executors.Workflow, error) {
+func NewExecutor(ctx context.Context, store *storage.DataStore, enQWorkflow v1alpha1.EnqueueWorkflow, eventSink events.EventSink, k8sEventRecorder record.EventRecorder, metadataPrefix string, nodeExecutor executors.Node, limiter ConcurrencyLimiter, scope promutils.Scope) (executors.Workflow, error) {
 	basePrefix := store.GetBaseContainerFQN(ctx)
 	if metadataPrefix != "" {
 		var err error
@@ -492,6 +518,7 @@ func NewExecutor(ctx context.Context, store *storage.DataStore, enQWorkflow v1al
 		wfRecorder:      events.NewWorkflowEventRecorder(eventSink, workflowScope),
 		k8sRecorder:     k8sEventRecorder,
 		metadataPrefix:  basePrefix,
+		limiter:         limiter,
 		metrics:         newMetrics(workflowScope),
 	}, nil
 }
@@ -499,6 +526,7 @@ func NewExecutor(ctx context.Context, store *storage.DataStore, enQWorkflow v1al
 func newMetrics(workflowScope promutils.Scope) *workflowMetrics {
 	return &workflowMetrics{
 		AcceptedWorkflows:         labeled.NewCounter("accepted", "Number of workflows accepted by propeller", workflowScope),
+		QueuedWorkflows:           labeled.NewCounter("queued", "Number of workflows held in the queued phase by a concurrency limit", workflowScope),
 		FailureDuration:           labeled.NewStopWatch("failure_duration", "Indicates the total execution time of a failed workflow.", time.Millisecond, workflowScope, labeled.EmitUnlabeledMetric),
 		SuccessDuration:           labeled.NewStopWatch("success_duration", "Indicates the total execution time of a successful workflow.", time.Millisecond, workflowScope, labeled.EmitUnlabeledMetric),
 		IncompleteWorkflowAborted: labeled.NewCounter("workflow_aborted", "Indicates an inprogress execution was aborted", workflowScope, labeled.EmitUnlabeledMetric),
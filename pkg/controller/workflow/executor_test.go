@@ -226,14 +226,14 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_Error(t *testing.T) {
 	enqueueWorkflow := func(workflowId v1alpha1.WorkflowID) {}
 
 	eventSink := events.NewMockEventSink()
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	assert.NoError(t, err)
 
 	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
 	nodeExec, err := nodes.NewExecutor(ctx, config.GetConfig().NodeConfig, store, enqueueWorkflow, eventSink, adminClient,
 		adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
 	assert.NoError(t, err)
-	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, promutils.NewTestScope())
+	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 	assert.NoError(t, err)
 
 	assert.NoError(t, executor.Initialize(ctx))
@@ -303,7 +303,7 @@ func TestWorkflowExecutor_HandleFlyteWorkflow(t *testing.T) {
 	enqueueWorkflow := func(workflowId v1alpha1.WorkflowID) {}
 
 	eventSink := events.NewMockEventSink()
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	assert.NoError(t, err)
 
 	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
@@ -311,7 +311,7 @@ func TestWorkflowExecutor_HandleFlyteWorkflow(t *testing.T) {
 		adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
 	assert.NoError(t, err)
 
-	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, promutils.NewTestScope())
+	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 	assert.NoError(t, err)
 
 	assert.NoError(t, executor.Initialize(ctx))
@@ -351,6 +351,86 @@ func TestWorkflowExecutor_HandleFlyteWorkflow(t *testing.T) {
 	}
 }
 
+func TestWorkflowExecutor_HandleFlyteWorkflow_SkippedLeaf(t *testing.T) {
+	ctx := context.Background()
+	wJSON, err := yamlutils.ReadYamlFileAsJSON("testdata/benchmark_wf.yaml")
+	assert.NoError(t, err)
+
+	// A cleanup node that only runs on failures is skipped as all the other nodes succeed, as is the optional notify
+	// node that runs after it. Neither must skip the end node, nor fail the workflow.
+	for name, notify := range map[string]bool{
+		"cleanup":        false,
+		"cleanup_notify": true,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// The replayer plugin replays the transitions of a single workflow
+			store := createInmemoryDataStore(t, testScope.NewSubScope("skipped_leaf_"+name))
+			recorder := StdOutEventRecorder()
+			_, err := events.ConstructEventSink(ctx, events.GetConfig(ctx))
+			assert.NoError(t, err)
+
+			te := createHappyPathTaskExecutor(t, true)
+			pluginmachinery.PluginRegistry().RegisterCorePlugin(te)
+
+			enqueueWorkflow := func(workflowId v1alpha1.WorkflowID) {}
+
+			eventSink := events.NewMockEventSink()
+			catalogClient, err := catalog.NewCatalogClient(ctx, store)
+			assert.NoError(t, err)
+
+			adminClient := launchplan.NewFailFastLaunchPlanExecutor()
+			nodeExec, err := nodes.NewExecutor(ctx, config.GetConfig().NodeConfig, store, enqueueWorkflow, eventSink, adminClient,
+				adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
+			assert.NoError(t, err)
+
+			executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
+			assert.NoError(t, err)
+
+			assert.NoError(t, executor.Initialize(ctx))
+			w := &v1alpha1.FlyteWorkflow{}
+			if !assert.NoError(t, json.Unmarshal(wJSON, w)) {
+				return
+			}
+
+			leaf := w.Nodes["print-every-time-0"]
+			cleanup := leaf.DeepCopy()
+			cleanup.ID = "cleanup"
+			cleanup.InputBindings = cleanup.InputBindings[:1]
+			cleanup.TriggerRule = v1alpha1.TriggerRuleOneFailed
+			w.Nodes[cleanup.ID] = cleanup
+			w.Connections.DownstreamEdges[leaf.ID] = []v1alpha1.NodeID{cleanup.ID}
+			w.Connections.UpstreamEdges[cleanup.ID] = []v1alpha1.NodeID{leaf.ID}
+			last := cleanup
+			if notify {
+				n := leaf.DeepCopy()
+				n.ID = "notify"
+				n.InputBindings = n.InputBindings[:1]
+				w.Nodes[n.ID] = n
+				w.Connections.DownstreamEdges[cleanup.ID] = []v1alpha1.NodeID{n.ID}
+				w.Connections.UpstreamEdges[n.ID] = []v1alpha1.NodeID{cleanup.ID}
+				last = n
+			}
+			w.Connections.DownstreamEdges[last.ID] = []v1alpha1.NodeID{v1alpha1.EndNodeID}
+			w.Connections.UpstreamEdges[v1alpha1.EndNodeID] = []v1alpha1.NodeID{last.ID}
+
+			for i := 0; i < 40 && !v1alpha1.IsWorkflowPhaseTerminal(w.Status.Phase); i++ {
+				assert.NoError(t, executor.HandleFlyteWorkflow(ctx, w))
+				for _, v := range w.Status.NodeStatus {
+					// Reset dirty manually for tests.
+					v.ResetDirty()
+				}
+			}
+
+			assert.Equal(t, v1alpha1.WorkflowPhaseSuccess.String(), w.Status.Phase.String(), "Message: [%v]", w.Status.Message)
+			assert.Equal(t, v1alpha1.NodePhaseSucceeded, w.Status.NodeStatus[leaf.ID].GetPhase())
+			assert.Equal(t, v1alpha1.NodePhaseSkipped, w.Status.NodeStatus[cleanup.ID].GetPhase())
+			assert.Equal(t, v1alpha1.NodePhaseSkipped, w.Status.NodeStatus[last.ID].GetPhase())
+			assert.Equal(t, v1alpha1.NodePhaseSucceeded, w.Status.NodeStatus[v1alpha1.EndNodeID].GetPhase())
+			assert.NotEmpty(t, w.Status.GetOutputReference())
+		})
+	}
+}
+
 func BenchmarkWorkflowExecutor(b *testing.B) {
 	scope := promutils.NewScope("test3")
 	ctx := context.Background()
@@ -364,14 +444,14 @@ func BenchmarkWorkflowExecutor(b *testing.B) {
 	enqueueWorkflow := func(workflowId v1alpha1.WorkflowID) {}
 
 	eventSink := events.NewMockEventSink()
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	assert.NoError(b, err)
 	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
 	nodeExec, err := nodes.NewExecutor(ctx, config.GetConfig().NodeConfig, store, enqueueWorkflow, eventSink, adminClient,
 		adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, scope)
 	assert.NoError(b, err)
 
-	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, promutils.NewTestScope())
+	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 	assert.NoError(b, err)
 
 	assert.NoError(b, executor.Initialize(ctx))
@@ -451,13 +531,13 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_Failing(t *testing.T) {
 		}
 		return nil
 	}
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	assert.NoError(t, err)
 	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
 	nodeExec, err := nodes.NewExecutor(ctx, config.GetConfig().NodeConfig, store, enqueueWorkflow, eventSink, adminClient,
 		adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
 	assert.NoError(t, err)
-	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, promutils.NewTestScope())
+	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 	assert.NoError(t, err)
 
 	assert.NoError(t, executor.Initialize(ctx))
@@ -489,6 +569,11 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_Failing(t *testing.T) {
 			}
 
 			assert.Equal(t, v1alpha1.WorkflowPhaseFailed.String(), w.Status.Phase.String(), "Message: [%v]", w.Status.Message)
+			// The workflow fails immediately, on the first node failure
+			if assert.Len(t, w.Status.NodeFailures, 1) {
+				assert.Equal(t, w.Status.GetExecutionError().GetCode(), w.Status.NodeFailures[0].Code)
+				assert.Equal(t, w.Status.GetExecutionError().GetMessage(), w.Status.NodeFailures[0].Message)
+			}
 		}
 	}
 	assert.True(t, recordedRunning)
@@ -541,13 +626,13 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_Events(t *testing.T) {
 		}
 		return nil
 	}
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	assert.NoError(t, err)
 	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
 	nodeExec, err := nodes.NewExecutor(ctx, config.GetConfig().NodeConfig, store, enqueueWorkflow, eventSink, adminClient,
 		adminClient, maxOutputSize, "s3://bucket", fakeKubeClient, catalogClient, promutils.NewTestScope())
 	assert.NoError(t, err)
-	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, promutils.NewTestScope())
+	executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 	assert.NoError(t, err)
 
 	assert.NoError(t, executor.Initialize(ctx))
@@ -594,7 +679,7 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_EventFailure(t *testing.T) {
 	assert.NoError(t, err)
 
 	nodeEventSink := events.NewMockEventSink()
-	catalogClient, err := catalog.NewCatalogClient(ctx)
+	catalogClient, err := catalog.NewCatalogClient(ctx, store)
 	assert.NoError(t, err)
 
 	adminClient := launchplan.NewFailFastLaunchPlanExecutor()
@@ -611,7 +696,7 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_EventFailure(t *testing.T) {
 				Cause: errors.New("already exists"),
 			}
 		}
-		executor, err := NewExecutor(ctx, store, enqueueWorkflow, mockSink, recorder, "metadata", nodeExec, promutils.NewTestScope())
+		executor, err := NewExecutor(ctx, store, enqueueWorkflow, mockSink, recorder, "metadata", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 		assert.NoError(t, err)
 		w := &v1alpha1.FlyteWorkflow{}
 		assert.NoError(t, json.Unmarshal(wJSON, w))
@@ -631,7 +716,7 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_EventFailure(t *testing.T) {
 				Cause: errors.New("already exists"),
 			}
 		}
-		executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, promutils.NewTestScope())
+		executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 		assert.NoError(t, err)
 		w := &v1alpha1.FlyteWorkflow{}
 		assert.NoError(t, json.Unmarshal(wJSON, w))
@@ -648,7 +733,7 @@ func TestWorkflowExecutor_HandleFlyteWorkflow_EventFailure(t *testing.T) {
 				Cause: errors.New("generic exists"),
 			}
 		}
-		executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, promutils.NewTestScope())
+		executor, err := NewExecutor(ctx, store, enqueueWorkflow, eventSink, recorder, "metadata", nodeExec, NewNoopConcurrencyLimiter(), promutils.NewTestScope())
 		assert.NoError(t, err)
 		w := &v1alpha1.FlyteWorkflow{}
 		assert.NoError(t, json.Unmarshal(wJSON, w))
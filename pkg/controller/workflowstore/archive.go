@@ -0,0 +1,151 @@
+package workflowstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/pkg/errors"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+const (
+	archiveDateFormat    = "2006-01-02"
+	archiveFileExtension = ".json"
+	unknownArchiveKey    = "unknown"
+)
+
+// A WorkflowArchive keeps completed workflows in blob storage after they are garbage collected from the cluster, so
+// that their spec, node statuses and errors can still be inspected. Workflows are stored as json under
+// <prefix>/<project>/<domain>/<yyyy-mm-dd>/<name>.json, partitioned by the day they completed.
+type WorkflowArchive struct {
+	store  *storage.DataStore
+	prefix storage.DataReference
+}
+
+// Returns the reference a workflow is archived under. The date is the day (in UTC) the workflow completed.
+func (a *WorkflowArchive) Reference(ctx context.Context, project, domain string, date time.Time, name string) (storage.DataReference, error) {
+	return a.store.ConstructReference(ctx, a.prefix, project, domain, date.UTC().Format(archiveDateFormat),
+		name+archiveFileExtension)
+}
+
+// Resolves a workflow in the archive given either as a fully qualified reference or as <project>/<domain>/<yyyy-mm-dd>/<name>.
+func (a *WorkflowArchive) ResolveReference(ctx context.Context, path string) (storage.DataReference, error) {
+	if strings.Contains(path, "://") {
+		return storage.DataReference(path), nil
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 4 {
+		return "", errors.Errorf("invalid archived workflow [%s], expected <project>/<domain>/<yyyy-mm-dd>/<name>", path)
+	}
+
+	date, err := time.Parse(archiveDateFormat, parts[2])
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid date in archived workflow [%s]", path)
+	}
+
+	return a.Reference(ctx, parts[0], parts[1], date, strings.TrimSuffix(parts[3], archiveFileExtension))
+}
+
+func completedAt(w *v1alpha1.FlyteWorkflow) time.Time {
+	if w.Status.StoppedAt != nil {
+		return w.Status.StoppedAt.Time
+	}
+	if w.Status.LastUpdatedAt != nil {
+		return w.Status.LastUpdatedAt.Time
+	}
+	return w.GetCreationTimestamp().Time
+}
+
+// Writes the workflow to the archive and returns the reference it was written to. Node statuses that were offloaded
+// to blob storage are inlined, so that the archived workflow is self contained.
+func (a *WorkflowArchive) Archive(ctx context.Context, w *v1alpha1.FlyteWorkflow) (storage.DataReference, error) {
+	project, domain, name := unknownArchiveKey, unknownArchiveKey, w.GetName()
+	if execID := w.GetExecutionID(); execID.WorkflowExecutionIdentifier != nil {
+		project, domain = execID.GetProject(), execID.GetDomain()
+	}
+
+	ref, err := a.Reference(ctx, project, domain, completedAt(w), name)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to construct archive reference for workflow [%s]", w.GetK8sWorkflowID())
+	}
+
+	if w.Status.OffloadedNodeStatus != nil {
+		nodeStatus, err := readOffloadedNodeStatus(ctx, a.store, w.Status.OffloadedNodeStatus)
+		if err != nil {
+			return "", err
+		}
+
+		w = w.DeepCopy()
+		w.Status.NodeStatus = nodeStatus
+		w.Status.OffloadedNodeStatus = nil
+	}
+
+	raw, err := json.Marshal(w)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal workflow [%s]", w.GetK8sWorkflowID())
+	}
+
+	if err := a.store.WriteRaw(ctx, ref, int64(len(raw)), storage.Options{}, bytes.NewReader(raw)); err != nil {
+		return "", errors.Wrapf(err, "failed to archive workflow [%s] to [%s]", w.GetK8sWorkflowID(), ref)
+	}
+
+	return ref, nil
+}
+
+// Reads an archived workflow.
+func (a *WorkflowArchive) Get(ctx context.Context, ref storage.DataReference) (*v1alpha1.FlyteWorkflow, error) {
+	rc, err := a.store.ReadRaw(ctx, ref)
+	if err != nil {
+		if storage.IsNotFound(err) {
+			return nil, errors.Wrapf(errWorkflowNotFound, "archived workflow [%s] not found", ref)
+		}
+		return nil, errors.Wrapf(err, "failed to read archived workflow [%s]", ref)
+	}
+
+	defer func() {
+		if err := rc.Close(); err != nil {
+			logger.Warnf(ctx, "Failed to close reader for [%s]. Error [%v]", ref, err)
+		}
+	}()
+
+	raw, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read archived workflow [%s]", ref)
+	}
+
+	w := &v1alpha1.FlyteWorkflow{}
+	if err := json.Unmarshal(raw, w); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal archived workflow [%s]", ref)
+	}
+
+	return w, nil
+}
+
+// Creates an archive rooted at <base container>/<metadataPrefix>/<archivePrefix>.
+func NewWorkflowArchive(ctx context.Context, store *storage.DataStore, metadataPrefix, archivePrefix string) (*WorkflowArchive, error) {
+	prefix := store.GetBaseContainerFQN(ctx)
+	for _, p := range []string{metadataPrefix, archivePrefix} {
+		if p == "" {
+			continue
+		}
+
+		var err error
+		prefix, err = store.ConstructReference(ctx, prefix, p)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to construct archive prefix")
+		}
+	}
+
+	return &WorkflowArchive{
+		store:  store,
+		prefix: prefix,
+	}, nil
+}
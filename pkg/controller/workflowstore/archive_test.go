@@ -0,0 +1,114 @@
+package workflowstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+func newTestArchive(t *testing.T) (*WorkflowArchive, *storage.DataStore) {
+	dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+	assert.NoError(t, err)
+	return &WorkflowArchive{store: dataStore, prefix: "s3://bucket/metadata/archive"}, dataStore
+}
+
+func newArchiveTestWorkflow() *v1alpha1.FlyteWorkflow {
+	w := newOffloadingTestWorkflow()
+	w.ExecutionID = v1alpha1.WorkflowExecutionIdentifier{
+		WorkflowExecutionIdentifier: &core.WorkflowExecutionIdentifier{Project: "p", Domain: "d", Name: "name"},
+	}
+	w.Status.Phase = v1alpha1.WorkflowPhaseFailed
+	w.Status.StoppedAt = &v1.Time{Time: time.Date(2020, time.March, 4, 23, 30, 0, 0, time.UTC)}
+	return w
+}
+
+func TestWorkflowArchive_Archive(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("inline-status", func(t *testing.T) {
+		a, _ := newTestArchive(t)
+		w := newArchiveTestWorkflow()
+
+		ref, err := a.Archive(ctx, w)
+		assert.NoError(t, err)
+		assert.Equal(t, storage.DataReference("s3://bucket/metadata/archive/p/d/2020-03-04/name.json"), ref)
+
+		archived, err := a.Get(ctx, ref)
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseFailed, archived.Status.Phase)
+		assert.Equal(t, w.Status.NodeStatus["n1"].Message, archived.Status.NodeStatus["n1"].Message)
+		assert.Len(t, archived.Status.NodeStatus, 2)
+	})
+
+	t.Run("offloaded-status", func(t *testing.T) {
+		s, underlying, dataStore := newOffloadingTestStore(t, 10)
+		w := newArchiveTestWorkflow()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+		_, err := s.UpdateStatus(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.NotNil(t, crd.Status.OffloadedNodeStatus)
+
+		a := &WorkflowArchive{store: dataStore, prefix: "s3://bucket/archive"}
+		ref, err := a.Archive(ctx, crd)
+		assert.NoError(t, err)
+		// The archived workflow is self contained
+		archived, err := a.Get(ctx, ref)
+		assert.NoError(t, err)
+		assert.Nil(t, archived.Status.OffloadedNodeStatus)
+		assert.Len(t, archived.Status.NodeStatus, 2)
+		// The workflow passed in is never modified
+		assert.Nil(t, crd.Status.NodeStatus)
+	})
+
+	t.Run("no-execution-id", func(t *testing.T) {
+		a, _ := newTestArchive(t)
+		w := newOffloadingTestWorkflow()
+		w.Status.StoppedAt = &v1.Time{Time: time.Date(2020, time.March, 4, 23, 30, 0, 0, time.UTC)}
+
+		ref, err := a.Archive(ctx, w)
+		assert.NoError(t, err)
+		assert.Equal(t, storage.DataReference("s3://bucket/metadata/archive/unknown/unknown/2020-03-04/name.json"), ref)
+	})
+}
+
+func TestWorkflowArchive_Get(t *testing.T) {
+	ctx := context.TODO()
+	a, _ := newTestArchive(t)
+
+	_, err := a.Get(ctx, "s3://bucket/metadata/archive/p/d/2020-03-04/missing.json")
+	assert.True(t, IsNotFound(err))
+}
+
+func TestWorkflowArchive_ResolveReference(t *testing.T) {
+	ctx := context.TODO()
+	a, _ := newTestArchive(t)
+
+	ref, err := a.ResolveReference(ctx, "p/d/2020-03-04/name")
+	assert.NoError(t, err)
+	assert.Equal(t, storage.DataReference("s3://bucket/metadata/archive/p/d/2020-03-04/name.json"), ref)
+
+	ref, err = a.ResolveReference(ctx, "p/d/2020-03-04/name.json")
+	assert.NoError(t, err)
+	assert.Equal(t, storage.DataReference("s3://bucket/metadata/archive/p/d/2020-03-04/name.json"), ref)
+
+	ref, err = a.ResolveReference(ctx, "s3://other/name.json")
+	assert.NoError(t, err)
+	assert.Equal(t, storage.DataReference("s3://other/name.json"), ref)
+
+	_, err = a.ResolveReference(ctx, "p/d/name")
+	assert.Error(t, err)
+
+	_, err = a.ResolveReference(ctx, "p/d/yesterday/name")
+	assert.Error(t, err)
+}
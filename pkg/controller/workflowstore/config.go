@@ -1,6 +1,10 @@
 package workflowstore
 
 import (
+	"time"
+
+	"github.com/lyft/flytestdlib/config"
+
 	ctrlConfig "github.com/lyft/flytepropeller/pkg/controller/config"
 )
 
@@ -12,18 +16,35 @@ const (
 	PolicyInMemory             = "InMemory"
 	PolicyPassThrough          = "PassThrough"
 	PolicyResourceVersionCache = "ResourceVersionCache"
+	PolicyEmbedded             = "Embedded"
 )
 
 var (
 	defaultConfig = &Config{
 		Policy: PolicyPassThrough,
+		Embedded: EmbeddedConfig{
+			Path:         "flytepropeller.db",
+			SyncInterval: config.Duration{Duration: time.Minute},
+		},
+		NodeStatusCacheSize: 100,
 	}
 
 	configSection = ctrlConfig.MustRegisterSubSection("workflowStore", defaultConfig)
 )
 
 type Config struct {
-	Policy Policy `json:"policy" pflag:",Workflow Store Policy to initialize"`
+	Policy   Policy         `json:"policy" pflag:",Workflow Store Policy to initialize"`
+	Embedded EmbeddedConfig `json:"embedded,omitempty" pflag:",Configuration for the Embedded workflow store policy"`
+	// Size in bytes of the serialized node statuses above which they are offloaded to blob storage.
+	MaxInlineNodeStatusSize int `json:"max-inline-node-status-size" pflag:",Size (in bytes) of the node statuses above which they are offloaded to blob storage instead of being stored in the CRD. 0 disables offloading."`
+	// Number of offloaded node statuses kept in memory, to avoid reading them from blob storage again.
+	NodeStatusCacheSize int `json:"node-status-cache-size" pflag:",Number of offloaded node statuses cached in memory. 0 disables caching."`
+}
+
+// Configuration for the Embedded policy, that persists workflow status in a local database and syncs it back to the CRD
+type EmbeddedConfig struct {
+	Path         string          `json:"path" pflag:",Path to the file that backs the embedded workflow store"`
+	SyncInterval config.Duration `json:"sync-interval" pflag:",Frequency at which workflow statuses are synced back to the CRD. Must be positive."`
 }
 
 func GetConfig() *Config {
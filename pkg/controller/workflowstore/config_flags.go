@@ -42,5 +42,9 @@ func (Config) mustMarshalJSON(v json.Marshaler) string {
 func (cfg Config) GetPFlagSet(prefix string) *pflag.FlagSet {
 	cmdFlags := pflag.NewFlagSet("Config", pflag.ExitOnError)
 	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "policy"), defaultConfig.Policy, "Workflow Store Policy to initialize")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "embedded.path"), defaultConfig.Embedded.Path, "Path to the file that backs the embedded workflow store")
+	cmdFlags.String(fmt.Sprintf("%v%v", prefix, "embedded.sync-interval"), defaultConfig.Embedded.SyncInterval.String(), "Frequency at which workflow statuses are synced back to the CRD. Must be positive.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "max-inline-node-status-size"), defaultConfig.MaxInlineNodeStatusSize, "Size (in bytes) of the node statuses above which they are offloaded to blob storage instead of being stored in the CRD. 0 disables offloading.")
+	cmdFlags.Int(fmt.Sprintf("%v%v", prefix, "node-status-cache-size"), defaultConfig.NodeStatusCacheSize, "Number of offloaded node statuses cached in memory. 0 disables caching.")
 	return cmdFlags
 }
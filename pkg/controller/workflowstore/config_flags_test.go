@@ -121,4 +121,92 @@ func TestConfig_SetFlags(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Test_embedded.path", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("embedded.path"); err == nil {
+				assert.Equal(t, string(defaultConfig.Embedded.Path), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("embedded.path", testValue)
+			if vString, err := cmdFlags.GetString("embedded.path"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Embedded.Path)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_embedded.sync-interval", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vString, err := cmdFlags.GetString("embedded.sync-interval"); err == nil {
+				assert.Equal(t, string(defaultConfig.Embedded.SyncInterval.String()), vString)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := defaultConfig.Embedded.SyncInterval.String()
+
+			cmdFlags.Set("embedded.sync-interval", testValue)
+			if vString, err := cmdFlags.GetString("embedded.sync-interval"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vString), &actual.Embedded.SyncInterval)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_max-inline-node-status-size", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("max-inline-node-status-size"); err == nil {
+				assert.Equal(t, int(defaultConfig.MaxInlineNodeStatusSize), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("max-inline-node-status-size", testValue)
+			if vInt, err := cmdFlags.GetInt("max-inline-node-status-size"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.MaxInlineNodeStatusSize)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
+	t.Run("Test_node-status-cache-size", func(t *testing.T) {
+		t.Run("DefaultValue", func(t *testing.T) {
+			// Test that default value is set properly
+			if vInt, err := cmdFlags.GetInt("node-status-cache-size"); err == nil {
+				assert.Equal(t, int(defaultConfig.NodeStatusCacheSize), vInt)
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+
+		t.Run("Override", func(t *testing.T) {
+			testValue := "1"
+
+			cmdFlags.Set("node-status-cache-size", testValue)
+			if vInt, err := cmdFlags.GetInt("node-status-cache-size"); err == nil {
+				testDecodeJson_Config(t, fmt.Sprintf("%v", vInt), &actual.NodeStatusCacheSize)
+
+			} else {
+				assert.FailNow(t, err.Error())
+			}
+		})
+	})
 }
@@ -0,0 +1,416 @@
+package workflowstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	bolt "go.etcd.io/bbolt"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+var embeddedWorkflowsBucket = []byte("workflows")
+
+type embeddedMetrics struct {
+	localUpdateCount  labeled.Counter
+	writeThroughCount labeled.Counter
+	conflictCount     labeled.Counter
+	syncSuccessCount  prometheus.Counter
+	syncFailedCount   prometheus.Counter
+	syncLatency       promutils.StopWatch
+	dirtyWorkflows    prometheus.Gauge
+}
+
+// A record in the embedded store. It holds the latest known status of a workflow, that may not yet have been written
+// to the CRD.
+type embeddedRecord struct {
+	// UID of the FlyteWorkflow CRD. Records are keyed by namespace and name, a workflow that is deleted and recreated
+	// with the same name must not pick up the status of the previous one.
+	UID types.UID `json:"uid"`
+	// Monotonically increasing version of the record, used for optimistic concurrency on local writes.
+	Version uint64 `json:"version"`
+	// The last known ResourceVersion of the FlyteWorkflow CRD.
+	ResourceVersion string `json:"resourceVersion"`
+	// Dirty indicates that Status has not been synced back to the CRD yet.
+	Dirty  bool                    `json:"dirty"`
+	Status v1alpha1.WorkflowStatus `json:"status"`
+}
+
+// The resource version handed out to callers. It changes with every local write even if the CRD itself is untouched.
+func (r embeddedRecord) localResourceVersion() string {
+	return fmt.Sprintf("%s.%d", r.ResourceVersion, r.Version)
+}
+
+// A workflow store that persists FlyteWorkflow status updates into an embedded on-disk database (bbolt) and only
+// periodically syncs them back to the CRD. Updates that change the metadata of a workflow (labels, finalizers,
+// annotations) or that move the workflow to a terminal phase are written through immediately.
+// Spec and metadata are always read from the underlying store, while the status is overlaid from the embedded database.
+type embeddedWorkflowStore struct {
+	w       FlyteWorkflow
+	db      *bolt.DB
+	metrics *embeddedMetrics
+}
+
+func (e *embeddedWorkflowStore) getRecord(tx *bolt.Tx, key string) (*embeddedRecord, error) {
+	raw := tx.Bucket(embeddedWorkflowsBucket).Get([]byte(key))
+	if raw == nil {
+		return nil, nil
+	}
+
+	r := &embeddedRecord{}
+	if err := json.Unmarshal(raw, r); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal embedded record for workflow [%s]", key)
+	}
+
+	return r, nil
+}
+
+func (e *embeddedWorkflowStore) putRecord(tx *bolt.Tx, key string, r *embeddedRecord) error {
+	raw, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrapf(err, "failed to marshal embedded record for workflow [%s]", key)
+	}
+
+	return tx.Bucket(embeddedWorkflowsBucket).Put([]byte(key), raw)
+}
+
+func (e *embeddedWorkflowStore) deleteRecord(key string) error {
+	return e.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(embeddedWorkflowsBucket).Delete([]byte(key))
+	})
+}
+
+// Deletes the record unless it was written for the workflow with the given UID in the meantime.
+func (e *embeddedWorkflowStore) deleteStaleRecord(key string, uid types.UID) error {
+	return e.db.Update(func(tx *bolt.Tx) error {
+		r, err := e.getRecord(tx, key)
+		if err != nil || r == nil || r.UID == uid {
+			return err
+		}
+
+		return tx.Bucket(embeddedWorkflowsBucket).Delete([]byte(key))
+	})
+}
+
+func (e *embeddedWorkflowStore) loadRecord(key string) (r *embeddedRecord, err error) {
+	err = e.db.View(func(tx *bolt.Tx) error {
+		r, err = e.getRecord(tx, key)
+		return err
+	})
+
+	return r, err
+}
+
+func (e *embeddedWorkflowStore) Get(ctx context.Context, namespace, name string) (*v1alpha1.FlyteWorkflow, error) {
+	w, err := e.w.Get(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := e.loadRecord(resourceVersionKey(namespace, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if r == nil || r.UID != w.UID {
+		return w, nil
+	}
+
+	overlaid := w.DeepCopy()
+	overlaid.Status = *r.Status.DeepCopy()
+	overlaid.ResourceVersion = r.localResourceVersion()
+	return overlaid, nil
+}
+
+func metadataChanged(old, new *v1alpha1.FlyteWorkflow) bool {
+	return !reflect.DeepEqual(old.Labels, new.Labels) ||
+		!reflect.DeepEqual(old.Annotations, new.Annotations) ||
+		!reflect.DeepEqual(old.Finalizers, new.Finalizers)
+}
+
+func (e *embeddedWorkflowStore) update(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass,
+	statusOnly bool) (*v1alpha1.FlyteWorkflow, error) {
+
+	key := resourceVersionKey(workflow.Namespace, workflow.Name)
+	current, err := e.w.Get(ctx, workflow.Namespace, workflow.Name)
+	if err != nil {
+		if IsNotFound(err) {
+			// Mirror the passthrough store, a deleted workflow is not an error
+			return nil, e.deleteRecord(key)
+		}
+		return nil, err
+	}
+
+	writeThrough := workflow.Status.IsTerminated() || (!statusOnly && metadataChanged(current, workflow))
+	var r *embeddedRecord
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		existing, err := e.getRecord(tx, key)
+		if err != nil {
+			return err
+		}
+
+		if existing != nil && existing.UID != current.UID {
+			logger.Infof(ctx, "Workflow [%s] was recreated, discarding the embedded record of the previous workflow", key)
+			existing = nil
+		}
+
+		expected := current.ResourceVersion
+		if existing != nil {
+			expected = existing.localResourceVersion()
+		}
+
+		if workflow.ResourceVersion != expected {
+			e.metrics.conflictCount.Inc(ctx)
+			return kubeerrors.NewConflict(v1alpha1.Resource(v1alpha1.FlyteWorkflowKind), workflow.Name,
+				fmt.Errorf("resource version [%s] does not match the embedded store [%s]", workflow.ResourceVersion, expected))
+		}
+
+		r = &embeddedRecord{
+			UID:             current.UID,
+			Version:         1,
+			ResourceVersion: current.ResourceVersion,
+			Dirty:           true,
+			Status:          *workflow.Status.DeepCopy(),
+		}
+
+		if existing != nil {
+			r.Version = existing.Version + 1
+			r.ResourceVersion = existing.ResourceVersion
+		}
+
+		if writeThrough {
+			// Nothing to persist locally, the record is updated once the write to the CRD succeeds.
+			return nil
+		}
+
+		return e.putRecord(tx, key, r)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !writeThrough {
+		e.metrics.localUpdateCount.Inc(ctx)
+		newWF := workflow.DeepCopy()
+		newWF.ResourceVersion = r.localResourceVersion()
+		return newWF, nil
+	}
+
+	e.metrics.writeThroughCount.Inc(ctx)
+	toWrite := workflow.DeepCopy()
+	toWrite.ResourceVersion = r.ResourceVersion
+	var newWF *v1alpha1.FlyteWorkflow
+	if statusOnly {
+		newWF, err = e.w.UpdateStatus(ctx, toWrite, priorityClass)
+	} else {
+		newWF, err = e.w.Update(ctx, toWrite, priorityClass)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if newWF == nil || newWF.Status.IsTerminated() {
+		// Terminated (or deleted) workflows are never looked at again, no need to keep them around
+		return newWF, e.deleteRecord(key)
+	}
+
+	r.Dirty = false
+	r.ResourceVersion = newWF.ResourceVersion
+	err = e.db.Update(func(tx *bolt.Tx) error {
+		return e.putRecord(tx, key, r)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	newWF = newWF.DeepCopy()
+	newWF.ResourceVersion = r.localResourceVersion()
+	return newWF, nil
+}
+
+func (e *embeddedWorkflowStore) UpdateStatus(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
+	newWF *v1alpha1.FlyteWorkflow, err error) {
+	return e.update(ctx, workflow, priorityClass, true)
+}
+
+func (e *embeddedWorkflowStore) Update(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
+	newWF *v1alpha1.FlyteWorkflow, err error) {
+	return e.update(ctx, workflow, priorityClass, false)
+}
+
+// Writes the status of a single dirty workflow back to the CRD.
+func (e *embeddedWorkflowStore) syncWorkflow(ctx context.Context, key string, r embeddedRecord) error {
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return err
+	}
+
+	current, err := e.w.Get(ctx, namespace, name)
+	if err != nil {
+		if IsNotFound(err) {
+			return e.deleteRecord(key)
+		}
+		return err
+	}
+
+	if current.UID != r.UID {
+		// The workflow was recreated, the record holds the status of the previous one
+		return e.deleteStaleRecord(key, current.UID)
+	}
+
+	toWrite := current.DeepCopy()
+	toWrite.ResourceVersion = r.ResourceVersion
+	toWrite.Status = *r.Status.DeepCopy()
+	newWF, err := e.w.UpdateStatus(ctx, toWrite, PriorityClassRegular)
+	if err != nil {
+		if kubeerrors.IsConflict(err) {
+			// The CRD was modified outside of this store, pick up the latest known version for the next attempt.
+			return e.db.Update(func(tx *bolt.Tx) error {
+				latest, err := e.getRecord(tx, key)
+				if err != nil || latest == nil {
+					return err
+				}
+
+				latest.ResourceVersion = current.ResourceVersion
+				return e.putRecord(tx, key, latest)
+			})
+		}
+		return err
+	}
+
+	if newWF == nil {
+		return e.deleteRecord(key)
+	}
+
+	return e.db.Update(func(tx *bolt.Tx) error {
+		latest, err := e.getRecord(tx, key)
+		if err != nil || latest == nil {
+			return err
+		}
+
+		// If the workflow was updated locally while we were syncing, it stays dirty
+		if latest.Version == r.Version {
+			latest.Dirty = false
+		}
+
+		latest.ResourceVersion = newWF.ResourceVersion
+		return e.putRecord(tx, key, latest)
+	})
+}
+
+// Sync writes all the statuses that have not yet been persisted back to the FlyteWorkflow CRDs.
+func (e *embeddedWorkflowStore) Sync(ctx context.Context) {
+	dirty := map[string]embeddedRecord{}
+	err := e.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(embeddedWorkflowsBucket).ForEach(func(k, v []byte) error {
+			r := embeddedRecord{}
+			if err := json.Unmarshal(v, &r); err != nil {
+				return errors.Wrapf(err, "failed to unmarshal embedded record for workflow [%s]", string(k))
+			}
+
+			if r.Dirty {
+				dirty[string(k)] = r
+			}
+			return nil
+		})
+	})
+
+	if err != nil {
+		logger.Errorf(ctx, "Failed to list dirty workflows from the embedded store. Error [%v]", err)
+		return
+	}
+
+	e.metrics.dirtyWorkflows.Set(float64(len(dirty)))
+	t := e.metrics.syncLatency.Start()
+	defer t.Stop()
+	for key, r := range dirty {
+		if err := e.syncWorkflow(ctx, key, r); err != nil {
+			e.metrics.syncFailedCount.Inc()
+			logger.Warnf(ctx, "Failed to sync workflow [%s] back to the CRD. Error [%v]", key, err)
+			continue
+		}
+		e.metrics.syncSuccessCount.Inc()
+	}
+}
+
+func (e *embeddedWorkflowStore) startSync(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Infof(ctx, "Stopping embedded workflow store sync, syncing one last time.")
+				e.Sync(context.Background())
+				if err := e.db.Close(); err != nil {
+					logger.Errorf(ctx, "Failed to close embedded workflow store. Error [%v]", err)
+				}
+				return
+			case <-ticker.C:
+				e.Sync(ctx)
+			}
+		}
+	}()
+}
+
+func NewEmbeddedWorkflowStore(ctx context.Context, cfg EmbeddedConfig, scope promutils.Scope, workflowStore FlyteWorkflow) (
+	FlyteWorkflow, error) {
+
+	if len(cfg.Path) == 0 {
+		return nil, fmt.Errorf("a path is required for the embedded workflow store")
+	}
+
+	// Statuses only reach the CRD through the sync, which also closes the database on shutdown
+	if cfg.SyncInterval.Duration <= 0 {
+		return nil, fmt.Errorf("a positive sync-interval is required for the embedded workflow store, found [%v]",
+			cfg.SyncInterval.Duration)
+	}
+
+	db, err := bolt.Open(cfg.Path, 0600, &bolt.Options{Timeout: time.Second * 10})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open embedded workflow store at [%s]", cfg.Path)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(embeddedWorkflowsBucket)
+		return err
+	})
+
+	if err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			logger.Errorf(ctx, "Failed to close embedded workflow store. Error [%v]", closeErr)
+		}
+		return nil, errors.Wrapf(err, "failed to initialize embedded workflow store at [%s]", cfg.Path)
+	}
+
+	e := &embeddedWorkflowStore{
+		w:  workflowStore,
+		db: db,
+		metrics: &embeddedMetrics{
+			localUpdateCount:  labeled.NewCounter("wf_local_update", "Workflow updates persisted only in the embedded store", scope, labeled.EmitUnlabeledMetric),
+			writeThroughCount: labeled.NewCounter("wf_write_through", "Workflow updates written through to the CRD", scope, labeled.EmitUnlabeledMetric),
+			conflictCount:     labeled.NewCounter("wf_local_conflict", "Workflow updates rejected because of a resource version mismatch", scope, labeled.EmitUnlabeledMetric),
+			syncSuccessCount:  scope.MustNewCounter("wf_sync_success", "Workflows synced back to the CRD"),
+			syncFailedCount:   scope.MustNewCounter("wf_sync_failed", "Failures to sync a workflow back to the CRD"),
+			syncLatency:       scope.MustNewStopWatch("wf_sync_latency", "Time taken to sync all dirty workflows back to the CRD", time.Millisecond),
+			dirtyWorkflows:    scope.MustNewGauge("wf_dirty", "Number of workflows with status not yet synced back to the CRD"),
+		},
+	}
+
+	e.startSync(ctx, cfg.SyncInterval.Duration)
+	return e, nil
+}
@@ -0,0 +1,288 @@
+package workflowstore
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/lyft/flytestdlib/config"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/stretchr/testify/assert"
+	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+// An in-memory store that behaves like the api server w.r.t resource versions
+type versionedInMemoryStore struct {
+	*InmemoryWorkflowStore
+	writes int
+}
+
+func (v *versionedInMemoryStore) Get(ctx context.Context, namespace, name string) (*v1alpha1.FlyteWorkflow, error) {
+	w, err := v.InmemoryWorkflowStore.Get(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return w.DeepCopy(), nil
+}
+
+func (v *versionedInMemoryStore) UpdateStatus(ctx context.Context, w *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
+	*v1alpha1.FlyteWorkflow, error) {
+	existing, err := v.InmemoryWorkflowStore.Get(ctx, w.Namespace, w.Name)
+	if err != nil {
+		return nil, nil
+	}
+
+	if existing.ResourceVersion != w.ResourceVersion {
+		return nil, kubeerrors.NewConflict(v1alpha1.Resource(v1alpha1.FlyteWorkflowKind), w.Name, nil)
+	}
+
+	v.writes++
+	rv, _ := strconv.Atoi(w.ResourceVersion)
+	newWF := w.DeepCopy()
+	newWF.ResourceVersion = strconv.Itoa(rv + 1)
+	return v.InmemoryWorkflowStore.UpdateStatus(ctx, newWF, priorityClass)
+}
+
+func (v *versionedInMemoryStore) Update(ctx context.Context, w *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
+	*v1alpha1.FlyteWorkflow, error) {
+	return v.UpdateStatus(ctx, w, priorityClass)
+}
+
+// Returns the store, the store it syncs to and a func that removes the database of the store.
+func newTestEmbeddedStore(t *testing.T) (*embeddedWorkflowStore, *versionedInMemoryStore, func()) {
+	dir, err := ioutil.TempDir("", "embedded")
+	assert.NoError(t, err)
+
+	underlying := &versionedInMemoryStore{InmemoryWorkflowStore: NewInMemoryWorkflowStore()}
+	// Tests sync explicitly
+	cfg := EmbeddedConfig{Path: filepath.Join(dir, "wf.db"), SyncInterval: config.Duration{Duration: time.Hour}}
+	s, err := NewEmbeddedWorkflowStore(context.TODO(), cfg, promutils.NewTestScope(), underlying)
+	assert.NoError(t, err)
+	return s.(*embeddedWorkflowStore), underlying, func() {
+		assert.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func newTestWorkflow() *v1alpha1.FlyteWorkflow {
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            "name",
+			Namespace:       "ns",
+			ResourceVersion: "1",
+		},
+		Status: v1alpha1.WorkflowStatus{
+			Phase: v1alpha1.WorkflowPhaseReady,
+		},
+	}
+}
+
+func TestNewEmbeddedWorkflowStore(t *testing.T) {
+	_, err := NewEmbeddedWorkflowStore(context.TODO(), EmbeddedConfig{SyncInterval: config.Duration{Duration: time.Minute}},
+		promutils.NewTestScope(), NewInMemoryWorkflowStore())
+	assert.Error(t, err)
+
+	// Without a sync, statuses would never reach the CRD
+	dir, err := ioutil.TempDir("", "embedded")
+	assert.NoError(t, err)
+	defer func() {
+		assert.NoError(t, os.RemoveAll(dir))
+	}()
+	_, err = NewEmbeddedWorkflowStore(context.TODO(), EmbeddedConfig{Path: filepath.Join(dir, "wf.db")},
+		promutils.NewTestScope(), NewInMemoryWorkflowStore())
+	assert.Error(t, err)
+}
+
+func TestEmbeddedWorkflowStore_Update(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("status-only-update-is-local", func(t *testing.T) {
+		s, underlying, cleanup := newTestEmbeddedStore(t)
+		defer cleanup()
+		assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+		w, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+		newWF, err := s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+		assert.NotEqual(t, w.ResourceVersion, newWF.ResourceVersion)
+		assert.Equal(t, 0, underlying.writes)
+
+		w, err = s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseRunning, w.Status.Phase)
+		assert.Equal(t, newWF.ResourceVersion, w.ResourceVersion)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseReady, crd.Status.Phase)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		s, underlying, cleanup := newTestEmbeddedStore(t)
+		defer cleanup()
+		assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+		w, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		// Writing the same (now stale) version again should fail
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.Error(t, err)
+		assert.True(t, kubeerrors.IsConflict(err))
+	})
+
+	t.Run("metadata-change-writes-through", func(t *testing.T) {
+		s, underlying, cleanup := newTestEmbeddedStore(t)
+		defer cleanup()
+		assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+		w, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+		w.Finalizers = []string{"x"}
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, underlying.writes)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseRunning, crd.Status.Phase)
+
+		// Subsequent reads & writes continue to work
+		w, err = s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+	})
+
+	t.Run("terminal-writes-through-and-evicts", func(t *testing.T) {
+		s, underlying, cleanup := newTestEmbeddedStore(t)
+		defer cleanup()
+		assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+		w, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+		w, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		w.Status.Phase = v1alpha1.WorkflowPhaseSuccess
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, underlying.writes)
+
+		r, err := s.loadRecord(resourceVersionKey("ns", "name"))
+		assert.NoError(t, err)
+		assert.Nil(t, r)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseSuccess, crd.Status.Phase)
+	})
+
+	t.Run("recreated", func(t *testing.T) {
+		s, underlying, cleanup := newTestEmbeddedStore(t)
+		defer cleanup()
+		assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+		w, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		// The workflow is deleted and created again with the same name, before the local status is synced
+		assert.NoError(t, underlying.Delete(ctx, "ns", "name"))
+		recreated := newTestWorkflow()
+		recreated.UID = "recreated"
+		assert.NoError(t, underlying.Create(ctx, recreated))
+
+		w, err = s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Equal(t, v1alpha1.WorkflowPhaseReady, w.Status.Phase)
+		assert.Equal(t, recreated.ResourceVersion, w.ResourceVersion)
+
+		w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+		r, err := s.loadRecord(resourceVersionKey("ns", "name"))
+		assert.NoError(t, err)
+		assert.Equal(t, recreated.UID, r.UID)
+		assert.Equal(t, uint64(1), r.Version)
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		s, _, cleanup := newTestEmbeddedStore(t)
+		defer cleanup()
+		newWF, err := s.Update(ctx, newTestWorkflow(), PriorityClassCritical)
+		assert.NoError(t, err)
+		assert.Nil(t, newWF)
+	})
+}
+
+func TestEmbeddedWorkflowStore_Sync(t *testing.T) {
+	ctx := context.TODO()
+	s, underlying, cleanup := newTestEmbeddedStore(t)
+	defer cleanup()
+	assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+	w, err := s.Get(ctx, "ns", "name")
+	assert.NoError(t, err)
+	w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+	_, err = s.Update(ctx, w, PriorityClassCritical)
+	assert.NoError(t, err)
+
+	s.Sync(ctx)
+	assert.Equal(t, 1, underlying.writes)
+	crd, err := underlying.Get(ctx, "ns", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.WorkflowPhaseRunning, crd.Status.Phase)
+
+	r, err := s.loadRecord(resourceVersionKey("ns", "name"))
+	assert.NoError(t, err)
+	assert.False(t, r.Dirty)
+	assert.Equal(t, crd.ResourceVersion, r.ResourceVersion)
+
+	// Nothing left to sync
+	s.Sync(ctx)
+	assert.Equal(t, 1, underlying.writes)
+}
+
+func TestEmbeddedWorkflowStore_Sync_Recreated(t *testing.T) {
+	ctx := context.TODO()
+	s, underlying, cleanup := newTestEmbeddedStore(t)
+	defer cleanup()
+	assert.NoError(t, underlying.Create(ctx, newTestWorkflow()))
+
+	w, err := s.Get(ctx, "ns", "name")
+	assert.NoError(t, err)
+	w.Status.Phase = v1alpha1.WorkflowPhaseRunning
+	_, err = s.Update(ctx, w, PriorityClassCritical)
+	assert.NoError(t, err)
+
+	assert.NoError(t, underlying.Delete(ctx, "ns", "name"))
+	recreated := newTestWorkflow()
+	recreated.UID = "recreated"
+	assert.NoError(t, underlying.Create(ctx, recreated))
+
+	// The status of the previous workflow is discarded instead of being written to the new one
+	s.Sync(ctx)
+	assert.Equal(t, 0, underlying.writes)
+	crd, err := underlying.Get(ctx, "ns", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.WorkflowPhaseReady, crd.Status.Phase)
+
+	r, err := s.loadRecord(resourceVersionKey("ns", "name"))
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+}
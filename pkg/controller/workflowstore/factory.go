@@ -7,18 +7,26 @@ import (
 	flyteworkflowv1alpha1 "github.com/lyft/flytepropeller/pkg/client/clientset/versioned/typed/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytepropeller/pkg/client/listers/flyteworkflow/v1alpha1"
 	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
 )
 
 func NewWorkflowStore(ctx context.Context, cfg *Config, lister v1alpha1.FlyteWorkflowLister,
-	workflows flyteworkflowv1alpha1.FlyteworkflowV1alpha1Interface, scope promutils.Scope) (FlyteWorkflow, error) {
+	workflows flyteworkflowv1alpha1.FlyteworkflowV1alpha1Interface, dataStore *storage.DataStore, scope promutils.Scope) (FlyteWorkflow, error) {
+
+	var crdStore FlyteWorkflow = NewPassthroughWorkflowStore(ctx, scope, workflows, lister)
+	if cfg.MaxInlineNodeStatusSize > 0 {
+		crdStore = NewOffloadingWorkflowStore(ctx, scope, cfg.MaxInlineNodeStatusSize, cfg.NodeStatusCacheSize, dataStore, crdStore)
+	}
 
 	switch cfg.Policy {
 	case PolicyInMemory:
 		return NewInMemoryWorkflowStore(), nil
 	case PolicyPassThrough:
-		return NewPassthroughWorkflowStore(ctx, scope, workflows, lister), nil
+		return crdStore, nil
 	case PolicyResourceVersionCache:
-		return NewResourceVersionCachingStore(ctx, scope, NewPassthroughWorkflowStore(ctx, scope, workflows, lister)), nil
+		return NewResourceVersionCachingStore(ctx, scope, crdStore), nil
+	case PolicyEmbedded:
+		return NewEmbeddedWorkflowStore(ctx, cfg.Embedded, scope, crdStore)
 	}
 
 	return nil, fmt.Errorf("empty workflow store config")
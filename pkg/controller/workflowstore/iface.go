@@ -13,6 +13,30 @@ const (
 	PriorityClassRegular
 )
 
+// Annotation on a FlyteWorkflow that overrides its priority class. Its value is the name of the priority class.
+const PriorityClassAnnotationKey = "flyte.lyft.com/priority-class"
+
+func (p PriorityClass) String() string {
+	switch p {
+	case PriorityClassCritical:
+		return "critical"
+	case PriorityClassRegular:
+		return "regular"
+	}
+	return "unknown"
+}
+
+// Parses the name of a priority class as returned by String.
+func ParsePriorityClass(name string) (PriorityClass, bool) {
+	switch name {
+	case PriorityClassCritical.String():
+		return PriorityClassCritical, true
+	case PriorityClassRegular.String():
+		return PriorityClassRegular, true
+	}
+	return PriorityClassRegular, false
+}
+
 type FlyteWorkflow interface {
 	Get(ctx context.Context, namespace, name string) (*v1alpha1.FlyteWorkflow, error)
 	UpdateStatus(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
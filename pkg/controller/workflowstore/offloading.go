@@ -0,0 +1,273 @@
+package workflowstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/lyft/flytestdlib/logger"
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/promutils/labeled"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+const (
+	offloadedNodeStatusDir = "node-status"
+	// Offloaded node statuses are content addressed and never change, entries only expire to release memory
+	offloadedNodeStatusCacheTTL = time.Hour
+)
+
+type offloadingMetrics struct {
+	offloadCount      labeled.Counter
+	offloadLatency    labeled.StopWatch
+	rehydrateLatency  labeled.StopWatch
+	rehydrateFailures labeled.Counter
+}
+
+// A workflow store that moves the NodeStatus subtree of a workflow into blob storage when it grows beyond a configured
+// size, keeping only a reference and a checksum in the CRD. This keeps the CRD well below etcd's object size limit for
+// workflows with a large number of nodes (big fan-outs, dynamic workflows). Workflows are transparently rehydrated on Get.
+//
+// Only workflows read through this store are rehydrated. Consumers that read workflows from the informer cache or the
+// API server directly see an empty NodeStatus and have to use an OffloadedNodeStatusReader, or make do with the
+// workflow level status. E.g. the stuck workflow detector only tracks the progress of offloaded workflows by their
+// LastUpdatedAt, while the workflow archive inlines the offloaded node status.
+type offloadingWorkflowStore struct {
+	w             FlyteWorkflow
+	store         *storage.DataStore
+	reader        *OffloadedNodeStatusReader
+	maxInlineSize int
+	metrics       *offloadingMetrics
+}
+
+// Reads node statuses offloaded to blob storage. They are content addressed, so they are cached by their checksum.
+type OffloadedNodeStatusReader struct {
+	store *storage.DataStore
+	// Nil if caching is disabled
+	cache *cache.LRUExpireCache
+}
+
+func copyNodeStatus(nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus) map[v1alpha1.NodeID]*v1alpha1.NodeStatus {
+	copied := make(map[v1alpha1.NodeID]*v1alpha1.NodeStatus, len(nodeStatus))
+	for id, s := range nodeStatus {
+		copied[id] = s.DeepCopy()
+	}
+	return copied
+}
+
+// Caches the node status offloaded under the checksum.
+func (r *OffloadedNodeStatusReader) add(checksum string, nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus) {
+	if r.cache != nil {
+		r.cache.Add(checksum, copyNodeStatus(nodeStatus), offloadedNodeStatusCacheTTL)
+	}
+}
+
+// Returns a copy of the workflow with its offloaded node status inlined, or the workflow itself if its node status was
+// not offloaded. The workflow is never mutated, so it may be shared (e.g. informer cache).
+func (r *OffloadedNodeStatusReader) Rehydrate(ctx context.Context, workflow *v1alpha1.FlyteWorkflow) (*v1alpha1.FlyteWorkflow, error) {
+	offloaded := workflow.Status.OffloadedNodeStatus
+	if offloaded == nil {
+		return workflow, nil
+	}
+
+	var nodeStatus map[v1alpha1.NodeID]*v1alpha1.NodeStatus
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(offloaded.Checksum); ok {
+			nodeStatus = copyNodeStatus(cached.(map[v1alpha1.NodeID]*v1alpha1.NodeStatus))
+		}
+	}
+
+	if nodeStatus == nil {
+		var err error
+		nodeStatus, err = readOffloadedNodeStatus(ctx, r.store, offloaded)
+		if err != nil {
+			return nil, err
+		}
+		r.add(offloaded.Checksum, nodeStatus)
+	}
+
+	rehydrated := workflow.DeepCopy()
+	rehydrated.Status.NodeStatus = nodeStatus
+	return rehydrated, nil
+}
+
+// Creates a reader that caches up to cacheSize node statuses. A cacheSize of 0 disables caching.
+func NewOffloadedNodeStatusReader(store *storage.DataStore, cacheSize int) *OffloadedNodeStatusReader {
+	r := &OffloadedNodeStatusReader{store: store}
+	if cacheSize > 0 {
+		r.cache = cache.NewLRUExpireCache(cacheSize)
+	}
+	return r
+}
+
+func nodeStatusChecksum(raw []byte) string {
+	h := sha256.Sum256(raw)
+	return hex.EncodeToString(h[:])
+}
+
+// Reads and verifies a node status that was offloaded to blob storage.
+func readOffloadedNodeStatus(ctx context.Context, store *storage.DataStore, offloaded *v1alpha1.OffloadedNodeStatus) (
+	map[v1alpha1.NodeID]*v1alpha1.NodeStatus, error) {
+
+	rc, err := store.ReadRaw(ctx, offloaded.Reference)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read offloaded node status from [%s]", offloaded.Reference)
+	}
+
+	defer func() {
+		if err := rc.Close(); err != nil {
+			logger.Warnf(ctx, "Failed to close reader for [%s]. Error [%v]", offloaded.Reference, err)
+		}
+	}()
+
+	raw, err := ioutil.ReadAll(rc)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read offloaded node status from [%s]", offloaded.Reference)
+	}
+
+	if checksum := nodeStatusChecksum(raw); checksum != offloaded.Checksum {
+		return nil, errors.Errorf("checksum mismatch for offloaded node status [%s], expected [%s] found [%s]",
+			offloaded.Reference, offloaded.Checksum, checksum)
+	}
+
+	nodeStatus := map[v1alpha1.NodeID]*v1alpha1.NodeStatus{}
+	if err := json.Unmarshal(raw, &nodeStatus); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal offloaded node status from [%s]", offloaded.Reference)
+	}
+
+	return nodeStatus, nil
+}
+
+func (o *offloadingWorkflowStore) rehydrate(ctx context.Context, workflow *v1alpha1.FlyteWorkflow) (*v1alpha1.FlyteWorkflow, error) {
+	t := o.metrics.rehydrateLatency.Start(ctx)
+	defer t.Stop()
+
+	rehydrated, err := o.reader.Rehydrate(ctx, workflow)
+	if err != nil {
+		o.metrics.rehydrateFailures.Inc(ctx)
+		return nil, err
+	}
+
+	return rehydrated, nil
+}
+
+func (o *offloadingWorkflowStore) Get(ctx context.Context, namespace, name string) (*v1alpha1.FlyteWorkflow, error) {
+	w, err := o.w.Get(ctx, namespace, name)
+	if err != nil || w.Status.OffloadedNodeStatus == nil {
+		return w, err
+	}
+
+	return o.rehydrate(ctx, w)
+}
+
+// Returns the workflow that should be written to the underlying store. If the NodeStatus is larger than the configured
+// limit, it is written to blob storage and stripped from the returned copy.
+func (o *offloadingWorkflowStore) offload(ctx context.Context, workflow *v1alpha1.FlyteWorkflow) (*v1alpha1.FlyteWorkflow, error) {
+	raw, err := json.Marshal(workflow.Status.NodeStatus)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to marshal node status for workflow [%s]", workflow.GetK8sWorkflowID())
+	}
+
+	dataDir := workflow.Status.GetDataDir()
+	if len(raw) <= o.maxInlineSize || len(dataDir) == 0 {
+		if workflow.Status.OffloadedNodeStatus == nil {
+			return workflow, nil
+		}
+
+		// The node status is inlined again, drop the stale reference.
+		toWrite := workflow.DeepCopy()
+		toWrite.Status.OffloadedNodeStatus = nil
+		return toWrite, nil
+	}
+
+	checksum := nodeStatusChecksum(raw)
+	toWrite := workflow.DeepCopy()
+	toWrite.Status.NodeStatus = nil
+	if existing := workflow.Status.OffloadedNodeStatus; existing != nil && existing.Checksum == checksum {
+		return toWrite, nil
+	}
+
+	// The reference is content addressed, so that a failed (e.g. conflicting) update of the CRD never leaves it
+	// pointing to data it does not match.
+	ref, err := o.store.ConstructReference(ctx, dataDir, offloadedNodeStatusDir, checksum)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to construct offloaded node status reference for workflow [%s]", workflow.GetK8sWorkflowID())
+	}
+
+	t := o.metrics.offloadLatency.Start(ctx)
+	err = o.store.WriteRaw(ctx, ref, int64(len(raw)), storage.Options{}, bytes.NewReader(raw))
+	t.Stop()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to offload node status for workflow [%s]", workflow.GetK8sWorkflowID())
+	}
+
+	o.metrics.offloadCount.Inc(ctx)
+	o.reader.add(checksum, workflow.Status.NodeStatus)
+	logger.Debugf(ctx, "Offloaded node status [%d bytes] to [%s]", len(raw), ref)
+	toWrite.Status.OffloadedNodeStatus = &v1alpha1.OffloadedNodeStatus{
+		Reference: ref,
+		Checksum:  checksum,
+	}
+
+	return toWrite, nil
+}
+
+func (o *offloadingWorkflowStore) update(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass,
+	statusOnly bool) (*v1alpha1.FlyteWorkflow, error) {
+
+	toWrite, err := o.offload(ctx, workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	var newWF *v1alpha1.FlyteWorkflow
+	if statusOnly {
+		newWF, err = o.w.UpdateStatus(ctx, toWrite, priorityClass)
+	} else {
+		newWF, err = o.w.Update(ctx, toWrite, priorityClass)
+	}
+
+	if err != nil || newWF == nil || newWF.Status.OffloadedNodeStatus == nil {
+		return newWF, err
+	}
+
+	// Hand back the workflow the way the caller sees it, with the node status inlined.
+	newWF = newWF.DeepCopy()
+	newWF.Status.NodeStatus = workflow.Status.DeepCopy().NodeStatus
+	return newWF, nil
+}
+
+func (o *offloadingWorkflowStore) UpdateStatus(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
+	newWF *v1alpha1.FlyteWorkflow, err error) {
+	return o.update(ctx, workflow, priorityClass, true)
+}
+
+func (o *offloadingWorkflowStore) Update(ctx context.Context, workflow *v1alpha1.FlyteWorkflow, priorityClass PriorityClass) (
+	newWF *v1alpha1.FlyteWorkflow, err error) {
+	return o.update(ctx, workflow, priorityClass, false)
+}
+
+func NewOffloadingWorkflowStore(_ context.Context, scope promutils.Scope, maxInlineSize, cacheSize int, store *storage.DataStore,
+	workflowStore FlyteWorkflow) FlyteWorkflow {
+
+	return &offloadingWorkflowStore{
+		w:             workflowStore,
+		store:         store,
+		reader:        NewOffloadedNodeStatusReader(store, cacheSize),
+		maxInlineSize: maxInlineSize,
+		metrics: &offloadingMetrics{
+			offloadCount:      labeled.NewCounter("node_status_offloaded", "Node statuses written to blob storage", scope, labeled.EmitUnlabeledMetric),
+			offloadLatency:    labeled.NewStopWatch("node_status_offload", "Time taken to write node statuses to blob storage", time.Millisecond, scope, labeled.EmitUnlabeledMetric),
+			rehydrateLatency:  labeled.NewStopWatch("node_status_rehydrate", "Time taken to read node statuses from blob storage", time.Millisecond, scope, labeled.EmitUnlabeledMetric),
+			rehydrateFailures: labeled.NewCounter("node_status_rehydrate_failed", "Failures to read node statuses from blob storage", scope, labeled.EmitUnlabeledMetric),
+		},
+	}
+}
@@ -0,0 +1,199 @@
+package workflowstore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/lyft/flytestdlib/promutils"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+func newOffloadingTestWorkflow() *v1alpha1.FlyteWorkflow {
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "ns",
+		},
+		Status: v1alpha1.WorkflowStatus{
+			DataDir: "s3://bucket/ns/name",
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				"n1": {Phase: v1alpha1.NodePhaseRunning, Message: "running"},
+				"n2": {Phase: v1alpha1.NodePhaseSucceeded},
+			},
+		},
+	}
+}
+
+func newOffloadingTestStore(t *testing.T, maxInlineSize int) (FlyteWorkflow, *InmemoryWorkflowStore, *storage.DataStore) {
+	dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+	assert.NoError(t, err)
+	underlying := NewInMemoryWorkflowStore()
+	return NewOffloadingWorkflowStore(context.TODO(), promutils.NewTestScope(), maxInlineSize, 10, dataStore, underlying),
+		underlying, dataStore
+}
+
+func TestOffloadingWorkflowStore_Update(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("small-status-inlined", func(t *testing.T) {
+		s, underlying, _ := newOffloadingTestStore(t, 1024*1024)
+		w := newOffloadingTestWorkflow()
+		assert.NoError(t, underlying.Create(ctx, w))
+
+		newWF, err := s.UpdateStatus(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+		assert.Nil(t, newWF.Status.OffloadedNodeStatus)
+		assert.Len(t, newWF.Status.NodeStatus, 2)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Nil(t, crd.Status.OffloadedNodeStatus)
+		assert.Len(t, crd.Status.NodeStatus, 2)
+	})
+
+	t.Run("large-status-offloaded", func(t *testing.T) {
+		s, underlying, dataStore := newOffloadingTestStore(t, 10)
+		w := newOffloadingTestWorkflow()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+
+		newWF, err := s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+		assert.NotNil(t, newWF.Status.OffloadedNodeStatus)
+		assert.Len(t, newWF.Status.NodeStatus, 2)
+		// The caller's copy is never modified
+		assert.Nil(t, w.Status.OffloadedNodeStatus)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Nil(t, crd.Status.NodeStatus)
+		assert.NotNil(t, crd.Status.OffloadedNodeStatus)
+		assert.Contains(t, crd.Status.OffloadedNodeStatus.Reference.String(), "s3://bucket/ns/name/node-status/")
+		assert.Equal(t, crd.Status.OffloadedNodeStatus.Checksum, newWF.Status.OffloadedNodeStatus.Checksum)
+
+		m, err := dataStore.Head(ctx, crd.Status.OffloadedNodeStatus.Reference)
+		assert.NoError(t, err)
+		assert.True(t, m.Exists())
+
+		rehydrated, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Len(t, rehydrated.Status.NodeStatus, 2)
+		assert.Equal(t, v1alpha1.NodePhaseRunning, rehydrated.Status.NodeStatus["n1"].Phase)
+		assert.Equal(t, "running", rehydrated.Status.NodeStatus["n1"].Message)
+		// The underlying copy is left untouched
+		assert.Nil(t, crd.Status.NodeStatus)
+	})
+
+	t.Run("shrinks-back-inline", func(t *testing.T) {
+		s, underlying, _ := newOffloadingTestStore(t, 64)
+		w := newOffloadingTestWorkflow()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+
+		_, err := s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		w, err = s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.NotNil(t, w.Status.OffloadedNodeStatus)
+		delete(w.Status.NodeStatus, "n1")
+		_, err = s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Nil(t, crd.Status.OffloadedNodeStatus)
+		assert.Len(t, crd.Status.NodeStatus, 1)
+	})
+
+	t.Run("no-data-dir", func(t *testing.T) {
+		s, underlying, _ := newOffloadingTestStore(t, 10)
+		w := newOffloadingTestWorkflow()
+		w.Status.DataDir = ""
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+
+		_, err := s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Nil(t, crd.Status.OffloadedNodeStatus)
+		assert.Len(t, crd.Status.NodeStatus, 2)
+	})
+}
+
+func TestOffloadingWorkflowStore_Get(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("checksum-mismatch", func(t *testing.T) {
+		s, underlying, dataStore := newOffloadingTestStore(t, 10)
+		w := newOffloadingTestWorkflow()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+		_, err := s.Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		corrupt := []byte("{}")
+		assert.NoError(t, dataStore.WriteRaw(ctx, crd.Status.OffloadedNodeStatus.Reference, int64(len(corrupt)),
+			storage.Options{}, bytes.NewReader(corrupt)))
+
+		// A store that did not write the node status has not cached it
+		s = NewOffloadingWorkflowStore(ctx, promutils.NewTestScope(), 10, 10, dataStore, underlying)
+		_, err = s.Get(ctx, "ns", "name")
+		assert.Error(t, err)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		dataStore, err := storage.NewDataStore(&storage.Config{Type: storage.TypeMemory}, promutils.NewTestScope())
+		assert.NoError(t, err)
+		underlying := NewInMemoryWorkflowStore()
+		w := newOffloadingTestWorkflow()
+		assert.NoError(t, underlying.Create(ctx, w.DeepCopy()))
+		_, err = NewOffloadingWorkflowStore(ctx, promutils.NewTestScope(), 10, 10, dataStore, underlying).Update(ctx, w, PriorityClassCritical)
+		assert.NoError(t, err)
+
+		s := NewOffloadingWorkflowStore(ctx, promutils.NewTestScope(), 10, 10, dataStore, underlying)
+		rehydrated, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Len(t, rehydrated.Status.NodeStatus, 2)
+		// The cached node status is never handed out
+		rehydrated.Status.NodeStatus["n1"].Phase = v1alpha1.NodePhaseFailed
+
+		crd, err := underlying.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		corrupt := []byte("{}")
+		assert.NoError(t, dataStore.WriteRaw(ctx, crd.Status.OffloadedNodeStatus.Reference, int64(len(corrupt)),
+			storage.Options{}, bytes.NewReader(corrupt)))
+
+		rehydrated, err = s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Len(t, rehydrated.Status.NodeStatus, 2)
+		assert.Equal(t, v1alpha1.NodePhaseRunning, rehydrated.Status.NodeStatus["n1"].Phase)
+	})
+
+	t.Run("missing-blob", func(t *testing.T) {
+		s, underlying, _ := newOffloadingTestStore(t, 10)
+		w := newOffloadingTestWorkflow()
+		w.Status.OffloadedNodeStatus = &v1alpha1.OffloadedNodeStatus{
+			Reference: "s3://bucket/ns/name/node-status/missing",
+			Checksum:  "abc",
+		}
+		assert.NoError(t, underlying.Create(ctx, w))
+
+		_, err := s.Get(ctx, "ns", "name")
+		assert.Error(t, err)
+	})
+
+	t.Run("not-offloaded", func(t *testing.T) {
+		s, underlying, _ := newOffloadingTestStore(t, 10)
+		assert.NoError(t, underlying.Create(ctx, newOffloadingTestWorkflow()))
+
+		w, err := s.Get(ctx, "ns", "name")
+		assert.NoError(t, err)
+		assert.Len(t, w.Status.NodeStatus, 2)
+	})
+}
@@ -0,0 +1,222 @@
+package visualize
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type GraphNodeKind = string
+
+const (
+	GraphNodeKindStart    GraphNodeKind = "start"
+	GraphNodeKindEnd      GraphNodeKind = "end"
+	GraphNodeKindTask     GraphNodeKind = "task"
+	GraphNodeKindWorkflow GraphNodeKind = "workflow"
+	GraphNodeKindBranch   GraphNodeKind = "branch"
+	// Pseudo node, the source of the inputs bound to static values
+	GraphNodeKindStatic GraphNodeKind = "static"
+)
+
+type GraphEdgeKind = string
+
+const (
+	// The downstream node binds outputs of the upstream node
+	GraphEdgeKindData GraphEdgeKind = "data"
+	// The downstream node only runs after the upstream node
+	GraphEdgeKindExecution GraphEdgeKind = executionEdgeLabel
+)
+
+type GraphNode struct {
+	ID   string        `json:"id"`
+	Kind GraphNodeKind `json:"kind"`
+	// The task, sub workflow or launch plan run by the node
+	Target string `json:"target,omitempty"`
+}
+
+// Binds an output of the upstream node to an input of the downstream node. The output is empty for static values.
+type GraphBinding struct {
+	FromVar string `json:"fromVar,omitempty"`
+	ToVar   string `json:"toVar"`
+}
+
+type GraphEdge struct {
+	From     string         `json:"from"`
+	To       string         `json:"to"`
+	Kind     GraphEdgeKind  `json:"kind"`
+	Bindings []GraphBinding `json:"bindings,omitempty"`
+}
+
+// A node and edge list representation of a compiled workflow.
+type Graph struct {
+	ID    string      `json:"id"`
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+func compiledNodeKind(n *core.Node) GraphNodeKind {
+	switch {
+	case n.GetId() == common.StartNodeID:
+		return GraphNodeKindStart
+	case n.GetId() == common.EndNodeID:
+		return GraphNodeKindEnd
+	case n.GetTaskNode() != nil:
+		return GraphNodeKindTask
+	case n.GetWorkflowNode() != nil:
+		return GraphNodeKindWorkflow
+	case n.GetBranchNode() != nil:
+		return GraphNodeKindBranch
+	}
+	return ""
+}
+
+func compiledNodeTarget(n *core.Node) string {
+	switch {
+	case n.GetTaskNode().GetReferenceId() != nil:
+		return n.GetTaskNode().GetReferenceId().String()
+	case n.GetWorkflowNode().GetSubWorkflowRef() != nil:
+		return n.GetWorkflowNode().GetSubWorkflowRef().String()
+	case n.GetWorkflowNode().GetLaunchplanRef() != nil:
+		return n.GetWorkflowNode().GetLaunchplanRef().String()
+	}
+	return ""
+}
+
+// Returns the bindings of the inputs of a node, by upstream node.
+func nodeBindings(n *core.Node) map[common.NodeID][]GraphBinding {
+	res := map[common.NodeID][]GraphBinding{}
+	for _, binding := range n.GetInputs() {
+		flatMap := make(map[common.NodeID]sets.String)
+		flatten(binding.GetBinding(), flatMap)
+		for from, vars := range flatMap {
+			// Workflow inputs are the outputs of the start node
+			if from == "" {
+				from = common.StartNodeID
+			}
+
+			if from == staticNodeID {
+				res[from] = append(res[from], GraphBinding{ToVar: binding.GetVar()})
+				continue
+			}
+
+			for _, v := range vars.List() {
+				res[from] = append(res[from], GraphBinding{FromVar: v, ToVar: binding.GetVar()})
+			}
+		}
+	}
+
+	return res
+}
+
+// Builds the node and edge list of a compiled workflow. An edge is created for every connection between two nodes, with
+// the bindings of the outputs of the upstream node to the inputs of the downstream node.
+func ToGraph(g *core.CompiledWorkflow) *Graph {
+	res := &Graph{ID: g.GetTemplate().GetId().String()}
+	bindings := make(map[common.NodeID]map[common.NodeID][]GraphBinding, len(g.GetTemplate().GetNodes()))
+	hasStatic := false
+	for _, n := range g.GetTemplate().GetNodes() {
+		res.Nodes = append(res.Nodes, GraphNode{
+			ID:     n.GetId(),
+			Kind:   compiledNodeKind(n),
+			Target: compiledNodeTarget(n),
+		})
+
+		bindings[n.GetId()] = nodeBindings(n)
+		if _, ok := bindings[n.GetId()][staticNodeID]; ok {
+			hasStatic = true
+		}
+	}
+
+	if hasStatic {
+		res.Nodes = append(res.Nodes, GraphNode{ID: staticNodeID, Kind: GraphNodeKindStatic})
+	}
+
+	for _, n := range g.GetTemplate().GetNodes() {
+		for _, to := range g.GetConnections().GetDownstream()[n.GetId()].GetIds() {
+			edge := GraphEdge{From: n.GetId(), To: to, Kind: GraphEdgeKindExecution}
+			if b, ok := bindings[to][n.GetId()]; ok {
+				edge.Kind = GraphEdgeKindData
+				edge.Bindings = b
+				delete(bindings[to], n.GetId())
+			}
+			res.Edges = append(res.Edges, edge)
+		}
+	}
+
+	// Static values, and bindings to nodes the node is not connected to, are not part of the connections
+	for _, n := range g.GetTemplate().GetNodes() {
+		remaining := make([]common.NodeID, 0, len(bindings[n.GetId()]))
+		for from := range bindings[n.GetId()] {
+			remaining = append(remaining, from)
+		}
+		sort.Strings(remaining)
+		for _, from := range remaining {
+			res.Edges = append(res.Edges, GraphEdge{
+				From:     from,
+				To:       n.GetId(),
+				Kind:     GraphEdgeKindData,
+				Bindings: bindings[n.GetId()][from],
+			})
+		}
+	}
+
+	return res
+}
+
+// Returns the node and edge list of a compiled workflow as json.
+func ToJSON(g *core.CompiledWorkflow) ([]byte, error) {
+	return json.MarshalIndent(ToGraph(g), "", "  ")
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+// Returns a Mermaid https://mermaid-js.github.io/ flowchart of a compiled workflow. Data edges are labeled with the
+// bound outputs and inputs, execution edges are dashed.
+func ToMermaid(g *core.CompiledWorkflow) string {
+	graph := ToGraph(g)
+	b := &strings.Builder{}
+	b.WriteString("flowchart TB\n")
+
+	// Node ids are not valid Mermaid ids, e.g. end is a keyword, the nodes are numbered instead
+	mermaidIDs := make(map[string]string, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		id := fmt.Sprintf("node%d", i)
+		mermaidIDs[n.ID] = id
+		switch n.Kind {
+		case GraphNodeKindStart, GraphNodeKindEnd:
+			fmt.Fprintf(b, "    %s([\"%s\"])\n", id, mermaidEscape(n.ID))
+		case GraphNodeKindStatic:
+			fmt.Fprintf(b, "    %s[/\"%s\"/]\n", id, mermaidEscape(n.ID))
+		case GraphNodeKindBranch:
+			fmt.Fprintf(b, "    %s{\"%s (%s)\"}\n", id, mermaidEscape(n.ID), n.Kind)
+		default:
+			fmt.Fprintf(b, "    %s[\"%s (%s)\"]\n", id, mermaidEscape(n.ID), n.Kind)
+		}
+	}
+
+	for _, e := range graph.Edges {
+		if e.Kind == GraphEdgeKindExecution {
+			fmt.Fprintf(b, "    %s -.-> %s\n", mermaidIDs[e.From], mermaidIDs[e.To])
+			continue
+		}
+
+		labels := make([]string, 0, len(e.Bindings))
+		for _, binding := range e.Bindings {
+			if len(binding.FromVar) == 0 {
+				labels = append(labels, binding.ToVar)
+			} else {
+				labels = append(labels, fmt.Sprintf("%s: %s", binding.FromVar, binding.ToVar))
+			}
+		}
+		fmt.Fprintf(b, "    %s -->|\"%s\"| %s\n", mermaidIDs[e.From], mermaidEscape(strings.Join(labels, ", ")), mermaidIDs[e.To])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package visualize
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lyft/flytepropeller/pkg/compiler/common"
+	"github.com/lyft/flytepropeller/pkg/utils"
+)
+
+func promise(nodeID, v string) *core.BindingData {
+	return &core.BindingData{
+		Value: &core.BindingData_Promise{Promise: &core.OutputReference{NodeId: nodeID, Var: v}},
+	}
+}
+
+// start -> n0 -> n1 -> end, n1 also binds a workflow input and a static value, n2 only runs after n0
+func newGraphTestWorkflow() *core.CompiledWorkflow {
+	taskID := &core.Identifier{ResourceType: core.ResourceType_TASK, Project: "p", Domain: "d", Name: "t", Version: "v"}
+	taskNode := func(id string, inputs ...*core.Binding) *core.Node {
+		return &core.Node{
+			Id:     id,
+			Inputs: inputs,
+			Target: &core.Node_TaskNode{TaskNode: &core.TaskNode{
+				Reference: &core.TaskNode_ReferenceId{ReferenceId: taskID},
+			}},
+		}
+	}
+
+	return &core.CompiledWorkflow{
+		Template: &core.WorkflowTemplate{
+			Id: &core.Identifier{ResourceType: core.ResourceType_WORKFLOW, Project: "p", Domain: "d", Name: "wf", Version: "v"},
+			Nodes: []*core.Node{
+				{Id: common.StartNodeID},
+				taskNode("n0", &core.Binding{Var: "x", Binding: promise(common.StartNodeID, "a")}),
+				taskNode("n1",
+					&core.Binding{Var: "x", Binding: promise("n0", "o0")},
+					&core.Binding{Var: "y", Binding: &core.BindingData{Value: &core.BindingData_Collection{
+						Collection: &core.BindingDataCollection{Bindings: []*core.BindingData{promise("n0", "o1"), promise("", "b")}},
+					}}},
+					&core.Binding{Var: "z", Binding: &core.BindingData{Value: &core.BindingData_Scalar{
+						Scalar: utils.MustMakeLiteral(1).GetScalar(),
+					}}},
+				),
+				taskNode("n2"),
+				{Id: common.EndNodeID, Inputs: []*core.Binding{{Var: "o", Binding: promise("n1", "o0")}}},
+			},
+		},
+		Connections: &core.ConnectionSet{
+			Downstream: map[string]*core.ConnectionSet_IdList{
+				common.StartNodeID: {Ids: []string{"n0", "n1"}},
+				"n0":               {Ids: []string{"n1", "n2"}},
+				"n1":               {Ids: []string{common.EndNodeID}},
+			},
+		},
+	}
+}
+
+func TestToGraph(t *testing.T) {
+	g := ToGraph(newGraphTestWorkflow())
+	assert.Contains(t, g.ID, "name:\"wf\"")
+
+	if assert.Len(t, g.Nodes, 6) {
+		assert.Equal(t, GraphNode{ID: common.StartNodeID, Kind: GraphNodeKindStart}, g.Nodes[0])
+		assert.Equal(t, GraphNodeKindTask, g.Nodes[1].Kind)
+		assert.Contains(t, g.Nodes[1].Target, "name:\"t\"")
+		assert.Equal(t, GraphNodeKindEnd, g.Nodes[4].Kind)
+		assert.Equal(t, GraphNode{ID: staticNodeID, Kind: GraphNodeKindStatic}, g.Nodes[5])
+	}
+
+	assert.Equal(t, []GraphEdge{
+		{From: common.StartNodeID, To: "n0", Kind: GraphEdgeKindData, Bindings: []GraphBinding{{FromVar: "a", ToVar: "x"}}},
+		// Bindings to the empty node id are bindings to the workflow inputs
+		{From: common.StartNodeID, To: "n1", Kind: GraphEdgeKindData, Bindings: []GraphBinding{{FromVar: "b", ToVar: "y"}}},
+		{From: "n0", To: "n1", Kind: GraphEdgeKindData, Bindings: []GraphBinding{{FromVar: "o0", ToVar: "x"}, {FromVar: "o1", ToVar: "y"}}},
+		{From: "n0", To: "n2", Kind: GraphEdgeKindExecution},
+		{From: "n1", To: common.EndNodeID, Kind: GraphEdgeKindData, Bindings: []GraphBinding{{FromVar: "o0", ToVar: "o"}}},
+		{From: staticNodeID, To: "n1", Kind: GraphEdgeKindData, Bindings: []GraphBinding{{ToVar: "z"}}},
+	}, g.Edges)
+}
+
+func TestToJSON(t *testing.T) {
+	raw, err := ToJSON(newGraphTestWorkflow())
+	assert.NoError(t, err)
+
+	g := &Graph{}
+	assert.NoError(t, json.Unmarshal(raw, g))
+	assert.Equal(t, ToGraph(newGraphTestWorkflow()), g)
+	assert.Contains(t, string(raw), `"kind": "execution"`)
+}
+
+func TestToMermaid(t *testing.T) {
+	m := ToMermaid(newGraphTestWorkflow())
+	lines := strings.Split(strings.TrimSpace(m), "\n")
+	assert.Equal(t, []string{
+		"flowchart TB",
+		`    node0(["start-node"])`,
+		`    node1["n0 (task)"]`,
+		`    node2["n1 (task)"]`,
+		`    node3["n2 (task)"]`,
+		`    node4(["end-node"])`,
+		`    node5[/"static"/]`,
+		`    node0 -->|"a: x"| node1`,
+		`    node0 -->|"b: y"| node2`,
+		`    node1 -->|"o0: x, o1: y"| node2`,
+		`    node1 -.-> node3`,
+		`    node2 -->|"o0: o"| node4`,
+		`    node5 -->|"z"| node2`,
+	}, lines)
+}
@@ -0,0 +1,406 @@
+package visualize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lyft/flytestdlib/storage"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+// Layout of the nodes of a graph, in pixels. Nodes are laid out top to bottom in ranks, a node is ranked right below the
+// furthest of its upstream nodes.
+const (
+	htmlNodeWidth  = 180
+	htmlNodeHeight = 40
+	htmlNodeGapX   = 30
+	htmlNodeGapY   = 50
+	htmlPadding    = 20
+	// Labels longer than this are truncated, the full id is part of the tooltip
+	htmlMaxLabelLength = 24
+)
+
+var nodePhaseColors = map[v1alpha1.NodePhase]string{
+	v1alpha1.NodePhaseNotYetStarted:    "#e0e0e0",
+	v1alpha1.NodePhaseQueued:           "#bbdefb",
+	v1alpha1.NodePhaseRunning:          "#fff176",
+	v1alpha1.NodePhaseFailing:          "#ffab91",
+	v1alpha1.NodePhaseSucceeding:       "#c5e1a5",
+	v1alpha1.NodePhaseSucceeded:        "#81c784",
+	v1alpha1.NodePhaseFailed:           "#e57373",
+	v1alpha1.NodePhaseSkipped:          "#b0bec5",
+	v1alpha1.NodePhaseRetryableFailure: "#ffcc80",
+	v1alpha1.NodePhaseTimingOut:        "#ffab91",
+	v1alpha1.NodePhaseTimedOut:         "#e57373",
+}
+
+type htmlNode struct {
+	Label   string
+	Tooltip string
+	Color   string
+	// Anchor of the cluster holding the nested nodes of this node, empty if it has none
+	Cluster string
+	X       int
+	Y       int
+	Width   int
+	Height  int
+}
+
+type htmlEdge struct {
+	X1 int
+	Y1 int
+	X2 int
+	Y2 int
+}
+
+// A graph of nodes, either of the workflow or nested in a node, e.g. the nodes of a sub workflow, branch or dynamic node.
+type htmlGraph struct {
+	Anchor   string
+	Title    string
+	Color    string
+	Open     bool
+	Width    int
+	Height   int
+	Nodes    []htmlNode
+	Edges    []htmlEdge
+	Clusters []*htmlGraph
+}
+
+type htmlPage struct {
+	Title  string
+	Phase  string
+	Legend map[string]string
+	Graph  *htmlGraph
+}
+
+// A node to be placed in a graph, the spec is nil for nodes that are not part of the workflow spec.
+type graphEntry struct {
+	id     v1alpha1.NodeID
+	spec   v1alpha1.ExecutableNode
+	status v1alpha1.ExecutableNodeStatus
+	rank   int
+}
+
+type htmlBuilder struct {
+	w *v1alpha1.FlyteWorkflow
+}
+
+func nodeTooltip(id v1alpha1.NodeID, kind string, s v1alpha1.ExecutableNodeStatus) string {
+	lines := []string{
+		fmt.Sprintf("%s (%s)", id, kind),
+		fmt.Sprintf("Phase: %s", s.GetPhase().String()),
+		fmt.Sprintf("Attempts: %d", s.GetAttempts()),
+	}
+
+	if s.GetStartedAt() != nil {
+		if s.GetStoppedAt() != nil {
+			lines = append(lines, fmt.Sprintf("Duration: %s", s.GetStoppedAt().Sub(s.GetStartedAt().Time).String()))
+		} else {
+			lines = append(lines, fmt.Sprintf("Running for: %s", time.Since(s.GetStartedAt().Time).Round(time.Second).String()))
+		}
+	}
+
+	if s.IsCached() {
+		lines = append(lines, "Cached: true")
+	}
+
+	if len(s.GetMessage()) > 0 {
+		lines = append(lines, fmt.Sprintf("Message: %s", s.GetMessage()))
+	}
+
+	if err := s.GetExecutionError(); err != nil {
+		lines = append(lines, fmt.Sprintf("Error: [%s] %s", err.GetCode(), err.GetMessage()))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func nodeLabel(id v1alpha1.NodeID, kind string) string {
+	label := fmt.Sprintf("%s (%s)", id, kind)
+	if len(label) > htmlMaxLabelLength {
+		return label[:htmlMaxLabelLength-3] + "..."
+	}
+	return label
+}
+
+func nodePhaseColor(p v1alpha1.NodePhase) string {
+	if c, ok := nodePhaseColors[p]; ok {
+		return c
+	}
+	return nodePhaseColors[v1alpha1.NodePhaseNotYetStarted]
+}
+
+// Clusters of nodes that did not run yet, or succeeded, are collapsed
+func isInteresting(p v1alpha1.NodePhase) bool {
+	return p != v1alpha1.NodePhaseNotYetStarted && p != v1alpha1.NodePhaseSucceeded && p != v1alpha1.NodePhaseSkipped
+}
+
+// Ranks the nodes reachable from the start node of a workflow, a node is ranked below all of its upstream nodes.
+func rankNodes(ctx context.Context, scope v1alpha1.ExecutableSubWorkflow, statuses v1alpha1.NodeStatusGetter) ([]*graphEntry, error) {
+	sorted, err := TopologicalSort(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	ranks := make(map[v1alpha1.NodeID]int, len(sorted))
+	entries := make([]*graphEntry, 0, len(sorted))
+	for _, n := range sorted {
+		rank := ranks[n.GetID()]
+		downstream, err := scope.FromNode(n.GetID())
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range downstream {
+			if ranks[d] < rank+1 {
+				ranks[d] = rank + 1
+			}
+		}
+
+		entries = append(entries, &graphEntry{
+			id:     n.GetID(),
+			spec:   n,
+			status: statuses.GetNodeExecutionStatus(ctx, n.GetID()),
+			rank:   rank,
+		})
+	}
+
+	return entries, nil
+}
+
+// Entries of the nested nodes of a node that are only known from the status, e.g. nodes of dynamic workflows.
+func statusEntries(s v1alpha1.ExecutableNodeStatus) []*graphEntry {
+	var entries []*graphEntry
+	s.VisitNodeStatuses(func(id v1alpha1.NodeID, status v1alpha1.ExecutableNodeStatus) {
+		entries = append(entries, &graphEntry{id: id, status: status})
+	})
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].id < entries[j].id
+	})
+	return entries
+}
+
+func (b htmlBuilder) buildGraph(ctx context.Context, anchor, title string, phase v1alpha1.NodePhase, entries []*graphEntry,
+	scope v1alpha1.ExecutableSubWorkflow, statuses v1alpha1.NodeStatusGetter) (*htmlGraph, error) {
+
+	g := &htmlGraph{
+		Anchor: anchor,
+		Title:  title,
+		Color:  nodePhaseColor(phase),
+		Open:   isInteresting(phase),
+	}
+
+	// Lays out each rank left to right, in topological order
+	positions := make(map[v1alpha1.NodeID]htmlNode, len(entries))
+	perRank := map[int]int{}
+	maxPerRank, maxRank := 0, 0
+	for _, e := range entries {
+		x := htmlPadding + perRank[e.rank]*(htmlNodeWidth+htmlNodeGapX)
+		y := htmlPadding + e.rank*(htmlNodeHeight+htmlNodeGapY)
+		perRank[e.rank]++
+		if perRank[e.rank] > maxPerRank {
+			maxPerRank = perRank[e.rank]
+		}
+		if e.rank > maxRank {
+			maxRank = e.rank
+		}
+
+		kind := "unknown"
+		if e.spec != nil {
+			kind = e.spec.GetKind().String()
+		}
+
+		n := htmlNode{
+			Label:   nodeLabel(e.id, kind),
+			Tooltip: nodeTooltip(e.id, kind, e.status),
+			Color:   nodePhaseColor(e.status.GetPhase()),
+			X:       x,
+			Y:       y,
+			Width:   htmlNodeWidth,
+			Height:  htmlNodeHeight,
+		}
+
+		cluster, err := b.buildCluster(ctx, fmt.Sprintf("%s/%s", anchor, e.id), e, scope, statuses)
+		if err != nil {
+			return nil, err
+		}
+		if cluster != nil {
+			n.Cluster = cluster.Anchor
+			g.Clusters = append(g.Clusters, cluster)
+		}
+
+		positions[e.id] = n
+		g.Nodes = append(g.Nodes, n)
+	}
+
+	if scope != nil {
+		for _, e := range entries {
+			if e.spec == nil {
+				continue
+			}
+			downstream, err := scope.FromNode(e.id)
+			if err != nil {
+				return nil, err
+			}
+			from := positions[e.id]
+			for _, d := range downstream {
+				to, ok := positions[d]
+				if !ok {
+					continue
+				}
+				g.Edges = append(g.Edges, htmlEdge{
+					X1: from.X + htmlNodeWidth/2,
+					Y1: from.Y + htmlNodeHeight,
+					X2: to.X + htmlNodeWidth/2,
+					Y2: to.Y,
+				})
+			}
+		}
+	}
+
+	if len(entries) > 0 {
+		g.Width = 2*htmlPadding + maxPerRank*htmlNodeWidth + (maxPerRank-1)*htmlNodeGapX
+		g.Height = 2*htmlPadding + (maxRank+1)*htmlNodeHeight + maxRank*htmlNodeGapY
+	}
+
+	return g, nil
+}
+
+// Builds the cluster of the nested nodes of a node, nil if the node has no nested nodes.
+func (b htmlBuilder) buildCluster(ctx context.Context, anchor string, e *graphEntry, scope v1alpha1.ExecutableSubWorkflow,
+	statuses v1alpha1.NodeStatusGetter) (*htmlGraph, error) {
+
+	phase := e.status.GetPhase()
+	if e.spec != nil {
+		switch e.spec.GetKind() {
+		case v1alpha1.NodeKindBranch:
+			// The nodes of a branch are part of the same workflow as the branch node
+			var ids []*v1alpha1.NodeID
+			ids = append(ids, e.spec.GetBranchNode().GetIf().GetThenNode())
+			for _, elseIf := range e.spec.GetBranchNode().GetElseIf() {
+				ids = append(ids, elseIf.GetThenNode())
+			}
+			ids = append(ids, e.spec.GetBranchNode().GetElse())
+
+			var entries []*graphEntry
+			for _, id := range ids {
+				if id == nil || scope == nil {
+					continue
+				}
+				if n, ok := scope.GetNode(*id); ok {
+					entries = append(entries, &graphEntry{id: *id, spec: n, status: statuses.GetNodeExecutionStatus(ctx, *id)})
+				}
+			}
+			if len(entries) == 0 {
+				return nil, nil
+			}
+			return b.buildGraph(ctx, anchor, fmt.Sprintf("Branch [%s]", e.id), phase, entries, scope, statuses)
+		case v1alpha1.NodeKindWorkflow:
+			if ref := e.spec.GetWorkflowNode().GetSubWorkflowRef(); ref != nil {
+				swf := b.w.FindSubWorkflow(*ref)
+				if swf == nil {
+					return nil, fmt.Errorf("sub workflow [%s] of node [%s] not found", *ref, e.id)
+				}
+				entries, err := rankNodes(ctx, swf, e.status)
+				if err != nil {
+					return nil, err
+				}
+				return b.buildGraph(ctx, anchor, fmt.Sprintf("SubWorkflow [%s] of [%s]", swf.GetID(), e.id), phase, entries, swf, e.status)
+			}
+		}
+	}
+
+	// Nodes of dynamic workflows are only known from the status
+	entries := statusEntries(e.status)
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	return b.buildGraph(ctx, anchor, fmt.Sprintf("Nodes of [%s]", e.id), phase, entries, nil, e.status)
+}
+
+var htmlTemplate = template.Must(template.New("workflow").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 20px; }
+details { border-left: 4px solid; margin: 10px 0 10px 10px; padding-left: 10px; }
+summary { cursor: pointer; font-weight: bold; }
+.legend span { display: inline-block; padding: 2px 8px; margin: 2px; border-radius: 4px; font-size: 12px; }
+svg text { font-size: 12px; pointer-events: none; }
+svg a rect { stroke: #1565c0; stroke-width: 2; }
+</style>
+</head>
+<body>
+<h2>{{.Title}}</h2>
+<p>Phase: {{.Phase}}</p>
+<div class="legend">{{range $phase, $color := .Legend}}<span style="background: {{$color}}">{{$phase}}</span>{{end}}</div>
+{{template "graph" .Graph}}
+<script>
+function openCluster() {
+  var el = document.getElementById(decodeURIComponent(window.location.hash.substring(1)));
+  for (; el; el = el.parentElement) {
+    if (el.tagName === "DETAILS") { el.open = true; }
+  }
+}
+window.addEventListener("hashchange", openCluster);
+openCluster();
+</script>
+</body>
+</html>
+{{define "node"}}<svg x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}"><rect width="100%" height="100%" rx="6" fill="{{.Color}}" stroke="#424242"><title>{{.Tooltip}}</title></rect><text x="50%" y="50%" dominant-baseline="middle" text-anchor="middle">{{.Label}}</text></svg>{{end}}
+{{define "graph"}}<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="{{.Height}}">
+<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto"><path d="M 0 0 L 10 5 L 0 10 z" fill="#616161"/></marker></defs>
+{{range .Edges}}<line x1="{{.X1}}" y1="{{.Y1}}" x2="{{.X2}}" y2="{{.Y2}}" stroke="#616161" marker-end="url(#arrow)"/>
+{{end}}{{range .Nodes}}{{if .Cluster}}<a href="#{{.Cluster}}">{{template "node" .}}</a>{{else}}{{template "node" .}}{{end}}
+{{end}}</svg>
+{{range .Clusters}}<details id="{{.Anchor}}" style="border-color: {{.Color}}"{{if .Open}} open{{end}}>
+<summary>{{.Title}}</summary>
+{{template "graph" .}}
+</details>
+{{end}}{{end}}`))
+
+// Returns a self-contained html page rendering the current state of a workflow. Nodes are colored by phase, hovering
+// a node shows its attempts, duration and error. The nodes nested in sub workflow, branch and dynamic nodes are
+// rendered as collapsible clusters, following the link of a node opens its cluster.
+func WorkflowToHTML(ctx context.Context, w *v1alpha1.FlyteWorkflow) (string, error) {
+	// Node statuses are looked up through the data reference constructor
+	if w.DataReferenceConstructor == nil {
+		w.DataReferenceConstructor = storage.URLPathConstructor{}
+	}
+
+	b := htmlBuilder{w: w}
+	entries, err := rankNodes(ctx, w, w)
+	if err != nil {
+		return "", err
+	}
+
+	g, err := b.buildGraph(ctx, "wf", fmt.Sprintf("Workflow [%s]", w.GetID()), v1alpha1.NodePhaseNotYetStarted, entries, w, w)
+	if err != nil {
+		return "", err
+	}
+
+	legend := make(map[string]string, len(nodePhaseColors))
+	for p, c := range nodePhaseColors {
+		legend[strings.TrimPrefix(p.String(), "NodePhase")] = c
+	}
+
+	page := htmlPage{
+		Title:  fmt.Sprintf("%s/%s [ExecId: %s]", w.GetNamespace(), w.GetName(), w.GetExecutionID()),
+		Phase:  w.GetExecutionStatus().GetPhase().String(),
+		Legend: legend,
+		Graph:  g,
+	}
+
+	buf := &bytes.Buffer{}
+	if err := htmlTemplate.Execute(buf, page); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
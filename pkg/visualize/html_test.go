@@ -0,0 +1,130 @@
+package visualize
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lyft/flyteidl/gen/pb-go/flyteidl/core"
+	"github.com/lyft/flytestdlib/storage"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/lyft/flytepropeller/pkg/apis/flyteworkflow/v1alpha1"
+)
+
+func newHTMLTestWorkflow() *v1alpha1.FlyteWorkflow {
+	subWorkflowID := "sub"
+	thenNode := "b1-n0"
+	startedAt := time.Date(2020, time.March, 4, 10, 0, 0, 0, time.UTC)
+	return &v1alpha1.FlyteWorkflow{
+		ObjectMeta: v1.ObjectMeta{Namespace: "ns", Name: "wf"},
+		WorkflowSpec: &v1alpha1.WorkflowSpec{
+			ID: "wf",
+			Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+				v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+				"n1":                 {ID: "n1", Kind: v1alpha1.NodeKindTask},
+				"sw": {ID: "sw", Kind: v1alpha1.NodeKindWorkflow,
+					WorkflowNode: &v1alpha1.WorkflowNodeSpec{SubWorkflowReference: &subWorkflowID}},
+				"b1": {ID: "b1", Kind: v1alpha1.NodeKindBranch, BranchNode: &v1alpha1.BranchNodeSpec{
+					If: v1alpha1.IfBlock{ThenNode: &thenNode},
+				}},
+				thenNode:           {ID: thenNode, Kind: v1alpha1.NodeKindTask},
+				v1alpha1.EndNodeID: {ID: v1alpha1.EndNodeID, Kind: v1alpha1.NodeKindEnd},
+			},
+			Connections: v1alpha1.Connections{
+				DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+					v1alpha1.StartNodeID: {"n1"},
+					"n1":                 {"sw", "b1"},
+					"sw":                 {v1alpha1.EndNodeID},
+					"b1":                 {v1alpha1.EndNodeID},
+				},
+			},
+		},
+		SubWorkflows: map[v1alpha1.WorkflowID]*v1alpha1.WorkflowSpec{
+			subWorkflowID: {
+				ID: subWorkflowID,
+				Nodes: map[v1alpha1.NodeID]*v1alpha1.NodeSpec{
+					v1alpha1.StartNodeID: {ID: v1alpha1.StartNodeID, Kind: v1alpha1.NodeKindStart},
+					"s1":                 {ID: "s1", Kind: v1alpha1.NodeKindTask},
+					v1alpha1.EndNodeID:   {ID: v1alpha1.EndNodeID, Kind: v1alpha1.NodeKindEnd},
+				},
+				Connections: v1alpha1.Connections{
+					DownstreamEdges: map[v1alpha1.NodeID][]v1alpha1.NodeID{
+						v1alpha1.StartNodeID: {"s1"},
+						"s1":                 {v1alpha1.EndNodeID},
+					},
+				},
+			},
+		},
+		Status: v1alpha1.WorkflowStatus{
+			Phase: v1alpha1.WorkflowPhaseFailing,
+			NodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+				v1alpha1.StartNodeID: {Phase: v1alpha1.NodePhaseSucceeded},
+				"n1": {
+					Phase:     v1alpha1.NodePhaseSucceeded,
+					Attempts:  2,
+					StartedAt: &v1.Time{Time: startedAt},
+					StoppedAt: &v1.Time{Time: startedAt.Add(90 * time.Second)},
+					SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+						"dn0": {Phase: v1alpha1.NodePhaseSucceeded},
+					},
+				},
+				"sw": {Phase: v1alpha1.NodePhaseFailed, SubNodeStatus: map[v1alpha1.NodeID]*v1alpha1.NodeStatus{
+					"s1": {Phase: v1alpha1.NodePhaseFailed, Error: &v1alpha1.ExecutionError{ExecutionError: &core.ExecutionError{
+						Code: "USER:Failed", Message: "<b>s1 failed</b>",
+					}}},
+				}},
+				"b1":     {Phase: v1alpha1.NodePhaseRunning},
+				thenNode: {Phase: v1alpha1.NodePhaseRunning},
+			},
+		},
+	}
+}
+
+func TestRankNodes(t *testing.T) {
+	w := newHTMLTestWorkflow()
+	w.DataReferenceConstructor = storage.URLPathConstructor{}
+	entries, err := rankNodes(context.TODO(), w, w)
+	assert.NoError(t, err)
+	ranks := map[v1alpha1.NodeID]int{}
+	for _, e := range entries {
+		ranks[e.id] = e.rank
+	}
+	// Branch nodes are not connected, they are part of the cluster of the branch
+	assert.Equal(t, map[v1alpha1.NodeID]int{
+		v1alpha1.StartNodeID: 0,
+		"n1":                 1,
+		"sw":                 2,
+		"b1":                 2,
+		v1alpha1.EndNodeID:   3,
+	}, ranks)
+}
+
+func TestWorkflowToHTML(t *testing.T) {
+	page, err := WorkflowToHTML(context.TODO(), newHTMLTestWorkflow())
+	assert.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assert.Contains(t, page, "<p>Phase: Failing</p>")
+	assert.Contains(t, page, ">TimedOut</span>")
+
+	// Nodes are colored by phase, with a tooltip
+	assert.Contains(t, page, `fill="#81c784"`)
+	assert.Contains(t, page, "<title>n1 (task)\nPhase: Succeeded\nAttempts: 2\nDuration: 1m30s</title>")
+	// Errors are escaped
+	assert.Contains(t, page, "Error: [USER:Failed] &lt;b&gt;s1 failed&lt;/b&gt;")
+	assert.NotContains(t, page, "<b>s1 failed</b>")
+
+	// Nested nodes are rendered as clusters, only clusters of nodes that did not succeed are open
+	assert.Contains(t, page, `<details id="wf/n1" style="border-color: #81c784">`)
+	assert.Contains(t, page, `<details id="wf/sw" style="border-color: #e57373" open>`)
+	assert.Contains(t, page, "<summary>SubWorkflow [sub] of [sw]</summary>")
+	assert.Contains(t, page, `<details id="wf/b1" style="border-color: #fff176" open>`)
+	assert.Contains(t, page, "b1-n0 (task)")
+	assert.Contains(t, page, `<a href="#wf%2fsw">`)
+
+	// start -> n1 -> (sw, b1) -> end, and start -> s1 -> end in the sub workflow
+	assert.Equal(t, 7, strings.Count(page, "<line "))
+}